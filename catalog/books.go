@@ -0,0 +1,215 @@
+// Package catalog queries books for exports and feeds that are served
+// outside of the gRPC services.
+package catalog
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"go-grpc/helpers"
+	pb "go-grpc/pb/library"
+
+	"gorm.io/gorm"
+)
+
+// Review statuses, only approved reviews are shown and rated
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewHidden   = "hidden"
+)
+
+// Filter selects books, the zero value is every book that is not deleted
+type Filter struct {
+	IDs            []int32
+	Search         string
+	CategoryID     int32
+	AuthorID       int32 // books the author contributed to in any role
+	IncludeDeleted bool
+	Newest         bool          // latest additions first instead of by id
+	Where          string        // an extra condition over the columns of Query, e.g. a translated CQL query
+	Args           []interface{} // arguments of Where
+	Limit          int
+	Offset         int
+}
+
+// Query joins books with their first author and category, rows are read with
+// ScanBook. Contributors are loaded separately with Contributors.
+func Query(db *gorm.DB) *gorm.DB {
+	return db.Table("books as b").
+		Joins("LEFT JOIN authors au on au.id = b.author_id").
+		Joins("LEFT JOIN categories c on c.id = b.category_id").
+		Select("b.id, b.title,b.publication_year, b.description, COALESCE(b.deleted_at, ''), COALESCE(b.isbn, ''), au.id, au.name, au.bio, c.id, c.name category_name, c.description")
+}
+
+// Search matches books by title, the name of any contributor or ISBN
+func Search(sql *gorm.DB, search string) *gorm.DB {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return sql
+	}
+
+	pattern := helpers.Contains(search)
+	contributor := ByContributor("ca.name LIKE ?")
+	isbn := helpers.NormalizeISBN(search)
+	if len(isbn) != 10 && len(isbn) != 13 {
+		return sql.Where("(b.title LIKE ? OR "+contributor+")", pattern, pattern)
+	}
+
+	return sql.Where("(b.title LIKE ? OR "+contributor+" OR b.isbn IN ?)", pattern, pattern, []string{helpers.ISBN13(isbn), helpers.ISBN10(isbn)})
+}
+
+// Scanner is implemented by *sql.Row and *sql.Rows
+type Scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// ScanBook reads a row selected by Query
+func ScanBook(row Scanner) (*pb.Book, error) {
+	var book pb.Book
+	var author pb.Author
+	var category pb.Category
+
+	if err := row.Scan(&book.Id, &book.Title, &book.PublicationYear, &book.Description, &book.DeletedAt, &book.Isbn, &author.Id, &author.Name, &author.Bio, &category.Id, &category.Name, &category.Description); err != nil {
+		return nil, err
+	}
+
+	book.Author = &author
+	book.Category = &category
+
+	return &book, nil
+}
+
+func (f Filter) apply(sql *gorm.DB) *gorm.DB {
+	sql = Search(sql, f.Search)
+
+	if len(f.IDs) > 0 {
+		sql = sql.Where("b.id IN ?", f.IDs)
+	}
+	if f.CategoryID > 0 {
+		sql = sql.Where("b.category_id = ?", f.CategoryID)
+	}
+	if f.AuthorID > 0 {
+		sql = sql.Where("b.id IN (SELECT book_id FROM book_contributors WHERE author_id = ?)", f.AuthorID)
+	}
+	if f.Where != "" {
+		sql = sql.Where(f.Where, f.Args...)
+	}
+	if !f.IncludeDeleted {
+		sql = sql.Where("b.deleted_at IS NULL")
+	}
+	return sql
+}
+
+// Books returns the books matching the filter ordered by id, with their contributors
+func Books(db *gorm.DB, f Filter) ([]*pb.Book, error) {
+	sql := f.apply(Query(db))
+
+	if f.Limit > 0 {
+		sql = sql.Limit(f.Limit).Offset(f.Offset)
+	}
+
+	if f.Newest {
+		sql = sql.Order("b.created_at DESC, b.id DESC")
+	} else {
+		sql = sql.Order("b.id")
+	}
+
+	rows, err := sql.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var books []*pb.Book
+	for rows.Next() {
+		book, err := ScanBook(rows)
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return books, Contributors(db, books)
+}
+
+// Count returns how many books match the filter, Limit and Offset are ignored
+func Count(db *gorm.DB, f Filter) (int64, error) {
+	var total int64
+	err := f.apply(Query(db)).Count(&total).Error
+	return total, err
+}
+
+// Updated returns when the books were last changed, read as unix seconds so
+// the session time zone of the connection does not matter
+func Updated(db *gorm.DB, ids []int32) (map[int32]time.Time, error) {
+	updated := map[int32]time.Time{}
+	if len(ids) == 0 {
+		return updated, nil
+	}
+
+	rows, err := db.Table("books").
+		Select("id, COALESCE(UNIX_TIMESTAMP(COALESCE(updated_at, created_at)), 0)").
+		Where("id IN ?", ids).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int32
+		var unix int64
+		if err := rows.Scan(&id, &unix); err != nil {
+			return nil, err
+		}
+		updated[id] = time.Unix(unix, 0).UTC()
+	}
+
+	return updated, rows.Err()
+}
+
+// Ratings fills in the average rating and rating count of the books from
+// their approved reviews
+func Ratings(db *gorm.DB, books []*pb.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	byID := map[int32][]*pb.Book{}
+	ids := make([]int32, 0, len(books))
+	for _, book := range books {
+		if _, ok := byID[book.Id]; !ok {
+			ids = append(ids, book.Id)
+		}
+		byID[book.Id] = append(byID[book.Id], book)
+	}
+
+	rows, err := db.Table("book_reviews").
+		Select("book_id, AVG(rating), COUNT(*)").
+		Where("book_id IN ? AND status = ?", ids, ReviewApproved).
+		Group("book_id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, count int32
+		var average float64
+		if err := rows.Scan(&id, &average, &count); err != nil {
+			return err
+		}
+		for _, book := range byID[id] {
+			book.AverageRating = math.Round(average*100) / 100
+			book.RatingCount = count
+		}
+	}
+
+	return rows.Err()
+}
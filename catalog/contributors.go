@@ -0,0 +1,152 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+
+	"go-grpc/model"
+	pb "go-grpc/pb/library"
+
+	"gorm.io/gorm"
+)
+
+// Contributor roles, a book has at least one author and the first of them
+// is also kept in books.author_id as the Author of the book
+const (
+	RoleAuthor      = "author"
+	RoleEditor      = "editor"
+	RoleTranslator  = "translator"
+	RoleIllustrator = "illustrator"
+)
+
+var Roles = []string{RoleAuthor, RoleEditor, RoleTranslator, RoleIllustrator}
+
+// Contributor credits an author with a role on a book
+type Contributor struct {
+	AuthorID int32
+	Role     string
+}
+
+// ByContributor turns a condition over contributor names ca.name and roles
+// bc.role into a condition over books b, a book matches when any of its
+// contributors does
+func ByContributor(condition string) string {
+	return "b.id IN (SELECT bc.book_id FROM book_contributors bc JOIN authors ca ON ca.id = bc.author_id WHERE " + condition + ")"
+}
+
+// CheckContributors fills in the default role and makes sure there is an
+// author and nobody is credited twice with the same role
+func CheckContributors(contributors []Contributor) ([]Contributor, error) {
+	checked := make([]Contributor, 0, len(contributors))
+	seen := map[Contributor]bool{}
+	authors := 0
+
+	for _, c := range contributors {
+		if c.AuthorID <= 0 {
+			return nil, errors.New("contributor author_id is required")
+		}
+		if c.Role == "" {
+			c.Role = RoleAuthor
+		}
+		if !validRole(c.Role) {
+			return nil, fmt.Errorf("unknown contributor role %q, use one of %v", c.Role, Roles)
+		}
+		if seen[c] {
+			return nil, fmt.Errorf("author %d is listed twice as %s", c.AuthorID, c.Role)
+		}
+		seen[c] = true
+
+		if c.Role == RoleAuthor {
+			authors++
+		}
+		checked = append(checked, c)
+	}
+
+	if authors == 0 {
+		return nil, errors.New("at least one contributor must be an author")
+	}
+
+	return checked, nil
+}
+
+func validRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// FirstAuthor is the author kept in books.author_id
+func FirstAuthor(contributors []Contributor) int32 {
+	for _, c := range contributors {
+		if c.Role == RoleAuthor {
+			return c.AuthorID
+		}
+	}
+	return 0
+}
+
+// SetContributors replaces the contributors of a book in the given order,
+// call it with contributors that passed CheckContributors
+func SetContributors(tx *gorm.DB, bookID int32, contributors []Contributor) error {
+	if err := tx.Where("book_id = ?", bookID).Delete(&model.BookContributor{}).Error; err != nil {
+		return err
+	}
+
+	rows := make([]model.BookContributor, len(contributors))
+	for i, c := range contributors {
+		rows[i] = model.BookContributor{BookID: bookID, AuthorID: c.AuthorID, Role: c.Role, Position: int32(i + 1)}
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return err
+	}
+
+	// The record of the book changes with its contributors, bump its OAI-PMH datestamp
+	return tx.Table("books").Where("id = ?", bookID).Updates(map[string]interface{}{
+		"author_id":  FirstAuthor(contributors),
+		"updated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+	}).Error
+}
+
+// Contributors fills in the contributors of the books in order
+func Contributors(db *gorm.DB, books []*pb.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	byID := map[int32][]*pb.Book{}
+	ids := make([]int32, 0, len(books))
+	for _, book := range books {
+		if _, ok := byID[book.Id]; !ok {
+			ids = append(ids, book.Id)
+		}
+		byID[book.Id] = append(byID[book.Id], book)
+	}
+
+	rows, err := db.Table("book_contributors bc").
+		Joins("JOIN authors ca ON ca.id = bc.author_id").
+		Select("bc.book_id, bc.author_id, ca.name, bc.role, bc.position").
+		Where("bc.book_id IN ?", ids).
+		Order("bc.book_id, bc.position").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, authorID, position int32
+		var name, role string
+		if err := rows.Scan(&id, &authorID, &name, &role, &position); err != nil {
+			return err
+		}
+		for _, book := range byID[id] {
+			book.Contributors = append(book.Contributors, &pb.Contributor{AuthorId: authorID, Name: name, Role: role, Position: position})
+		}
+	}
+
+	return rows.Err()
+}
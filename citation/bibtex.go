@@ -0,0 +1,81 @@
+package citation
+
+import (
+	"fmt"
+	"strings"
+)
+
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// BibTeX renders @book entries, names are joined with "and" and the title
+// is braced so styles keep its capitalisation
+func BibTeX(entries []Entry) string {
+	var b strings.Builder
+	keys := keys{}
+
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		fmt.Fprintf(&b, "@book{%s,\n", keys.next(e))
+
+		// translator is a biblatex field, classic styles skip it
+		for _, names := range []struct {
+			field string
+			names []string
+		}{{"author", e.Authors}, {"editor", e.Editors}, {"translator", e.Translators}} {
+			if len(names.names) > 0 {
+				bibtexField(&b, names.field, bibtexNames(names.names))
+			}
+		}
+
+		bibtexField(&b, "title", "{"+bibtexEscaper.Replace(oneLine(e.Title))+"}")
+		if e.Year > 0 {
+			bibtexField(&b, "year", fmt.Sprint(e.Year))
+		}
+		if e.ISBN != "" {
+			bibtexField(&b, "isbn", e.ISBN)
+		}
+		if len(e.Subjects) > 0 {
+			bibtexField(&b, "keywords", bibtexEscaper.Replace(strings.Join(e.Subjects, ", ")))
+		}
+		if e.Description != "" {
+			bibtexField(&b, "abstract", bibtexEscaper.Replace(oneLine(e.Description)))
+		}
+
+		b.WriteString("}\n")
+	}
+
+	return b.String()
+}
+
+// bibtexNames joins names with "and"
+func bibtexNames(names []string) string {
+	escaped := make([]string, len(names))
+	for i, n := range names {
+		name := ParseName(n)
+		if name.Literal != "" {
+			// Braces stop BibTeX from splitting an organisation's name
+			escaped[i] = "{" + bibtexEscaper.Replace(name.Literal) + "}"
+		} else {
+			escaped[i] = bibtexEscaper.Replace(name.Inverted())
+		}
+	}
+	return strings.Join(escaped, " and ")
+}
+
+func bibtexField(b *strings.Builder, name, value string) {
+	fmt.Fprintf(b, "  %s = {%s},\n", name, value)
+}
@@ -0,0 +1,78 @@
+package citation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type cslItem struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Title       string    `json:"title"`
+	Author      []cslName `json:"author,omitempty"`
+	Editor      []cslName `json:"editor,omitempty"`
+	Translator  []cslName `json:"translator,omitempty"`
+	Illustrator []cslName `json:"illustrator,omitempty"`
+	Issued      *cslDate  `json:"issued,omitempty"`
+	ISBN        string    `json:"ISBN,omitempty"`
+	Abstract    string    `json:"abstract,omitempty"`
+	Keyword     string    `json:"keyword,omitempty"`
+}
+
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int32 `json:"date-parts"`
+}
+
+// CSLJSON renders the array of items read by citeproc processors
+func CSLJSON(entries []Entry) (string, error) {
+	items := make([]cslItem, 0, len(entries))
+	keys := keys{}
+
+	for _, e := range entries {
+		item := cslItem{
+			ID:       keys.next(e),
+			Type:     "book",
+			Title:    e.Title,
+			ISBN:     e.ISBN,
+			Abstract: e.Description,
+			Keyword:  strings.Join(e.Subjects, ", "),
+		}
+
+		item.Author = cslNames(e.Authors)
+		item.Editor = cslNames(e.Editors)
+		item.Translator = cslNames(e.Translators)
+		item.Illustrator = cslNames(e.Illustrators)
+
+		if e.Year > 0 {
+			item.Issued = &cslDate{DateParts: [][]int32{{e.Year}}}
+		}
+
+		items = append(items, item)
+	}
+
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(items); err != nil {
+		return "", fmt.Errorf("failed to encode CSL-JSON: %w", err)
+	}
+	return b.String(), nil
+}
+
+func cslNames(names []string) []cslName {
+	var csl []cslName
+	for _, n := range names {
+		name := ParseName(n)
+		csl = append(csl, cslName{Family: name.Family, Given: name.Given, Literal: name.Literal})
+	}
+	return csl
+}
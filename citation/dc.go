@@ -0,0 +1,79 @@
+package citation
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Namespaces of simple Dublin Core as used by OAI-PMH and SRU
+const (
+	NamespaceOAIDC = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	NamespaceSRWDC = "info:srw/schema/1/dc-schema"
+	NamespaceDC    = "http://purl.org/dc/elements/1.1/"
+)
+
+// DublinCore renders a collection of oai_dc records
+func DublinCore(entries []Entry) string {
+	var b strings.Builder
+
+	b.WriteString(xml.Header)
+	b.WriteString("<collection>\n")
+	for _, e := range entries {
+		b.WriteString(DublinCoreRecord(e, "  "))
+	}
+	b.WriteString("</collection>\n")
+
+	return b.String()
+}
+
+// DublinCoreRecord renders one oai_dc:dc element that declares its own
+// namespaces, so it can be embedded in other documents
+func DublinCoreRecord(e Entry, indent string) string {
+	return dublinCore(e, indent, "oai_dc", NamespaceOAIDC)
+}
+
+// SRWDublinCoreRecord renders one srw_dc:dc element, the Dublin Core schema of SRU
+func SRWDublinCoreRecord(e Entry, indent string) string {
+	return dublinCore(e, indent, "srw_dc", NamespaceSRWDC)
+}
+
+func dublinCore(e Entry, indent, prefix, namespace string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s<%s:dc xmlns:%s=%q xmlns:dc=%q>\n", indent, prefix, prefix, namespace, NamespaceDC)
+
+	element := func(name, value string) {
+		if value == "" {
+			return
+		}
+		fmt.Fprintf(&b, "%s  <dc:%s>", indent, name)
+		xml.EscapeText(&b, []byte(value))
+		fmt.Fprintf(&b, "</dc:%s>\n", name)
+	}
+
+	element("title", e.Title)
+	for _, author := range e.Authors {
+		element("creator", author)
+	}
+	for _, names := range [][]string{e.Editors, e.Translators, e.Illustrators} {
+		for _, name := range names {
+			element("contributor", name)
+		}
+	}
+	for _, subject := range e.Subjects {
+		element("subject", subject)
+	}
+	element("description", e.Description)
+	if e.Year > 0 {
+		element("date", fmt.Sprint(e.Year))
+	}
+	element("type", "Text")
+	if e.ISBN != "" {
+		element("identifier", "urn:isbn:"+e.ISBN)
+	}
+
+	fmt.Fprintf(&b, "%s</%s:dc>\n", indent, prefix)
+
+	return b.String()
+}
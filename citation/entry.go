@@ -0,0 +1,152 @@
+// Package citation renders catalog books as citations: BibTeX, RIS,
+// CSL-JSON and Dublin Core.
+package citation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"go-grpc/catalog"
+	pb "go-grpc/pb/library"
+)
+
+// Entry is the bibliographic data of one book
+type Entry struct {
+	ID           int32
+	Title        string
+	Authors      []string
+	Editors      []string
+	Translators  []string
+	Illustrators []string
+	Year         int32
+	ISBN         string
+	Description  string
+	Subjects     []string
+}
+
+// FromBook takes the contributors, or the joined author, and the category of a book
+func FromBook(book *pb.Book) Entry {
+	entry := Entry{
+		ID:          book.Id,
+		Title:       book.Title,
+		Year:        book.PublicationYear,
+		ISBN:        book.Isbn,
+		Description: book.Description,
+	}
+
+	for _, c := range book.Contributors {
+		switch c.Role {
+		case catalog.RoleEditor:
+			entry.Editors = append(entry.Editors, c.Name)
+		case catalog.RoleTranslator:
+			entry.Translators = append(entry.Translators, c.Name)
+		case catalog.RoleIllustrator:
+			entry.Illustrators = append(entry.Illustrators, c.Name)
+		default:
+			entry.Authors = append(entry.Authors, c.Name)
+		}
+	}
+	if len(book.Contributors) == 0 && book.Author != nil && book.Author.Name != "" {
+		entry.Authors = append(entry.Authors, book.Author.Name)
+	}
+	if book.Category != nil && book.Category.Name != "" {
+		entry.Subjects = append(entry.Subjects, book.Category.Name)
+	}
+
+	return entry
+}
+
+// Name is a personal name split for citation styles, single word names
+// (often organisations) only have Literal
+type Name struct {
+	Family  string
+	Given   string
+	Literal string
+}
+
+// ParseName reads "Family, Given" as well as "Given Family"
+func ParseName(name string) Name {
+	name = strings.Join(strings.Fields(name), " ")
+
+	if family, given, ok := strings.Cut(name, ","); ok {
+		return Name{Family: strings.TrimSpace(family), Given: strings.TrimSpace(given)}
+	}
+
+	i := strings.LastIndex(name, " ")
+	if i < 0 {
+		return Name{Literal: name}
+	}
+	return Name{Family: name[i+1:], Given: name[:i]}
+}
+
+// Inverted is the "Family, Given" form used by BibTeX and RIS
+func (n Name) Inverted() string {
+	switch {
+	case n.Literal != "":
+		return n.Literal
+	case n.Given == "":
+		return n.Family
+	default:
+		return n.Family + ", " + n.Given
+	}
+}
+
+// keys hands out BibTeX keys like knuth1984texbook, unique within one export
+type keys map[string]int
+
+func (k keys) next(e Entry) string {
+	// Edited books without an author go by their first editor
+	names := e.Authors
+	if len(names) == 0 {
+		names = e.Editors
+	}
+
+	author := "anon"
+	if len(names) > 0 {
+		n := ParseName(names[0])
+		author = n.Family + n.Literal
+	}
+
+	var word string
+	for _, w := range strings.Fields(e.Title) {
+		if w = asciiLower(w); w != "" && !stopWords[w] {
+			word = w
+			break
+		}
+	}
+
+	key := asciiLower(author)
+	if key == "" {
+		key = "anon"
+	}
+	if e.Year > 0 {
+		key += fmt.Sprint(e.Year)
+	}
+	key += word
+
+	k[key]++
+	if n := k[key]; n > 1 {
+		// second and later books with the same key get b, c, ...
+		key += string(rune('a' + (n-1)%26))
+	}
+	return key
+}
+
+var stopWords = map[string]bool{"a": true, "an": true, "the": true, "on": true, "of": true}
+
+// asciiLower keeps the ASCII letters and digits of s, lower cased
+func asciiLower(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// oneLine folds line breaks, which end a tag in RIS
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
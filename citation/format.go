@@ -0,0 +1,57 @@
+package citation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported formats
+const (
+	FormatBibTeX  = "bibtex"
+	FormatRIS     = "ris"
+	FormatCSLJSON = "csl-json"
+	FormatDC      = "dc"
+)
+
+// ContentTypes of the formats
+var ContentTypes = map[string]string{
+	FormatBibTeX:  "application/x-bibtex",
+	FormatRIS:     "application/x-research-info-systems",
+	FormatCSLJSON: "application/vnd.citationstyles.csl+json",
+	FormatDC:      "application/xml",
+}
+
+// ParseFormat accepts the format names and common aliases, bibtex by default
+func ParseFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "", "bibtex", "bib":
+		return FormatBibTeX, nil
+	case "ris":
+		return FormatRIS, nil
+	case "csl-json", "csljson", "csl", "json":
+		return FormatCSLJSON, nil
+	case "dc", "dublin-core", "dublincore", "xml":
+		return FormatDC, nil
+	default:
+		return "", fmt.Errorf("unsupported citation format %q, use bibtex, ris, csl-json or dc", format)
+	}
+}
+
+// Render writes the entries in the format
+func Render(format string, entries []Entry) (string, error) {
+	format, err := ParseFormat(format)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case FormatRIS:
+		return RIS(entries), nil
+	case FormatCSLJSON:
+		return CSLJSON(entries)
+	case FormatDC:
+		return DublinCore(entries), nil
+	default:
+		return BibTeX(entries), nil
+	}
+}
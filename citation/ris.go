@@ -0,0 +1,44 @@
+package citation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RIS renders BOOK records, one AU line per author, ED per editor and A4
+// per translator. Lines end in CRLF as the format asks for.
+func RIS(entries []Entry) string {
+	var b strings.Builder
+
+	for _, e := range entries {
+		risTag(&b, "TY", "BOOK")
+		risTag(&b, "ID", fmt.Sprint(e.ID))
+		for _, author := range e.Authors {
+			risTag(&b, "AU", ParseName(author).Inverted())
+		}
+		for _, editor := range e.Editors {
+			risTag(&b, "ED", ParseName(editor).Inverted())
+		}
+		for _, translator := range e.Translators {
+			risTag(&b, "A4", ParseName(translator).Inverted())
+		}
+		risTag(&b, "TI", e.Title)
+		if e.Year > 0 {
+			risTag(&b, "PY", fmt.Sprint(e.Year))
+		}
+		risTag(&b, "SN", e.ISBN)
+		for _, subject := range e.Subjects {
+			risTag(&b, "KW", subject)
+		}
+		risTag(&b, "AB", e.Description)
+		b.WriteString("ER  - \r\n\r\n")
+	}
+
+	return b.String()
+}
+
+func risTag(b *strings.Builder, tag, value string) {
+	if value = oneLine(value); value != "" {
+		fmt.Fprintf(b, "%s  - %s\r\n", tag, value)
+	}
+}
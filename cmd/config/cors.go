@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-grpc/gateway"
+)
+
+// CORS reads the browser origins allowed to call the API from CORS_ALLOWED_ORIGINS
+// (comma separated, "*" for any), CORS_ALLOW_CREDENTIALS and CORS_MAX_AGE (seconds).
+// No origin is allowed when CORS_ALLOWED_ORIGINS is not set. Credentials are
+// only allowed for origins listed by name, never through "*".
+func CORS() gateway.CORS {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	maxAge, err := strconv.Atoi(os.Getenv("CORS_MAX_AGE"))
+	if err != nil {
+		maxAge = 600
+	}
+
+	credentials, _ := strconv.ParseBool(os.Getenv("CORS_ALLOW_CREDENTIALS"))
+
+	return gateway.CORS{
+		AllowedOrigins:   origins,
+		AllowCredentials: credentials,
+		MaxAge:           time.Duration(maxAge) * time.Second,
+	}
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"math"
+	"os"
+	"strconv"
+)
+
+// FinePerDay reads FINE_PER_DAY, the fine charged for every started day a
+// book is returned late, in the currency of the fine_amount columns. Amounts
+// are rounded to cents, the default is 1000.
+func FinePerDay() float64 {
+	fine, err := strconv.ParseFloat(os.Getenv("FINE_PER_DAY"), 64)
+	if err != nil || fine < 0 || math.IsInf(fine, 0) || math.IsNaN(fine) {
+		return 1000
+	}
+	return math.Round(fine*100) / 100
+}
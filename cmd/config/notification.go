@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"strconv"
+
+	"go-grpc/notification"
+)
+
+// Mailer returns the SMTP mailer configured with SMTP_HOST, SMTP_PORT,
+// SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM, or nil when SMTP_HOST is not set.
+func Mailer() *notification.SMTPMailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil
+	}
+
+	port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil {
+		port = 587
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "library@localhost"
+	}
+
+	return &notification.SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}
+}
+
+// SMSProvider returns the SMS gateway configured with SMS_GATEWAY_URL and
+// SMS_GATEWAY_TOKEN, or nil when SMS_GATEWAY_URL is not set.
+func SMSProvider() notification.SMSProvider {
+	url := os.Getenv("SMS_GATEWAY_URL")
+	if url == "" {
+		return nil
+	}
+
+	return &notification.HTTPSMSProvider{
+		URL:   url,
+		Token: os.Getenv("SMS_GATEWAY_TOKEN"),
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"os"
+
+	"go-grpc/oai"
+
+	"gorm.io/gorm"
+)
+
+// OAI reads the OAI-PMH repository description from OAI_REPOSITORY_NAME,
+// OAI_ADMIN_EMAIL, OAI_REPOSITORY_IDENTIFIER (a domain name, used in record
+// identifiers) and OAI_BASE_URL (taken from the request when not set).
+func OAI(db *gorm.DB) *oai.Provider {
+	return &oai.Provider{
+		DB:           db,
+		Name:         envOr("OAI_REPOSITORY_NAME", "Library Catalog"),
+		AdminEmail:   envOr("OAI_ADMIN_EMAIL", "admin@library.local"),
+		RepositoryID: envOr("OAI_REPOSITORY_IDENTIFIER", "library.local"),
+		BaseURL:      os.Getenv("OAI_BASE_URL"),
+	}
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"os"
+
+	"go-grpc/opds"
+
+	"gorm.io/gorm"
+)
+
+// OPDS reads the catalog title from OPDS_TITLE and the scheme and host used
+// in feed links from OPDS_BASE_URL (taken from the request when not set).
+func OPDS(db *gorm.DB) *opds.Server {
+	return opds.New(db, envOr("OPDS_TITLE", "Library Catalog"), os.Getenv("OPDS_BASE_URL"))
+}
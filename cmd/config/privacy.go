@@ -0,0 +1,17 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// HistoryRetentionDays reads HISTORY_RETENTION_DAYS, how long returned loans
+// of borrowers who opted out of a reading history are kept before they are
+// anonymized. The default of 30 days leaves time to settle fines and disputes.
+func HistoryRetentionDays() int32 {
+	days, err := strconv.Atoi(os.Getenv("HISTORY_RETENTION_DAYS"))
+	if err != nil || days < 0 {
+		return 30
+	}
+	return int32(days)
+}
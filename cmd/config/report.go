@@ -0,0 +1,24 @@
+package config
+
+import (
+	"os"
+
+	"go-grpc/report"
+
+	"gorm.io/gorm"
+)
+
+// ReportScheduler delivers scheduled reports by email through the SMTP
+// mailer and as files into REPORT_DIR. Failed runs of schedules without an
+// alert address are reported to REPORT_ALERT_EMAIL.
+func ReportScheduler(db *gorm.DB) *report.Scheduler {
+	scheduler := report.NewScheduler(db)
+	scheduler.Dir = os.Getenv("REPORT_DIR")
+	scheduler.AlertEmail = os.Getenv("REPORT_ALERT_EMAIL")
+
+	if mailer := Mailer(); mailer != nil {
+		scheduler.Mailer = mailer
+	}
+
+	return scheduler
+}
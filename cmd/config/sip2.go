@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	pb "go-grpc/pb/library"
+	"go-grpc/sip2"
+
+	"gorm.io/gorm"
+)
+
+// SIP2 returns the kiosk server and the address it listens on from SIP2_ADDR
+// (e.g. ":6001"), nil when not set. SIP2_INSTITUTION is the institution id
+// kiosks are configured with, SIP2_LIBRARY_NAME is shown on their screen and
+// SIP2_LOAN_DAYS is the loan period of a checkout.
+func SIP2(db *gorm.DB, borrowing pb.BorrowingServiceServer, returning pb.ReturningServiceServer) (*sip2.Server, string) {
+	addr := os.Getenv("SIP2_ADDR")
+	if addr == "" {
+		return nil, ""
+	}
+
+	server := sip2.NewServer(db, borrowing, returning)
+	server.Institution = envOr("SIP2_INSTITUTION", server.Institution)
+	server.Name = envOr("SIP2_LIBRARY_NAME", server.Name)
+	if days, err := strconv.Atoi(os.Getenv("SIP2_LOAN_DAYS")); err == nil && days > 0 {
+		server.LoanPeriod = time.Duration(days) * 24 * time.Hour
+	}
+
+	return server, addr
+}
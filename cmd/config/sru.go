@@ -0,0 +1,19 @@
+package config
+
+import (
+	"os"
+
+	"go-grpc/sru"
+
+	"gorm.io/gorm"
+)
+
+// SRU reads the database title of the SRU endpoint from SRU_TITLE and its
+// public address from SRU_BASE_URL (taken from the request when not set).
+func SRU(db *gorm.DB) *sru.Server {
+	return &sru.Server{
+		DB:      db,
+		Title:   envOr("SRU_TITLE", "Library Catalog"),
+		BaseURL: os.Getenv("SRU_BASE_URL"),
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	pb "go-grpc/pb/library"
+)
+
+// account answers data subject requests for a borrower
+func account(a *app, args []string) error {
+	action, args, err := subcommand("account", args, "export", "erase")
+	if err != nil {
+		return err
+	}
+
+	flags := newFlags("account " + action)
+	borrower := flags.Int("borrower", 0, "borrower id")
+	format := flags.String("format", "", "export only: json or zip, guessed from the -out extension by default")
+	out := flags.String("out", "", "export only: file to write, named by the server by default")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *borrower == 0 {
+		return errors.New("-borrower is required")
+	}
+
+	client, err := a.accounts()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	if action == "erase" {
+		_, err := client.EraseAccount(ctx, &pb.EraseAccountRequest{BorrowerId: int32(*borrower)})
+		return done(err, "account erased")
+	}
+
+	if *format == "" && strings.HasSuffix(*out, ".zip") {
+		*format = "zip"
+	}
+
+	resp, err := client.ExportMyData(ctx, &pb.ExportMyDataRequest{BorrowerId: int32(*borrower), Format: *format})
+	if err != nil {
+		return err
+	}
+
+	path := *out
+	if path == "" {
+		path = resp.Filename
+	}
+	if err := os.WriteFile(path, resp.Data, 0o600); err != nil {
+		return err
+	}
+	return done(nil, fmt.Sprintf("data of borrower %d written to %s", *borrower, path))
+}
+
+// fine settles the fine of a returned loan
+func fine(a *app, args []string) error {
+	_, args, err := subcommand("fine", args, "pay")
+	if err != nil {
+		return err
+	}
+
+	flags := newFlags("fine pay")
+	loan := flags.Int("loan", 0, "loan (transaction) id")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *loan == 0 {
+		return errors.New("-loan is required")
+	}
+
+	client, err := a.returning()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	resp, err := client.PayFine(ctx, &pb.PayFineRequest{TransactionId: int32(*loan)})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.Message)
+	}
+	return done(nil, resp.Message)
+}
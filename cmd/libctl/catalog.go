@@ -0,0 +1,427 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+	"strings"
+
+	pb "go-grpc/pb/library"
+)
+
+var bookHeaders = []string{"id", "isbn", "title", "author", "category", "year", "rating", "deleted_at"}
+
+func bookRow(b *pb.Book) []string {
+	rating := ""
+	if b.RatingCount > 0 {
+		rating = strconv.FormatFloat(b.AverageRating, 'f', 1, 64) + " (" + itoa(b.RatingCount) + ")"
+	}
+	return []string{itoa(b.Id), b.Isbn, b.Title, credits(b), b.GetCategory().GetName(), itoa(b.PublicationYear), rating, b.DeletedAt}
+}
+
+// credits lists the contributors of a book, roles other than author in parentheses
+func credits(b *pb.Book) string {
+	if len(b.Contributors) == 0 {
+		return b.GetAuthor().GetName()
+	}
+	names := make([]string, len(b.Contributors))
+	for i, c := range b.Contributors {
+		names[i] = c.Name
+		if c.Role != "author" {
+			names[i] += " (" + c.Role + ")"
+		}
+	}
+	return strings.Join(names, "; ")
+}
+
+// parseContributors reads author ids with an optional role in order, e.g. "3,7:editor"
+func parseContributors(value string) ([]*pb.Contributor, error) {
+	var contributors []*pb.Contributor
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, role, _ := strings.Cut(part, ":")
+		n, err := strconv.Atoi(id)
+		if err != nil || n <= 0 {
+			return nil, errors.New("-contributors takes author ids with an optional role, e.g. 3,7:editor")
+		}
+		contributors = append(contributors, &pb.Contributor{AuthorId: int32(n), Role: role})
+	}
+	return contributors, nil
+}
+
+func books(a *app, args []string) error {
+	action, args, err := subcommand("books", args, "list", "get", "create", "update", "delete", "restore", "purge")
+	if err != nil {
+		return err
+	}
+
+	client, err := a.books()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	flags := newFlags("books " + action)
+
+	switch action {
+	case "list":
+		page, limit, deleted := pageFlags(flags)
+		search := flags.String("search", "", "title, author or ISBN")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		resp, err := client.ListBooks(ctx, &pb.ParameterReq{Page: *page, Limit: *limit, IncludeDeleted: *deleted, Search: *search})
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, b := range resp.Data {
+			rows = append(rows, bookRow(b))
+		}
+		return a.print(resp, bookHeaders, rows)
+
+	case "get":
+		id := flags.Int("id", 0, "book id")
+		isbn := flags.String("isbn", "", "ISBN or barcode instead of the id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		book, err := findBook(a, int32(*id), *isbn)
+		if err != nil {
+			return err
+		}
+		return a.print(&pb.BookResponse{Data: book}, bookHeaders, [][]string{bookRow(book)})
+
+	case "create":
+		title := flags.String("title", "", "title")
+		author := flags.Int("author", 0, "author id")
+		contributorList := flags.String("contributors", "", "author ids with roles in order instead of -author, e.g. 3,7:editor,9:translator")
+		category := flags.String("category", "", "category name, created when missing")
+		year := flags.Int("year", 0, "publication year")
+		description := flags.String("description", "", "description")
+		isbn := flags.String("isbn", "", "ISBN-10 or ISBN-13")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *title == "" || (*author == 0 && *contributorList == "") {
+			return errors.New("-title and -author or -contributors are required")
+		}
+		contributors, err := parseContributors(*contributorList)
+		if err != nil {
+			return err
+		}
+		_, err = client.CreateBook(ctx, &pb.CreateBookRequest{
+			Title:           *title,
+			AuthorId:        int32(*author),
+			Category:        &pb.Category{Name: *category},
+			PublicationYear: int32(*year),
+			Description:     *description,
+			Isbn:            *isbn,
+			Contributors:    contributors,
+		})
+		return done(err, "book created")
+
+	case "update":
+		id := flags.Int("id", 0, "book id")
+		title := flags.String("title", "", "title")
+		year := flags.Int("year", 0, "publication year")
+		description := flags.String("description", "", "description")
+		isbn := flags.String("isbn", "", "ISBN-10 or ISBN-13")
+		contributorList := flags.String("contributors", "", "replaces the contributors, author ids with roles in order, e.g. 3,7:editor")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return errors.New("-id is required")
+		}
+		contributors, err := parseContributors(*contributorList)
+		if err != nil {
+			return err
+		}
+		_, err = client.UpdateBook(ctx, &pb.BookUpdateReq{
+			Id:              int32(*id),
+			Title:           *title,
+			PublicationYear: int32(*year),
+			Description:     *description,
+			Isbn:            *isbn,
+			Contributors:    contributors,
+		})
+		return done(err, "book updated")
+
+	case "delete", "restore", "purge":
+		id := flags.Int("id", 0, "book id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return errors.New("-id is required")
+		}
+		req := &pb.BookRequest{Id: int32(*id)}
+		switch action {
+		case "delete":
+			_, err = client.DeleteBook(ctx, req)
+		case "restore":
+			_, err = client.RestoreBook(ctx, req)
+		default:
+			_, err = client.PurgeBook(ctx, req)
+		}
+		return done(err, "book "+action+"d")
+	}
+
+	return nil
+}
+
+// findBook loads a book by id or by ISBN, a scanned barcode is the ISBN-13
+func findBook(a *app, id int32, isbn string) (*pb.Book, error) {
+	if id == 0 && isbn == "" {
+		return nil, errors.New("-id or -isbn is required")
+	}
+
+	client, err := a.books()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	var resp *pb.BookResponse
+	if isbn != "" {
+		resp, err = client.GetBookByIsbn(ctx, &pb.IsbnRequest{Isbn: isbn})
+	} else {
+		resp, err = client.GetBook(ctx, &pb.BookRequest{Id: id})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+var authorHeaders = []string{"id", "name", "bio", "deleted_at"}
+
+func authorRow(au *pb.Author) []string {
+	return []string{itoa(au.Id), au.Name, au.Bio, au.DeletedAt}
+}
+
+func authors(a *app, args []string) error {
+	action, args, err := subcommand("authors", args, "list", "get", "create", "update", "delete", "restore", "purge")
+	if err != nil {
+		return err
+	}
+
+	client, err := a.authors()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	flags := newFlags("authors " + action)
+
+	switch action {
+	case "list":
+		page, limit, deleted := pageFlags(flags)
+		search := flags.String("search", "", "part of the name")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		resp, err := client.ListAuthors(ctx, &pb.ParameterReq{Page: *page, Limit: *limit, IncludeDeleted: *deleted, Search: *search})
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, au := range resp.Data {
+			rows = append(rows, authorRow(au))
+		}
+		return a.print(resp, authorHeaders, rows)
+
+	case "get":
+		id := flags.Int("id", 0, "author id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		resp, err := client.GetAuthor(ctx, &pb.IdRequest{Id: int32(*id)})
+		if err != nil {
+			return err
+		}
+		return a.print(resp, authorHeaders, [][]string{authorRow(resp.Data)})
+
+	case "create", "update":
+		id := flags.Int("id", 0, "author id, update only")
+		name := flags.String("name", "", "name")
+		bio := flags.String("bio", "", "biography")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		author := &pb.Author{Id: int32(*id), Name: *name, Bio: *bio}
+
+		var resp *pb.AuthorResponse
+		if action == "create" {
+			if *name == "" {
+				return errors.New("-name is required")
+			}
+			resp, err = client.CreateAuthor(ctx, author)
+		} else {
+			if *id == 0 {
+				return errors.New("-id is required")
+			}
+			resp, err = client.UpdateAuthor(ctx, author)
+		}
+		if err != nil {
+			return err
+		}
+		return a.print(resp, authorHeaders, [][]string{authorRow(resp.GetData())})
+
+	case "delete", "restore", "purge":
+		id := flags.Int("id", 0, "author id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return errors.New("-id is required")
+		}
+		req := &pb.IdRequest{Id: int32(*id)}
+		switch action {
+		case "delete":
+			_, err = client.DeleteAuthor(ctx, req)
+		case "restore":
+			_, err = client.RestoreAuthor(ctx, req)
+		default:
+			_, err = client.PurgeAuthor(ctx, req)
+		}
+		return done(err, "author "+action+"d")
+	}
+
+	return nil
+}
+
+var categoryHeaders = []string{"id", "name", "description", "deleted_at"}
+
+func categoryRow(c *pb.Category) []string {
+	return []string{itoa(c.Id), c.Name, c.Description, c.DeletedAt}
+}
+
+func categories(a *app, args []string) error {
+	action, args, err := subcommand("categories", args, "list", "get", "create", "update", "delete", "restore", "purge")
+	if err != nil {
+		return err
+	}
+
+	client, err := a.categories()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	flags := newFlags("categories " + action)
+
+	switch action {
+	case "list":
+		page, limit, deleted := pageFlags(flags)
+		search := flags.String("search", "", "part of the name")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		resp, err := client.ListCategories(ctx, &pb.ParameterReq{Page: *page, Limit: *limit, IncludeDeleted: *deleted, Search: *search})
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, c := range resp.Data {
+			rows = append(rows, categoryRow(c))
+		}
+		return a.print(resp, categoryHeaders, rows)
+
+	case "get":
+		id := flags.Int("id", 0, "category id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		resp, err := client.GetCategory(ctx, &pb.IdRequest{Id: int32(*id)})
+		if err != nil {
+			return err
+		}
+		return a.print(resp, categoryHeaders, [][]string{categoryRow(resp.Data)})
+
+	case "create", "update":
+		id := flags.Int("id", 0, "category id, update only")
+		name := flags.String("name", "", "name")
+		description := flags.String("description", "", "description")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		req := &pb.CategoryRequest{Name: *name, Description: *description}
+
+		var resp *pb.CategoryResponse
+		if action == "create" {
+			if *name == "" {
+				return errors.New("-name is required")
+			}
+			resp, err = client.CreateCategory(ctx, req)
+		} else {
+			if *id == 0 {
+				return errors.New("-id is required")
+			}
+			categoryID := int32(*id)
+			req.Id = &categoryID
+			resp, err = client.UpdateCategory(ctx, req)
+		}
+		if err != nil {
+			return err
+		}
+		return a.print(resp, categoryHeaders, [][]string{categoryRow(resp.GetData())})
+
+	case "delete", "restore", "purge":
+		id := flags.Int("id", 0, "category id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return errors.New("-id is required")
+		}
+		req := &pb.IdRequest{Id: int32(*id)}
+		switch action {
+		case "delete":
+			_, err = client.DeleteCategory(ctx, req)
+		case "restore":
+			_, err = client.RestoreCategory(ctx, req)
+		default:
+			_, err = client.PurgeCategory(ctx, req)
+		}
+		return done(err, "category "+action+"d")
+	}
+
+	return nil
+}
+
+func pageFlags(flags *flag.FlagSet) (*int64, *int64, *bool) {
+	page := flags.Int64("page", 1, "page number")
+	limit := flags.Int64("limit", 20, "rows per page")
+	deleted := flags.Bool("deleted", false, "include deleted rows (admin only)")
+	return page, limit, deleted
+}
+
+// parseIDs reads a comma separated list of ids such as "1,2,5"
+func parseIDs(list string) ([]int32, error) {
+	var ids []int32
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, errors.New("-id must be a list of numbers")
+		}
+		ids = append(ids, int32(n))
+	}
+	return ids, nil
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go-grpc/helpers"
+	pb "go-grpc/pb/library"
+)
+
+var stockHeaders = []string{"book_id", "isbn", "title", "total_stock"}
+
+var movementHeaders = []string{"id", "book_id", "delta", "reason", "note", "stock_after", "actor", "created_at"}
+
+func stock(a *app, args []string) error {
+	action, args, err := subcommand("stock", args, "get", "adjust", "movements")
+	if err != nil {
+		return err
+	}
+
+	client, err := a.stock()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	flags := newFlags("stock " + action)
+	bookID := flags.Int("book", 0, "book id")
+	barcode := flags.String("barcode", "", "ISBN or barcode instead of the book id")
+
+	switch action {
+	case "get":
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		book, err := findBook(a, int32(*bookID), *barcode)
+		if err != nil {
+			return err
+		}
+		resp, err := client.GetBookStock(ctx, &pb.IdRequest{Id: book.Id})
+		if err != nil {
+			return err
+		}
+		s := resp.Data
+		return a.print(resp, stockHeaders, [][]string{{itoa(s.GetBook().GetId()), s.GetBook().GetIsbn(), s.GetBook().GetTitle(), itoa(s.TotalStock)}})
+
+	case "adjust":
+		delta := flags.Int("delta", 0, "signed change, e.g. 5 or -1")
+		reason := flags.String("reason", "", "purchase, loss, damage, donation or correction")
+		note := flags.String("note", "", "free text note")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *delta == 0 || *reason == "" {
+			return errors.New("-delta and -reason are required")
+		}
+		book, err := findBook(a, int32(*bookID), *barcode)
+		if err != nil {
+			return err
+		}
+		resp, err := client.AdjustStock(ctx, &pb.AdjustStockRequest{BookId: book.Id, Delta: int32(*delta), Reason: *reason, Note: *note})
+		if err != nil {
+			return err
+		}
+		return a.print(resp, stockHeaders, [][]string{{itoa(book.Id), book.Isbn, book.Title, itoa(resp.GetData().GetTotalStock())}})
+
+	case "movements":
+		page, limit, _ := pageFlags(flags)
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		book, err := findBook(a, int32(*bookID), *barcode)
+		if err != nil {
+			return err
+		}
+		resp, err := client.ListStockMovements(ctx, &pb.StockMovementsRequest{BookId: book.Id, Page: *page, Limit: *limit})
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, m := range resp.Data {
+			actor := m.ActorRole
+			if m.ActorId != 0 {
+				actor += " " + itoa(m.ActorId)
+			}
+			rows = append(rows, []string{itoa(m.Id), itoa(m.BookId), itoa(m.Delta), m.Reason, m.Note, itoa(m.StockAfter), actor, m.CreatedAt})
+		}
+		return a.print(resp, movementHeaders, rows)
+	}
+
+	return nil
+}
+
+var loanHeaders = []string{"id", "borrower_id", "borrower", "book_id", "title", "borrowed_at", "due_date", "returned_at", "status"}
+
+func loanRow(t *pb.BorrowingTransaction) []string {
+	return []string{
+		itoa(t.Id), itoa(t.GetBorrower().GetId()), t.GetBorrower().GetName(), itoa(t.GetBook().GetId()), t.GetBook().GetTitle(),
+		t.BorrowedAt, t.DueDate, t.ReturnedAt, t.Status,
+	}
+}
+
+func checkout(a *app, args []string) error {
+	flags := newFlags("checkout")
+	bookID := flags.Int("book", 0, "book id")
+	barcode := flags.String("barcode", "", "ISBN or barcode instead of the book id")
+	borrower := flags.Int("borrower", 0, "borrower id, admin only, defaults to the logged in user")
+	days := flags.Int("days", 14, "loan period in days")
+	due := flags.String("due", "", `due date "2006-01-02 15:04:05", overrides -days`)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	book, err := findBook(a, int32(*bookID), *barcode)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if *due == "" {
+		*due = now.AddDate(0, 0, *days).Format(helpers.DateTimeLayout)
+	}
+
+	client, err := a.borrowing()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	resp, err := client.CreateBorrowingTransaction(ctx, &pb.CreateBorrowingTransactionRequest{
+		BookId:     book.Id,
+		BorrowerId: int32(*borrower),
+		BorrowedAt: now.Format(helpers.DateTimeLayout),
+		DueDate:    *due,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp.Data.Book = book
+	return a.print(resp, loanHeaders, [][]string{loanRow(resp.Data)})
+}
+
+func returnBook(a *app, args []string) error {
+	flags := newFlags("return")
+	loanID := flags.Int("loan", 0, "loan (borrowing transaction) id")
+	barcode := flags.String("barcode", "", "ISBN or barcode of the returned copy")
+	borrower := flags.Int("borrower", 0, "borrower id, when several copies of the book are out")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	if *loanID == 0 {
+		if *barcode == "" {
+			return errors.New("-loan or -barcode is required")
+		}
+		loan, err := openLoan(a, *barcode, int32(*borrower))
+		if err != nil {
+			return err
+		}
+		*loanID = int(loan.Id)
+	}
+
+	client, err := a.returning()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	resp, err := client.ReturnBook(ctx, &pb.ReturnBookRequest{
+		TransactionId: int32(*loanID),
+		ReturnedAt:    time.Now().Format(helpers.DateTimeLayout),
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.Message)
+	}
+
+	return a.print(resp, []string{"loan_id", "message", "fine_amount"}, [][]string{{itoa(int32(*loanID)), resp.Message, resp.FineAmount}})
+}
+
+// openLoan finds the loan of a book that is still out
+func openLoan(a *app, barcode string, borrowerID int32) (*pb.BorrowingTransaction, error) {
+	book, err := findBook(a, 0, barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := listLoans(a)
+	if err != nil {
+		return nil, err
+	}
+
+	var open []*pb.BorrowingTransaction
+	for _, t := range all {
+		if t.GetBook().GetId() != book.Id || t.ReturnedAt != "" {
+			continue
+		}
+		if borrowerID != 0 && t.GetBorrower().GetId() != borrowerID {
+			continue
+		}
+		open = append(open, t)
+	}
+
+	switch len(open) {
+	case 0:
+		return nil, fmt.Errorf("no open loan for %q", book.Title)
+	case 1:
+		return open[0], nil
+	}
+
+	var ids []string
+	for _, t := range open {
+		ids = append(ids, fmt.Sprintf("loan %v (borrower %v)", t.Id, t.GetBorrower().GetId()))
+	}
+	return nil, fmt.Errorf("several copies of %q are out, pass -loan or -borrower: %v", book.Title, strings.Join(ids, ", "))
+}
+
+func listLoans(a *app) ([]*pb.BorrowingTransaction, error) {
+	client, err := a.borrowing()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	resp, err := client.ListBorrowingTransactions(ctx, &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+func loans(a *app, args []string) error {
+	action, args, err := subcommand("loans", args, "list", "overdue")
+	if err != nil {
+		return err
+	}
+
+	flags := newFlags("loans " + action)
+	status := flags.String("status", "", "only loans with this status: borrowed, returned or overdue")
+	borrower := flags.Int("borrower", 0, "only loans of this borrower")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	all, err := listLoans(a)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var rows [][]string
+	var data []*pb.BorrowingTransaction
+	for _, t := range all {
+		if *borrower != 0 && t.GetBorrower().GetId() != int32(*borrower) {
+			continue
+		}
+		if *status != "" && t.Status != *status {
+			continue
+		}
+		if action == "overdue" && !overdue(t, now) {
+			continue
+		}
+		data = append(data, t)
+		rows = append(rows, loanRow(t))
+	}
+
+	if len(rows) == 0 && a.output == "table" {
+		fmt.Fprintln(os.Stderr, "no loans")
+		return nil
+	}
+
+	return a.print(&pb.BorrowingTransactionsResponse{Data: data}, loanHeaders, rows)
+}
+
+// overdue also catches loans past due that the overdue worker did not mark yet
+func overdue(t *pb.BorrowingTransaction, now time.Time) bool {
+	if t.ReturnedAt != "" || t.Status == "returned" {
+		return false
+	}
+	if t.Status == "overdue" {
+		return true
+	}
+
+	due, err := time.ParseInLocation(helpers.DateTimeLayout, t.DueDate, time.Local)
+	return err == nil && due.Before(now)
+}
+
+func done(err error, message string) error {
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, message)
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	pb "go-grpc/pb/library"
+)
+
+func cite(a *app, args []string) error {
+	flags := newFlags("cite")
+	ids := flags.String("id", "", "comma separated book ids")
+	search := flags.String("search", "", "cite the books matching a title, author or ISBN")
+	format := flags.String("format", "bibtex", "bibtex, ris, csl-json or dc")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	bookIDs, err := parseIDs(*ids)
+	if err != nil {
+		return err
+	}
+	if len(bookIDs) == 0 && *search == "" {
+		return errors.New("-id or -search is required")
+	}
+
+	client, err := a.books()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	resp, err := client.ExportCitations(ctx, &pb.ExportCitationsRequest{BookIds: bookIDs, Search: *search, Format: *format})
+	if err != nil {
+		return err
+	}
+
+	// The citation is the output, -o json still gets the whole response
+	if a.output == "json" {
+		return a.print(resp, nil, nil)
+	}
+	_, err = fmt.Fprint(a.out, resp.Content)
+	return err
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	pb "go-grpc/pb/library"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type app struct {
+	addr      string
+	output    string
+	tokenFile string
+	timeout   time.Duration
+	out       io.Writer // where results are written, stdout
+
+	conn *grpc.ClientConn
+}
+
+// session is what login caches in the token file
+type session struct {
+	Addr    string `json:"addr"`
+	UserID  int32  `json:"user_id"`
+	Name    string `json:"name"`
+	Token   string `json:"token"`
+	SavedAt string `json:"saved_at"`
+}
+
+func (a *app) dial() (*grpc.ClientConn, error) {
+	if a.conn != nil {
+		return a.conn, nil
+	}
+
+	conn, err := grpc.NewClient(a.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	a.conn = conn
+	return conn, nil
+}
+
+func (a *app) close() {
+	if a.conn != nil {
+		a.conn.Close()
+	}
+}
+
+// context returns a call context carrying the cached token, if any
+func (a *app) context() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+
+	if s, err := a.loadSession(); err == nil && s.Token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+s.Token)
+	}
+
+	return ctx, cancel
+}
+
+func (a *app) loadSession() (*session, error) {
+	raw, err := os.ReadFile(a.tokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var s session
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+
+	// A token of another server is of no use here
+	if s.Addr != a.addr {
+		return nil, fmt.Errorf("cached token is for %v", s.Addr)
+	}
+
+	return &s, nil
+}
+
+func (a *app) saveSession(s session) error {
+	if err := os.MkdirAll(filepath.Dir(a.tokenFile), 0o700); err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(a.tokenFile, raw, 0o600)
+}
+
+func (a *app) books() (pb.BookServiceClient, error) {
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewBookServiceClient(conn), nil
+}
+
+func (a *app) authors() (pb.AuthorServiceClient, error) {
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewAuthorServiceClient(conn), nil
+}
+
+func (a *app) categories() (pb.CategoryServiceClient, error) {
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewCategoryServiceClient(conn), nil
+}
+
+func (a *app) stock() (pb.BookStockServiceClient, error) {
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewBookStockServiceClient(conn), nil
+}
+
+func (a *app) borrowing() (pb.BorrowingServiceClient, error) {
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewBorrowingServiceClient(conn), nil
+}
+
+func (a *app) returning() (pb.ReturningServiceClient, error) {
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewReturningServiceClient(conn), nil
+}
+
+func (a *app) reports() (pb.ReportServiceClient, error) {
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewReportServiceClient(conn), nil
+}
+
+func (a *app) reviews() (pb.ReviewServiceClient, error) {
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewReviewServiceClient(conn), nil
+}
+
+func (a *app) recommendations() (pb.RecommendationServiceClient, error) {
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewRecommendationServiceClient(conn), nil
+}
+
+func (a *app) accounts() (pb.AccountServiceClient, error) {
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewAccountServiceClient(conn), nil
+}
+
+// describe turns gRPC errors into a short message, with a hint when the token is missing or expired
+func describe(err error) string {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err.Error()
+	}
+
+	// JWTMiddleware answers with the HTTP status 401 as code
+	if s.Code() == 401 {
+		return s.Message() + " (run libctl login)"
+	}
+
+	return fmt.Sprintf("%v: %v", s.Code(), s.Message())
+}
+
+func login(a *app, args []string) error {
+	flags := newFlags("login")
+	email := flags.String("email", os.Getenv("LIBCTL_EMAIL"), "account email")
+	password := flags.String("password", os.Getenv("LIBCTL_PASSWORD"), "account password, read from stdin when empty")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	if *password == "" {
+		fmt.Fprint(os.Stderr, "password: ")
+		if _, err := fmt.Fscanln(os.Stdin, password); err != nil {
+			return fmt.Errorf("failed to read password: %w", err)
+		}
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	resp, err := pb.NewAuthServiceClient(conn).Login(ctx, &pb.LoginRequest{Email: *email, Password: *password})
+	if err != nil {
+		return err
+	}
+	if resp.GetData().GetToken() == "" {
+		return fmt.Errorf("login failed: %v", resp.GetMessage())
+	}
+
+	err = a.saveSession(session{
+		Addr:    a.addr,
+		UserID:  resp.Data.Id,
+		Name:    resp.Data.Name,
+		Token:   resp.Data.Token,
+		SavedAt: time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cache token: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "logged in as %v (id %v)\n", resp.Data.Name, resp.Data.Id)
+	return nil
+}
+
+func logout(a *app, args []string) error {
+	if err := parse(newFlags("logout"), args); err != nil {
+		return err
+	}
+	if err := os.Remove(a.tokenFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
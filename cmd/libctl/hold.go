@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+
+	pb "go-grpc/pb/library"
+)
+
+var holdHeaders = []string{"id", "book", "title", "borrower", "status", "position", "created_at", "ready_at"}
+
+// holds places and cancels holds on behalf of borrowers
+func holds(a *app, args []string) error {
+	action, args, err := subcommand("holds", args, "list", "place", "cancel")
+	if err != nil {
+		return err
+	}
+
+	client, err := a.borrowing()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	flags := newFlags("holds " + action)
+
+	switch action {
+	case "list":
+		borrower := flags.Int("borrower", 0, "only holds of this borrower")
+		closed := flags.Bool("closed", false, "include fulfilled and cancelled holds")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		resp, err := client.ListHolds(ctx, &pb.HoldsRequest{BorrowerId: int32(*borrower), IncludeClosed: *closed})
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, h := range resp.Data {
+			rows = append(rows, holdRow(h))
+		}
+		return a.print(resp, holdHeaders, rows)
+
+	case "place":
+		book := flags.Int("book", 0, "book id")
+		borrower := flags.Int("borrower", 0, "borrower id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *book == 0 || *borrower == 0 {
+			return errors.New("-book and -borrower are required")
+		}
+		resp, err := client.PlaceHold(ctx, &pb.PlaceHoldRequest{BookId: int32(*book), BorrowerId: int32(*borrower)})
+		if err != nil {
+			return err
+		}
+		return a.print(resp, holdHeaders, [][]string{holdRow(resp.Data)})
+
+	default:
+		id := flags.Int("id", 0, "hold id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return errors.New("-id is required")
+		}
+		_, err := client.CancelHold(ctx, &pb.CancelHoldRequest{HoldId: int32(*id)})
+		return done(err, "hold cancelled")
+	}
+}
+
+func holdRow(h *pb.Hold) []string {
+	position := ""
+	if h.Position > 0 {
+		position = itoa(h.Position)
+	}
+	return []string{itoa(h.Id), itoa(h.GetBook().GetId()), h.GetBook().GetTitle(), itoa(h.BorrowerId), h.Status, position, h.CreatedAt, h.ReadyAt}
+}
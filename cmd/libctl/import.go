@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-grpc/importer"
+	pb "go-grpc/pb/library"
+)
+
+// Rows sent per ImportBooks message
+const importChunk = 100
+
+func importBooks(a *app, args []string) error {
+	flags := newFlags("import")
+	file := flags.String("file", "", "CSV or JSON lines file, - for stdin")
+	format := flags.String("format", "", "csv or jsonl, guessed from the file extension by default")
+	dryRun := flags.Bool("dry-run", false, "validate every row without writing anything")
+	failedOnly := flags.Bool("failed", false, "only list the rows that failed")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+		if *file == "-" || *format == "" {
+			*format = "csv"
+		}
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	reader, err := importer.NewReader(in, *format)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.books()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	stream, err := client.ImportBooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := &pb.ImportBooksRequest{DryRun: *dryRun}
+	send := func() error {
+		if err := stream.Send(req); err != nil {
+			return err
+		}
+		req = &pb.ImportBooksRequest{DryRun: *dryRun}
+		return nil
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		// Lines that cannot be parsed still show up in the server's report
+		var rowErr *importer.RowError
+		switch {
+		case errors.As(err, &rowErr):
+			req.Rows = append(req.Rows, &pb.ImportBookRow{Line: int32(rowErr.Line), Error: rowErr.Err.Error()})
+		case err != nil:
+			return err
+		default:
+			req.Rows = append(req.Rows, importRowToPb(row))
+		}
+
+		if len(req.Rows) >= importChunk {
+			if err := send(); err != nil {
+				break // the server ended the call, CloseAndRecv returns why
+			}
+		}
+	}
+
+	if len(req.Rows) > 0 {
+		send()
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+
+	return printImportReport(a, resp, *failedOnly)
+}
+
+// printImportReport writes the totals to stderr and lists the rows
+func printImportReport(a *app, resp *pb.ImportBooksResponse, failedOnly bool) error {
+	var rows [][]string
+	for _, r := range resp.Rows {
+		if failedOnly && r.Status != importer.StatusFailed {
+			continue
+		}
+		rows = append(rows, []string{itoa(r.Line), r.Isbn, r.Title, r.Status, itoa(r.BookId), r.Error})
+	}
+
+	mode := ""
+	if resp.DryRun {
+		mode = " (dry run, nothing was written)"
+	}
+	fmt.Fprintf(os.Stderr, "%d rows: %d created, %d skipped, %d failed%s\n", resp.Total, resp.Created, resp.Skipped, resp.Failed, mode)
+
+	return a.print(resp, []string{"line", "isbn", "title", "status", "book_id", "error"}, rows)
+}
+
+func importRowToPb(row importer.Row) *pb.ImportBookRow {
+	return &pb.ImportBookRow{
+		Line:            int32(row.Line),
+		Title:           row.Title,
+		Isbn:            row.ISBN,
+		Authors:         row.Authors,
+		Editors:         row.Editors,
+		Translators:     row.Translators,
+		Illustrators:    row.Illustrators,
+		Category:        row.Category,
+		PublicationYear: row.Year,
+		Description:     row.Description,
+		Copies:          row.Copies,
+	}
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "go-grpc/pb/library"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// library serves the calls libctl makes from a small catalog, it records
+// the authorization header and requests it gets
+type library struct {
+	pb.UnimplementedAuthServiceServer
+	pb.UnimplementedBookServiceServer
+	pb.UnimplementedBorrowingServiceServer
+	pb.UnimplementedReturningServiceServer
+
+	mu            sync.Mutex
+	authorization []string
+	books         []*pb.Book
+	loans         []*pb.BorrowingTransaction
+	created       []*pb.CreateBorrowingTransactionRequest
+	returned      []int32
+	imports       [][]*pb.ImportBookRow
+}
+
+func newLibrary() *library {
+	return &library{
+		books: []*pb.Book{
+			{Id: 1, Isbn: "9780306406157", Title: "The Art of Computer Programming", Author: &pb.Author{Id: 4, Name: "Donald Knuth"}, PublicationYear: 1968},
+			{
+				Id: 2, Isbn: "9780131103627", Title: "The C Programming Language", PublicationYear: 1978,
+				Contributors: []*pb.Contributor{{AuthorId: 5, Name: "Brian Kernighan", Role: "author"}, {AuthorId: 6, Name: "Dennis Ritchie", Role: "author"}},
+			},
+		},
+	}
+}
+
+// serve registers the library on a gRPC server over bufconn and returns an app
+// connected to it, with the token cached in a temporary directory
+func serve(t *testing.T, lib *library) (*app, *bytes.Buffer) {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(lib.record), grpc.StreamInterceptor(lib.recordStream))
+	pb.RegisterAuthServiceServer(server, lib)
+	pb.RegisterBookServiceServer(server, lib)
+	pb.RegisterBorrowingServiceServer(server, lib)
+	pb.RegisterReturningServiceServer(server, lib)
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	out := &bytes.Buffer{}
+	return &app{
+		addr:      "bufconn",
+		output:    "table",
+		tokenFile: filepath.Join(t.TempDir(), "token.json"),
+		timeout:   5 * time.Second,
+		out:       out,
+		conn:      conn,
+	}, out
+}
+
+func (l *library) record(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	l.recordAuthorization(ctx)
+	return handler(ctx, req)
+}
+
+func (l *library) recordStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	l.recordAuthorization(ss.Context())
+	return handler(srv, ss)
+}
+
+func (l *library) recordAuthorization(ctx context.Context) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	l.mu.Lock()
+	l.authorization = append(l.authorization, strings.Join(md.Get("authorization"), ","))
+	l.mu.Unlock()
+}
+
+func (l *library) Login(_ context.Context, req *pb.LoginRequest) (*pb.ResponseParamLogin, error) {
+	if req.Email != "admin@library.test" || req.Password != "secret" {
+		return &pb.ResponseParamLogin{StatusCode: 401, Message: "invalid email or password"}, nil
+	}
+	return &pb.ResponseParamLogin{StatusCode: 200, Data: &pb.LoginResponse{Id: 1, Name: "Admin", Token: "token-1"}}, nil
+}
+
+func (l *library) ListBooks(_ context.Context, req *pb.ParameterReq) (*pb.BooksResponse, error) {
+	var data []*pb.Book
+	for _, b := range l.books {
+		if strings.Contains(strings.ToLower(b.Title), strings.ToLower(req.Search)) {
+			data = append(data, b)
+		}
+	}
+	return &pb.BooksResponse{Data: data}, nil
+}
+
+func (l *library) GetBookByIsbn(_ context.Context, req *pb.IsbnRequest) (*pb.BookResponse, error) {
+	for _, b := range l.books {
+		if b.Isbn == req.Isbn {
+			return &pb.BookResponse{Data: b}, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "Book not found")
+}
+
+func (l *library) CreateBorrowingTransaction(_ context.Context, req *pb.CreateBorrowingTransactionRequest) (*pb.BorrowingTransactionResponse, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.created = append(l.created, req)
+	return &pb.BorrowingTransactionResponse{Data: &pb.BorrowingTransaction{
+		Id:         int32(len(l.created)),
+		Borrower:   &pb.Borrower{Id: req.BorrowerId},
+		BorrowedAt: req.BorrowedAt,
+		DueDate:    req.DueDate,
+		Status:     "borrowed",
+	}}, nil
+}
+
+func (l *library) ListBorrowingTransactions(context.Context, *pb.Empty) (*pb.BorrowingTransactionsResponse, error) {
+	return &pb.BorrowingTransactionsResponse{Data: l.loans}, nil
+}
+
+func (l *library) ReturnBook(_ context.Context, req *pb.ReturnBookRequest) (*pb.ReturnBookResponse, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.returned = append(l.returned, req.TransactionId)
+	return &pb.ReturnBookResponse{Success: true, Message: "Book returned successfully"}, nil
+}
+
+func (l *library) ImportBooks(stream pb.BookService_ImportBooksServer) error {
+	resp := &pb.ImportBooksResponse{}
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+
+		l.mu.Lock()
+		l.imports = append(l.imports, req.Rows)
+		l.mu.Unlock()
+
+		resp.DryRun = req.DryRun
+		for _, row := range req.Rows {
+			resp.Total++
+			result := &pb.ImportRowResult{Line: row.Line, Isbn: row.Isbn, Title: row.Title, Status: "created", BookId: resp.Total}
+			if row.Error != "" {
+				result.Status, result.Error, result.BookId = "failed", row.Error, 0
+				resp.Failed++
+			} else {
+				resp.Created++
+			}
+			resp.Rows = append(resp.Rows, result)
+		}
+	}
+}
+
+func TestLoginCachesToken(t *testing.T) {
+	lib := newLibrary()
+	a, _ := serve(t, lib)
+
+	if err := login(a, []string{"-email", "admin@library.test", "-password", "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := books(a, []string{"list"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := lib.authorization[len(lib.authorization)-1]; got != "Bearer token-1" {
+		t.Errorf("books list sent authorization %q, want the cached token", got)
+	}
+
+	if err := logout(a, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(a.tokenFile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("token file is still there after logout: %v", err)
+	}
+}
+
+func TestLoginRejected(t *testing.T) {
+	a, _ := serve(t, newLibrary())
+
+	err := login(a, []string{"-email", "admin@library.test", "-password", "wrong"})
+	if err == nil || !strings.Contains(err.Error(), "invalid email or password") {
+		t.Fatalf("login = %v, want the server's message", err)
+	}
+	if _, err := os.Stat(a.tokenFile); !errors.Is(err, os.ErrNotExist) {
+		t.Error("a token was cached for a failed login")
+	}
+}
+
+func TestBooksListOutput(t *testing.T) {
+	a, out := serve(t, newLibrary())
+
+	if err := books(a, []string{"list"}); err != nil {
+		t.Fatal(err)
+	}
+	table := out.String()
+	for _, want := range []string{"ID", "ISBN", "TITLE", "9780306406157", "Donald Knuth", "Brian Kernighan; Dennis Ritchie"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table output has no %q:\n%s", want, table)
+		}
+	}
+
+	out.Reset()
+	a.output = "csv"
+	if err := books(a, []string{"list", "-search", "c programming"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,isbn,title,author,category,year,rating,deleted_at\n" +
+		"2,9780131103627,The C Programming Language,Brian Kernighan; Dennis Ritchie,,1978,,\n"
+	if out.String() != want {
+		t.Errorf("csv output:\n%s\nwant:\n%s", out, want)
+	}
+
+	out.Reset()
+	a.output = "json"
+	if err := books(a, []string{"list"}); err != nil {
+		t.Fatal(err)
+	}
+	var resp pb.BooksResponse
+	if err := protojson.Unmarshal(out.Bytes(), &resp); err != nil {
+		t.Fatalf("json output is not the response message: %v", err)
+	}
+	if len(resp.Data) != 2 || resp.Data[1].Isbn != "9780131103627" {
+		t.Errorf("json output has the books %v", resp.Data)
+	}
+}
+
+func TestBooksUnknownAction(t *testing.T) {
+	a, _ := serve(t, newLibrary())
+
+	if err := books(a, []string{"shelve"}); err == nil || !strings.Contains(err.Error(), `unknown action "shelve"`) {
+		t.Fatalf("books shelve = %v", err)
+	}
+}
+
+func TestCheckoutByBarcode(t *testing.T) {
+	lib := newLibrary()
+	a, out := serve(t, lib)
+
+	if err := checkout(a, []string{"-barcode", "9780306406157", "-borrower", "12", "-due", "2030-01-15 12:00:00"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lib.created) != 1 {
+		t.Fatalf("%d loans created, want 1", len(lib.created))
+	}
+	req := lib.created[0]
+	if req.BookId != 1 || req.BorrowerId != 12 || req.DueDate != "2030-01-15 12:00:00" {
+		t.Errorf("CreateBorrowingTransaction got book %d, borrower %d, due %q", req.BookId, req.BorrowerId, req.DueDate)
+	}
+	if !strings.Contains(out.String(), "The Art of Computer Programming") {
+		t.Errorf("the loan is printed without its book:\n%s", out)
+	}
+
+	if err := checkout(a, []string{"-barcode", "9780000000002"}); status.Code(err) != codes.NotFound {
+		t.Errorf("checkout of an unknown barcode = %v, want NotFound", err)
+	}
+}
+
+func TestReturnByBarcode(t *testing.T) {
+	lib := newLibrary()
+	book := lib.books[0]
+	lib.loans = []*pb.BorrowingTransaction{
+		{Id: 7, Book: book, Borrower: &pb.Borrower{Id: 3}, ReturnedAt: "2024-01-02 10:00:00", Status: "returned"},
+		{Id: 8, Book: book, Borrower: &pb.Borrower{Id: 3}, Status: "borrowed"},
+		{Id: 9, Book: book, Borrower: &pb.Borrower{Id: 4}, Status: "borrowed"},
+	}
+	a, _ := serve(t, lib)
+
+	// Two copies are out, the borrower tells them apart
+	err := returnBook(a, []string{"-barcode", "9780306406157"})
+	if err == nil || !strings.Contains(err.Error(), "several copies") {
+		t.Fatalf("return of an ambiguous barcode = %v", err)
+	}
+
+	if err := returnBook(a, []string{"-barcode", "9780306406157", "-borrower", "4"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(lib.returned) != 1 || lib.returned[0] != 9 {
+		t.Errorf("returned loans %v, want [9]", lib.returned)
+	}
+}
+
+func TestImportStreamsRows(t *testing.T) {
+	lib := newLibrary()
+	a, out := serve(t, lib)
+	a.output = "csv"
+
+	var csv strings.Builder
+	csv.WriteString("title,isbn,authors,editors,year\n")
+	for i := 0; i < importChunk+5; i++ {
+		csv.WriteString("Book,,Someone,Someone Else,1999\n")
+	}
+	csv.WriteString("Broken,,Someone,,soon\n")
+
+	file := filepath.Join(t.TempDir(), "books.csv")
+	if err := os.WriteFile(file, []byte(csv.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := importBooks(a, []string{"-file", file, "-failed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lib.imports) != 2 || len(lib.imports[0]) != importChunk || len(lib.imports[1]) != 6 {
+		t.Fatalf("rows were not sent in chunks of %d: %d messages", importChunk, len(lib.imports))
+	}
+	if row := lib.imports[0][0]; len(row.Editors) != 1 || row.Editors[0] != "Someone Else" {
+		t.Errorf("editors sent as %v", row.Editors)
+	}
+
+	// Only the line that could not be parsed is listed
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], itoa(int32(importChunk+7))+",,,failed,0,") {
+		t.Errorf("failed rows:\n%s", out)
+	}
+}
@@ -0,0 +1,153 @@
+// Command libctl is a command line client for librarians, built on the
+// generated pb/library gRPC clients.
+//
+//	libctl login -email admin@library.test
+//	libctl books list -limit 50 -o csv
+//	libctl checkout -barcode 9780306406157 -borrower 12
+//	libctl return -barcode 9780306406157
+//	libctl loans overdue -o json
+//	libctl import -file new-branch.csv -dry-run
+//	libctl report export -name most_borrowed -from 2024-07-01 -to 2024-08-01 -out july.xlsx
+//	libctl schedule create -name weekly -report most_borrowed -cron "0 7 * * 1" -email board@library.test
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// command runs a subcommand with the arguments after its name
+type command func(app *app, args []string) error
+
+var commands = map[string]command{
+	"login":      login,
+	"logout":     logout,
+	"books":      books,
+	"authors":    authors,
+	"categories": categories,
+	"stock":      stock,
+	"checkout":   checkout,
+	"return":     returnBook,
+	"loans":      loans,
+	"import":     importBooks,
+	"marc":       marcRecords,
+	"cite":       cite,
+	"report":     reports,
+	"schedule":   schedules,
+	"account":    account,
+	"fine":       fine,
+	"reviews":    reviews,
+	"holds":      holds,
+	"recommend":  recommend,
+}
+
+const usage = `usage: libctl [flags] <command> [arguments]
+
+commands:
+  login        -email <email> [-password <password>]
+  logout
+  books        list|get|create|update|delete|restore|purge
+  authors      list|get|create|update|delete|restore|purge
+  categories   list|get|create|update|delete|restore|purge
+  stock        get|adjust|movements
+  checkout     -book <id> | -barcode <isbn> [-borrower <id>] [-days 14]
+  return       -loan <id> | -barcode <isbn> [-borrower <id>]
+  loans        list|overdue
+  import       -file <books.csv|books.jsonl> [-dry-run] [-failed]
+  marc         import|export
+  cite         -id <ids> | -search <text> [-format bibtex|ris|csl-json|dc]
+  report       list|run|export
+  schedule     list|create|delete|run|runs
+  account      export|erase -borrower <id>
+  fine         pay -loan <id>
+  reviews      list|queue|approve|hide|delete
+  holds        list|place|cancel
+  recommend    similar -book <id> | borrower -borrower <id> [-limit 10] [-available]
+
+flags:
+`
+
+func main() {
+	app := &app{out: os.Stdout}
+
+	home, _ := os.UserConfigDir()
+
+	flags := flag.NewFlagSet("libctl", flag.ExitOnError)
+	flags.StringVar(&app.addr, "addr", envOr("LIBCTL_ADDR", "localhost:50051"), "gRPC server address")
+	flags.StringVar(&app.output, "o", "table", "output format: table, json or csv")
+	flags.StringVar(&app.tokenFile, "token-file", filepath.Join(home, "libctl", "token.json"), "where the login token is cached")
+	flags.DurationVar(&app.timeout, "timeout", 30*time.Second, "timeout of a single call")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	run, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "libctl: unknown command %q\n\n", flags.Arg(0))
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if app.output != "table" && app.output != "json" && app.output != "csv" {
+		fmt.Fprintf(os.Stderr, "libctl: unknown output format %q\n", app.output)
+		os.Exit(2)
+	}
+
+	err := run(app, flags.Args()[1:])
+	app.close()
+
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "libctl: %v\n", describe(err))
+		os.Exit(1)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// subcommand splits "books list -limit 5" into the action and its flags
+func subcommand(name string, args []string, actions ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("%v: missing action, one of %v", name, actions)
+	}
+	for _, action := range actions {
+		if args[0] == action {
+			return action, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("%v: unknown action %q, one of %v", name, args[0], actions)
+}
+
+// parse parses the flags of a subcommand, errors are returned instead of exiting
+func parse(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+	return nil
+}
+
+func newFlags(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go-grpc/marc"
+	pb "go-grpc/pb/library"
+)
+
+// Bytes sent per ImportMarc message
+const marcChunk = 64 << 10
+
+func marcRecords(a *app, args []string) error {
+	action, args, err := subcommand("marc", args, "import", "export")
+	if err != nil {
+		return err
+	}
+
+	client, err := a.books()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	flags := newFlags("marc " + action)
+
+	switch action {
+	case "import":
+		file := flags.String("file", "", "MARC21 (.mrc) or MARCXML (.xml) file, - for stdin")
+		format := flags.String("format", "", "marc21 or marcxml, guessed from the file extension by default")
+		copies := flags.Int("copies", 0, "copies to add for every new book")
+		dryRun := flags.Bool("dry-run", false, "validate every record without writing anything")
+		failedOnly := flags.Bool("failed", false, "only list the records that failed")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *file == "" {
+			return errors.New("-file is required")
+		}
+		if *format == "" && *file != "-" {
+			*format = filepath.Ext(*file)
+		}
+		if _, err := marc.ParseFormat(*format); err != nil {
+			return err
+		}
+
+		var in io.Reader = os.Stdin
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+
+		stream, err := client.ImportMarc(ctx)
+		if err != nil {
+			return err
+		}
+
+		req := &pb.ImportMarcRequest{Format: *format, DryRun: *dryRun, Copies: int32(*copies)}
+		buf := make([]byte, marcChunk)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 {
+				req.Data = buf[:n]
+				if stream.Send(req) != nil {
+					break // the server ended the call, CloseAndRecv returns why
+				}
+				req = &pb.ImportMarcRequest{}
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			return err
+		}
+		return printImportReport(a, resp, *failedOnly)
+
+	case "export":
+		ids := flags.String("id", "", "comma separated book ids")
+		search := flags.String("search", "", "export the books matching a title, author or ISBN")
+		format := flags.String("format", "", "marc21 or marcxml, guessed from the -out extension by default")
+		out := flags.String("out", "-", "file to write, - for stdout")
+		deleted := flags.Bool("deleted", false, "include deleted books (admin only)")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *format == "" && *out != "-" {
+			*format = filepath.Ext(*out)
+		}
+		if _, err := marc.ParseFormat(*format); err != nil {
+			return err
+		}
+
+		bookIDs, err := parseIDs(*ids)
+		if err != nil {
+			return err
+		}
+
+		req := &pb.ExportMarcRequest{BookIds: bookIDs, Search: *search, Format: *format, IncludeDeleted: *deleted}
+
+		stream, err := client.ExportMarc(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		w := a.out
+		if *out != "-" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(chunk.Data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
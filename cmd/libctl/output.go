@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// print writes rows as an aligned table or CSV, JSON output is the full response message
+func (a *app) print(resp proto.Message, headers []string, rows [][]string) error {
+	switch a.output {
+	case "json":
+		raw, err := protojson.MarshalOptions{Multiline: true, UseProtoNames: true, EmitUnpopulated: true}.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(a.out, string(raw))
+		return err
+
+	case "csv":
+		w := csv.NewWriter(a.out)
+		if err := w.Write(headers); err != nil {
+			return err
+		}
+		if err := w.WriteAll(rows); err != nil {
+			return err
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(headers, "\t")))
+	for _, row := range rows {
+		for i := range row {
+			row[i] = oneLine(row[i], 60)
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// oneLine keeps long descriptions from breaking the table layout
+func oneLine(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len([]rune(s)) > max {
+		return string([]rune(s)[:max-1]) + "…"
+	}
+	return s
+}
+
+func itoa[T ~int32 | ~int64 | ~uint32 | ~uint64](v T) string {
+	return strconv.FormatInt(int64(v), 10)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	pb "go-grpc/pb/library"
+)
+
+var recommendationHeaders = []string{"id", "title", "author", "category", "score", "reason"}
+
+// recommend shows books similar to a book and the recommendations of a borrower
+func recommend(a *app, args []string) error {
+	action, args, err := subcommand("recommend", args, "similar", "borrower")
+	if err != nil {
+		return err
+	}
+
+	client, err := a.recommendations()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	flags := newFlags("recommend " + action)
+	limit := flags.Int("limit", 10, "number of books, at most 50")
+	available := flags.Bool("available", false, "only books with a free copy on the shelf")
+
+	var resp *pb.RecommendationsResponse
+
+	switch action {
+	case "similar":
+		book := flags.Int("book", 0, "book id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *book == 0 {
+			return errors.New("-book is required")
+		}
+		resp, err = client.GetSimilarBooks(ctx, &pb.SimilarBooksRequest{BookId: int32(*book), Limit: int32(*limit), AvailableOnly: *available})
+
+	default:
+		borrower := flags.Int("borrower", 0, "borrower id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *borrower == 0 {
+			return errors.New("-borrower is required")
+		}
+		resp, err = client.GetRecommendationsForMe(ctx, &pb.MyRecommendationsRequest{BorrowerId: int32(*borrower), Limit: int32(*limit), AvailableOnly: *available})
+	}
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for _, r := range resp.Data {
+		b := r.GetBook()
+		rows = append(rows, []string{itoa(b.Id), b.Title, credits(b), b.GetCategory().GetName(), strconv.FormatFloat(r.Score, 'f', 3, 64), r.Reason})
+	}
+	return a.print(resp, recommendationHeaders, rows)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pb "go-grpc/pb/library"
+)
+
+func reports(a *app, args []string) error {
+	action, args, err := subcommand("report", args, "list", "run", "export")
+	if err != nil {
+		return err
+	}
+
+	client, err := a.reports()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	if action == "list" {
+		if err := parse(newFlags("report list"), args); err != nil {
+			return err
+		}
+		resp, err := client.ListReports(ctx, &pb.Empty{})
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, d := range resp.Data {
+			rows = append(rows, []string{d.Name, d.Title, d.Description})
+		}
+		return a.print(resp, []string{"name", "title", "description"}, rows)
+	}
+
+	flags := newFlags("report " + action)
+	name := flags.String("name", "", "report to run, see report list")
+	from := flags.String("from", "", "loans borrowed at or after, 2006-01-02 or 2006-01-02 15:04:05")
+	to := flags.String("to", "", "loans borrowed before")
+	category := flags.Int("category", 0, "only books of this category")
+	limit := flags.Int("limit", 0, "rows of ranked reports")
+	format := flags.String("format", "", "export only: csv or xlsx, guessed from the -out extension by default")
+	out := flags.String("out", "-", "export only: file to write, - for stdout")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	req := &pb.ReportRequest{Report: *name, From: *from, To: *to, CategoryId: int32(*category), Limit: int32(*limit), Format: *format}
+
+	if action == "run" {
+		resp, err := client.RunReport(ctx, req)
+		if err != nil {
+			return err
+		}
+		headers := make([]string, len(resp.Columns))
+		for i, c := range resp.Columns {
+			headers[i] = c.Name
+		}
+		rows := make([][]string, len(resp.Rows))
+		for i, r := range resp.Rows {
+			rows[i] = r.Values
+		}
+		return a.print(resp, headers, rows)
+	}
+
+	if req.Format == "" && *out != "-" {
+		req.Format = strings.TrimPrefix(filepath.Ext(*out), ".")
+	}
+
+	stream, err := client.ExportReport(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	w := a.out
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(chunk.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+
+	pb "go-grpc/pb/library"
+)
+
+var reviewHeaders = []string{"id", "book", "title", "borrower", "rating", "status", "review", "note", "updated_at"}
+
+// reviews lists book reviews and works through the moderation queue
+func reviews(a *app, args []string) error {
+	action, args, err := subcommand("reviews", args, "list", "queue", "approve", "hide", "delete")
+	if err != nil {
+		return err
+	}
+
+	client, err := a.reviews()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	flags := newFlags("reviews " + action)
+
+	switch action {
+	case "list", "queue":
+		book := flags.Int("book", 0, "book id")
+		status := flags.String("status", "", "queue only: pending (default), approved or hidden")
+		page, limit, _ := pageFlags(flags)
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+
+		var resp *pb.ReviewsResponse
+		if action == "list" {
+			if *book == 0 {
+				return errors.New("-book is required")
+			}
+			resp, err = client.ListBookReviews(ctx, &pb.ListBookReviewsRequest{BookId: int32(*book), Page: *page, Limit: *limit})
+		} else {
+			resp, err = client.ListReviewQueue(ctx, &pb.ReviewQueueRequest{Status: *status, BookId: int32(*book), Page: *page, Limit: *limit})
+		}
+		if err != nil {
+			return err
+		}
+
+		var rows [][]string
+		for _, r := range resp.Data {
+			rows = append(rows, reviewRow(r))
+		}
+		return a.print(resp, reviewHeaders, rows)
+
+	case "approve", "hide":
+		id := flags.Int("id", 0, "review id")
+		note := flags.String("note", "", "reason, kept with the review")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return errors.New("-id is required")
+		}
+		status := "approved"
+		if action == "hide" {
+			status = "hidden"
+		}
+		resp, err := client.ModerateReview(ctx, &pb.ModerateReviewRequest{Id: int32(*id), Status: status, Note: *note})
+		if err != nil {
+			return err
+		}
+		return a.print(resp, reviewHeaders, [][]string{reviewRow(resp.Data)})
+
+	default:
+		id := flags.Int("id", 0, "review id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return errors.New("-id is required")
+		}
+		_, err := client.DeleteReview(ctx, &pb.IdRequest{Id: int32(*id)})
+		return done(err, "review deleted")
+	}
+}
+
+func reviewRow(r *pb.Review) []string {
+	return []string{itoa(r.Id), itoa(r.BookId), r.BookTitle, r.BorrowerName, itoa(r.Rating), r.Status, r.Body, r.ModerationNote, r.UpdatedAt}
+}
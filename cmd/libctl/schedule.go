@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+
+	pb "go-grpc/pb/library"
+)
+
+var scheduleHeaders = []string{"id", "name", "report", "cron", "format", "delivery", "email", "active", "next_run_at"}
+
+var runHeaders = []string{"id", "schedule", "period_start", "period_end", "triggered_by", "status", "rows", "location", "error"}
+
+func schedules(a *app, args []string) error {
+	action, args, err := subcommand("schedule", args, "list", "create", "delete", "run", "runs")
+	if err != nil {
+		return err
+	}
+
+	client, err := a.reports()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.context()
+	defer cancel()
+
+	flags := newFlags("schedule " + action)
+
+	switch action {
+	case "list":
+		page, limit, _ := pageFlags(flags)
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		resp, err := client.ListReportSchedules(ctx, &pb.ParameterReq{Page: *page, Limit: *limit})
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, s := range resp.Data {
+			active := "no"
+			if s.Active {
+				active = "yes"
+			}
+			rows = append(rows, []string{itoa(s.Id), s.Name, s.Report, s.Cron, s.Format, s.Delivery, s.Email, active, s.NextRunAt})
+		}
+		return a.print(resp, scheduleHeaders, rows)
+
+	case "create":
+		name := flags.String("name", "", "name of the schedule")
+		report := flags.String("report", "", "report to run, see report list")
+		cron := flags.String("cron", "", `when to run, e.g. "0 7 * * 1" for Mondays at 7:00`)
+		category := flags.Int("category", 0, "only books of this category")
+		limit := flags.Int("limit", 0, "rows of ranked reports")
+		format := flags.String("format", "csv", "csv or xlsx")
+		email := flags.String("email", "", "email the report to this address, written to REPORT_DIR when empty")
+		alert := flags.String("alert", "", "address told about failed runs")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *name == "" || *report == "" || *cron == "" {
+			return errors.New("-name, -report and -cron are required")
+		}
+		delivery := "directory"
+		if *email != "" {
+			delivery = "email"
+		}
+		_, err := client.CreateReportSchedule(ctx, &pb.ReportScheduleRequest{
+			Name:       *name,
+			Report:     *report,
+			Cron:       *cron,
+			CategoryId: int32(*category),
+			Limit:      int32(*limit),
+			Format:     *format,
+			Delivery:   delivery,
+			Email:      *email,
+			AlertEmail: *alert,
+		})
+		return done(err, "report schedule created")
+
+	case "delete":
+		id := flags.Int("id", 0, "schedule id")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return errors.New("-id is required")
+		}
+		_, err := client.DeleteReportSchedule(ctx, &pb.IdRequest{Id: int32(*id)})
+		return done(err, "report schedule deleted")
+
+	case "run":
+		id := flags.Int("id", 0, "schedule id")
+		from := flags.String("from", "", "start of the period to run again, the last completed period by default")
+		to := flags.String("to", "", "end of the period")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return errors.New("-id is required")
+		}
+		resp, err := client.RunReportSchedule(ctx, &pb.RunReportScheduleRequest{ScheduleId: int32(*id), From: *from, To: *to})
+		if err != nil {
+			return err
+		}
+		return a.print(resp, runHeaders, [][]string{runRow(resp.Data)})
+
+	default:
+		id := flags.Int("id", 0, "only runs of this schedule")
+		status := flags.String("status", "", "pending, running, succeeded or failed")
+		page, limit, _ := pageFlags(flags)
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		resp, err := client.ListReportRuns(ctx, &pb.ReportRunsRequest{ScheduleId: int32(*id), Status: *status, Page: *page, Limit: *limit})
+		if err != nil {
+			return err
+		}
+		var rows [][]string
+		for _, r := range resp.Data {
+			rows = append(rows, runRow(r))
+		}
+		return a.print(resp, runHeaders, rows)
+	}
+}
+
+func runRow(r *pb.ReportRun) []string {
+	return []string{itoa(r.Id), r.ScheduleName, r.PeriodStart, r.PeriodEnd, r.TriggeredBy, r.Status, itoa(r.Rows), r.Location, r.Error}
+}
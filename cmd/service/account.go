@@ -0,0 +1,439 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-grpc/cmd/worker"
+	"go-grpc/helpers"
+	"go-grpc/model"
+	pb "go-grpc/pb/library"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AccountService answers data subject requests: a copy of everything stored
+// about a borrower, and erasure of the account
+type AccountService struct {
+	pb.UnimplementedAccountServiceServer
+	DB *gorm.DB
+}
+
+// Name left on the borrowers row of an erased account
+const erasedBorrowerName = "Erased borrower"
+
+// dataExport is the bundle of ExportMyData, each section is a file in the ZIP format
+type dataExport struct {
+	ExportedAt    string               `json:"exported_at"`
+	Profile       exportProfile        `json:"profile"`
+	Settings      exportSettings       `json:"settings"`
+	Loans         []exportLoan         `json:"loans"`
+	Returns       []exportReturn       `json:"returns"`
+	Fines         []exportFine         `json:"fines"`
+	Notifications []exportNotification `json:"notifications"`
+	Reviews       []exportReview       `json:"reviews"`
+	Holds         []exportHold         `json:"holds"`
+	ReadingLists  []exportReadingList  `json:"reading_lists"`
+}
+
+type exportProfile struct {
+	ID        int32  `json:"id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	ErasedAt  string `json:"erased_at,omitempty"`
+}
+
+type exportSettings struct {
+	ReadingHistory     string `json:"reading_history"`
+	EmailEnabled       bool   `json:"email_enabled"`
+	SmsEnabled         bool   `json:"sms_enabled"`
+	InappEnabled       bool   `json:"inapp_enabled"`
+	Phone              string `json:"phone"`
+	ReminderDaysBefore int32  `json:"reminder_days_before"`
+}
+
+type exportLoan struct {
+	ID         int32  `json:"id"`
+	BookID     int32  `json:"book_id"`
+	Title      string `json:"title"`
+	ISBN       string `json:"isbn"`
+	BorrowedAt string `json:"borrowed_at"`
+	DueDate    string `json:"due_date"`
+	ReturnedAt string `json:"returned_at"`
+	Status     string `json:"status"`
+	Renewals   int32  `json:"renewals"`
+}
+
+type exportReturn struct {
+	ID            int32   `json:"id"`
+	TransactionID int32   `json:"transaction_id"`
+	ReturnedAt    string  `json:"returned_at"`
+	FineAmount    float64 `json:"fine_amount"`
+}
+
+type exportFine struct {
+	TransactionID int32   `json:"transaction_id"`
+	Title         string  `json:"title"`
+	Amount        float64 `json:"amount"`
+	AssessedAt    string  `json:"assessed_at"`
+	PaidAt        string  `json:"paid_at"`
+}
+
+type exportNotification struct {
+	ID            int64  `json:"id"`
+	Kind          string `json:"kind"`
+	Title         string `json:"title"`
+	Body          string `json:"body"`
+	TransactionID int32  `json:"transaction_id"`
+	ReadAt        string `json:"read_at"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type exportReview struct {
+	ID        int32  `json:"id"`
+	BookID    int32  `json:"book_id"`
+	Title     string `json:"title"`
+	Rating    int32  `json:"rating"`
+	Body      string `json:"body"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type exportHold struct {
+	ID        int32  `json:"id"`
+	BookID    int32  `json:"book_id"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	ReadyAt   string `json:"ready_at"`
+	ClosedAt  string `json:"closed_at"`
+}
+
+type exportReadingList struct {
+	ID          int32                   `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Shared      bool                    `json:"shared"`
+	CreatedAt   string                  `json:"created_at"`
+	Items       []exportReadingListItem `json:"items" gorm:"-"`
+}
+
+type exportReadingListItem struct {
+	ListID   int32  `json:"-"`
+	BookID   int32  `json:"book_id"`
+	Title    string `json:"title"`
+	Position int32  `json:"position"`
+	Note     string `json:"note"`
+	AddedAt  string `json:"added_at"`
+}
+
+// ExportMyData(context.Context, *ExportMyDataRequest) (*ExportMyDataResponse, error)
+func (s *AccountService) ExportMyData(ctx context.Context, req *pb.ExportMyDataRequest) (*pb.ExportMyDataResponse, error) {
+
+	borrowerID, _, err := subjectOf(ctx, req.GetBorrowerId())
+	if err != nil {
+		return nil, err
+	}
+
+	format := strings.ToLower(req.GetFormat())
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "zip" {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown format %q, use json or zip", req.GetFormat())
+	}
+
+	bundle, err := s.collect(borrowerID)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := fmt.Sprintf("borrower-%d-data-%s.%s", borrowerID, time.Now().Format("20060102"), format)
+
+	if format == "json" {
+		data, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return &pb.ExportMyDataResponse{Filename: filename, ContentType: "application/json", Data: data}, nil
+	}
+
+	data, err := zipSections(bundle)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.ExportMyDataResponse{Filename: filename, ContentType: "application/zip", Data: data}, nil
+}
+
+// EraseAccount(context.Context, *EraseAccountRequest) (*ReturnSimpleResponse, error)
+func (s *AccountService) EraseAccount(ctx context.Context, req *pb.EraseAccountRequest) (*pb.ReturnSimpleResponse, error) {
+
+	borrowerID, role, err := subjectOf(ctx, req.GetBorrowerId())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var borrower struct {
+			ID       int32
+			Email    string
+			Password string
+		}
+
+		if err := tx.Table("borrowers").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, email, password").
+			Where("id = ? AND erased_at IS NULL", borrowerID).
+			Take(&borrower).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return status.Errorf(codes.NotFound, "borrower not found or already erased")
+			}
+			return err
+		}
+
+		// Borrowers confirm with their password, a stolen token alone cannot erase an account
+		if role != "admin" && helpers.VerifyPassword(borrower.Password, req.GetPassword()) != nil {
+			return status.Errorf(codes.PermissionDenied, "wrong password")
+		}
+
+		var openLoans, unpaidFines int64
+		if err := tx.Table("borrowing_transactions").
+			Where("borrower_id = ? AND returned_at IS NULL", borrowerID).
+			Count(&openLoans).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("returning_transactions rt").
+			Joins("JOIN borrowing_transactions bt on bt.id = rt.borrowing_transaction_id").
+			Where("bt.borrower_id = ? AND rt.fine_amount > 0 AND rt.fine_paid_at IS NULL", borrowerID).
+			Count(&unpaidFines).Error; err != nil {
+			return err
+		}
+		if openLoans > 0 || unpaidFines > 0 {
+			return status.Errorf(codes.FailedPrecondition, "account has %d open loans and %d unpaid fines, they must be settled first", openLoans, unpaidFines)
+		}
+
+		// Loans stay for the statistics, without the borrower
+		var loans []int32
+		if err := tx.Table("borrowing_transactions").Where("borrower_id = ?", borrowerID).Pluck("id", &loans).Error; err != nil {
+			return err
+		}
+		if err := worker.DetachLoans(tx, loans); err != nil {
+			return err
+		}
+
+		if err := tx.Table("circulation_events").Where("borrower_id = ?", borrowerID).Update("borrower_id", nil).Error; err != nil {
+			return err
+		}
+
+		// Copies set aside for the borrower go to the next borrower in the queue
+		var ready []int32
+		if err := tx.Table("holds").Where("borrower_id = ? AND status = ?", borrowerID, holdReady).Pluck("book_id", &ready).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM holds WHERE borrower_id = ?", borrowerID).Error; err != nil {
+			return err
+		}
+		for _, bookID := range ready {
+			var stock model.BookStock
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("book_id = ?", bookID).First(&stock).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				}
+				return err
+			}
+			if err := readyHolds(tx, bookID, stock.TotalStock); err != nil {
+				return err
+			}
+		}
+
+		// Items of the reading lists are removed by ON DELETE CASCADE
+		for _, table := range []string{"notifications", "notification_preferences", "privacy_settings", "book_reviews", "reading_lists"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE borrower_id = ?", borrowerID).Error; err != nil {
+				return err
+			}
+		}
+
+		// The registration request in the audit log holds the name and email
+		if borrower.Email != "" {
+			if err := tx.Exec("UPDATE audit_events SET request_data = JSON_REMOVE(request_data, '$.name', '$.email') "+
+				"WHERE method LIKE ? AND JSON_UNQUOTE(JSON_EXTRACT(request_data, '$.email')) = ?", "%/RegisterBorrower", borrower.Email).Error; err != nil {
+				return err
+			}
+		}
+
+		// The row stays so ids in the audit log and statistics still resolve
+		return tx.Table("borrowers").Where("id = ?", borrowerID).Updates(map[string]interface{}{
+			"name":      erasedBorrowerName,
+			"email":     nil,
+			"password":  nil,
+			"erased_at": time.Now().Format(helpers.DateTimeLayout),
+		}).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReturnSimpleResponse{Success: true, Message: "Account erased"}, nil
+}
+
+// subjectOf is the borrower a request is about, admins name one and borrowers
+// may only ask about themselves
+func subjectOf(ctx context.Context, borrowerID int32) (int32, string, error) {
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return 0, "", status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role == "admin" {
+		if borrowerID == 0 {
+			return 0, role, status.Errorf(codes.InvalidArgument, "borrower_id is required")
+		}
+		return borrowerID, role, nil
+	}
+
+	if borrowerID != 0 && borrowerID != int32(userID) {
+		return 0, role, status.Errorf(codes.PermissionDenied, "borrower_id is only available for admin")
+	}
+	return int32(userID), role, nil
+}
+
+// collect reads everything stored about a borrower
+func (s *AccountService) collect(borrowerID int32) (*dataExport, error) {
+	bundle := &dataExport{ExportedAt: time.Now().Format(helpers.DateTimeLayout)}
+
+	if err := s.DB.Table("borrowers").
+		Select("id, name, COALESCE(email, '') email, COALESCE(created_at, '') created_at, COALESCE(updated_at, '') updated_at, COALESCE(erased_at, '') erased_at").
+		Where("id = ?", borrowerID).
+		Take(&bundle.Profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "borrower not found")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	defaults := defaultNotificationPreferences
+	queries := []struct {
+		dest interface{}
+		sql  *gorm.DB
+	}{
+		{&bundle.Settings, s.DB.Raw("SELECT COALESCE(ps.reading_history, ?) reading_history, "+
+			"COALESCE(np.email_enabled, ?) email_enabled, COALESCE(np.sms_enabled, ?) sms_enabled, COALESCE(np.inapp_enabled, ?) inapp_enabled, "+
+			"COALESCE(np.phone, '') phone, COALESCE(np.reminder_days_before, ?) reminder_days_before "+
+			"FROM borrowers br LEFT JOIN privacy_settings ps on ps.borrower_id = br.id LEFT JOIN notification_preferences np on np.borrower_id = br.id "+
+			"WHERE br.id = ?", worker.HistoryRetain, defaults.EmailEnabled, defaults.SmsEnabled, defaults.InappEnabled, defaults.ReminderDaysBefore, borrowerID)},
+		{&bundle.Loans, s.DB.Table("borrowing_transactions bt").
+			Joins("JOIN books b on b.id = bt.book_id").
+			Select("bt.id, bt.book_id, b.title, b.isbn, COALESCE(bt.borrowed_at, '') borrowed_at, bt.due_date, COALESCE(bt.returned_at, '') returned_at, "+
+				"COALESCE(bt.status, '') status, bt.renewals").
+			Where("bt.borrower_id = ?", borrowerID).
+			Order("bt.id")},
+		{&bundle.Returns, s.DB.Table("returning_transactions rt").
+			Joins("JOIN borrowing_transactions bt on bt.id = rt.borrowing_transaction_id").
+			Select("rt.id, rt.borrowing_transaction_id transaction_id, rt.returned_at, rt.fine_amount").
+			Where("bt.borrower_id = ?", borrowerID).
+			Order("rt.id")},
+		{&bundle.Fines, s.DB.Table("returning_transactions rt").
+			Joins("JOIN borrowing_transactions bt on bt.id = rt.borrowing_transaction_id").
+			Joins("JOIN books b on b.id = bt.book_id").
+			Select("rt.borrowing_transaction_id transaction_id, b.title, rt.fine_amount amount, rt.returned_at assessed_at, COALESCE(rt.fine_paid_at, '') paid_at").
+			Where("bt.borrower_id = ? AND rt.fine_amount > 0", borrowerID).
+			Order("rt.id")},
+		{&bundle.Notifications, s.DB.Table("notifications").
+			Select("id, kind, title, COALESCE(body, '') body, COALESCE(transaction_id, 0) transaction_id, COALESCE(read_at, '') read_at, created_at").
+			Where("borrower_id = ?", borrowerID).
+			Order("id")},
+		{&bundle.Reviews, s.DB.Table("book_reviews r").
+			Joins("JOIN books b on b.id = r.book_id").
+			Select("r.id, r.book_id, b.title, r.rating, COALESCE(r.body, '') body, r.status, COALESCE(r.created_at, '') created_at, COALESCE(r.updated_at, '') updated_at").
+			Where("r.borrower_id = ?", borrowerID).
+			Order("r.id")},
+		{&bundle.Holds, s.DB.Table("holds h").
+			Joins("JOIN books b on b.id = h.book_id").
+			Select("h.id, h.book_id, b.title, h.status, COALESCE(h.created_at, '') created_at, COALESCE(h.ready_at, '') ready_at, COALESCE(h.closed_at, '') closed_at").
+			Where("h.borrower_id = ?", borrowerID).
+			Order("h.id")},
+		{&bundle.ReadingLists, s.DB.Table("reading_lists").
+			Select("id, name, COALESCE(description, '') description, share_token IS NOT NULL shared, COALESCE(created_at, '') created_at").
+			Where("borrower_id = ?", borrowerID).
+			Order("id")},
+	}
+
+	for _, q := range queries {
+		if err := q.sql.Scan(q.dest).Error; err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	var items []exportReadingListItem
+	if err := s.DB.Table("reading_list_items li").
+		Joins("JOIN reading_lists rl on rl.id = li.list_id").
+		Joins("JOIN books b on b.id = li.book_id").
+		Select("li.list_id, li.book_id, b.title, li.position, COALESCE(li.note, '') note, COALESCE(li.added_at, '') added_at").
+		Where("rl.borrower_id = ?", borrowerID).
+		Order("li.list_id, li.position").
+		Scan(&items).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	for i := range bundle.ReadingLists {
+		for _, item := range items {
+			if item.ListID == bundle.ReadingLists[i].ID {
+				bundle.ReadingLists[i].Items = append(bundle.ReadingLists[i].Items, item)
+			}
+		}
+	}
+
+	return bundle, nil
+}
+
+// zipSections writes every section of the bundle as its own JSON file
+func zipSections(bundle *dataExport) ([]byte, error) {
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", map[string]interface{}{"exported_at": bundle.ExportedAt, "profile": bundle.Profile}},
+		{"settings.json", bundle.Settings},
+		{"loans.json", bundle.Loans},
+		{"returns.json", bundle.Returns},
+		{"fines.json", bundle.Fines},
+		{"notifications.json", bundle.Notifications},
+		{"reviews.json", bundle.Reviews},
+		{"holds.json", bundle.Holds},
+		{"reading_lists.json", bundle.ReadingLists},
+	}
+
+	for _, section := range sections {
+		data, err := json.MarshalIndent(section.data, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		w, err := z.Create(section.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := z.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
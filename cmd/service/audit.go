@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"go-grpc/helpers"
+	pb "go-grpc/pb/library"
+	paginationPb "go-grpc/pb/pagination"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type AuditService struct {
+	pb.UnimplementedAuditServiceServer
+	DB *gorm.DB
+}
+
+// ListAuditEvents(context.Context, *ListAuditEventsRequest) (*AuditEventsResponse, error)
+func (s *AuditService) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.AuditEventsResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	var events []*pb.AuditEvent
+	var pagination paginationPb.Pagination
+
+	sql := s.DB.Table("audit_events as ae").
+		Select("ae.id, COALESCE(ae.actor_id, 0), COALESCE(ae.actor_role, ''), ae.method, COALESCE(ae.entity, ''), COALESCE(ae.entity_id, 0), " +
+			"COALESCE(ae.request_data, ''), COALESCE(ae.before_data, ''), COALESCE(ae.after_data, ''), COALESCE(ae.diff, ''), " +
+			"COALESCE(ae.client_ip, ''), ae.status, COALESCE(ae.error, ''), ae.created_at")
+
+	if req.GetActorId() > 0 {
+		sql = sql.Where("ae.actor_id = ?", req.GetActorId())
+	}
+	if req.GetActorRole() != "" {
+		sql = sql.Where("ae.actor_role = ?", req.GetActorRole())
+	}
+	if req.GetEntity() != "" {
+		sql = sql.Where("ae.entity = ?", req.GetEntity())
+	}
+	if req.GetEntityId() > 0 {
+		sql = sql.Where("ae.entity_id = ?", req.GetEntityId())
+	}
+	if req.GetFrom() != "" {
+		sql = sql.Where("ae.created_at >= ?", req.GetFrom())
+	}
+	if req.GetTo() != "" {
+		sql = sql.Where("ae.created_at < ?", req.GetTo())
+	}
+
+	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
+
+	rows, err := sql.Order("ae.id DESC").Offset(int(offset)).Limit(int(limit)).Rows()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var event pb.AuditEvent
+
+		if err := rows.Scan(&event.Id, &event.ActorId, &event.ActorRole, &event.Method, &event.Entity, &event.EntityId,
+			&event.Request, &event.Before, &event.After, &event.Diff, &event.ClientIp, &event.Status, &event.Error, &event.CreatedAt); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		events = append(events, &event)
+	}
+
+	return &pb.AuditEventsResponse{
+		Pagination: &pagination,
+		Data:       events,
+	}, nil
+}
@@ -2,15 +2,18 @@ package service
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"go-grpc/helpers"
 	pb "go-grpc/pb/library"
 	paginationPb "go-grpc/pb/pagination"
+	"time"
 
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AuthorService struct {
@@ -21,11 +24,28 @@ type AuthorService struct {
 // ListAuthors(context.Context, *ParameterReq) (*AuthorsResponse, error)
 func (s *AuthorService) ListAuthors(ctx context.Context, req *pb.ParameterReq) (*pb.AuthorsResponse, error) {
 
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if req.IncludeDeleted && role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "include_deleted is only available for admin")
+	}
+
 	var authors []*pb.Author
 	var pagination paginationPb.Pagination
 
 	sql := s.DB.Table("authors as a").
-		Select("a.id, a.name, a.bio")
+		Select("a.id, a.name, a.bio, COALESCE(a.deleted_at, '')")
+
+	if !req.IncludeDeleted {
+		sql = sql.Where("a.deleted_at IS NULL")
+	}
+
+	if req.Search != "" {
+		sql = sql.Where("a.name LIKE ?", helpers.Contains(req.Search))
+	}
 
 	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
 
@@ -39,7 +59,7 @@ func (s *AuthorService) ListAuthors(ctx context.Context, req *pb.ParameterReq) (
 	for rows.Next() {
 		var author pb.Author
 
-		if err := rows.Scan(&author.Id, &author.Name, &author.Bio); err != nil {
+		if err := rows.Scan(&author.Id, &author.Name, &author.Bio, &author.DeletedAt); err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 
@@ -57,14 +77,25 @@ func (s *AuthorService) ListAuthors(ctx context.Context, req *pb.ParameterReq) (
 // GetAuthor(context.Context, *IdRequest) (*AuthorResponse, error)
 func (s *AuthorService) GetAuthor(ctx context.Context, req *pb.IdRequest) (*pb.AuthorResponse, error) {
 
-	row := s.DB.Table("authors as a").
-		Select("a.id, a.name, a.bio").
-		Where("a.id = ?", req.GetId()).
-		Row()
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	sql := s.DB.Table("authors as a").
+		Select("a.id, a.name, a.bio, COALESCE(a.deleted_at, '')").
+		Where("a.id = ?", req.GetId())
+
+	// Deleted authors are only visible to admin
+	if role != "admin" {
+		sql = sql.Where("a.deleted_at IS NULL")
+	}
+
+	row := sql.Row()
 
 	var auhtor pb.Author
 
-	if err := row.Scan(&auhtor.Id, &auhtor.Name, &auhtor.Bio); err != nil {
+	if err := row.Scan(&auhtor.Id, &auhtor.Name, &auhtor.Bio, &auhtor.DeletedAt); err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -94,7 +125,10 @@ func (s *AuthorService) UpdateAuthor(ctx context.Context, req *pb.Author) (*pb.A
 			return fmt.Errorf("no ParamRequests affected")
 		}
 
-		return nil
+		// Records of the books the author contributed to change with the name, bump their OAI-PMH datestamp
+		return tx.Table("books").
+			Where("id IN (SELECT book_id FROM book_contributors WHERE author_id = ?)", author.GetId()).
+			Update("updated_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
 
 	})
 
@@ -114,7 +148,7 @@ func (s *AuthorService) DeleteAuthor(ctx context.Context, req *pb.IdRequest) (*p
 
 	q := s.DB.Table("books as b").
 		Select("b.id").
-		Where("author_id = ?", req.GetId()).
+		Where("b.id IN (SELECT book_id FROM book_contributors WHERE author_id = ?) AND deleted_at IS NULL", req.GetId()).
 		Row()
 
 	var book pb.Book
@@ -128,9 +162,92 @@ func (s *AuthorService) DeleteAuthor(ctx context.Context, req *pb.IdRequest) (*p
 	if book.Id > 0 {
 		return nil, status.Error(codes.Canceled, "author id sudah tercantum di book, tidak bisa di hapus")
 	} else {
-		if err := s.DB.Table("authors").Where("id = ?", req.Id).Delete(nil).Error; err != nil {
-			return nil, err
+		result := s.DB.Table("authors").
+			Where("id = ? AND deleted_at IS NULL", req.Id).
+			Update("deleted_at", time.Now().Format(helpers.DateTimeLayout))
+		if result.Error != nil {
+			return nil, result.Error
 		}
+		if result.RowsAffected == 0 {
+			return nil, status.Errorf(codes.NotFound, "author not found or already deleted")
+		}
+	}
+	return &pb.Empty{}, nil
+}
+
+// RestoreAuthor(context.Context, *IdRequest) (*AuthorResponse, error)
+func (s *AuthorService) RestoreAuthor(ctx context.Context, req *pb.IdRequest) (*pb.AuthorResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
 	}
-	return nil, nil
+
+	result := s.DB.Table("authors").
+		Where("id = ? AND deleted_at IS NOT NULL", req.GetId()).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, status.Errorf(codes.NotFound, "author not found or not deleted")
+	}
+
+	return s.GetAuthor(ctx, req)
+}
+
+// PurgeAuthor(context.Context, *IdRequest) (*Empty, error)
+func (s *AuthorService) PurgeAuthor(ctx context.Context, req *pb.IdRequest) (*pb.Empty, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		row := tx.Table("authors").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("deleted_at IS NOT NULL").
+			Where("id = ?", req.GetId()).
+			Row()
+
+		var deleted bool
+		if err := row.Scan(&deleted); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return status.Errorf(codes.NotFound, "author not found")
+			}
+			return err
+		}
+
+		if !deleted {
+			return status.Errorf(codes.FailedPrecondition, "author must be deleted before it can be purged")
+		}
+
+		// Deleting the author would cascade to its books and their loan history,
+		// and take the author off the books they contributed to
+		var books int64
+		if err := tx.Table("book_contributors").Where("author_id = ?", req.GetId()).Distinct("book_id").Count(&books).Error; err != nil {
+			return err
+		}
+
+		if books > 0 {
+			return status.Errorf(codes.FailedPrecondition, "author is referenced by %d book(s) and cannot be purged", books)
+		}
+
+		return tx.Table("authors").Where("id = ?", req.GetId()).Delete(nil).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Empty{}, nil
 }
@@ -1,7 +1,14 @@
 package service
 
 import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"go-grpc/catalog"
+	"go-grpc/events"
 	"go-grpc/helpers"
+	"go-grpc/model"
 	pb "go-grpc/pb/library"
 	paginationPb "go-grpc/pb/pagination"
 
@@ -9,23 +16,35 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookService struct {
 	pb.UnimplementedBookServiceServer
-	DB *gorm.DB
+	DB     *gorm.DB
+	Events *events.CirculationFeed
 }
 
 // ListBooks(context.Context, *ParameterReq) (*BooksResponse, error)
 func (s *BookService) ListBooks(ctx context.Context, req *pb.ParameterReq) (*pb.BooksResponse, error) {
 
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if req.IncludeDeleted && role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "include_deleted is only available for admin")
+	}
+
 	var books []*pb.Book
 	var pagination paginationPb.Pagination
 
-	sql := s.DB.Table("books as b").
-		Joins("LEFT JOIN authors au on au.id = b.author_id").
-		Joins("LEFT JOIN categories c on c.id = b.category_id").
-		Select("b.id, b.title,b.publication_year, b.description, au.id, au.name, au.bio, c.id, c.name category_name, c.description")
+	sql := catalog.Search(catalog.Query(s.DB), req.Search)
+
+	if !req.IncludeDeleted {
+		sql = sql.Where("b.deleted_at IS NULL")
+	}
 
 	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
 
@@ -37,18 +56,19 @@ func (s *BookService) ListBooks(ctx context.Context, req *pb.ParameterReq) (*pb.
 	defer rows.Close()
 
 	for rows.Next() {
-		var book pb.Book
-		var author pb.Author
-		var category pb.Category
-
-		if err := rows.Scan(&book.Id, &book.Title, &book.PublicationYear, &book.Description, &author.Id, &author.Name, &author.Bio, &category.Id, &category.Name, &category.Description); err != nil {
+		book, err := catalog.ScanBook(rows)
+		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 
-		book.Author = &author
-		book.Category = &category
+		books = append(books, book)
+	}
 
-		books = append(books, &book)
+	if err := catalog.Ratings(s.DB, books); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err := catalog.Contributors(s.DB, books); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	booksRes := &pb.BooksResponse{
@@ -62,32 +82,74 @@ func (s *BookService) ListBooks(ctx context.Context, req *pb.ParameterReq) (*pb.
 // GetBook(context.Context, *BookRequest) (*BookResponse, error)
 func (s *BookService) GetBook(ctx context.Context, req *pb.BookRequest) (*pb.BookResponse, error) {
 
-	row := s.DB.Table("books as b").
-		Joins("LEFT JOIN authors au on au.id = b.author_id").
-		Joins("LEFT JOIN categories c on c.id = b.category_id").
-		Select("b.id, b.title,b.publication_year, b.description, au.id, au.name, au.bio, c.id, c.name category_name, c.description").
-		Where("b.id = ?", req.GetId()).
-		Row()
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	sql := catalog.Query(s.DB).Where("b.id = ?", req.GetId())
 
-	var book pb.Book
-	var author pb.Author
-	var category pb.Category
+	// Deleted books are only visible to admin
+	if role != "admin" {
+		sql = sql.Where("b.deleted_at IS NULL")
+	}
 
-	if err := row.Scan(&book.Id, &book.Title, &book.PublicationYear, &book.Description, &author.Id, &author.Name, &author.Bio, &category.Id, &category.Name, &category.Description); err != nil {
+	book, err := catalog.ScanBook(sql.Row())
+	if err != nil {
 		return nil, status.Error(codes.NotFound, err.Error())
 	}
 
-	book.Author = &author
-	book.Category = &category
+	if err := catalog.Ratings(s.DB, []*pb.Book{book}); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err := catalog.Contributors(s.DB, []*pb.Book{book}); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
 
 	bookRes := &pb.BookResponse{
-		Data: &book,
+		Data: book,
 	}
 
 	return bookRes, nil
 
 }
 
+// GetBookByIsbn(context.Context, *IsbnRequest) (*BookResponse, error)
+func (s *BookService) GetBookByIsbn(ctx context.Context, req *pb.IsbnRequest) (*pb.BookResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	isbn := helpers.NormalizeISBN(req.GetIsbn())
+	if !helpers.ValidISBN(isbn) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid isbn %q", req.GetIsbn())
+	}
+
+	// Books may be catalogued with either form, barcodes always carry the ISBN-13
+	sql := catalog.Query(s.DB).Where("b.isbn IN ?", []string{helpers.ISBN13(isbn), helpers.ISBN10(isbn)})
+
+	// Deleted books are only visible to admin
+	if role != "admin" {
+		sql = sql.Where("b.deleted_at IS NULL")
+	}
+
+	book, err := catalog.ScanBook(sql.Row())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	if err := catalog.Ratings(s.DB, []*pb.Book{book}); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err := catalog.Contributors(s.DB, []*pb.Book{book}); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.BookResponse{Data: book}, nil
+}
+
 // CreateBook(context.Context, *Book) (*BookResponse, error)
 func (s *BookService) CreateBook(ctx context.Context, book *pb.CreateBookRequest) (*pb.BookResponse, error) {
 
@@ -100,36 +162,54 @@ func (s *BookService) CreateBook(ctx context.Context, book *pb.CreateBookRequest
 		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
 	}
 
+	isbn, err := s.checkIsbn(book.GetIsbn(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// A bare author_id is the only author, as before contributors
+	requested := book.GetContributors()
+	if len(requested) == 0 {
+		requested = []*pb.Contributor{{AuthorId: book.GetAuthorId(), Role: catalog.RoleAuthor}}
+	}
+
+	contributors, err := s.checkContributors(requested)
+	if err != nil {
+		return nil, err
+	}
+
 	err = s.DB.Transaction(func(tx *gorm.DB) error {
-		category := pb.Category{
-			Id:          0,
+		category := model.Category{
 			Name:        book.GetCategory().GetName(),
 			Description: book.GetCategory().GetDescription(),
 		}
 
-		if err := tx.Table("categories").Where("LCASE(name) = ?", category.GetName()).FirstOrCreate(&category).Error; err != nil {
+		if err := tx.Table("categories").Where("LCASE(name) = ? AND deleted_at IS NULL", category.Name).FirstOrCreate(&category).Error; err != nil {
 			return err
 		}
 
 		b := struct {
+			ID              int32 `gorm:"primaryKey"`
 			Title           string
 			Description     string
 			AuthorID        uint64
 			CategoryID      uint64
 			PublicationYear uint32
+			ISBN            sql.NullString
 		}{
 			Title:           book.GetTitle(),
 			Description:     book.GetDescription(),
-			AuthorID:        uint64(book.GetAuthorId()),
-			CategoryID:      uint64(category.GetId()),
+			AuthorID:        uint64(catalog.FirstAuthor(contributors)),
+			CategoryID:      uint64(category.ID),
 			PublicationYear: uint32(book.PublicationYear),
+			ISBN:            isbn,
 		}
 
 		if err := tx.Table("books").Create(&b).Error; err != nil {
 			return err
 		}
 
-		return nil
+		return catalog.SetContributors(tx, b.ID, contributors)
 
 	})
 
@@ -148,6 +228,19 @@ func (s *BookService) UpdateBook(ctx context.Context, req *pb.BookUpdateReq) (*p
 		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
 	}
 
+	isbn, err := s.checkIsbn(req.GetIsbn(), req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var contributors []catalog.Contributor
+	if len(req.GetContributors()) > 0 {
+		contributors, err = s.checkContributors(req.GetContributors())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = s.DB.Transaction(func(tx *gorm.DB) error {
 
 		b := struct {
@@ -156,17 +249,22 @@ func (s *BookService) UpdateBook(ctx context.Context, req *pb.BookUpdateReq) (*p
 			AuthorID        uint64
 			CategoryID      uint64
 			PublicationYear uint32
+			ISBN            sql.NullString
 		}{
 			Title:           req.GetTitle(),
 			Description:     req.GetDescription(),
 			PublicationYear: uint32(req.PublicationYear),
+			ISBN:            isbn,
 		}
 
 		if err := tx.Table("books").Where("id = ?", req.Id).Updates(&b).Error; err != nil {
 			return err
 		}
 
-		return nil
+		if contributors == nil {
+			return nil
+		}
+		return catalog.SetContributors(tx, req.Id, contributors)
 
 	})
 
@@ -185,9 +283,170 @@ func (s *BookService) DeleteBook(ctx context.Context, req *pb.BookRequest) (*pb.
 		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
 	}
 
-	if err = s.DB.Table("books").Where("id = ?", req.Id).Delete(nil).Error; err != nil {
+	// Soft delete, stock and loan history stay untouched
+	result := s.DB.Table("books").
+		Where("id = ? AND deleted_at IS NULL", req.Id).
+		Update("deleted_at", time.Now().Format(helpers.DateTimeLayout))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, status.Errorf(codes.NotFound, "book not found or already deleted")
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// RestoreBook(context.Context, *BookRequest) (*BookResponse, error)
+func (s *BookService) RestoreBook(ctx context.Context, req *pb.BookRequest) (*pb.BookResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	row := s.DB.Table("books as b").
+		Select("b.deleted_at IS NOT NULL, "+catalog.ByContributor("ca.deleted_at IS NOT NULL")).
+		Where("b.id = ?", req.GetId()).
+		Row()
+
+	var bookDeleted, authorDeleted bool
+
+	if err := row.Scan(&bookDeleted, &authorDeleted); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "book not found")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if !bookDeleted {
+		return nil, status.Errorf(codes.FailedPrecondition, "book is not deleted")
+	}
+
+	if authorDeleted {
+		return nil, status.Errorf(codes.FailedPrecondition, "a contributor of the book is deleted, restore the author first")
+	}
+
+	if err := s.DB.Table("books").Where("id = ?", req.GetId()).Update("deleted_at", nil).Error; err != nil {
 		return nil, err
 	}
 
-	return nil, nil
+	return s.GetBook(ctx, req)
+}
+
+// PurgeBook(context.Context, *BookRequest) (*Empty, error)
+func (s *BookService) PurgeBook(ctx context.Context, req *pb.BookRequest) (*pb.Empty, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		row := tx.Table("books").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("deleted_at IS NOT NULL").
+			Where("id = ?", req.GetId()).
+			Row()
+
+		var deleted bool
+		if err := row.Scan(&deleted); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return status.Errorf(codes.NotFound, "book not found")
+			}
+			return err
+		}
+
+		if !deleted {
+			return status.Errorf(codes.FailedPrecondition, "book must be deleted before it can be purged")
+		}
+
+		var loans int64
+		if err := tx.Table("borrowing_transactions").Where("book_id = ?", req.GetId()).Count(&loans).Error; err != nil {
+			return err
+		}
+
+		if loans > 0 {
+			return status.Errorf(codes.FailedPrecondition, "book is referenced by %d loan(s) and cannot be purged", loans)
+		}
+
+		// OAI-PMH harvesters still see the book, as a deleted record
+		if err := tx.Exec("INSERT INTO deleted_books (book_id, category_id) SELECT id, category_id FROM books WHERE id = ? ON DUPLICATE KEY UPDATE deleted_at = CURRENT_TIMESTAMP", req.GetId()).Error; err != nil {
+			return err
+		}
+
+		// book_stocks and stock_movements are removed by ON DELETE CASCADE
+		return tx.Table("books").Where("id = ?", req.GetId()).Delete(nil).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// checkContributors validates the requested contributors of a book, every one
+// must be an author that is not deleted
+func (s *BookService) checkContributors(requested []*pb.Contributor) ([]catalog.Contributor, error) {
+	contributors := make([]catalog.Contributor, len(requested))
+	ids := make([]int32, len(requested))
+	for i, c := range requested {
+		contributors[i] = catalog.Contributor{AuthorID: c.GetAuthorId(), Role: c.GetRole()}
+		ids[i] = c.GetAuthorId()
+	}
+
+	contributors, err := catalog.CheckContributors(contributors)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var found []int32
+	if err := s.DB.Table("authors").Where("id IN ? AND deleted_at IS NULL", ids).Pluck("id", &found).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	known := map[int32]bool{}
+	for _, id := range found {
+		known[id] = true
+	}
+	for _, id := range ids {
+		if !known[id] {
+			return nil, status.Errorf(codes.NotFound, "author %d not found", id)
+		}
+	}
+
+	return contributors, nil
+}
+
+// checkIsbn normalizes an optional ISBN and makes sure no other book already uses it
+func (s *BookService) checkIsbn(value string, bookID int32) (sql.NullString, error) {
+	if value == "" {
+		return sql.NullString{}, nil
+	}
+
+	isbn := helpers.NormalizeISBN(value)
+	if !helpers.ValidISBN(isbn) {
+		return sql.NullString{}, status.Errorf(codes.InvalidArgument, "invalid isbn %q", value)
+	}
+
+	var count int64
+	if err := s.DB.Table("books").
+		Where("isbn IN ? AND id <> ?", []string{helpers.ISBN13(isbn), helpers.ISBN10(isbn)}, bookID).
+		Count(&count).Error; err != nil {
+		return sql.NullString{}, status.Error(codes.Internal, err.Error())
+	}
+	if count > 0 {
+		return sql.NullString{}, status.Errorf(codes.AlreadyExists, "a book with isbn %v already exists", isbn)
+	}
+
+	return sql.NullString{String: isbn, Valid: true}, nil
 }
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"go-grpc/catalog"
+	"go-grpc/citation"
+	pb "go-grpc/pb/library"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Books per citation export, the response is a single message
+const maxCitations = 1000
+
+// ExportCitations(context.Context, *ExportCitationsRequest) (*ExportCitationsResponse, error)
+func (s *BookService) ExportCitations(ctx context.Context, req *pb.ExportCitationsRequest) (*pb.ExportCitationsResponse, error) {
+
+	format, err := citation.ParseFormat(req.Format)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if len(req.BookIds) == 0 && req.Search == "" {
+		return nil, status.Error(codes.InvalidArgument, "book_ids or search is required")
+	}
+
+	books, err := catalog.Books(s.DB, catalog.Filter{IDs: req.BookIds, Search: req.Search, Limit: maxCitations})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if len(books) == 0 {
+		return nil, status.Error(codes.NotFound, "no book found")
+	}
+
+	entries := make([]citation.Entry, len(books))
+	for i, book := range books {
+		entries[i] = citation.FromBook(book)
+	}
+
+	content, err := citation.Render(format, entries)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ExportCitationsResponse{
+		Format:      format,
+		ContentType: citation.ContentTypes[format],
+		Count:       int32(len(entries)),
+		Content:     content,
+	}, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"log"
+	"sort"
+
+	"go-grpc/helpers"
+	"go-grpc/importer"
+	pb "go-grpc/pb/library"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// ImportBooks(BookService_ImportBooksServer) error
+func (s *BookService) ImportBooks(stream pb.BookService_ImportBooksServer) error {
+
+	userID, role, err := helpers.GetData(stream.Context())
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	var imp *importer.Importer
+
+	// A batch that cannot be written ends the import, the report so far is sent back
+	failed := false
+
+	for !failed {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if imp == nil {
+			imp = s.newImporter(req.DryRun, userID, role)
+		}
+
+		for _, row := range req.Rows {
+			if row.Error != "" {
+				imp.Fail(int(row.Line), errors.New(row.Error))
+				continue
+			}
+
+			if err := imp.Add(importRowFromPb(row)); err != nil {
+				log.Printf("import books: %v", err)
+				failed = true
+				break
+			}
+		}
+	}
+
+	if imp == nil {
+		return status.Error(codes.InvalidArgument, "no rows to import")
+	}
+
+	return stream.SendAndClose(s.finishImport(imp))
+}
+
+// newImporter records the copies of imported books as purchases in the stock ledger
+func (s *BookService) newImporter(dryRun bool, userID int, role string) *importer.Importer {
+	return importer.New(s.DB, dryRun, func(tx *gorm.DB, bookID, copies int32) error {
+		_, err := adjustStock(tx, bookID, copies, "purchase", "import", userID, role)
+		return err
+	})
+}
+
+// finishImport flushes the last batch and reports every row in source order,
+// the rows of a batch that could not be written are reported as failed
+func (s *BookService) finishImport(imp *importer.Importer) *pb.ImportBooksResponse {
+	if err := imp.Flush(); err != nil {
+		log.Printf("import books: %v", err)
+	}
+
+	if !imp.DryRun {
+		s.Events.Notify()
+	}
+
+	// Parse failures are reported right away and batches when flushed, restore the file order
+	results := imp.Results()
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Line < results[j].Line })
+
+	resp := &pb.ImportBooksResponse{DryRun: imp.DryRun}
+	for _, result := range results {
+		resp.Total++
+		switch result.Status {
+		case importer.StatusCreated:
+			resp.Created++
+		case importer.StatusSkipped:
+			resp.Skipped++
+		default:
+			resp.Failed++
+		}
+
+		resp.Rows = append(resp.Rows, &pb.ImportRowResult{
+			Line:   int32(result.Line),
+			Isbn:   result.ISBN,
+			Title:  result.Title,
+			Status: result.Status,
+			BookId: result.BookID,
+			Error:  result.Error,
+		})
+	}
+
+	return resp
+}
+
+func importRowFromPb(row *pb.ImportBookRow) importer.Row {
+	return importer.Row{
+		Line:         int(row.Line),
+		Title:        row.Title,
+		ISBN:         row.Isbn,
+		Authors:      row.Authors,
+		Editors:      row.Editors,
+		Translators:  row.Translators,
+		Illustrators: row.Illustrators,
+		Category:     row.Category,
+		Year:         row.PublicationYear,
+		Description:  row.Description,
+		Copies:       row.Copies,
+	}
+}
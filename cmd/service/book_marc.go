@@ -0,0 +1,162 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"go-grpc/catalog"
+	"go-grpc/helpers"
+	"go-grpc/marc"
+	pb "go-grpc/pb/library"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Size of the MarcChunk messages of an export
+const marcChunkSize = 32 << 10
+
+// ImportMarc(BookService_ImportMarcServer) error
+func (s *BookService) ImportMarc(stream pb.BookService_ImportMarcServer) error {
+
+	userID, role, err := helpers.GetData(stream.Context())
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "no MARC data to import")
+	}
+	if err != nil {
+		return err
+	}
+
+	// The file arrives in chunks, records are decoded while it streams in
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	reader, err := marc.NewFormatReader(pr, first.Format)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	go func() {
+		req := first
+		for {
+			if _, err := pw.Write(req.Data); err != nil {
+				return
+			}
+
+			var err error
+			req, err = stream.Recv()
+			if errors.Is(err, io.EOF) {
+				pw.Close()
+				return
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	imp := s.newImporter(first.DryRun, userID, role)
+
+	// Records are numbered from 1 in the report
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var recordErr *marc.RecordError
+		if errors.As(err, &recordErr) {
+			imp.Fail(n, recordErr.Err)
+			continue
+		}
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "failed to read record %d: %v", n, err)
+		}
+
+		row := marc.ToRow(record)
+		row.Line = n
+		row.Copies = first.Copies
+
+		// A batch that cannot be written ends the import, the report so far is sent back
+		if err := imp.Add(row); err != nil {
+			log.Printf("import marc: %v", err)
+			break
+		}
+	}
+
+	return stream.SendAndClose(s.finishImport(imp))
+}
+
+// ExportMarc(*ExportMarcRequest, BookService_ExportMarcServer) error
+func (s *BookService) ExportMarc(req *pb.ExportMarcRequest, stream pb.BookService_ExportMarcServer) error {
+
+	_, role, err := helpers.GetData(stream.Context())
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if req.IncludeDeleted && role != "admin" {
+		return status.Errorf(codes.PermissionDenied, "include_deleted is only available for admin")
+	}
+
+	books, err := catalog.Books(s.DB, catalog.Filter{
+		IDs:            req.BookIds,
+		Search:         req.Search,
+		IncludeDeleted: req.IncludeDeleted,
+	})
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if len(req.BookIds) > 0 && len(books) == 0 {
+		return status.Error(codes.NotFound, "no book found")
+	}
+
+	out := bufio.NewWriterSize(chunkWriter{stream}, marcChunkSize)
+
+	writer, err := marc.NewWriter(out, req.Format)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	now := time.Now()
+	for _, book := range books {
+		if err := writer.Write(marc.FromBook(book, now)); err != nil {
+			return status.Errorf(codes.Internal, "book %d: %v", book.Id, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return out.Flush()
+}
+
+// chunkWriter sends what is written as MarcChunk messages
+type chunkWriter struct {
+	stream pb.BookService_ExportMarcServer
+}
+
+func (c chunkWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	if err := c.stream.Send(&pb.MarcChunk{Data: data}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -1,19 +1,37 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"time"
+
+	"go-grpc/events"
 	"go-grpc/helpers"
+	"go-grpc/model"
 	pb "go-grpc/pb/library"
+	paginationPb "go-grpc/pb/pagination"
 
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Reasons accepted by AdjustStock. Borrow and return movements are written
+// by the circulation services only.
+var adjustReasons = map[string]bool{
+	"purchase":   true,
+	"loss":       true,
+	"damage":     true,
+	"donation":   true,
+	"correction": true,
+}
+
 type BookStockService struct {
 	pb.UnimplementedBookStockServiceServer
-	DB *gorm.DB
+	DB     *gorm.DB
+	Events *events.CirculationFeed
 }
 
 // GetBookStock(context.Context, *BookStockRequest) (*BookStockResponse, error)
@@ -23,7 +41,7 @@ func (s *BookStockService) GetBookStock(ctx context.Context, req *pb.IdRequest)
 		Joins("LEFT JOIN authors au on au.id = b.author_id").
 		Joins("LEFT JOIN categories c on c.id = b.category_id").
 		Joins("LEFT JOIN book_stocks bs on bs.book_id = b.id").
-		Select("b.id, b.title,b.publication_year, b.description, au.id, au.name, au.bio, c.id, c.name category_name, c.description, bs.id, bs.total_stock").
+		Select("b.id, b.title,b.publication_year, b.description, COALESCE(b.isbn, ''), au.id, au.name, au.bio, c.id, c.name category_name, c.description, bs.id, bs.total_stock").
 		Where("b.id = ?", req.GetId()).
 		Row()
 
@@ -32,7 +50,7 @@ func (s *BookStockService) GetBookStock(ctx context.Context, req *pb.IdRequest)
 	var category pb.Category
 	var stock pb.BookStock
 
-	if err := row.Scan(&book.Id, &book.Title, &book.PublicationYear, &book.Description, &author.Id,
+	if err := row.Scan(&book.Id, &book.Title, &book.PublicationYear, &book.Description, &book.Isbn, &author.Id,
 		&author.Name, &author.Bio, &category.Id, &category.Name, &category.Description, &stock.Id, &stock.TotalStock); err != nil {
 		return nil, status.Error(codes.NotFound, err.Error())
 	}
@@ -52,7 +70,7 @@ func (s *BookStockService) GetBookStock(ctx context.Context, req *pb.IdRequest)
 // UpdateBookStock(context.Context, *BookStockUpdate) (*BookStockResponse, error)
 func (s *BookStockService) UpdateBookStock(ctx context.Context, req *pb.BookStockUpdate) (*pb.BookStockResponse, error) {
 
-	_, role, err := helpers.GetData(ctx)
+	userID, role, err := helpers.GetData(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
 	}
@@ -61,20 +79,179 @@ func (s *BookStockService) UpdateBookStock(ctx context.Context, req *pb.BookStoc
 		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
 	}
 
+	if req.TotalStock < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "total stock cannot be negative")
+	}
+
+	// The absolute value is recorded in the ledger as a correction of the difference
 	err = s.DB.Transaction(func(tx *gorm.DB) error {
-		result := tx.Table("book_stocks").
-			Where("book_id = ?", req.BookId).
-			Update("total_stock", req.TotalStock)
-		if result.Error != nil {
-			return result.Error
+		var stock model.BookStock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("book_id = ?", req.BookId).First(&stock).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return status.Errorf(codes.NotFound, "book stock not found")
+			}
+			return err
 		}
-		if result.RowsAffected == 0 {
-			return fmt.Errorf("no ParamRequests affected")
+
+		delta := req.TotalStock - int32(stock.TotalStock)
+		if delta == 0 {
+			return nil
 		}
 
-		return nil
+		_, err := adjustStock(tx, req.BookId, delta, "correction", "stock set to absolute value", userID, role)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
 
+	s.Events.Notify()
+
+	return s.GetBookStock(ctx, &pb.IdRequest{Id: req.BookId})
+}
+
+// AdjustStock(context.Context, *AdjustStockRequest) (*BookStockResponse, error)
+func (s *BookStockService) AdjustStock(ctx context.Context, req *pb.AdjustStockRequest) (*pb.BookStockResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	if !adjustReasons[req.GetReason()] {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid reason %q, expected one of purchase, loss, damage, donation, correction", req.GetReason())
+	}
+
+	if req.GetDelta() == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "delta cannot be zero")
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		_, err := adjustStock(tx, req.GetBookId(), req.GetDelta(), req.GetReason(), req.GetNote(), userID, role)
+		return err
 	})
 
-	return nil, err
+	if err != nil {
+		return nil, err
+	}
+
+	s.Events.Notify()
+
+	return s.GetBookStock(ctx, &pb.IdRequest{Id: req.GetBookId()})
+}
+
+// ListStockMovements(context.Context, *StockMovementsRequest) (*StockMovementsResponse, error)
+func (s *BookStockService) ListStockMovements(ctx context.Context, req *pb.StockMovementsRequest) (*pb.StockMovementsResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	var movements []*pb.StockMovement
+	var pagination paginationPb.Pagination
+
+	sql := s.DB.Table("stock_movements as sm").
+		Select("sm.id, sm.book_id, sm.delta, sm.reason, COALESCE(sm.note, ''), sm.stock_after, COALESCE(sm.actor_id, 0), COALESCE(sm.actor_role, ''), sm.created_at").
+		Where("sm.book_id = ?", req.GetBookId())
+
+	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
+
+	rows, err := sql.Order("sm.id DESC").Offset(int(offset)).Limit(int(limit)).Rows()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var movement pb.StockMovement
+
+		if err := rows.Scan(&movement.Id, &movement.BookId, &movement.Delta, &movement.Reason, &movement.Note,
+			&movement.StockAfter, &movement.ActorId, &movement.ActorRole, &movement.CreatedAt); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		movements = append(movements, &movement)
+	}
+
+	return &pb.StockMovementsResponse{
+		Pagination: &pagination,
+		Data:       movements,
+	}, nil
+}
+
+// adjustStock applies a signed delta to the stock of a book and records the
+// movement and a stock_changed event. The stock row is locked with SELECT ... FOR UPDATE so concurrent
+// borrows cannot oversell the last copy; it must be called inside a transaction.
+func adjustStock(tx *gorm.DB, bookID, delta int32, reason, note string, actorID int, actorRole string) (*model.BookStock, error) {
+	var stock model.BookStock
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("book_id = ?", bookID).First(&stock).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if delta < 0 {
+			return nil, status.Errorf(codes.NotFound, "book stock not found")
+		}
+
+		// First stock for this book, e.g. a purchase for a newly created book
+		stock = model.BookStock{BookID: int(bookID)}
+		if err := tx.Create(&stock).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if stock.TotalStock+int(delta) < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "insufficient book stock")
+	}
+
+	stock.TotalStock += int(delta)
+	if err := tx.Model(&stock).Update("total_stock", stock.TotalStock).Error; err != nil {
+		return nil, err
+	}
+
+	movement := model.StockMovement{
+		BookID:     bookID,
+		Delta:      delta,
+		Reason:     reason,
+		Note:       note,
+		StockAfter: int32(stock.TotalStock),
+		ActorID:    int32(actorID),
+		ActorRole:  actorRole,
+		CreatedAt:  time.Now().Format(helpers.DateTimeLayout),
+	}
+
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, err
+	}
+
+	event := model.CirculationEvent{
+		Type:    events.StockChanged,
+		BookID:  bookID,
+		Message: fmt.Sprintf("stock %+d (%s), %d left", delta, reason, stock.TotalStock),
+	}
+
+	if err := events.Record(tx, event); err != nil {
+		return nil, err
+	}
+
+	// Copies coming back go to the borrowers waiting for the book first
+	if delta > 0 {
+		if err := readyHolds(tx, bookID, stock.TotalStock); err != nil {
+			return nil, err
+		}
+	}
+
+	return &stock, nil
 }
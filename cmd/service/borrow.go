@@ -5,18 +5,25 @@ import (
 	"errors"
 	"time"
 
+	"go-grpc/events"
 	"go-grpc/helpers"
 	"go-grpc/model"
+	"go-grpc/outbox"
 	pb "go-grpc/pb/library"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BorrowingServiceServer struct {
 	pb.UnimplementedBorrowingServiceServer
-	DB *gorm.DB
+	DB     *gorm.DB
+	Events *events.CirculationFeed
+
+	// Days returned loans are kept before borrowers who opted out of a reading history are detached
+	HistoryRetentionDays int32
 }
 
 // CreateBorrowingTransaction(context.Context, *CreateBorrowingTransactionRequest) (*BorrowingTransactionResponse, error)
@@ -56,58 +63,104 @@ type BorrowingServiceServer struct {
 //	}
 func (s *BorrowingServiceServer) CreateBorrowingTransaction(ctx context.Context, req *pb.CreateBorrowingTransactionRequest) (*pb.BorrowingTransactionResponse, error) {
 
-	userID, _, err := helpers.GetData(ctx)
+	userID, role, err := helpers.GetData(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
 	}
 
+	// Admin can check a book out on behalf of a borrower, e.g. at the front desk
+	borrowerID := int32(userID)
+	if req.BorrowerId != 0 {
+		if role != "admin" {
+			return nil, status.Errorf(codes.PermissionDenied, "borrower_id is only available for admin")
+		}
+		borrowerID = req.BorrowerId
+	}
+
 	// Parsing string ke time.Time
 	layout := "2006-01-02 15:04:05"
 
 	borrowingTransaction := model.BorrowingTransaction{
-		BorrowerID: int32(userID),
+		BorrowerID: borrowerID,
 		BookID:     req.BookId,
 		BorrowedAt: time.Now().Format(layout),
 		DueDate:    req.DueDate,
 		Status:     "borrowed",
 	}
 
-	// Start a transaction
-	tx := s.DB.Begin()
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// Deleted books can no longer be borrowed
+		var books int64
+		if err := tx.Table("books").Where("id = ? AND deleted_at IS NULL", req.BookId).Count(&books).Error; err != nil {
+			return err
+		}
+		if books == 0 {
+			return status.Errorf(codes.NotFound, "book not found")
+		}
 
-	// Save the transaction to the database
-	if err := tx.Create(&borrowingTransaction).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
+		// Erased accounts are kept without personal data and cannot borrow
+		if req.BorrowerId != 0 || role == "borrower" {
+			var borrowers int64
+			if err := tx.Table("borrowers").Where("id = ? AND erased_at IS NULL", borrowerID).Count(&borrowers).Error; err != nil {
+				return err
+			}
+			if borrowers == 0 {
+				return status.Errorf(codes.NotFound, "borrower not found")
+			}
+		}
 
-	// Update the book stock
-	var bookStock model.BookStock
-	if err := tx.Where("book_id = ?", req.BookId).First(&bookStock).Error; err != nil {
-		tx.Rollback()
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, status.Errorf(codes.NotFound, "book stock not found")
+		// Decrease the stock first, the row lock serializes concurrent borrows of the same book
+		stock, err := adjustStock(tx, req.BookId, -1, "borrow", "", userID, role)
+		if err != nil {
+			return err
 		}
-		return nil, err
-	}
 
-	if bookStock.TotalStock <= 0 {
-		tx.Rollback()
-		return nil, status.Errorf(codes.InvalidArgument, "insufficient book stock")
-	}
+		// Copies set aside for holds of other borrowers stay on the shelf
+		held, err := readyHoldCount(tx, req.BookId, borrowerID)
+		if err != nil {
+			return err
+		}
+		if int64(stock.TotalStock) < held {
+			return status.Errorf(codes.FailedPrecondition, "all copies on the shelf are held for other borrowers")
+		}
 
-	// Decrease the stock
-	bookStock.TotalStock -= 1
-	if err := tx.Save(&bookStock).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
+		if err := tx.Model(&model.Hold{}).
+			Where("borrower_id = ? AND book_id = ? AND status IN ?", borrowerID, req.BookId, openHolds).
+			Updates(map[string]interface{}{"status": holdFulfilled, "closed_at": time.Now().Format(helpers.DateTimeLayout)}).Error; err != nil {
+			return err
+		}
 
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
+		// Save the transaction to the database
+		if err := tx.Create(&borrowingTransaction).Error; err != nil {
+			return err
+		}
+
+		// Published to webhooks by the outbox dispatcher once this transaction commits
+		if err := outbox.Write(tx, outbox.LoanCreated, map[string]interface{}{
+			"transaction_id": borrowingTransaction.ID,
+			"borrower_id":    borrowingTransaction.BorrowerID,
+			"book_id":        borrowingTransaction.BookID,
+			"borrowed_at":    borrowingTransaction.BorrowedAt,
+			"due_date":       borrowingTransaction.DueDate,
+		}); err != nil {
+			return err
+		}
+
+		return events.Record(tx, model.CirculationEvent{
+			Type:          events.BookBorrowed,
+			BookID:        borrowingTransaction.BookID,
+			BorrowerID:    borrowingTransaction.BorrowerID,
+			TransactionID: borrowingTransaction.ID,
+			Message:       "due " + borrowingTransaction.DueDate,
+		})
+	})
+
+	if err != nil {
 		return nil, err
 	}
 
+	s.Events.Notify()
+
 	return &pb.BorrowingTransactionResponse{
 		Data: &pb.BorrowingTransaction{
 			Id:         borrowingTransaction.ID,
@@ -177,6 +230,7 @@ func (s *BorrowingServiceServer) GetBorrowingTransaction(ctx context.Context, re
 			DueDate:    borrowingTransaction.DueDate,
 			ReturnedAt: borrowingTransaction.ReturnedAt.String,
 			Status:     borrowingTransaction.Status,
+			Renewals:   borrowingTransaction.Renewals,
 		},
 	}, nil
 }
@@ -204,17 +258,44 @@ func (s *BorrowingServiceServer) UpdateBorrowingTransaction(ctx context.Context,
 		return nil, err
 	}
 
+	// borrower_id is NULL after anonymization and a loan cannot be attributed again
+	if existingTransaction.BorrowerID == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "transaction has been anonymized")
+	}
+
+	renewed := existingTransaction.DueDate != req.DueDate
+
 	existingTransaction.BookID = req.BookId
 	existingTransaction.BorrowerID = req.BorrowerId
 	existingTransaction.DueDate = req.DueDate
 	existingTransaction.ReturnedAt.String = req.ReturnedAt
 	existingTransaction.Status = req.Status
 
-	// Save the changes
-	if err := s.DB.Save(&existingTransaction).Error; err != nil {
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// Save the changes
+		if err := tx.Save(&existingTransaction).Error; err != nil {
+			return err
+		}
+
+		if !renewed {
+			return nil
+		}
+
+		return events.Record(tx, model.CirculationEvent{
+			Type:          events.BookRenewed,
+			BookID:        existingTransaction.BookID,
+			BorrowerID:    existingTransaction.BorrowerID,
+			TransactionID: existingTransaction.ID,
+			Message:       "due " + existingTransaction.DueDate,
+		})
+	})
+
+	if err != nil {
 		return nil, err
 	}
 
+	s.Events.Notify()
+
 	return &pb.BorrowingTransactionResponse{
 		Data: &pb.BorrowingTransaction{
 			Id:         existingTransaction.ID,
@@ -224,6 +305,7 @@ func (s *BorrowingServiceServer) UpdateBorrowingTransaction(ctx context.Context,
 			DueDate:    existingTransaction.DueDate,
 			ReturnedAt: existingTransaction.ReturnedAt.String,
 			Status:     existingTransaction.Status,
+			Renewals:   existingTransaction.Renewals,
 		},
 	}, nil
 }
@@ -282,6 +364,7 @@ func (s *BorrowingServiceServer) ListBorrowingTransactions(ctx context.Context,
 			DueDate:    transaction.DueDate,
 			ReturnedAt: transaction.ReturnedAt.String,
 			Status:     transaction.Status,
+			Renewals:   transaction.Renewals,
 		}
 		pbTransactions = append(pbTransactions, pbTransaction)
 	}
@@ -290,3 +373,231 @@ func (s *BorrowingServiceServer) ListBorrowingTransactions(ctx context.Context,
 		Data: pbTransactions,
 	}, nil
 }
+
+// WatchCirculation(*WatchCirculationRequest, BorrowingService_WatchCirculationServer) error
+func (s *BorrowingServiceServer) WatchCirculation(req *pb.WatchCirculationRequest, stream pb.BorrowingService_WatchCirculationServer) error {
+
+	userID, role, err := helpers.GetData(stream.Context())
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	borrowerID := req.GetBorrowerId()
+	if role != "admin" {
+		borrowerID = int32(userID)
+	}
+
+	types := map[string]bool{}
+	for _, t := range req.GetTypes() {
+		types[t] = true
+	}
+
+	match := func(event model.CirculationEvent) bool {
+		if req.GetBookId() > 0 && event.BookID != req.GetBookId() {
+			return false
+		}
+		if borrowerID > 0 && event.BorrowerID != borrowerID {
+			return false
+		}
+		if len(types) > 0 && !types[event.Type] {
+			return false
+		}
+		return true
+	}
+
+	// Subscribe before reading the backlog so nothing is missed in between
+	live, cancel := s.Events.Subscribe()
+	defer cancel()
+
+	lastID := req.GetLastEventId()
+
+	// Events of the backlog that may come again from the feed, which does not
+	// publish in id order when a transaction commits late
+	sent := map[int64]bool{}
+
+	if lastID > 0 {
+		for {
+			backlog, err := s.Events.Since(lastID, 500)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+
+			for _, event := range backlog {
+				if match(event) {
+					if err := stream.Send(circulationEventToPb(event)); err != nil {
+						return err
+					}
+				}
+				sent[event.ID] = true
+				lastID = event.ID
+			}
+
+			if len(backlog) < 500 {
+				break
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-live:
+			if !ok {
+				return status.Errorf(codes.Unavailable, "stream fell behind, resume with last_event_id %d", lastID)
+			}
+
+			// Already sent from the backlog
+			if sent[event.ID] {
+				delete(sent, event.ID)
+				continue
+			}
+			lastID = max(lastID, event.ID)
+
+			if !match(event) {
+				continue
+			}
+
+			if err := stream.Send(circulationEventToPb(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func circulationEventToPb(event model.CirculationEvent) *pb.CirculationEvent {
+	return &pb.CirculationEvent{
+		Id:            event.ID,
+		Type:          event.Type,
+		BookId:        event.BookID,
+		BorrowerId:    event.BorrowerID,
+		TransactionId: event.TransactionID,
+		Message:       event.Message,
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+// Loan period of a renewal, and how often a borrower may renew a loan
+const (
+	loanPeriod  = 14 * 24 * time.Hour
+	maxRenewals = 2
+)
+
+// RenewBorrowingTransaction(context.Context, *RenewBorrowingTransactionRequest) (*BorrowingTransactionResponse, error)
+func (s *BorrowingServiceServer) RenewBorrowingTransaction(ctx context.Context, req *pb.RenewBorrowingTransactionRequest) (*pb.BorrowingTransactionResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if req.DueDate != "" && role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "due_date is only available for admin")
+	}
+
+	var loan model.BorrowingTransaction
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", req.TransactionId)
+		if role != "admin" {
+			query = query.Where("borrower_id = ?", userID)
+		}
+
+		if err := query.First(&loan).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return status.Errorf(codes.NotFound, "transaction not found or does not belong to user")
+			}
+			return err
+		}
+
+		if loan.ReturnedAt.Valid {
+			return status.Errorf(codes.FailedPrecondition, "book already returned")
+		}
+
+		// Due dates are wall clock strings, compare them the same way lateDays does
+		now, _ := time.Parse(helpers.DateTimeLayout, time.Now().Format(helpers.DateTimeLayout))
+		due, err := time.Parse(helpers.DateTimeLayout, loan.DueDate)
+		if err != nil {
+			return status.Errorf(codes.Internal, "invalid due date %q", loan.DueDate)
+		}
+
+		// Admin may override the limits, e.g. at the front desk
+		if role != "admin" {
+			if now.After(due) {
+				return status.Errorf(codes.FailedPrecondition, "loan is overdue and can no longer be renewed")
+			}
+			if loan.Renewals >= maxRenewals {
+				return status.Errorf(codes.FailedPrecondition, "loan was already renewed %d times", loan.Renewals)
+			}
+
+			var waiting int64
+			if err := tx.Table("holds").Where("book_id = ? AND status = ?", loan.BookID, holdWaiting).Count(&waiting).Error; err != nil {
+				return err
+			}
+			if waiting > 0 {
+				return status.Errorf(codes.FailedPrecondition, "other borrowers are waiting for this book, it can no longer be renewed")
+			}
+		}
+
+		// A renewal never shortens a loan
+		start := now
+		if due.After(start) {
+			start = due
+		}
+		newDue := start.Add(loanPeriod)
+
+		if req.DueDate != "" {
+			if newDue, err = time.Parse(helpers.DateTimeLayout, req.DueDate); err != nil {
+				return status.Errorf(codes.InvalidArgument, "due_date must look like %q", helpers.DateTimeLayout)
+			}
+			if !newDue.After(now) {
+				return status.Errorf(codes.InvalidArgument, "due_date must be in the future")
+			}
+		}
+
+		loan.DueDate = newDue.Format(helpers.DateTimeLayout)
+		loan.Renewals++
+		loan.Status = "borrowed"
+
+		if err := tx.Save(&loan).Error; err != nil {
+			return err
+		}
+
+		// Published to webhooks by the outbox dispatcher once this transaction commits
+		if err := outbox.Write(tx, outbox.LoanRenewed, map[string]interface{}{
+			"transaction_id": loan.ID,
+			"borrower_id":    loan.BorrowerID,
+			"book_id":        loan.BookID,
+			"due_date":       loan.DueDate,
+			"renewals":       loan.Renewals,
+		}); err != nil {
+			return err
+		}
+
+		return events.Record(tx, model.CirculationEvent{
+			Type:          events.BookRenewed,
+			BookID:        loan.BookID,
+			BorrowerID:    loan.BorrowerID,
+			TransactionID: loan.ID,
+			Message:       "due " + loan.DueDate,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.Events.Notify()
+
+	return &pb.BorrowingTransactionResponse{
+		Data: &pb.BorrowingTransaction{
+			Id:         loan.ID,
+			Borrower:   &pb.Borrower{Id: loan.BorrowerID},
+			Book:       &pb.Book{Id: loan.BookID},
+			BorrowedAt: loan.BorrowedAt,
+			DueDate:    loan.DueDate,
+			Status:     loan.Status,
+			Renewals:   loan.Renewals,
+		},
+	}, nil
+}
@@ -2,13 +2,18 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"go-grpc/helpers"
+	"go-grpc/model"
 	pb "go-grpc/pb/library"
 	paginationPb "go-grpc/pb/pagination"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CategoryService struct {
@@ -19,11 +24,28 @@ type CategoryService struct {
 // ListCategories(context.Context, *ParameterReq) (*CategoriesResponse, error)
 func (s *CategoryService) ListCategories(ctx context.Context, req *pb.ParameterReq) (*pb.CategoriesResponse, error) {
 
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if req.IncludeDeleted && role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "include_deleted is only available for admin")
+	}
+
 	var categories []*pb.Category
 	var pagination paginationPb.Pagination
 
 	sql := s.DB.Table("categories as c").
-		Select("c.id, c.name category_name, c.description")
+		Select("c.id, c.name category_name, c.description, COALESCE(c.deleted_at, '')")
+
+	if !req.IncludeDeleted {
+		sql = sql.Where("c.deleted_at IS NULL")
+	}
+
+	if req.Search != "" {
+		sql = sql.Where("c.name LIKE ?", helpers.Contains(req.Search))
+	}
 
 	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
 
@@ -37,7 +59,7 @@ func (s *CategoryService) ListCategories(ctx context.Context, req *pb.ParameterR
 	for rows.Next() {
 		var category pb.Category
 
-		if err := rows.Scan(&category.Id, &category.Name, &category.Description); err != nil {
+		if err := rows.Scan(&category.Id, &category.Name, &category.Description, &category.DeletedAt); err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 
@@ -55,14 +77,25 @@ func (s *CategoryService) ListCategories(ctx context.Context, req *pb.ParameterR
 // GetCategory(context.Context, *CategoryRequest) (*CategoryResponse, error)
 func (s *CategoryService) GetCategory(ctx context.Context, req *pb.IdRequest) (*pb.CategoryResponse, error) {
 
-	row := s.DB.Table("categories as c").
-		Select("c.id, c.name category_name, c.description").
-		Where("c.id = ?", req.GetId()).
-		Row()
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	sql := s.DB.Table("categories as c").
+		Select("c.id, c.name category_name, c.description, COALESCE(c.deleted_at, '')").
+		Where("c.id = ?", req.GetId())
+
+	// Deleted categories are only visible to admin
+	if role != "admin" {
+		sql = sql.Where("c.deleted_at IS NULL")
+	}
+
+	row := sql.Row()
 
 	var category pb.Category
 
-	if err := row.Scan(&category.Id, &category.Name, &category.Description); err != nil {
+	if err := row.Scan(&category.Id, &category.Name, &category.Description, &category.DeletedAt); err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -77,15 +110,14 @@ func (s *CategoryService) GetCategory(ctx context.Context, req *pb.IdRequest) (*
 // CreateCategory(context.Context, *Category) (*CategoryResponse, error)
 func (s *CategoryService) CreateCategory(ctx context.Context, req *pb.CategoryRequest) (*pb.CategoryResponse, error) {
 
-	category := pb.Category{
-		Id:          0,
+	category := model.Category{
 		Name:        req.GetName(),
 		Description: req.GetDescription(),
 	}
 
 	err := s.DB.Transaction(func(tx *gorm.DB) error {
 
-		if err := tx.Table("categories").Where("LCASE(name) = ?", category.GetName()).FirstOrCreate(&category).Error; err != nil {
+		if err := tx.Table("categories").Where("LCASE(name) = ? AND deleted_at IS NULL", category.Name).FirstOrCreate(&category).Error; err != nil {
 			return err
 		}
 
@@ -94,7 +126,11 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req *pb.CategoryRe
 	})
 
 	categoryRes := &pb.CategoryResponse{
-		Data: &category,
+		Data: &pb.Category{
+			Id:          category.ID,
+			Name:        category.Name,
+			Description: category.Description,
+		},
 	}
 
 	return categoryRes, err
@@ -115,7 +151,8 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, req *pb.CategoryRe
 			return err
 		}
 
-		return nil
+		// Records of the category's books change with the name, bump their OAI-PMH datestamp
+		return tx.Table("books").Where("category_id = ?", category.GetId()).Update("updated_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
 
 	})
 
@@ -131,22 +168,106 @@ func (s *CategoryService) DeleteCategory(ctx context.Context, req *pb.IdRequest)
 
 	q := s.DB.Table("books as b").
 		Select("b.id").
-		Where("category_id = ?", req.GetId()).
+		Where("category_id = ? AND deleted_at IS NULL", req.GetId()).
 		Row()
 
 	var book pb.Book
 
 	if err := q.Scan(&book.Id); err != nil {
-		return nil, status.Error(codes.NotFound, err.Error())
+		if err != sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
 	}
 
 	if book.Id > 0 {
 		return nil, status.Error(codes.Canceled, "category id sudah tercantum di book, tidak bisa di hapus")
 	} else {
-		if err := s.DB.Table("categories").Where("id = ?", req.Id).Delete(nil).Error; err != nil {
-			return nil, err
+		result := s.DB.Table("categories").
+			Where("id = ? AND deleted_at IS NULL", req.Id).
+			Update("deleted_at", time.Now().Format(helpers.DateTimeLayout))
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, status.Errorf(codes.NotFound, "category not found or already deleted")
+		}
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// RestoreCategory(context.Context, *IdRequest) (*CategoryResponse, error)
+func (s *CategoryService) RestoreCategory(ctx context.Context, req *pb.IdRequest) (*pb.CategoryResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	result := s.DB.Table("categories").
+		Where("id = ? AND deleted_at IS NOT NULL", req.GetId()).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, status.Errorf(codes.NotFound, "category not found or not deleted")
+	}
+
+	return s.GetCategory(ctx, req)
+}
+
+// PurgeCategory(context.Context, *IdRequest) (*Empty, error)
+func (s *CategoryService) PurgeCategory(ctx context.Context, req *pb.IdRequest) (*pb.Empty, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		row := tx.Table("categories").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("deleted_at IS NOT NULL").
+			Where("id = ?", req.GetId()).
+			Row()
+
+		var deleted bool
+		if err := row.Scan(&deleted); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return status.Errorf(codes.NotFound, "category not found")
+			}
+			return err
+		}
+
+		if !deleted {
+			return status.Errorf(codes.FailedPrecondition, "category must be deleted before it can be purged")
+		}
+
+		// Books keep their category until they are purged themselves
+		var books int64
+		if err := tx.Table("books").Where("category_id = ?", req.GetId()).Count(&books).Error; err != nil {
+			return err
+		}
+
+		if books > 0 {
+			return status.Errorf(codes.FailedPrecondition, "category is referenced by %d book(s) and cannot be purged", books)
 		}
+
+		return tx.Table("categories").Where("id = ?", req.GetId()).Delete(nil).Error
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, nil
+	return &pb.Empty{}, nil
 }
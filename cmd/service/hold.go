@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-grpc/catalog"
+	"go-grpc/events"
+	"go-grpc/helpers"
+	"go-grpc/model"
+	pb "go-grpc/pb/library"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Hold statuses, waiting and ready holds are open
+const (
+	holdWaiting   = "waiting"
+	holdReady     = "ready"
+	holdFulfilled = "fulfilled"
+	holdCancelled = "cancelled"
+)
+
+var openHolds = []string{holdWaiting, holdReady}
+
+// Outcomes of placeHold
+const (
+	holdPlaced    = "placed"
+	holdAvailable = "available"
+	holdHeld      = "held"
+	holdOnLoan    = "on_loan"
+)
+
+// PlaceHold(context.Context, *PlaceHoldRequest) (*HoldResponse, error)
+func (s *BorrowingServiceServer) PlaceHold(ctx context.Context, req *pb.PlaceHoldRequest) (*pb.HoldResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	// Admin can place a hold on behalf of a borrower, e.g. at the front desk
+	borrowerID := int32(userID)
+	if role == "admin" {
+		if req.BorrowerId == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "borrower_id is required")
+		}
+		borrowerID = req.BorrowerId
+	} else if req.BorrowerId != 0 && req.BorrowerId != borrowerID {
+		return nil, status.Errorf(codes.PermissionDenied, "borrower_id is only available for admin")
+	}
+
+	var hold *model.Hold
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var books int64
+		if err := tx.Table("books").Where("id = ? AND deleted_at IS NULL", req.BookId).Count(&books).Error; err != nil {
+			return err
+		}
+		if books == 0 {
+			return status.Errorf(codes.NotFound, "book not found")
+		}
+
+		var borrowers int64
+		if err := tx.Table("borrowers").Where("id = ? AND erased_at IS NULL", borrowerID).Count(&borrowers).Error; err != nil {
+			return err
+		}
+		if borrowers == 0 {
+			return status.Errorf(codes.NotFound, "borrower not found")
+		}
+
+		result, placed, err := placeHold(tx, borrowerID, req.BookId)
+		if err != nil {
+			return err
+		}
+
+		switch result {
+		case holdAvailable:
+			return status.Errorf(codes.FailedPrecondition, "a copy is available, check it out instead")
+		case holdHeld:
+			return status.Errorf(codes.AlreadyExists, "book is already on hold for the borrower")
+		case holdOnLoan:
+			return status.Errorf(codes.FailedPrecondition, "borrower has the book on loan")
+		}
+
+		hold = placed
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.hold(hold.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.HoldResponse{Data: data}, nil
+}
+
+// ListHolds(context.Context, *HoldsRequest) (*HoldsResponse, error)
+func (s *BorrowingServiceServer) ListHolds(ctx context.Context, req *pb.HoldsRequest) (*pb.HoldsResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	sql := holdQuery(s.DB)
+
+	// Admin see the holds of every borrower unless they name one
+	if role != "admin" {
+		if req.BorrowerId != 0 && req.BorrowerId != int32(userID) {
+			return nil, status.Errorf(codes.PermissionDenied, "borrower_id is only available for admin")
+		}
+		sql = sql.Where("h.borrower_id = ?", userID)
+	} else if req.BorrowerId != 0 {
+		sql = sql.Where("h.borrower_id = ?", req.BorrowerId)
+	}
+
+	if !req.IncludeClosed {
+		sql = sql.Where("h.status IN ?", openHolds)
+	}
+
+	rows, err := sql.Order("h.id DESC").Rows()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	defer rows.Close()
+
+	var holds []*pb.Hold
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		holds = append(holds, hold)
+	}
+
+	return &pb.HoldsResponse{Data: holds}, nil
+}
+
+// CancelHold(context.Context, *CancelHoldRequest) (*ReturnSimpleResponse, error)
+func (s *BorrowingServiceServer) CancelHold(ctx context.Context, req *pb.CancelHoldRequest) (*pb.ReturnSimpleResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var hold model.Hold
+
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND status IN ?", req.HoldId, openHolds)
+		if role != "admin" {
+			query = query.Where("borrower_id = ?", userID)
+		}
+
+		if err := query.First(&hold).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return status.Errorf(codes.NotFound, "hold not found or already closed")
+			}
+			return err
+		}
+
+		wasReady := hold.Status == holdReady
+
+		if err := tx.Model(&hold).Updates(map[string]interface{}{
+			"status":    holdCancelled,
+			"closed_at": time.Now().Format(helpers.DateTimeLayout),
+		}).Error; err != nil {
+			return err
+		}
+
+		if !wasReady {
+			return nil
+		}
+
+		// The copy set aside goes to the next borrower in the queue
+		var stock model.BookStock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("book_id = ?", hold.BookID).First(&stock).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		return readyHolds(tx, hold.BookID, stock.TotalStock)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.Events.Notify()
+
+	return &pb.ReturnSimpleResponse{Success: true, Message: "Hold cancelled"}, nil
+}
+
+// placeHold puts the borrower in the queue of a book without a free copy.
+// The stock row is locked so holds and checkouts of the book are serialized.
+func placeHold(tx *gorm.DB, borrowerID, bookID int32) (string, *model.Hold, error) {
+	var stock model.BookStock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("book_id = ?", bookID).First(&stock).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil, err
+	}
+
+	var hold model.Hold
+	err := tx.Where("borrower_id = ? AND book_id = ? AND status IN ?", borrowerID, bookID, openHolds).First(&hold).Error
+	if err == nil {
+		return holdHeld, &hold, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil, err
+	}
+
+	var loans int64
+	if err := tx.Table("borrowing_transactions").
+		Where("borrower_id = ? AND book_id = ? AND returned_at IS NULL", borrowerID, bookID).
+		Count(&loans).Error; err != nil {
+		return "", nil, err
+	}
+	if loans > 0 {
+		return holdOnLoan, nil, nil
+	}
+
+	ready, err := readyHoldCount(tx, bookID, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	if stock.TotalStock > int(ready) {
+		return holdAvailable, nil, nil
+	}
+
+	hold = model.Hold{
+		BookID:     bookID,
+		BorrowerID: borrowerID,
+		Status:     holdWaiting,
+		CreatedAt:  time.Now().Format(helpers.DateTimeLayout),
+	}
+	if err := tx.Create(&hold).Error; err != nil {
+		return "", nil, err
+	}
+
+	return holdPlaced, &hold, nil
+}
+
+// readyHolds sets the free copies on the shelf aside for the borrowers first
+// in the queue, call it with the stock row of the book locked
+func readyHolds(tx *gorm.DB, bookID int32, onShelf int) error {
+	ready, err := readyHoldCount(tx, bookID, 0)
+	if err != nil {
+		return err
+	}
+
+	free := onShelf - int(ready)
+	if free <= 0 {
+		return nil
+	}
+
+	var holds []model.Hold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND status = ?", bookID, holdWaiting).
+		Order("id").
+		Limit(free).
+		Find(&holds).Error; err != nil {
+		return err
+	}
+
+	now := time.Now().Format(helpers.DateTimeLayout)
+	for _, hold := range holds {
+		if err := tx.Model(&hold).Updates(map[string]interface{}{"status": holdReady, "ready_at": now}).Error; err != nil {
+			return err
+		}
+
+		if err := events.Record(tx, model.CirculationEvent{
+			Type:       events.HoldReady,
+			BookID:     hold.BookID,
+			BorrowerID: hold.BorrowerID,
+			Message:    "ready for pickup",
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readyHoldCount counts the copies set aside for borrowers other than the given one
+func readyHoldCount(tx *gorm.DB, bookID, exceptBorrowerID int32) (int64, error) {
+	var ready int64
+	err := tx.Table("holds").
+		Where("book_id = ? AND status = ? AND borrower_id <> ?", bookID, holdReady, exceptBorrowerID).
+		Count(&ready).Error
+	return ready, err
+}
+
+// holdQuery joins holds with their book, rows are read with scanHold. Waiting
+// holds get their place in the queue of the book.
+func holdQuery(db *gorm.DB) *gorm.DB {
+	return db.Table("holds as h").
+		Joins("JOIN books b on b.id = h.book_id").
+		Select("h.id, b.id, b.title, COALESCE(b.isbn, ''), h.borrower_id, h.status, "+
+			"CASE WHEN h.status = ? THEN (SELECT COUNT(*) FROM holds q WHERE q.book_id = h.book_id AND q.status = ? AND q.id <= h.id) ELSE 0 END, "+
+			"COALESCE(h.created_at, ''), COALESCE(h.ready_at, ''), COALESCE(h.closed_at, '')", holdWaiting, holdWaiting)
+}
+
+func scanHold(row catalog.Scanner) (*pb.Hold, error) {
+	hold := pb.Hold{Book: &pb.Book{}}
+
+	if err := row.Scan(&hold.Id, &hold.Book.Id, &hold.Book.Title, &hold.Book.Isbn, &hold.BorrowerId, &hold.Status, &hold.Position,
+		&hold.CreatedAt, &hold.ReadyAt, &hold.ClosedAt); err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+func (s *BorrowingServiceServer) hold(id int32) (*pb.Hold, error) {
+	hold, err := scanHold(holdQuery(s.DB).Where("h.id = ?", id).Row())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return hold, nil
+}
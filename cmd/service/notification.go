@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-grpc/events"
+	"go-grpc/helpers"
+	"go-grpc/model"
+	"go-grpc/notification"
+	pb "go-grpc/pb/library"
+	paginationPb "go-grpc/pb/pagination"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Used until a borrower saves their own preferences, matches the column defaults
+var defaultNotificationPreferences = model.NotificationPreference{
+	EmailEnabled:       true,
+	SmsEnabled:         false,
+	InappEnabled:       true,
+	ReminderDaysBefore: 3,
+}
+
+type NotificationService struct {
+	pb.UnimplementedNotificationServiceServer
+	DB  *gorm.DB
+	Hub *events.Topics[int32, model.Notification]
+}
+
+// GetNotificationPreferences(context.Context, *Empty) (*NotificationPreferencesResponse, error)
+func (s *NotificationService) GetNotificationPreferences(ctx context.Context, req *pb.Empty) (*pb.NotificationPreferencesResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "borrower" {
+		return nil, status.Errorf(codes.PermissionDenied, "notification preferences are only available for borrowers")
+	}
+
+	preference := defaultNotificationPreferences
+	if err := s.DB.Where("borrower_id = ?", userID).First(&preference).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.NotificationPreferencesResponse{
+		Data: notificationPreferenceToPb(preference),
+	}, nil
+}
+
+// UpdateNotificationPreferences(context.Context, *NotificationPreferences) (*NotificationPreferencesResponse, error)
+func (s *NotificationService) UpdateNotificationPreferences(ctx context.Context, req *pb.NotificationPreferences) (*pb.NotificationPreferencesResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "borrower" {
+		return nil, status.Errorf(codes.PermissionDenied, "notification preferences are only available for borrowers")
+	}
+
+	if req.GetReminderDaysBefore() < 0 || req.GetReminderDaysBefore() > 30 {
+		return nil, status.Errorf(codes.InvalidArgument, "reminder_days_before must be between 0 and 30")
+	}
+
+	if req.GetSmsEnabled() && req.GetPhone() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "phone is required to enable sms")
+	}
+
+	preference := model.NotificationPreference{
+		BorrowerID:         int32(userID),
+		EmailEnabled:       req.GetEmailEnabled(),
+		SmsEnabled:         req.GetSmsEnabled(),
+		InappEnabled:       req.GetInappEnabled(),
+		Phone:              req.GetPhone(),
+		ReminderDaysBefore: req.GetReminderDaysBefore(),
+	}
+
+	if err := s.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&preference).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.NotificationPreferencesResponse{
+		Data: notificationPreferenceToPb(preference),
+	}, nil
+}
+
+// ListMyNotifications(context.Context, *ListNotificationsRequest) (*NotificationsResponse, error)
+func (s *NotificationService) ListMyNotifications(ctx context.Context, req *pb.ListNotificationsRequest) (*pb.NotificationsResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "borrower" {
+		return nil, status.Errorf(codes.PermissionDenied, "notifications are only available for borrowers")
+	}
+
+	var notifications []*pb.Notification
+	var pagination paginationPb.Pagination
+
+	sql := s.DB.Table("notifications as n").
+		Select("n.id, n.kind, n.title, COALESCE(n.body, ''), COALESCE(n.transaction_id, 0), n.read_at IS NOT NULL, COALESCE(n.read_at, ''), n.created_at").
+		Where("n.borrower_id = ?", userID)
+
+	if req.GetUnreadOnly() {
+		sql = sql.Where("n.read_at IS NULL")
+	}
+
+	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
+
+	rows, err := sql.Order("n.id DESC").Offset(int(offset)).Limit(int(limit)).Rows()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var n pb.Notification
+
+		if err := rows.Scan(&n.Id, &n.Kind, &n.Title, &n.Body, &n.TransactionId, &n.Read, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		notifications = append(notifications, &n)
+	}
+
+	var unread int64
+	if err := s.DB.Table("notifications").Where("borrower_id = ? AND read_at IS NULL", userID).Count(&unread).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.NotificationsResponse{
+		Pagination:  &pagination,
+		Data:        notifications,
+		UnreadCount: unread,
+	}, nil
+}
+
+// MarkRead(context.Context, *IdRequest) (*ReturnSimpleResponse, error)
+func (s *NotificationService) MarkRead(ctx context.Context, req *pb.IdRequest) (*pb.ReturnSimpleResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "borrower" {
+		return nil, status.Errorf(codes.PermissionDenied, "notifications are only available for borrowers")
+	}
+
+	result := s.DB.Table("notifications").
+		Where("id = ? AND borrower_id = ? AND read_at IS NULL", req.GetId(), userID).
+		Update("read_at", time.Now().Format(helpers.DateTimeLayout))
+	if result.Error != nil {
+		return nil, status.Error(codes.Internal, result.Error.Error())
+	}
+
+	if result.RowsAffected == 0 {
+		return &pb.ReturnSimpleResponse{Success: false, Message: "Notification not found or already read"}, nil
+	}
+
+	return &pb.ReturnSimpleResponse{Success: true, Message: "Notification marked as read"}, nil
+}
+
+// MarkAllRead(context.Context, *Empty) (*ReturnSimpleResponse, error)
+func (s *NotificationService) MarkAllRead(ctx context.Context, req *pb.Empty) (*pb.ReturnSimpleResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "borrower" {
+		return nil, status.Errorf(codes.PermissionDenied, "notifications are only available for borrowers")
+	}
+
+	result := s.DB.Table("notifications").
+		Where("borrower_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now().Format(helpers.DateTimeLayout))
+	if result.Error != nil {
+		return nil, status.Error(codes.Internal, result.Error.Error())
+	}
+
+	return &pb.ReturnSimpleResponse{Success: true, Message: fmt.Sprintf("%d notification(s) marked as read", result.RowsAffected)}, nil
+}
+
+// SubscribeNotifications(*Empty, NotificationService_SubscribeNotificationsServer) error
+func (s *NotificationService) SubscribeNotifications(req *pb.Empty, stream pb.NotificationService_SubscribeNotificationsServer) error {
+
+	userID, role, err := helpers.GetData(stream.Context())
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "borrower" {
+		return status.Errorf(codes.PermissionDenied, "notifications are only available for borrowers")
+	}
+
+	live, cancel := s.Hub.Subscribe(int32(userID))
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case n, ok := <-live:
+			if !ok {
+				return status.Errorf(codes.Unavailable, "stream fell behind, list notifications and subscribe again")
+			}
+
+			if err := stream.Send(notificationToPb(n)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// BroadcastAnnouncement(context.Context, *BroadcastRequest) (*BroadcastResponse, error)
+func (s *NotificationService) BroadcastAnnouncement(ctx context.Context, req *pb.BroadcastRequest) (*pb.BroadcastResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	if req.GetTitle() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "title is required")
+	}
+
+	// Erased accounts are kept for the statistics, nobody reads their notifications
+	query := s.DB.Table("borrowers as br").Select("DISTINCT br.id").Where("br.erased_at IS NULL")
+
+	switch {
+	case len(req.GetBorrowerIds()) > 0:
+		query = query.Where("br.id IN ?", req.GetBorrowerIds())
+	case req.GetSegment() == "" || req.GetSegment() == "all":
+	case req.GetSegment() == "active_loans":
+		query = query.Joins("JOIN borrowing_transactions bt on bt.borrower_id = br.id AND bt.returned_at IS NULL")
+	case req.GetSegment() == "overdue":
+		query = query.Joins("JOIN borrowing_transactions bt on bt.borrower_id = br.id AND bt.returned_at IS NULL AND bt.status = 'overdue'")
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown segment %q, expected all, active_loans or overdue", req.GetSegment())
+	}
+
+	var borrowerIDs []int32
+	if err := query.Pluck("br.id", &borrowerIDs).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if len(borrowerIDs) == 0 {
+		return &pb.BroadcastResponse{Recipients: 0}, nil
+	}
+
+	now := time.Now().Format(helpers.DateTimeLayout)
+	notifications := make([]model.Notification, 0, len(borrowerIDs))
+	for _, id := range borrowerIDs {
+		notifications = append(notifications, model.Notification{
+			BorrowerID: id,
+			Kind:       notification.KindAnnouncement,
+			Title:      req.GetTitle(),
+			Body:       req.GetBody(),
+			CreatedAt:  now,
+		})
+	}
+
+	if err := s.DB.CreateInBatches(&notifications, 500).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Each borrower's stream only gets their own copy
+	for _, n := range notifications {
+		s.Hub.Publish(n.BorrowerID, n)
+	}
+
+	return &pb.BroadcastResponse{Recipients: int64(len(notifications))}, nil
+}
+
+func notificationToPb(n model.Notification) *pb.Notification {
+	return &pb.Notification{
+		Id:            n.ID,
+		Kind:          n.Kind,
+		Title:         n.Title,
+		Body:          n.Body,
+		TransactionId: n.TransactionID.Int32,
+		Read:          n.ReadAt.Valid,
+		ReadAt:        n.ReadAt.String,
+		CreatedAt:     n.CreatedAt,
+	}
+}
+
+func notificationPreferenceToPb(preference model.NotificationPreference) *pb.NotificationPreferences {
+	return &pb.NotificationPreferences{
+		EmailEnabled:       preference.EmailEnabled,
+		SmsEnabled:         preference.SmsEnabled,
+		InappEnabled:       preference.InappEnabled,
+		Phone:              preference.Phone,
+		ReminderDaysBefore: preference.ReminderDaysBefore,
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go-grpc/catalog"
+	"go-grpc/cmd/worker"
+	"go-grpc/helpers"
+	"go-grpc/model"
+	pb "go-grpc/pb/library"
+	paginationPb "go-grpc/pb/pagination"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListMyReadingHistory(context.Context, *ReadingHistoryRequest) (*ReadingHistoryResponse, error)
+func (s *BorrowingServiceServer) ListMyReadingHistory(ctx context.Context, req *pb.ReadingHistoryRequest) (*pb.ReadingHistoryResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "borrower" {
+		return nil, status.Errorf(codes.PermissionDenied, "reading history is only available for borrowers")
+	}
+
+	settings, err := s.privacySettings(userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var history []*pb.ReadingHistoryEntry
+	var pagination paginationPb.Pagination
+
+	// Anonymized loans have no borrower anymore and are not part of anyone's history
+	sql := s.DB.Table("borrowing_transactions as bt").
+		Joins("JOIN books b on b.id = bt.book_id").
+		Joins("LEFT JOIN authors au on au.id = b.author_id").
+		Joins("LEFT JOIN categories c on c.id = b.category_id").
+		Joins("LEFT JOIN returning_transactions rt on rt.borrowing_transaction_id = bt.id").
+		Select("bt.id, b.id, b.title, b.isbn, b.publication_year, COALESCE(au.id, 0), COALESCE(au.name, ''), COALESCE(c.id, 0), COALESCE(c.name, ''), "+
+			"COALESCE(bt.borrowed_at, ''), bt.due_date, COALESCE(bt.returned_at, rt.returned_at, ''), COALESCE(bt.status, ''), bt.renewals, COALESCE(rt.fine_amount, 0)").
+		Where("bt.borrower_id = ?", userID)
+
+	if req.GetSearch() != "" {
+		like := "%" + req.GetSearch() + "%"
+		sql = sql.Where("b.title LIKE ? OR "+catalog.ByContributor("ca.name LIKE ?")+" OR b.isbn = ?", like, like, helpers.NormalizeISBN(req.GetSearch()))
+	}
+
+	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
+
+	rows, err := sql.Order("bt.borrowed_at DESC, bt.id DESC").Offset(int(offset)).Limit(int(limit)).Rows()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := pb.ReadingHistoryEntry{Book: &pb.Book{Author: &pb.Author{}, Category: &pb.Category{}}}
+
+		if err := rows.Scan(&entry.TransactionId, &entry.Book.Id, &entry.Book.Title, &entry.Book.Isbn, &entry.Book.PublicationYear,
+			&entry.Book.Author.Id, &entry.Book.Author.Name, &entry.Book.Category.Id, &entry.Book.Category.Name,
+			&entry.BorrowedAt, &entry.DueDate, &entry.ReturnedAt, &entry.Status, &entry.Renewals, &entry.FineAmount); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		history = append(history, &entry)
+	}
+
+	return &pb.ReadingHistoryResponse{
+		Pagination: &pagination,
+		Data:       history,
+		Settings:   settings,
+	}, nil
+}
+
+// GetPrivacySettings(context.Context, *Empty) (*PrivacySettings, error)
+func (s *BorrowingServiceServer) GetPrivacySettings(ctx context.Context, req *pb.Empty) (*pb.PrivacySettings, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "borrower" {
+		return nil, status.Errorf(codes.PermissionDenied, "privacy settings are only available for borrowers")
+	}
+
+	settings, err := s.privacySettings(userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return settings, nil
+}
+
+// UpdatePrivacySettings(context.Context, *PrivacySettings) (*PrivacySettings, error)
+func (s *BorrowingServiceServer) UpdatePrivacySettings(ctx context.Context, req *pb.PrivacySettings) (*pb.PrivacySettings, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "borrower" {
+		return nil, status.Errorf(codes.PermissionDenied, "privacy settings are only available for borrowers")
+	}
+
+	if req.GetReadingHistory() != worker.HistoryRetain && req.GetReadingHistory() != worker.HistoryAnonymize {
+		return nil, status.Errorf(codes.InvalidArgument, "reading_history must be %q or %q", worker.HistoryRetain, worker.HistoryAnonymize)
+	}
+
+	// Anonymized loans cannot be attached again, going back to retain only keeps what is left
+	setting := model.PrivacySetting{
+		BorrowerID:     int32(userID),
+		ReadingHistory: req.GetReadingHistory(),
+	}
+
+	if err := s.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&setting).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.PrivacySettings{ReadingHistory: setting.ReadingHistory, RetentionDays: s.HistoryRetentionDays}, nil
+}
+
+// privacySettings of a borrower, history is retained until they choose otherwise
+func (s *BorrowingServiceServer) privacySettings(userID int) (*pb.PrivacySettings, error) {
+	setting := model.PrivacySetting{ReadingHistory: worker.HistoryRetain}
+	if err := s.DB.Where("borrower_id = ?", userID).First(&setting).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return &pb.PrivacySettings{ReadingHistory: setting.ReadingHistory, RetentionDays: s.HistoryRetentionDays}, nil
+}
@@ -0,0 +1,663 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-grpc/catalog"
+	"go-grpc/helpers"
+	"go-grpc/model"
+	pb "go-grpc/pb/library"
+	paginationPb "go-grpc/pb/pagination"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Most books a single reading list holds
+const maxReadingListItems = 500
+
+// ReadingListService keeps the reading lists of borrowers, every call works on
+// the lists of the borrower in the token except GetSharedReadingList, which
+// reads a shared list by its link without logging in
+type ReadingListService struct {
+	pb.UnimplementedReadingListServiceServer
+	DB *gorm.DB
+}
+
+// CreateReadingList(context.Context, *ReadingListRequest) (*ReadingListResponse, error)
+func (s *ReadingListService) CreateReadingList(ctx context.Context, req *pb.ReadingListRequest) (*pb.ReadingListResponse, error) {
+
+	userID, err := listOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name, description, err := readingListFields(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkListName(userID, name, 0); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Format(helpers.DateTimeLayout)
+	list := model.ReadingList{
+		BorrowerID:  userID,
+		Name:        name,
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.DB.Create(&list).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return s.response(list.ID, userID)
+}
+
+// UpdateReadingList(context.Context, *ReadingListRequest) (*ReadingListResponse, error)
+func (s *ReadingListService) UpdateReadingList(ctx context.Context, req *pb.ReadingListRequest) (*pb.ReadingListResponse, error) {
+
+	userID, err := listOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name, description, err := readingListFields(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.ownList(s.DB, req.GetId(), userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkListName(userID, name, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Model(&model.ReadingList{}).Where("id = ?", req.GetId()).Updates(map[string]interface{}{
+		"name":        name,
+		"description": description,
+	}).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return s.response(req.GetId(), userID)
+}
+
+// DeleteReadingList(context.Context, *IdRequest) (*Empty, error)
+func (s *ReadingListService) DeleteReadingList(ctx context.Context, req *pb.IdRequest) (*pb.Empty, error) {
+
+	userID, err := listOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The items are removed by ON DELETE CASCADE, holds placed from the list stay
+	result := s.DB.Where("id = ? AND borrower_id = ?", req.GetId(), userID).Delete(&model.ReadingList{})
+	if result.Error != nil {
+		return nil, status.Error(codes.Internal, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, status.Errorf(codes.NotFound, "reading list not found")
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// ListReadingLists(context.Context, *ParameterReq) (*ReadingListsResponse, error)
+func (s *ReadingListService) ListReadingLists(ctx context.Context, req *pb.ParameterReq) (*pb.ReadingListsResponse, error) {
+
+	userID, err := listOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var lists []*pb.ReadingList
+	var pagination paginationPb.Pagination
+
+	sql := s.DB.Table("reading_lists as rl").
+		Select("rl.id, rl.name, COALESCE(rl.description, ''), COALESCE(rl.share_token, ''), "+
+			"(SELECT COUNT(*) FROM reading_list_items li JOIN books b on b.id = li.book_id WHERE li.list_id = rl.id AND b.deleted_at IS NULL), "+
+			"COALESCE(rl.created_at, ''), COALESCE(rl.updated_at, '')").
+		Where("rl.borrower_id = ?", userID)
+
+	if req.Search != "" {
+		sql = sql.Where("rl.name LIKE ?", helpers.Contains(req.Search))
+	}
+
+	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
+
+	rows, err := sql.Order("rl.name, rl.id").Offset(int(offset)).Limit(int(limit)).Rows()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var list pb.ReadingList
+
+		if err := rows.Scan(&list.Id, &list.Name, &list.Description, &list.ShareToken, &list.ItemCount, &list.CreatedAt, &list.UpdatedAt); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		list.Shared = list.ShareToken != ""
+
+		lists = append(lists, &list)
+	}
+
+	return &pb.ReadingListsResponse{
+		Pagination: &pagination,
+		Data:       lists,
+	}, nil
+}
+
+// GetReadingList(context.Context, *IdRequest) (*ReadingListResponse, error)
+func (s *ReadingListService) GetReadingList(ctx context.Context, req *pb.IdRequest) (*pb.ReadingListResponse, error) {
+
+	userID, err := listOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.response(req.GetId(), userID)
+}
+
+// AddReadingListItem(context.Context, *ReadingListItemRequest) (*ReadingListResponse, error)
+func (s *ReadingListService) AddReadingListItem(ctx context.Context, req *pb.ReadingListItemRequest) (*pb.ReadingListResponse, error) {
+
+	userID, err := listOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	note := strings.TrimSpace(req.GetNote())
+	if utf8.RuneCountInString(note) > 255 {
+		return nil, status.Errorf(codes.InvalidArgument, "note is longer than 255 characters")
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// Locking the list serializes changes to the positions of its items
+		if _, err := s.ownList(tx.Clauses(clause.Locking{Strength: "UPDATE"}), req.GetListId(), userID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Table("books").Where("id = ? AND deleted_at IS NULL", req.GetBookId()).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return status.Errorf(codes.NotFound, "book not found")
+		}
+
+		if err := tx.Model(&model.ReadingListItem{}).Where("list_id = ? AND book_id = ?", req.GetListId(), req.GetBookId()).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return status.Errorf(codes.AlreadyExists, "book is already on the list")
+		}
+
+		if err := tx.Model(&model.ReadingListItem{}).Where("list_id = ?", req.GetListId()).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= maxReadingListItems {
+			return status.Errorf(codes.FailedPrecondition, "a reading list holds at most %d books", maxReadingListItems)
+		}
+
+		position := req.GetPosition()
+		if position <= 0 || int64(position) > count+1 {
+			position = int32(count) + 1
+		}
+
+		if err := tx.Model(&model.ReadingListItem{}).
+			Where("list_id = ? AND position >= ?", req.GetListId(), position).
+			Update("position", gorm.Expr("position + 1")).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&model.ReadingListItem{
+			ListID:   req.GetListId(),
+			BookID:   req.GetBookId(),
+			Position: position,
+			Note:     note,
+			AddedAt:  time.Now().Format(helpers.DateTimeLayout),
+		}).Error; err != nil {
+			return err
+		}
+
+		return touchList(tx, req.GetListId())
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.response(req.GetListId(), userID)
+}
+
+// RemoveReadingListItem(context.Context, *ReadingListItemRequest) (*ReadingListResponse, error)
+func (s *ReadingListService) RemoveReadingListItem(ctx context.Context, req *pb.ReadingListItemRequest) (*pb.ReadingListResponse, error) {
+
+	userID, err := listOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.ownList(tx.Clauses(clause.Locking{Strength: "UPDATE"}), req.GetListId(), userID); err != nil {
+			return err
+		}
+
+		var item model.ReadingListItem
+		if err := tx.Where("list_id = ? AND book_id = ?", req.GetListId(), req.GetBookId()).First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return status.Errorf(codes.NotFound, "book is not on the list")
+			}
+			return err
+		}
+
+		if err := tx.Where("list_id = ? AND book_id = ?", item.ListID, item.BookID).Delete(&model.ReadingListItem{}).Error; err != nil {
+			return err
+		}
+
+		// Close the gap
+		if err := tx.Model(&model.ReadingListItem{}).
+			Where("list_id = ? AND position > ?", item.ListID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error; err != nil {
+			return err
+		}
+
+		return touchList(tx, req.GetListId())
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.response(req.GetListId(), userID)
+}
+
+// ReorderReadingList(context.Context, *ReorderReadingListRequest) (*ReadingListResponse, error)
+func (s *ReadingListService) ReorderReadingList(ctx context.Context, req *pb.ReorderReadingListRequest) (*pb.ReadingListResponse, error) {
+
+	userID, err := listOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.ownList(tx.Clauses(clause.Locking{Strength: "UPDATE"}), req.GetListId(), userID); err != nil {
+			return err
+		}
+
+		// Deleted books are not shown, they keep their order after the others
+		var books []int32
+		if err := tx.Table("reading_list_items li").
+			Joins("JOIN books b on b.id = li.book_id").
+			Where("li.list_id = ? AND b.deleted_at IS NULL", req.GetListId()).
+			Pluck("li.book_id", &books).Error; err != nil {
+			return err
+		}
+
+		onList := make(map[int32]bool, len(books))
+		for _, id := range books {
+			onList[id] = true
+		}
+
+		if len(req.GetBookIds()) != len(books) {
+			return status.Errorf(codes.InvalidArgument, "book_ids must name all %d books of the list", len(books))
+		}
+		for _, id := range req.GetBookIds() {
+			if !onList[id] {
+				return status.Errorf(codes.InvalidArgument, "book %d is not on the list or named twice", id)
+			}
+			delete(onList, id)
+		}
+
+		if err := tx.Model(&model.ReadingListItem{}).
+			Where("list_id = ? AND book_id NOT IN ?", req.GetListId(), append(books, 0)).
+			Update("position", gorm.Expr("position + ?", len(books))).Error; err != nil {
+			return err
+		}
+
+		for i, id := range req.GetBookIds() {
+			if err := tx.Model(&model.ReadingListItem{}).
+				Where("list_id = ? AND book_id = ?", req.GetListId(), id).
+				Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+
+		return touchList(tx, req.GetListId())
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.response(req.GetListId(), userID)
+}
+
+// ShareReadingList(context.Context, *ShareReadingListRequest) (*ReadingListResponse, error)
+func (s *ReadingListService) ShareReadingList(ctx context.Context, req *pb.ShareReadingListRequest) (*pb.ReadingListResponse, error) {
+
+	userID, err := listOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		list, err := s.ownList(tx.Clauses(clause.Locking{Strength: "UPDATE"}), req.GetListId(), userID)
+		if err != nil {
+			return err
+		}
+
+		// Sharing again keeps the link that was handed out
+		token := sql.NullString{}
+		if req.GetShared() {
+			token = list.ShareToken
+			if !token.Valid {
+				b := make([]byte, 24)
+				if _, err := rand.Read(b); err != nil {
+					return err
+				}
+				token = sql.NullString{String: hex.EncodeToString(b), Valid: true}
+			}
+		}
+
+		return tx.Model(&model.ReadingList{}).Where("id = ?", list.ID).Update("share_token", token).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.response(req.GetListId(), userID)
+}
+
+// GetSharedReadingList(context.Context, *SharedReadingListRequest) (*ReadingListResponse, error)
+func (s *ReadingListService) GetSharedReadingList(ctx context.Context, req *pb.SharedReadingListRequest) (*pb.ReadingListResponse, error) {
+
+	if req.GetToken() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	var list model.ReadingList
+	if err := s.DB.Where("share_token = ?", req.GetToken()).First(&list).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "reading list not found or no longer shared")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	data, err := s.readingList(list, false)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ReadingListResponse{Data: data}, nil
+}
+
+// HoldUnavailable(context.Context, *HoldUnavailableRequest) (*HoldUnavailableResponse, error)
+func (s *ReadingListService) HoldUnavailable(ctx context.Context, req *pb.HoldUnavailableRequest) (*pb.HoldUnavailableResponse, error) {
+
+	userID, err := listOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*pb.HoldResult
+	var placed int32
+	var holds []int32
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.ownList(tx, req.GetListId(), userID); err != nil {
+			return err
+		}
+
+		var items []struct {
+			BookID  int32
+			Title   string
+			Deleted bool
+		}
+		if err := tx.Table("reading_list_items li").
+			Joins("JOIN books b on b.id = li.book_id").
+			Select("li.book_id, b.title, b.deleted_at IS NOT NULL deleted").
+			Where("li.list_id = ?", req.GetListId()).
+			Order("li.position").
+			Scan(&items).Error; err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			result := &pb.HoldResult{BookId: item.BookID, Title: item.Title, Result: "deleted"}
+			results = append(results, result)
+
+			if item.Deleted {
+				holds = append(holds, 0)
+				continue
+			}
+
+			outcome, hold, err := placeHold(tx, userID, item.BookID)
+			if err != nil {
+				return err
+			}
+
+			result.Result = outcome
+			if outcome == holdPlaced {
+				placed++
+			}
+			if hold != nil {
+				holds = append(holds, hold.ID)
+			} else {
+				holds = append(holds, 0)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, id := range holds {
+		if id == 0 {
+			continue
+		}
+		hold, err := scanHold(holdQuery(s.DB).Where("h.id = ?", id).Row())
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		results[i].Hold = hold
+	}
+
+	return &pb.HoldUnavailableResponse{Results: results, Placed: placed}, nil
+}
+
+// listOwner is the borrower whose lists a call works on
+func listOwner(ctx context.Context) (int32, error) {
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return 0, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "borrower" {
+		return 0, status.Errorf(codes.PermissionDenied, "reading lists are only available for borrowers")
+	}
+
+	return int32(userID), nil
+}
+
+func readingListFields(req *pb.ReadingListRequest) (string, string, error) {
+	name := strings.TrimSpace(req.GetName())
+	if name == "" {
+		return "", "", status.Errorf(codes.InvalidArgument, "name is required")
+	}
+	if utf8.RuneCountInString(name) > 100 {
+		return "", "", status.Errorf(codes.InvalidArgument, "name is longer than 100 characters")
+	}
+
+	description := strings.TrimSpace(req.GetDescription())
+	if utf8.RuneCountInString(description) > 500 {
+		return "", "", status.Errorf(codes.InvalidArgument, "description is longer than 500 characters")
+	}
+
+	return name, description, nil
+}
+
+// checkListName makes sure the borrower has no other list of that name
+func (s *ReadingListService) checkListName(userID int32, name string, listID int32) error {
+	var count int64
+	if err := s.DB.Model(&model.ReadingList{}).
+		Where("borrower_id = ? AND name = ? AND id <> ?", userID, name, listID).
+		Count(&count).Error; err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if count > 0 {
+		return status.Errorf(codes.AlreadyExists, "you already have a reading list named %q", name)
+	}
+	return nil
+}
+
+// ownList reads a list of the borrower, other borrowers' lists are not found
+func (s *ReadingListService) ownList(db *gorm.DB, listID, userID int32) (*model.ReadingList, error) {
+	var list model.ReadingList
+	if err := db.Where("id = ? AND borrower_id = ?", listID, userID).First(&list).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "reading list not found")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &list, nil
+}
+
+// touchList moves updated_at of a list whose items changed
+func touchList(tx *gorm.DB, listID int32) error {
+	return tx.Model(&model.ReadingList{}).Where("id = ?", listID).Update("updated_at", time.Now().Format(helpers.DateTimeLayout)).Error
+}
+
+// response reads a list of the borrower with its items
+func (s *ReadingListService) response(listID, userID int32) (*pb.ReadingListResponse, error) {
+	list, err := s.ownList(s.DB, listID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.readingList(*list, true)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ReadingListResponse{Data: data}, nil
+}
+
+// readingList turns a list into its message with the books in list order.
+// Deleted books are left out. The share token and the owner's holds are
+// only part of the owner's view.
+func (s *ReadingListService) readingList(list model.ReadingList, owner bool) (*pb.ReadingList, error) {
+	data := &pb.ReadingList{
+		Id:          list.ID,
+		Name:        list.Name,
+		Description: list.Description,
+		Shared:      list.ShareToken.Valid,
+		CreatedAt:   list.CreatedAt,
+		UpdatedAt:   list.UpdatedAt,
+	}
+	if owner {
+		data.ShareToken = list.ShareToken.String
+	}
+
+	var items []model.ReadingListItem
+	if err := s.DB.Where("list_id = ?", list.ID).Order("position").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return data, nil
+	}
+
+	ids := make([]int32, len(items))
+	for i, item := range items {
+		ids[i] = item.BookID
+	}
+
+	books, err := catalog.Books(s.DB, catalog.Filter{IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+	if err := catalog.Ratings(s.DB, books); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int32]*pb.Book, len(books))
+	for _, book := range books {
+		byID[book.Id] = book
+	}
+
+	// Copies on the shelf that are not set aside for a hold
+	free := map[int32]int{}
+	rows, err := s.DB.Table("book_stocks bs").
+		Select("bs.book_id, bs.total_stock - (SELECT COUNT(*) FROM holds h WHERE h.book_id = bs.book_id AND h.status = ?)", holdReady).
+		Where("bs.book_id IN ?", ids).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int32
+		var copies int
+		if err := rows.Scan(&id, &copies); err != nil {
+			return nil, err
+		}
+		free[id] = copies
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	holds := map[int32]string{}
+	if owner {
+		var open []model.Hold
+		if err := s.DB.Where("borrower_id = ? AND book_id IN ? AND status IN ?", list.BorrowerID, ids, openHolds).Find(&open).Error; err != nil {
+			return nil, err
+		}
+		for _, hold := range open {
+			holds[hold.BookID] = hold.Status
+		}
+	}
+
+	position := int32(0)
+	for _, item := range items {
+		book, ok := byID[item.BookID]
+		if !ok {
+			continue
+		}
+
+		position++
+		data.Items = append(data.Items, &pb.ReadingListItem{
+			Book:       book,
+			Position:   position,
+			Note:       item.Note,
+			AddedAt:    item.AddedAt,
+			Available:  free[item.BookID] > 0,
+			HoldStatus: holds[item.BookID],
+		})
+	}
+	data.ItemCount = int32(len(data.Items))
+
+	return data, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+
+	"go-grpc/catalog"
+	"go-grpc/helpers"
+	pb "go-grpc/pb/library"
+	"go-grpc/recommend"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// Recommendations per request unless a limit is given, and the most allowed
+const (
+	defaultRecommendations = 10
+	maxRecommendations     = 50
+)
+
+// RecommendationService suggests books from what borrowers borrow together,
+// see package recommend
+type RecommendationService struct {
+	pb.UnimplementedRecommendationServiceServer
+	DB *gorm.DB
+}
+
+// GetSimilarBooks(context.Context, *SimilarBooksRequest) (*RecommendationsResponse, error)
+func (s *RecommendationService) GetSimilarBooks(ctx context.Context, req *pb.SimilarBooksRequest) (*pb.RecommendationsResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	var count int64
+	if err := s.DB.Table("books").Where("id = ? AND deleted_at IS NULL", req.GetBookId()).Count(&count).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if count == 0 {
+		return nil, status.Errorf(codes.NotFound, "book not found")
+	}
+
+	opts := recommend.Options{
+		Limit:         recommendationLimit(req.GetLimit()),
+		AvailableOnly: req.GetAvailableOnly(),
+	}
+
+	// Admin do not borrow, there is nothing to leave out for them
+	if req.GetExcludeBorrowed() && role == "borrower" {
+		opts.ExcludeBorrowedBy = int32(userID)
+	}
+
+	recommendations, err := recommend.Similar(s.DB, req.GetBookId(), opts)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return s.response(recommendations)
+}
+
+// GetRecommendationsForMe(context.Context, *MyRecommendationsRequest) (*RecommendationsResponse, error)
+func (s *RecommendationService) GetRecommendationsForMe(ctx context.Context, req *pb.MyRecommendationsRequest) (*pb.RecommendationsResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	// Admin can look at the recommendations of a borrower, e.g. to help at the desk
+	borrowerID := int32(userID)
+	if role == "admin" {
+		if req.GetBorrowerId() == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "borrower_id is required")
+		}
+		borrowerID = req.GetBorrowerId()
+	} else if req.GetBorrowerId() != 0 && req.GetBorrowerId() != borrowerID {
+		return nil, status.Errorf(codes.PermissionDenied, "borrower_id is only available for admin")
+	}
+
+	var count int64
+	if err := s.DB.Table("borrowers").Where("id = ? AND erased_at IS NULL", borrowerID).Count(&count).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if count == 0 {
+		return nil, status.Errorf(codes.NotFound, "borrower not found")
+	}
+
+	recommendations, err := recommend.ForBorrower(s.DB, borrowerID, recommend.Options{
+		Limit:         recommendationLimit(req.GetLimit()),
+		AvailableOnly: req.GetAvailableOnly(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return s.response(recommendations)
+}
+
+func recommendationLimit(limit int32) int {
+	if limit <= 0 {
+		return defaultRecommendations
+	}
+	if limit > maxRecommendations {
+		return maxRecommendations
+	}
+	return int(limit)
+}
+
+// response loads the recommended books, keeping the order of the recommendations
+func (s *RecommendationService) response(recommendations []recommend.Recommendation) (*pb.RecommendationsResponse, error) {
+	ids := make([]int32, 0, len(recommendations))
+	for _, r := range recommendations {
+		ids = append(ids, r.BookID)
+	}
+
+	resp := &pb.RecommendationsResponse{}
+
+	computedAt, err := recommend.ComputedAt(s.DB)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp.ComputedAt = computedAt
+
+	if len(ids) == 0 {
+		return resp, nil
+	}
+
+	books, err := catalog.Books(s.DB, catalog.Filter{IDs: ids})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err := catalog.Ratings(s.DB, books); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	byID := map[int32]*pb.Book{}
+	for _, book := range books {
+		byID[book.Id] = book
+	}
+
+	for _, r := range recommendations {
+		book, ok := byID[r.BookID]
+		if !ok {
+			continue
+		}
+		resp.Data = append(resp.Data, &pb.Recommendation{Book: book, Score: r.Score, Reason: r.Reason})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bufio"
+	"context"
+
+	"go-grpc/helpers"
+	pb "go-grpc/pb/library"
+	"go-grpc/report"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type ReportService struct {
+	pb.UnimplementedReportServiceServer
+	DB        *gorm.DB
+	Scheduler *report.Scheduler
+}
+
+// Size of the ReportChunk messages of ExportReport
+const reportChunkSize = 32 * 1024
+
+// ListReports(context.Context, *Empty) (*ReportDefinitionsResponse, error)
+func (s *ReportService) ListReports(ctx context.Context, req *pb.Empty) (*pb.ReportDefinitionsResponse, error) {
+
+	if err := adminOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	var definitions []*pb.ReportDefinition
+	for _, d := range report.Definitions {
+		definitions = append(definitions, &pb.ReportDefinition{Name: d.Name, Title: d.Title, Description: d.Description})
+	}
+
+	return &pb.ReportDefinitionsResponse{Data: definitions}, nil
+}
+
+// RunReport(context.Context, *ReportRequest) (*ReportResponse, error)
+func (s *ReportService) RunReport(ctx context.Context, req *pb.ReportRequest) (*pb.ReportResponse, error) {
+
+	if err := adminOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	table, err := s.run(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ReportResponse{
+		Report:      table.Report,
+		GeneratedAt: table.GeneratedAt.Format(helpers.DateTimeLayout),
+	}
+	for _, c := range table.Columns {
+		resp.Columns = append(resp.Columns, &pb.ReportColumn{Name: c.Name, Type: c.Type})
+	}
+	for _, row := range table.Rows {
+		resp.Rows = append(resp.Rows, &pb.ReportRow{Values: row})
+	}
+
+	return resp, nil
+}
+
+// ExportReport(*ReportRequest, ReportService_ExportReportServer) error
+func (s *ReportService) ExportReport(req *pb.ReportRequest, stream pb.ReportService_ExportReportServer) error {
+
+	if err := adminOnly(stream.Context()); err != nil {
+		return err
+	}
+
+	format, err := report.ParseFormat(req.Format)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	table, err := s.run(req)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriterSize(&reportChunkWriter{
+		stream:      stream,
+		filename:    table.Filename(format),
+		contentType: report.ContentTypes[format],
+	}, reportChunkSize)
+
+	if err := table.Write(out, format); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return out.Flush()
+}
+
+func (s *ReportService) run(req *pb.ReportRequest) (*report.Table, error) {
+	if _, ok := report.Find(req.Report); !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown report %q, see ListReports", req.Report)
+	}
+
+	// There is no branch in the data model, every loan belongs to the one library
+	if req.Branch != "" {
+		return nil, status.Error(codes.Unimplemented, "branches are not supported, the library has a single location")
+	}
+
+	params := report.Params{From: req.From, To: req.To, CategoryID: req.CategoryId, Limit: int(req.Limit)}
+	for _, bound := range []string{req.From, req.To} {
+		if bound == "" {
+			continue
+		}
+		if _, err := report.ParseTime(bound); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	table, err := report.Run(s.DB, req.Report, params)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return table, nil
+}
+
+// adminOnly checks the role of the caller
+func adminOnly(ctx context.Context) error {
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+	return nil
+}
+
+// reportChunkWriter sends what is written as ReportChunk messages, the
+// first one names the file
+type reportChunkWriter struct {
+	stream      pb.ReportService_ExportReportServer
+	filename    string
+	contentType string
+	sent        bool
+}
+
+func (c *reportChunkWriter) Write(p []byte) (int, error) {
+	chunk := &pb.ReportChunk{Data: make([]byte, len(p))}
+	copy(chunk.Data, p)
+
+	if !c.sent {
+		chunk.Filename, chunk.ContentType = c.filename, c.contentType
+		c.sent = true
+	}
+
+	if err := c.stream.Send(chunk); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"net/mail"
+	"time"
+
+	"go-grpc/cron"
+	"go-grpc/helpers"
+	"go-grpc/model"
+	pb "go-grpc/pb/library"
+	paginationPb "go-grpc/pb/pagination"
+	"go-grpc/report"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateReportSchedule(context.Context, *ReportScheduleRequest) (*ReportScheduleResponse, error)
+func (s *ReportService) CreateReportSchedule(ctx context.Context, req *pb.ReportScheduleRequest) (*pb.ReportScheduleResponse, error) {
+
+	if err := adminOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.scheduleFromPb(req)
+	if err != nil {
+		return nil, err
+	}
+	schedule.CreatedAt = time.Now().Format(helpers.DateTimeLayout)
+
+	if err := s.DB.Create(&schedule).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ReportScheduleResponse{Data: reportScheduleToPb(schedule)}, nil
+}
+
+// UpdateReportSchedule(context.Context, *ReportScheduleRequest) (*ReportScheduleResponse, error)
+func (s *ReportService) UpdateReportSchedule(ctx context.Context, req *pb.ReportScheduleRequest) (*pb.ReportScheduleResponse, error) {
+
+	if err := adminOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	var existing model.ReportSchedule
+	if err := s.DB.First(&existing, req.GetId()).Error; err != nil {
+		return nil, status.Errorf(codes.NotFound, "report schedule not found")
+	}
+
+	schedule, err := s.scheduleFromPb(req)
+	if err != nil {
+		return nil, err
+	}
+	schedule.ID, schedule.CreatedAt = existing.ID, existing.CreatedAt
+
+	// Every field is replaced, next_run_at follows the new cron expression
+	if err := s.DB.Select("*").Omit("created_at").Updates(&schedule).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ReportScheduleResponse{Data: reportScheduleToPb(schedule)}, nil
+}
+
+// ListReportSchedules(context.Context, *ParameterReq) (*ReportSchedulesResponse, error)
+func (s *ReportService) ListReportSchedules(ctx context.Context, req *pb.ParameterReq) (*pb.ReportSchedulesResponse, error) {
+
+	if err := adminOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	var schedules []model.ReportSchedule
+	var pagination paginationPb.Pagination
+
+	sql := s.DB.Model(&model.ReportSchedule{})
+	if req.GetSearch() != "" {
+		sql = sql.Where("name LIKE ?", "%"+req.GetSearch()+"%")
+	}
+
+	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
+
+	if err := sql.Order("id").Offset(int(offset)).Limit(int(limit)).Find(&schedules).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var data []*pb.ReportSchedule
+	for _, schedule := range schedules {
+		data = append(data, reportScheduleToPb(schedule))
+	}
+
+	return &pb.ReportSchedulesResponse{
+		Pagination: &pagination,
+		Data:       data,
+	}, nil
+}
+
+// DeleteReportSchedule(context.Context, *IdRequest) (*Empty, error)
+func (s *ReportService) DeleteReportSchedule(ctx context.Context, req *pb.IdRequest) (*pb.Empty, error) {
+
+	if err := adminOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	// The run history of the schedule is removed by ON DELETE CASCADE, files
+	// already delivered are kept
+	result := s.DB.Where("id = ?", req.GetId()).Delete(&model.ReportSchedule{})
+	if result.Error != nil {
+		return nil, status.Error(codes.Internal, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, status.Errorf(codes.NotFound, "report schedule not found")
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// RunReportSchedule(context.Context, *RunReportScheduleRequest) (*ReportRunResponse, error)
+func (s *ReportService) RunReportSchedule(ctx context.Context, req *pb.RunReportScheduleRequest) (*pb.ReportRunResponse, error) {
+
+	if err := adminOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	var schedule model.ReportSchedule
+	if err := s.DB.First(&schedule, req.GetScheduleId()).Error; err != nil {
+		return nil, status.Errorf(codes.NotFound, "report schedule not found")
+	}
+
+	now := time.Now()
+	var from, to string
+
+	switch {
+	case req.GetFrom() == "" && req.GetTo() == "":
+		expr, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		start, end, err := report.LastPeriod(expr, now)
+		if err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "no completed period, give from and to: %v", err)
+		}
+		from, to = start.Format(helpers.DateTimeLayout), end.Format(helpers.DateTimeLayout)
+
+	case req.GetFrom() == "" || req.GetTo() == "":
+		return nil, status.Error(codes.InvalidArgument, "give both from and to, or neither for the last completed period")
+
+	default:
+		var err error
+		if from, err = report.ParseTime(req.GetFrom()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "from: %v", err)
+		}
+		if to, err = report.ParseTime(req.GetTo()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "to: %v", err)
+		}
+		// Both are in helpers.DateTimeLayout, which sorts like time
+		if from >= to {
+			return nil, status.Error(codes.InvalidArgument, "from must be before to")
+		}
+	}
+
+	// Queued for the scheduler, which picks it up within a minute
+	run := model.ReportRun{
+		ScheduleID:  schedule.ID,
+		PeriodStart: from,
+		PeriodEnd:   to,
+		TriggeredBy: report.TriggerManual,
+		Status:      report.RunPending,
+		CreatedAt:   now.Format(helpers.DateTimeLayout),
+	}
+
+	if err := s.DB.Create(&run).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ReportRunResponse{Data: reportRunToPb(run, schedule)}, nil
+}
+
+// ListReportRuns(context.Context, *ReportRunsRequest) (*ReportRunsResponse, error)
+func (s *ReportService) ListReportRuns(ctx context.Context, req *pb.ReportRunsRequest) (*pb.ReportRunsResponse, error) {
+
+	if err := adminOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	var runs []*pb.ReportRun
+	var pagination paginationPb.Pagination
+
+	sql := s.DB.Table("report_runs as rr").
+		Joins("JOIN report_schedules rs on rs.id = rr.schedule_id").
+		Select("rr.id, rr.schedule_id, rs.name, rs.report, rr.period_start, rr.period_end, rr.triggered_by, rr.status, rr.row_count, " +
+			"COALESCE(rr.filename, ''), COALESCE(rr.location, ''), COALESCE(rr.error, ''), COALESCE(rr.created_at, ''), " +
+			"COALESCE(rr.started_at, ''), COALESCE(rr.finished_at, '')")
+
+	if req.GetScheduleId() > 0 {
+		sql = sql.Where("rr.schedule_id = ?", req.GetScheduleId())
+	}
+	if req.GetStatus() != "" {
+		sql = sql.Where("rr.status = ?", req.GetStatus())
+	}
+
+	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
+
+	rows, err := sql.Order("rr.id DESC").Offset(int(offset)).Limit(int(limit)).Rows()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var run pb.ReportRun
+
+		if err := rows.Scan(&run.Id, &run.ScheduleId, &run.ScheduleName, &run.Report, &run.PeriodStart, &run.PeriodEnd, &run.TriggeredBy,
+			&run.Status, &run.Rows, &run.Filename, &run.Location, &run.Error, &run.CreatedAt, &run.StartedAt, &run.FinishedAt); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		runs = append(runs, &run)
+	}
+
+	return &pb.ReportRunsResponse{
+		Pagination: &pagination,
+		Data:       runs,
+	}, nil
+}
+
+// scheduleFromPb validates a schedule request, next_run_at is worked out from the cron expression
+func (s *ReportService) scheduleFromPb(req *pb.ReportScheduleRequest) (model.ReportSchedule, error) {
+	if req.GetName() == "" {
+		return model.ReportSchedule{}, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	if _, ok := report.Find(req.GetReport()); !ok {
+		return model.ReportSchedule{}, status.Errorf(codes.InvalidArgument, "unknown report %q, see ListReports", req.GetReport())
+	}
+
+	format, err := report.ParseFormat(req.GetFormat())
+	if err != nil {
+		return model.ReportSchedule{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	expr, err := cron.Parse(req.GetCron())
+	if err != nil {
+		return model.ReportSchedule{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	next := report.NextRunAt(expr, time.Now())
+	if !next.Valid {
+		return model.ReportSchedule{}, status.Errorf(codes.InvalidArgument, "cron expression %q never fires", req.GetCron())
+	}
+
+	switch req.GetDelivery() {
+	case report.DeliveryEmail:
+		if _, err := mail.ParseAddress(req.GetEmail()); err != nil {
+			return model.ReportSchedule{}, status.Errorf(codes.InvalidArgument, "email delivery needs a valid email address: %v", err)
+		}
+		if s.Scheduler == nil || s.Scheduler.Mailer == nil {
+			return model.ReportSchedule{}, status.Error(codes.FailedPrecondition, "email delivery needs SMTP_HOST to be configured")
+		}
+	case report.DeliveryDirectory:
+		if s.Scheduler == nil || s.Scheduler.Dir == "" {
+			return model.ReportSchedule{}, status.Error(codes.FailedPrecondition, "directory delivery needs REPORT_DIR to be configured")
+		}
+	default:
+		return model.ReportSchedule{}, status.Errorf(codes.InvalidArgument, "delivery must be %q or %q", report.DeliveryEmail, report.DeliveryDirectory)
+	}
+
+	if req.GetAlertEmail() != "" {
+		if _, err := mail.ParseAddress(req.GetAlertEmail()); err != nil {
+			return model.ReportSchedule{}, status.Errorf(codes.InvalidArgument, "alert_email: %v", err)
+		}
+	}
+
+	active := true
+	if req.Active != nil {
+		active = req.GetActive()
+	}
+
+	return model.ReportSchedule{
+		Name:       req.GetName(),
+		Report:     req.GetReport(),
+		CategoryID: req.GetCategoryId(),
+		RowLimit:   req.GetLimit(),
+		Format:     format,
+		Cron:       req.GetCron(),
+		Delivery:   req.GetDelivery(),
+		Email:      req.GetEmail(),
+		AlertEmail: req.GetAlertEmail(),
+		Active:     active,
+		NextRunAt:  next,
+	}, nil
+}
+
+func reportScheduleToPb(schedule model.ReportSchedule) *pb.ReportSchedule {
+	return &pb.ReportSchedule{
+		Id:         schedule.ID,
+		Name:       schedule.Name,
+		Report:     schedule.Report,
+		CategoryId: schedule.CategoryID,
+		Limit:      schedule.RowLimit,
+		Format:     schedule.Format,
+		Cron:       schedule.Cron,
+		Delivery:   schedule.Delivery,
+		Email:      schedule.Email,
+		AlertEmail: schedule.AlertEmail,
+		Active:     schedule.Active,
+		NextRunAt:  schedule.NextRunAt.String,
+		CreatedAt:  schedule.CreatedAt,
+	}
+}
+
+func reportRunToPb(run model.ReportRun, schedule model.ReportSchedule) *pb.ReportRun {
+	return &pb.ReportRun{
+		Id:           run.ID,
+		ScheduleId:   run.ScheduleID,
+		ScheduleName: schedule.Name,
+		Report:       schedule.Report,
+		PeriodStart:  run.PeriodStart,
+		PeriodEnd:    run.PeriodEnd,
+		TriggeredBy:  run.TriggeredBy,
+		Status:       run.Status,
+		Rows:         run.RowCount,
+		Filename:     run.Filename,
+		Location:     run.Location,
+		Error:        run.Error.String,
+		CreatedAt:    run.CreatedAt,
+		StartedAt:    run.StartedAt.String,
+		FinishedAt:   run.FinishedAt.String,
+	}
+}
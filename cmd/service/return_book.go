@@ -3,34 +3,47 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"time"
 
+	"go-grpc/events"
+	"go-grpc/helpers"
 	"go-grpc/model" // Import the models package
+	"go-grpc/outbox"
 	pb "go-grpc/pb/library"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ReturningServiceServer struct {
 	pb.UnimplementedReturningServiceServer
-	DB *gorm.DB
+	DB     *gorm.DB
+	Events *events.CirculationFeed
+
+	// Fine charged for every started day a book is returned late
+	FinePerDay float64
+}
+
+// errReturnRejected marks a return that was refused, the message is sent back to the caller
+type errReturnRejected struct {
+	message string
+}
+
+func (e *errReturnRejected) Error() string {
+	return e.message
 }
 
 func (s *ReturningServiceServer) ReturnBook(ctx context.Context, req *pb.ReturnBookRequest) (*pb.ReturnBookResponse, error) {
-	var transaction model.BorrowingTransaction
 
-	// Temukan transaksi berdasarkan ID
-	err := s.DB.First(&transaction, req.TransactionId).Error
+	userID, role, err := helpers.GetData(ctx)
 	if err != nil {
-		if gorm.ErrRecordNotFound == err {
-			return &pb.ReturnBookResponse{Success: false, Message: "Transaction not found"}, nil
-		}
-		return nil, err
-	}
-
-	if transaction.ReturnedAt.String != "" {
-		return &pb.ReturnBookResponse{Success: false, Message: "Book already returned"}, nil
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
 	}
 
 	// Parse timestamp
@@ -41,23 +54,177 @@ func (s *ReturningServiceServer) ReturnBook(ctx context.Context, req *pb.ReturnB
 		return nil, err
 	}
 
-	transaction.ReturnedAt = sql.NullString{String: req.ReturnedAt, Valid: true}
-	transaction.Status = "returned"
+	var fineAmount float64
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var transaction model.BorrowingTransaction
+
+		// Temukan transaksi berdasarkan ID, dikunci agar tidak dikembalikan dua kali
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, req.TransactionId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &errReturnRejected{message: "Transaction not found"}
+			}
+			return err
+		}
+
+		if transaction.ReturnedAt.String != "" {
+			return &errReturnRejected{message: "Book already returned"}
+		}
+
+		transaction.ReturnedAt = sql.NullString{String: req.ReturnedAt, Valid: true}
+		transaction.Status = "returned"
+
+		// Simpan perubahan
+		if err := tx.Save(&transaction).Error; err != nil {
+			return err
+		}
+
+		daysLate := lateDays(transaction.DueDate, returnAt)
+		fineAmount = float64(daysLate) * s.FinePerDay
+
+		// Simpan informasi pengembalian di tabel returning_transactions
+		returningTransaction := model.ReturningTransaction{
+			BorrowingTransactionID: transaction.ID,
+			ReturnedAt:             returnAt,
+			FineAmount:             fineAmount,
+		}
+
+		if err := tx.Create(&returningTransaction).Error; err != nil {
+			return err
+		}
+
+		// Published to webhooks by the outbox dispatcher once this transaction commits
+		if err := outbox.Write(tx, outbox.LoanReturned, map[string]interface{}{
+			"transaction_id": transaction.ID,
+			"borrower_id":    transaction.BorrowerID,
+			"book_id":        transaction.BookID,
+			"due_date":       transaction.DueDate,
+			"returned_at":    req.ReturnedAt,
+			"fine_amount":    helpers.Money(fineAmount),
+		}); err != nil {
+			return err
+		}
+
+		if fineAmount > 0 {
+			if err := outbox.Write(tx, outbox.FineAssessed, map[string]interface{}{
+				"transaction_id":           transaction.ID,
+				"returning_transaction_id": returningTransaction.ID,
+				"borrower_id":              transaction.BorrowerID,
+				"book_id":                  transaction.BookID,
+				"days_late":                daysLate,
+				"amount":                   helpers.Money(fineAmount),
+			}); err != nil {
+				return err
+			}
+		}
+
+		// Put the copy back on the shelf
+		if _, err := adjustStock(tx, transaction.BookID, 1, "return", "", userID, role); err != nil {
+			return err
+		}
+
+		return events.Record(tx, model.CirculationEvent{
+			Type:          events.BookReturned,
+			BookID:        transaction.BookID,
+			BorrowerID:    transaction.BorrowerID,
+			TransactionID: transaction.ID,
+		})
+	})
 
-	// Simpan perubahan
-	if err := s.DB.Save(&transaction).Error; err != nil {
+	var rejected *errReturnRejected
+	if errors.As(err, &rejected) {
+		return &pb.ReturnBookResponse{Success: false, Message: rejected.message}, nil
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	// Simpan informasi pengembalian di tabel returning_transactions
-	returningTransaction := model.ReturningTransaction{
-		BorrowingTransactionID: transaction.ID,
-		ReturnedAt:             returnAt,
+	s.Events.Notify()
+
+	return &pb.ReturnBookResponse{Success: true, Message: "Book returned successfully", FineAmount: helpers.Money(fineAmount)}, nil
+}
+
+// PayFine(context.Context, *PayFineRequest) (*ReturnSimpleResponse, error)
+func (s *ReturningServiceServer) PayFine(ctx context.Context, req *pb.PayFineRequest) (*pb.ReturnSimpleResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
 	}
 
-	if err := s.DB.Create(&returningTransaction).Error; err != nil {
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// Timestamps are read as strings, the connection does not parse them into time.Time
+		var returning struct {
+			ID                     int32
+			BorrowingTransactionID int32
+			BorrowerID             sql.NullInt32
+			FineAmount             string
+			FinePaidAt             sql.NullString
+		}
+
+		if err := tx.Table("returning_transactions rt").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Joins("JOIN borrowing_transactions bt on bt.id = rt.borrowing_transaction_id").
+			Select("rt.id, rt.borrowing_transaction_id, bt.borrower_id, rt.fine_amount, rt.fine_paid_at").
+			Where("rt.borrowing_transaction_id = ?", req.GetTransactionId()).
+			Take(&returning).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &errReturnRejected{message: "Transaction not found or not returned"}
+			}
+			return err
+		}
+
+		fine, err := strconv.ParseFloat(returning.FineAmount, 64)
+		if err != nil {
+			return err
+		}
+		if fine <= 0 {
+			return &errReturnRejected{message: "No fine for this transaction"}
+		}
+		if returning.FinePaidAt.Valid {
+			return &errReturnRejected{message: "Fine already paid"}
+		}
+
+		now := time.Now().Format(helpers.DateTimeLayout)
+		if err := tx.Table("returning_transactions").Where("id = ?", returning.ID).Update("fine_paid_at", now).Error; err != nil {
+			return err
+		}
+
+		// The loan keeps no borrower once its history is anonymized
+		var borrowerID interface{}
+		if returning.BorrowerID.Valid {
+			borrowerID = returning.BorrowerID.Int32
+		}
+
+		return outbox.Write(tx, outbox.FinePaid, map[string]interface{}{
+			"transaction_id":           returning.BorrowingTransactionID,
+			"returning_transaction_id": returning.ID,
+			"borrower_id":              borrowerID,
+			"amount":                   helpers.Money(fine),
+			"paid_at":                  now,
+		})
+	})
+
+	var rejected *errReturnRejected
+	if errors.As(err, &rejected) {
+		return &pb.ReturnSimpleResponse{Success: false, Message: rejected.message}, nil
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	return &pb.ReturnBookResponse{Success: true, Message: "Book returned successfully"}, nil
+	return &pb.ReturnSimpleResponse{Success: true, Message: "Fine paid"}, nil
+}
+
+// lateDays counts the started days between the due date and the return, 0 when on time
+func lateDays(dueDate string, returnedAt time.Time) int {
+	due, err := time.Parse(helpers.DateTimeLayout, dueDate)
+	if err != nil || !returnedAt.After(due) {
+		return 0
+	}
+
+	return int(math.Ceil(returnedAt.Sub(due).Hours() / 24))
 }
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-grpc/helpers"
+	pb "go-grpc/pb/library"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestPayFine(t *testing.T) {
+	ledger := &fines{returns: []testReturn{
+		{ID: 10, TransactionID: 1, BorrowerID: 7, FineAmount: "3000.00"},
+		{ID: 11, TransactionID: 2, BorrowerID: 7, FineAmount: "0.00"},
+		// Anonymized history, the loan has no borrower anymore
+		{ID: 12, TransactionID: 3, FineAmount: "1000.00"},
+	}}
+	s := &ReturningServiceServer{DB: ledger.db(t)}
+	admin := helpers.WithUser(context.Background(), 1, "admin")
+
+	resp, err := s.PayFine(admin, &pb.PayFineRequest{TransactionId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success {
+		t.Fatalf("PayFine = %q, want paid", resp.Message)
+	}
+	if !ledger.returns[0].FinePaidAt.Valid {
+		t.Error("fine_paid_at was not set")
+	}
+
+	if len(ledger.outbox) != 1 {
+		t.Fatalf("outbox has %d events, want 1", len(ledger.outbox))
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(ledger.outbox[0]), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload["amount"] != "3000.00" || payload["borrower_id"] != float64(7) || payload["returning_transaction_id"] != float64(10) {
+		t.Errorf("fine.paid payload = %v", payload)
+	}
+
+	for _, tt := range []struct {
+		transactionID int32
+		message       string
+	}{
+		{1, "Fine already paid"},
+		{2, "No fine for this transaction"},
+		{99, "Transaction not found or not returned"},
+	} {
+		resp, err := s.PayFine(admin, &pb.PayFineRequest{TransactionId: tt.transactionID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Success || resp.Message != tt.message {
+			t.Errorf("PayFine(%d) = %v %q, want %q", tt.transactionID, resp.Success, resp.Message, tt.message)
+		}
+	}
+
+	if resp, err := s.PayFine(admin, &pb.PayFineRequest{TransactionId: 3}); err != nil || !resp.Success {
+		t.Fatalf("PayFine of an anonymized loan = %v, %v", resp, err)
+	}
+	if !strings.Contains(ledger.outbox[1], `"borrower_id":null`) {
+		t.Errorf("fine.paid payload of an anonymized loan = %s", ledger.outbox[1])
+	}
+
+	borrower := helpers.WithUser(context.Background(), 7, "borrower")
+	if _, err := s.PayFine(borrower, &pb.PayFineRequest{TransactionId: 2}); err == nil {
+		t.Error("borrower paid a fine")
+	}
+}
+
+// fines holds returning_transactions with the borrower of their loan and the
+// outbox, behind a database/sql connector. Values come back as bytes, as from
+// a MySQL connection without parseTime.
+type fines struct {
+	mu sync.Mutex
+
+	returns []testReturn
+	outbox  []string
+}
+
+type testReturn struct {
+	ID, TransactionID, BorrowerID int32
+	FineAmount                    string
+	FinePaidAt                    sql.NullString
+}
+
+func (f *fines) db(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(f), SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func (f *fines) query(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.Contains(query, "FROM returning_transactions rt JOIN borrowing_transactions bt") || !strings.HasSuffix(query, "FOR UPDATE") {
+		return nil, nil, fmt.Errorf("unexpected query %q", query)
+	}
+
+	var rows [][]driver.Value
+	for _, r := range f.returns {
+		if int64(r.TransactionID) != args[0].Value {
+			continue
+		}
+
+		var borrowerID, paidAt driver.Value
+		if r.BorrowerID != 0 {
+			borrowerID = []byte(fmt.Sprint(r.BorrowerID))
+		}
+		if r.FinePaidAt.Valid {
+			paidAt = []byte(r.FinePaidAt.String)
+		}
+		rows = append(rows, []driver.Value{[]byte(fmt.Sprint(r.ID)), []byte(fmt.Sprint(r.TransactionID)), borrowerID, []byte(r.FineAmount), paidAt})
+	}
+	return []string{"id", "borrowing_transaction_id", "borrower_id", "fine_amount", "fine_paid_at"}, rows, nil
+}
+
+func (f *fines) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "UPDATE `returning_transactions` SET `fine_paid_at`=? WHERE id = ?"):
+		for i := range f.returns {
+			if int64(f.returns[i].ID) == args[1].Value {
+				f.returns[i].FinePaidAt = sql.NullString{String: args[0].Value.(string), Valid: true}
+				return driver.RowsAffected(1), nil
+			}
+		}
+		return driver.RowsAffected(0), nil
+
+	case strings.HasPrefix(query, "INSERT INTO `outbox_events` (`event_type`,`payload`,"):
+		f.outbox = append(f.outbox, args[1].Value.(string))
+		return testResult{id: int64(len(f.outbox))}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected statement %q", query)
+}
+
+// Connect and Driver make the fines a driver.Connector
+func (f *fines) Connect(context.Context) (driver.Conn, error) { return &testConn{fines: f}, nil }
+func (f *fines) Driver() driver.Driver                        { return nil }
+
+type testConn struct{ fines *fines }
+
+func (c *testConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns, rows, err := c.fines.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &testRows{columns: columns, rows: rows}, nil
+}
+
+func (c *testConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.fines.exec(query, args)
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported: %q", query)
+}
+func (c *testConn) Close() error { return nil }
+
+// Begin hands out a transaction that applies every statement right away
+func (c *testConn) Begin() (driver.Tx, error) { return testTx{}, nil }
+
+type testTx struct{}
+
+func (testTx) Commit() error   { return nil }
+func (testTx) Rollback() error { return nil }
+
+type testResult struct{ id int64 }
+
+func (r testResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r testResult) RowsAffected() (int64, error) { return 1, nil }
+
+type testRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *testRows) Columns() []string { return r.columns }
+func (r *testRows) Close() error      { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
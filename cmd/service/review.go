@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-grpc/catalog"
+	"go-grpc/helpers"
+	"go-grpc/model"
+	pb "go-grpc/pb/library"
+	paginationPb "go-grpc/pb/pagination"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Longest review text in characters
+const maxReviewLength = 2000
+
+// ReviewService lets borrowers rate and review the books they have read, and
+// admins moderate the reviews before they are shown
+type ReviewService struct {
+	pb.UnimplementedReviewServiceServer
+	DB *gorm.DB
+}
+
+// PostReview(context.Context, *PostReviewRequest) (*ReviewResponse, error)
+func (s *ReviewService) PostReview(ctx context.Context, req *pb.PostReviewRequest) (*pb.ReviewResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "borrower" {
+		return nil, status.Errorf(codes.PermissionDenied, "reviews are only available for borrowers")
+	}
+
+	if req.GetRating() < 1 || req.GetRating() > 5 {
+		return nil, status.Errorf(codes.InvalidArgument, "rating must be between 1 and 5")
+	}
+
+	body := strings.TrimSpace(req.GetBody())
+	if utf8.RuneCountInString(body) > maxReviewLength {
+		return nil, status.Errorf(codes.InvalidArgument, "review is longer than %d characters", maxReviewLength)
+	}
+
+	var count int64
+	if err := s.DB.Table("books").Where("id = ? AND deleted_at IS NULL", req.GetBookId()).Count(&count).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if count == 0 {
+		return nil, status.Errorf(codes.NotFound, "book not found")
+	}
+
+	// Anonymized loans have no borrower anymore and do not count
+	if err := s.DB.Table("borrowing_transactions").
+		Where("borrower_id = ? AND book_id = ? AND returned_at IS NOT NULL", userID, req.GetBookId()).
+		Count(&count).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if count == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "only books you have borrowed and returned can be reviewed")
+	}
+
+	// A bare rating has nothing to moderate, text waits for an admin
+	reviewStatus := catalog.ReviewApproved
+	if body != "" {
+		reviewStatus = catalog.ReviewPending
+	}
+
+	now := time.Now().Format(helpers.DateTimeLayout)
+	review := model.BookReview{
+		BookID:     req.GetBookId(),
+		BorrowerID: int32(userID),
+		Rating:     req.GetRating(),
+		Body:       body,
+		Status:     reviewStatus,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	// Posting again replaces the review and sends it through moderation again
+	if err := s.DB.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"rating", "body", "status", "moderation_note", "moderated_by", "moderated_at", "updated_at"}),
+	}).Create(&review).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	data, err := s.review("r.book_id = ? AND r.borrower_id = ?", req.GetBookId(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReviewResponse{Data: data}, nil
+}
+
+// ListBookReviews(context.Context, *ListBookReviewsRequest) (*ReviewsResponse, error)
+func (s *ReviewService) ListBookReviews(ctx context.Context, req *pb.ListBookReviewsRequest) (*pb.ReviewsResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	sql := reviewQuery(s.DB).Where("r.book_id = ?", req.GetBookId())
+
+	// Admin see every review, reviewers their own one while it waits for moderation
+	if role != "admin" {
+		sql = sql.Where("b.deleted_at IS NULL AND (r.status = ? OR r.borrower_id = ?)", catalog.ReviewApproved, userID)
+	}
+
+	return s.listReviews(sql, "r.created_at DESC, r.id DESC", req.Page, req.Limit, userID, role)
+}
+
+// DeleteReview(context.Context, *IdRequest) (*Empty, error)
+func (s *ReviewService) DeleteReview(ctx context.Context, req *pb.IdRequest) (*pb.Empty, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	sql := s.DB.Table("book_reviews").Where("id = ?", req.GetId())
+
+	// Borrowers may only withdraw their own reviews
+	if role != "admin" {
+		sql = sql.Where("borrower_id = ?", userID)
+	}
+
+	result := sql.Delete(nil)
+	if result.Error != nil {
+		return nil, status.Error(codes.Internal, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, status.Errorf(codes.NotFound, "review not found")
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// ListReviewQueue(context.Context, *ReviewQueueRequest) (*ReviewsResponse, error)
+func (s *ReviewService) ListReviewQueue(ctx context.Context, req *pb.ReviewQueueRequest) (*pb.ReviewsResponse, error) {
+
+	if err := adminOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	reviewStatus := req.GetStatus()
+	if reviewStatus == "" {
+		reviewStatus = catalog.ReviewPending
+	}
+	if reviewStatus != catalog.ReviewPending && reviewStatus != catalog.ReviewApproved && reviewStatus != catalog.ReviewHidden {
+		return nil, status.Errorf(codes.InvalidArgument, "status must be %q, %q or %q", catalog.ReviewPending, catalog.ReviewApproved, catalog.ReviewHidden)
+	}
+
+	sql := reviewQuery(s.DB).Where("r.status = ?", reviewStatus)
+
+	if req.GetBookId() > 0 {
+		sql = sql.Where("r.book_id = ?", req.GetBookId())
+	}
+
+	// Oldest first, the queue is worked through in the order reviews came in
+	return s.listReviews(sql, "r.updated_at, r.id", req.Page, req.Limit, 0, "admin")
+}
+
+// ModerateReview(context.Context, *ModerateReviewRequest) (*ReviewResponse, error)
+func (s *ReviewService) ModerateReview(ctx context.Context, req *pb.ModerateReviewRequest) (*pb.ReviewResponse, error) {
+
+	userID, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	if req.GetStatus() != catalog.ReviewApproved && req.GetStatus() != catalog.ReviewHidden {
+		return nil, status.Errorf(codes.InvalidArgument, "status must be %q or %q", catalog.ReviewApproved, catalog.ReviewHidden)
+	}
+
+	if utf8.RuneCountInString(req.GetNote()) > 255 {
+		return nil, status.Errorf(codes.InvalidArgument, "note is longer than 255 characters")
+	}
+
+	if _, err := s.review("r.id = ?", req.GetId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Table("book_reviews").Where("id = ?", req.GetId()).Updates(map[string]interface{}{
+		"status":          req.GetStatus(),
+		"moderation_note": req.GetNote(),
+		"moderated_by":    userID,
+		"moderated_at":    time.Now().Format(helpers.DateTimeLayout),
+	}).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	data, err := s.review("r.id = ?", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReviewResponse{Data: data}, nil
+}
+
+// reviewQuery joins reviews with their book and reviewer, rows are read with scanReview
+func reviewQuery(db *gorm.DB) *gorm.DB {
+	return db.Table("book_reviews as r").
+		Joins("JOIN books b on b.id = r.book_id").
+		Joins("JOIN borrowers br on br.id = r.borrower_id").
+		Select("r.id, r.book_id, b.title, r.borrower_id, br.name, r.rating, COALESCE(r.body, ''), r.status, COALESCE(r.moderation_note, ''), " +
+			"COALESCE(r.created_at, ''), COALESCE(r.updated_at, ''), COALESCE(r.moderated_at, '')")
+}
+
+func scanReview(row catalog.Scanner) (*pb.Review, error) {
+	var review pb.Review
+
+	if err := row.Scan(&review.Id, &review.BookId, &review.BookTitle, &review.BorrowerId, &review.BorrowerName, &review.Rating, &review.Body,
+		&review.Status, &review.ModerationNote, &review.CreatedAt, &review.UpdatedAt, &review.ModeratedAt); err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// review reads a single review for a response
+func (s *ReviewService) review(query string, args ...interface{}) (*pb.Review, error) {
+	review, err := scanReview(reviewQuery(s.DB).Where(query, args...).Row())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "review not found")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return review, nil
+}
+
+// listReviews pages through the reviews, reviewers are named to admin and to themselves only
+func (s *ReviewService) listReviews(sql *gorm.DB, order string, page, limit int64, userID int, role string) (*pb.ReviewsResponse, error) {
+	var reviews []*pb.Review
+	var pagination paginationPb.Pagination
+
+	offset, limit := helpers.Pagination(sql, page, limit, &pagination)
+
+	rows, err := sql.Order(order).Offset(int(offset)).Limit(int(limit)).Rows()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		if role != "admin" && review.BorrowerId != int32(userID) {
+			review.BorrowerId = 0
+			review.BorrowerName = ""
+			review.ModerationNote = ""
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	return &pb.ReviewsResponse{
+		Pagination: &pagination,
+		Data:       reviews,
+	}, nil
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-grpc/helpers"
+	"go-grpc/model"
+	pb "go-grpc/pb/library"
+	paginationPb "go-grpc/pb/pagination"
+	"go-grpc/webhook"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type WebhookService struct {
+	pb.UnimplementedWebhookServiceServer
+	DB         *gorm.DB
+	Dispatcher *webhook.Dispatcher
+}
+
+// RegisterWebhook(context.Context, *RegisterWebhookRequest) (*WebhookResponse, error)
+func (s *WebhookService) RegisterWebhook(ctx context.Context, req *pb.RegisterWebhookRequest) (*pb.WebhookResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	u, err := url.Parse(req.GetUrl())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "url must be an absolute http or https url")
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	endpoint := model.WebhookEndpoint{
+		URL:         u.String(),
+		Secret:      secret,
+		EventTypes:  strings.Join(req.GetEventTypes(), ","),
+		Description: req.GetDescription(),
+		Active:      true,
+		CreatedAt:   time.Now().Format(helpers.DateTimeLayout),
+	}
+
+	if err := s.DB.Create(&endpoint).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	data := webhookToPb(endpoint)
+	// The secret is only shown once, receivers need it to verify signatures
+	data.Secret = endpoint.Secret
+
+	return &pb.WebhookResponse{Data: data}, nil
+}
+
+// ListWebhooks(context.Context, *ParameterReq) (*WebhooksResponse, error)
+func (s *WebhookService) ListWebhooks(ctx context.Context, req *pb.ParameterReq) (*pb.WebhooksResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	var endpoints []model.WebhookEndpoint
+	var pagination paginationPb.Pagination
+
+	sql := s.DB.Model(&model.WebhookEndpoint{})
+
+	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
+
+	if err := sql.Order("id").Offset(int(offset)).Limit(int(limit)).Find(&endpoints).Error; err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var webhooks []*pb.Webhook
+	for _, endpoint := range endpoints {
+		webhooks = append(webhooks, webhookToPb(endpoint))
+	}
+
+	return &pb.WebhooksResponse{
+		Pagination: &pagination,
+		Data:       webhooks,
+	}, nil
+}
+
+// TestWebhook(context.Context, *IdRequest) (*TestWebhookResponse, error)
+func (s *WebhookService) TestWebhook(ctx context.Context, req *pb.IdRequest) (*pb.TestWebhookResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	var endpoint model.WebhookEndpoint
+	if err := s.DB.First(&endpoint, req.GetId()).Error; err != nil {
+		return nil, status.Errorf(codes.NotFound, "webhook not found")
+	}
+
+	// Sent synchronously and not stored in the outbox
+	event := model.OutboxEvent{
+		EventType: "webhook.test",
+		Payload:   `{"message":"test event from library management"}`,
+		CreatedAt: time.Now().Format(helpers.DateTimeLayout),
+	}
+
+	statusCode, err := s.Dispatcher.Post(ctx, endpoint, event, 0)
+	if err != nil {
+		return &pb.TestWebhookResponse{Success: false, StatusCode: int32(statusCode), Message: err.Error()}, nil
+	}
+
+	return &pb.TestWebhookResponse{Success: true, StatusCode: int32(statusCode), Message: "Test event delivered"}, nil
+}
+
+// DeleteWebhook(context.Context, *IdRequest) (*Empty, error)
+func (s *WebhookService) DeleteWebhook(ctx context.Context, req *pb.IdRequest) (*pb.Empty, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	// Deliveries of the endpoint are removed by ON DELETE CASCADE
+	result := s.DB.Where("id = ?", req.GetId()).Delete(&model.WebhookEndpoint{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, status.Errorf(codes.NotFound, "webhook not found")
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// ListWebhookDeliveries(context.Context, *WebhookDeliveriesRequest) (*WebhookDeliveriesResponse, error)
+func (s *WebhookService) ListWebhookDeliveries(ctx context.Context, req *pb.WebhookDeliveriesRequest) (*pb.WebhookDeliveriesResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	var deliveries []*pb.WebhookDelivery
+	var pagination paginationPb.Pagination
+
+	sql := s.DB.Table("webhook_deliveries as wd").
+		Joins("JOIN outbox_events oe on oe.id = wd.outbox_event_id").
+		Select("wd.id, wd.outbox_event_id, oe.event_type, wd.endpoint_id, wd.status, wd.attempts, COALESCE(wd.next_attempt_at, ''), " +
+			"COALESCE(wd.response_status, 0), COALESCE(wd.last_error, ''), COALESCE(wd.delivered_at, '')")
+
+	if req.GetWebhookId() > 0 {
+		sql = sql.Where("wd.endpoint_id = ?", req.GetWebhookId())
+	}
+	if req.GetStatus() != "" {
+		sql = sql.Where("wd.status = ?", req.GetStatus())
+	}
+
+	offset, limit := helpers.Pagination(sql, req.Page, req.Limit, &pagination)
+
+	rows, err := sql.Order("wd.id DESC").Offset(int(offset)).Limit(int(limit)).Rows()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var delivery pb.WebhookDelivery
+
+		if err := rows.Scan(&delivery.Id, &delivery.EventId, &delivery.EventType, &delivery.WebhookId, &delivery.Status, &delivery.Attempts,
+			&delivery.NextAttemptAt, &delivery.ResponseStatus, &delivery.LastError, &delivery.DeliveredAt); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return &pb.WebhookDeliveriesResponse{
+		Pagination: &pagination,
+		Data:       deliveries,
+	}, nil
+}
+
+// RetryWebhookDelivery(context.Context, *IdRequest) (*ReturnSimpleResponse, error)
+func (s *WebhookService) RetryWebhookDelivery(ctx context.Context, req *pb.IdRequest) (*pb.ReturnSimpleResponse, error) {
+
+	_, role, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user data: %v", err)
+	}
+
+	if role != "admin" {
+		return nil, status.Errorf(codes.PermissionDenied, "no access for borrower: %v", err)
+	}
+
+	// Only dead-lettered deliveries are re-queued, with a fresh retry budget
+	result := s.DB.Model(&model.WebhookDelivery{}).
+		Where("id = ? AND status = ?", req.GetId(), webhook.StatusDead).
+		Updates(map[string]interface{}{
+			"status":          webhook.StatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now().Format(helpers.DateTimeLayout),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &pb.ReturnSimpleResponse{Success: false, Message: "Delivery not found or not dead-lettered"}, nil
+	}
+
+	return &pb.ReturnSimpleResponse{Success: true, Message: "Delivery queued for retry"}, nil
+}
+
+func webhookToPb(endpoint model.WebhookEndpoint) *pb.Webhook {
+	var eventTypes []string
+	for _, t := range strings.Split(endpoint.EventTypes, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			eventTypes = append(eventTypes, t)
+		}
+	}
+
+	return &pb.Webhook{
+		Id:          endpoint.ID,
+		Url:         endpoint.URL,
+		EventTypes:  eventTypes,
+		Description: endpoint.Description,
+		Active:      endpoint.Active,
+		CreatedAt:   endpoint.CreatedAt,
+	}
+}
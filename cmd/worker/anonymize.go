@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go-grpc/helpers"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reading history settings of a borrower
+const (
+	HistoryRetain    = "retain"
+	HistoryAnonymize = "anonymize"
+)
+
+// HistoryAnonymizer detaches borrowers who chose to anonymize their reading
+// history from their loans once the loans have been returned for longer than
+// Retention. The loans stay, without a borrower, so statistics over loans,
+// returns and fines do not change.
+type HistoryAnonymizer struct {
+	DB        *gorm.DB
+	Retention time.Duration
+	BatchSize int
+}
+
+func (w *HistoryAnonymizer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.anonymize(ctx); err != nil {
+			log.Printf("history anonymizer: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// anonymize works through the due loans in batches
+func (w *HistoryAnonymizer) anonymize(ctx context.Context) error {
+	cutoff := time.Now().Add(-w.Retention).Format(helpers.DateTimeLayout)
+	total := 0
+
+	for ctx.Err() == nil {
+		n, err := w.anonymizeBatch(cutoff)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < w.BatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("history anonymizer: %d loans anonymized", total)
+	}
+	return nil
+}
+
+func (w *HistoryAnonymizer) anonymizeBatch(cutoff string) (int, error) {
+	var ids []int32
+
+	err := w.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("borrowing_transactions as bt").
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "bt"}, Options: "SKIP LOCKED"}).
+			Joins("JOIN privacy_settings ps on ps.borrower_id = bt.borrower_id").
+			Where("ps.reading_history = ? AND bt.returned_at IS NOT NULL AND bt.returned_at < ?", HistoryAnonymize, cutoff).
+			Order("bt.id").
+			Limit(w.BatchSize).
+			Pluck("bt.id", &ids).Error; err != nil {
+			return err
+		}
+
+		return DetachLoans(tx, ids)
+	})
+
+	return len(ids), err
+}
+
+// DetachLoans removes the borrower from loans and from the records that tie
+// them to the borrower: circulation events, outbox payloads and reminders.
+// The loans, their returns and fines are kept.
+func DetachLoans(tx *gorm.DB, ids []int32) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := tx.Table("borrowing_transactions").Where("id IN ?", ids).Update("borrower_id", nil).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Table("circulation_events").Where("transaction_id IN ?", ids).Update("borrower_id", nil).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Exec("UPDATE outbox_events SET payload = JSON_REMOVE(payload, '$.borrower_id') "+
+		"WHERE JSON_CONTAINS_PATH(payload, 'one', '$.borrower_id') AND JSON_UNQUOTE(JSON_EXTRACT(payload, '$.transaction_id')) IN ?", ids).Error; err != nil {
+		return err
+	}
+
+	// Reminders about the loans name the book, and there is nothing left to remind of
+	if err := tx.Exec("DELETE FROM notifications WHERE transaction_id IN ?", ids).Error; err != nil {
+		return err
+	}
+	return tx.Exec("DELETE FROM notification_logs WHERE transaction_id IN ?", ids).Error
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go-grpc/events"
+	"go-grpc/helpers"
+	"go-grpc/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OverdueWorker marks borrowed books past their due date as overdue
+type OverdueWorker struct {
+	DB     *gorm.DB
+	Events *events.CirculationFeed
+}
+
+func (w *OverdueWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.markOverdue(); err != nil {
+			log.Printf("overdue worker: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *OverdueWorker) markOverdue() error {
+	now := time.Now().Format(helpers.DateTimeLayout)
+	marked := 0
+
+	err := w.DB.Transaction(func(tx *gorm.DB) error {
+		var transactions []model.BorrowingTransaction
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND returned_at IS NULL AND due_date < ?", "borrowed", now).
+			Find(&transactions).Error; err != nil {
+			return err
+		}
+
+		for _, transaction := range transactions {
+			if err := tx.Model(&transaction).Update("status", "overdue").Error; err != nil {
+				return err
+			}
+
+			if err := events.Record(tx, model.CirculationEvent{
+				Type:          events.BookOverdue,
+				BookID:        transaction.BookID,
+				BorrowerID:    transaction.BorrowerID,
+				TransactionID: transaction.ID,
+				Message:       "was due " + transaction.DueDate,
+			}); err != nil {
+				return err
+			}
+		}
+
+		marked = len(transactions)
+		return nil
+	})
+
+	if err == nil && marked > 0 {
+		w.Events.Notify()
+	}
+
+	return err
+}
@@ -0,0 +1,253 @@
+// Package cql parses Contextual Query Language 1.2 queries, as sent by SRU
+// clients, into a tree of boolean nodes and search clauses.
+//
+//	dc.title any "fish frog" and (author = smith* or isbn = 9780306406157)
+package cql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Node is a *Boolean or a *Clause
+type Node interface {
+	String() string
+}
+
+// Boolean combines two nodes with and, or, not or prox
+type Boolean struct {
+	Operator  string // lower case
+	Modifiers []Modifier
+	Left      Node
+	Right     Node
+}
+
+// Clause is a search clause, a bare term gets the server choice index and
+// the = relation
+type Clause struct {
+	Index     string
+	Relation  string // symbols as written, names lower case
+	Modifiers []Modifier
+	Term      string
+}
+
+// Modifier is /name or /name comparitor value after a relation or boolean
+type Modifier struct {
+	Name       string
+	Comparitor string
+	Value      string
+}
+
+// ServerChoice is the index of a clause that is only a term
+const ServerChoice = "cql.serverChoice"
+
+func (b *Boolean) String() string {
+	return fmt.Sprintf("(%s %s%s %s)", b.Left, b.Operator, modifiers(b.Modifiers), b.Right)
+}
+
+func (c *Clause) String() string {
+	return fmt.Sprintf("%s %s%s %s", c.Index, c.Relation, modifiers(c.Modifiers), quote(c.Term))
+}
+
+func modifiers(list []Modifier) string {
+	var b strings.Builder
+	for _, m := range list {
+		b.WriteString("/" + m.Name)
+		if m.Comparitor != "" {
+			b.WriteString(m.Comparitor + quote(m.Value))
+		}
+	}
+	return b.String()
+}
+
+func quote(term string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(term) + `"`
+}
+
+// SyntaxError reports where a query could not be parsed
+type SyntaxError struct {
+	Offset  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Message, e.Offset)
+}
+
+// ErrSortBy is returned for a query with a sortBy clause
+var ErrSortBy = fmt.Errorf("sortBy is not supported")
+
+// Parse reads a query. Prefix assignments are rejected as syntax errors and
+// sortBy with ErrSortBy.
+func Parse(query string) (Node, error) {
+	p := &parser{lexer: lexer{input: query}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.token.kind == tokenEOF {
+		return nil, &SyntaxError{Offset: 0, Message: "empty query"}
+	}
+
+	node, err := p.query()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.token.kind == tokenWord && strings.EqualFold(p.token.text, "sortBy") {
+		return nil, ErrSortBy
+	}
+	if p.token.kind != tokenEOF {
+		return nil, p.errorf("unexpected %q", p.token.text)
+	}
+	return node, nil
+}
+
+type parser struct {
+	lexer
+	token token
+}
+
+func (p *parser) next() error {
+	t, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.token = t
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Offset: p.token.offset, Message: fmt.Sprintf(format, args...)}
+}
+
+// query is a list of search clauses joined by booleans, left associative
+func (p *parser) query() (Node, error) {
+	left, err := p.searchClause()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.token.kind == tokenWord && isBoolean(p.token.text) {
+		operator := strings.ToLower(p.token.text)
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		mods, err := p.modifiers()
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := p.searchClause()
+		if err != nil {
+			return nil, err
+		}
+		left = &Boolean{Operator: operator, Modifiers: mods, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) searchClause() (Node, error) {
+	switch p.token.kind {
+	case tokenOpen:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		node, err := p.query()
+		if err != nil {
+			return nil, err
+		}
+		if p.token.kind != tokenClose {
+			return nil, p.errorf("missing )")
+		}
+		return node, p.next()
+
+	case tokenSymbol:
+		if p.token.text == ">" {
+			return nil, p.errorf("prefix assignments are not supported")
+		}
+		return nil, p.errorf("unexpected %q", p.token.text)
+
+	case tokenWord, tokenString:
+	default:
+		return nil, p.errorf("missing search term")
+	}
+
+	first := p.token
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	// index relation term, a relation is a symbol or a name that is followed by a term
+	var relation string
+	switch {
+	case p.token.kind == tokenSymbol && p.token.text != "/":
+		relation = p.token.text
+	case p.token.kind == tokenWord && !isBoolean(p.token.text) && !strings.EqualFold(p.token.text, "sortBy"):
+		relation = strings.ToLower(p.token.text)
+	default:
+		if first.kind == tokenWord && isBoolean(first.text) {
+			return nil, &SyntaxError{Offset: first.offset, Message: fmt.Sprintf("missing search term before %q", first.text)}
+		}
+		return &Clause{Index: ServerChoice, Relation: "=", Term: first.text}, nil
+	}
+
+	if first.kind != tokenWord {
+		return nil, &SyntaxError{Offset: first.offset, Message: "an index must not be quoted"}
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	mods, err := p.modifiers()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.token.kind != tokenWord && p.token.kind != tokenString {
+		return nil, p.errorf("missing search term after %s", relation)
+	}
+	clause := &Clause{Index: first.text, Relation: relation, Modifiers: mods, Term: p.token.text}
+	return clause, p.next()
+}
+
+// modifiers reads /name[comparitor value] after a relation or boolean
+func (p *parser) modifiers() ([]Modifier, error) {
+	var list []Modifier
+	for p.token.kind == tokenSymbol && p.token.text == "/" {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.token.kind != tokenWord {
+			return nil, p.errorf("missing modifier name")
+		}
+		m := Modifier{Name: strings.ToLower(p.token.text)}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		if p.token.kind == tokenSymbol && p.token.text != "/" {
+			m.Comparitor = p.token.text
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if p.token.kind != tokenWord && p.token.kind != tokenString {
+				return nil, p.errorf("missing modifier value")
+			}
+			m.Value = p.token.text
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+		list = append(list, m)
+	}
+	return list, nil
+}
+
+func isBoolean(word string) bool {
+	switch strings.ToLower(word) {
+	case "and", "or", "not", "prox":
+		return true
+	}
+	return false
+}
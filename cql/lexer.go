@@ -0,0 +1,94 @@
+package cql
+
+import "strings"
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenOpen
+	tokenClose
+	tokenSymbol // relation symbols and the modifier slash
+	tokenWord
+	tokenString // a quoted term, without the quotes
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && strings.IndexByte(" \t\r\n", l.input[l.pos]) >= 0 {
+		l.pos++
+	}
+
+	start := l.pos
+	if l.pos == len(l.input) {
+		return token{kind: tokenEOF, offset: start}, nil
+	}
+
+	switch c := l.input[l.pos]; c {
+	case '(':
+		l.pos++
+		return token{kind: tokenOpen, text: "(", offset: start}, nil
+	case ')':
+		l.pos++
+		return token{kind: tokenClose, text: ")", offset: start}, nil
+	case '/':
+		l.pos++
+		return token{kind: tokenSymbol, text: "/", offset: start}, nil
+	case '=', '<', '>':
+		// =, ==, <, >, <=, >= and <>
+		l.pos++
+		if l.pos < len(l.input) {
+			pair := string(c) + string(l.input[l.pos])
+			if pair == "==" || pair == "<=" || pair == ">=" || pair == "<>" {
+				l.pos++
+				return token{kind: tokenSymbol, text: pair, offset: start}, nil
+			}
+		}
+		return token{kind: tokenSymbol, text: string(c), offset: start}, nil
+	case '"':
+		return l.quoted()
+	}
+
+	for l.pos < len(l.input) && strings.IndexByte(" \t\r\n()/=<>\"", l.input[l.pos]) < 0 {
+		l.pos++
+	}
+	return token{kind: tokenWord, text: l.input[start:l.pos], offset: start}, nil
+}
+
+// quoted reads a string, \" is a quote and other escapes are kept for the
+// masking characters * ? ^ and the backslash
+func (l *lexer) quoted() (token, error) {
+	start := l.pos
+	l.pos++
+
+	var b strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokenString, text: b.String(), offset: start}, nil
+		case c == '\\' && l.pos+1 < len(l.input):
+			if l.input[l.pos+1] != '"' {
+				b.WriteByte(c)
+			}
+			b.WriteByte(l.input[l.pos+1])
+			l.pos += 2
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return token{}, &SyntaxError{Offset: start, Message: "unterminated string"}
+}
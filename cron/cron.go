@@ -0,0 +1,259 @@
+// Package cron parses the five field cron expressions report schedules are
+// written in and finds the times they fire.
+//
+// Fields are minute, hour, day of month, month and day of week. Each takes
+// *, a number, a range a-b, a step */n or a-b/n, or a comma separated list of
+// those. Months and weekdays may be written as jan-dec and sun-sat, 7 is
+// Sunday as well as 0. When both the day of month and the day of week are
+// restricted a day matches either of them, like in crontab. @yearly,
+// @monthly, @weekly, @daily and @hourly are accepted as shorthands.
+//
+// Times are wall clock times. When the clocks go forward, a time in the
+// skipped hour does not fire that day. When they go back, a time in the
+// repeated hour fires once, on its first occurrence, unless the schedule
+// runs every hour.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	expr string
+
+	minute, hour, dom, month, dow uint64
+
+	// Day of month and day of week are restricted, a day matches either
+	domRestricted, dowRestricted bool
+}
+
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+var dayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+type field struct {
+	name     string
+	min, max int
+	names    []string // names of min, min+1, ...
+}
+
+var fields = []field{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, monthNames},
+	{"day of week", 0, 7, dayNames},
+}
+
+// Parse reads a cron expression
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if s, ok := shorthands[strings.ToLower(spec)]; ok {
+		spec = s
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields: minute hour day-of-month month day-of-week", expr)
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, f := range fields {
+		var err error
+		if bits[i], err = f.parse(parts[i]); err != nil {
+			return nil, fmt.Errorf("cron expression %q: %v", expr, err)
+		}
+	}
+
+	s := &Schedule{
+		expr:          expr,
+		minute:        bits[0],
+		hour:          bits[1],
+		dom:           bits[2],
+		month:         bits[3],
+		dow:           bits[4],
+		domRestricted: !strings.HasPrefix(parts[2], "*"),
+		dowRestricted: !strings.HasPrefix(parts[4], "*"),
+	}
+
+	// 7 is another name for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	return s, nil
+}
+
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+func (f field) parse(spec string) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(spec, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%s: bad step in %q", f.name, part)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			i := strings.Index(rng, "-")
+			var err error
+			if lo, err = f.value(rng[:i]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(rng[i+1:]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%s: range %q goes backwards", f.name, rng)
+			}
+		default:
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			// A single value with a step runs to the end, like 5/15
+			hi = lo
+			if step > 1 {
+				hi = f.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a number", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: %d is out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// The furthest Next and Prev look, enough for a schedule on the 29th of February
+const searchYears = 5
+
+// Next is the first time after t the schedule fires, in the location of t.
+// It is the zero time if the schedule never fires, e.g. on the 31st of February.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchYears, 0, 0)
+
+	for t.Before(limit) {
+		y, m, d := t.Date()
+		loc := t.Location()
+
+		switch {
+		case !has(s.month, int(m)):
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+		case !s.matchDay(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		case !has(s.hour, t.Hour()):
+			// Elapsed minutes, time.Date would jump past the first of a repeated hour
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case !has(s.minute, t.Minute()) || s.skip(t):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// Prev is the last time at or before t the schedule fired, the zero time if
+// there is none in the past five years
+func (s *Schedule) Prev(t time.Time) time.Time {
+	t = t.Truncate(time.Minute)
+	limit := t.AddDate(-searchYears, 0, 0)
+
+	for t.After(limit) {
+		y, m, d := t.Date()
+		loc := t.Location()
+
+		// Step back to the last minute before the period that does not match
+		switch {
+		case !has(s.month, int(m)):
+			t = time.Date(y, m, 1, 0, 0, 0, 0, loc).Add(-time.Minute)
+		case !s.matchDay(t):
+			t = time.Date(y, m, d, 0, 0, 0, 0, loc).Add(-time.Minute)
+		case !has(s.hour, t.Hour()):
+			t = t.Add(-time.Duration(t.Minute()+1) * time.Minute)
+		case !has(s.minute, t.Minute()) || s.skip(t):
+			t = t.Add(-time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	dom := has(s.dom, t.Day())
+	dow := has(s.dow, int(t.Weekday()))
+
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// skip tells whether t is the second occurrence of a wall clock time in the
+// hour repeated when the clocks go back, which only fires when the schedule
+// runs every hour
+func (s *Schedule) skip(t time.Time) bool {
+	if s.hour == 1<<24-1 {
+		return false
+	}
+
+	_, offset := t.Zone()
+	_, before := t.Add(-time.Hour).Zone()
+	if before <= offset {
+		return false
+	}
+
+	first := t.Add(-time.Duration(before-offset) * time.Second)
+	return first.Hour() == t.Hour() && first.Minute() == t.Minute()
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
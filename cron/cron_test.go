@@ -0,0 +1,161 @@
+package cron
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParse(t *testing.T) {
+	for _, expr := range []string{
+		"* * * * *",
+		"0 6 * * mon-fri",
+		"*/15 8-18 * * *",
+		"5/20 0 1,15 jan,jul *",
+		"0 0 * * 7",
+		"@monthly",
+		" @Daily ",
+	} {
+		if _, err := Parse(expr); err != nil {
+			t.Errorf("Parse(%q): %v", expr, err)
+		}
+	}
+
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@fortnightly",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) accepted a bad expression", expr)
+		}
+	}
+}
+
+func TestNextPrev(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		expr, from, next, prev string
+	}{
+		{"0 6 * * *", "2024-05-10 06:00", "2024-05-11 06:00", "2024-05-10 06:00"},
+		{"*/15 * * * *", "2024-05-10 10:07", "2024-05-10 10:15", "2024-05-10 10:00"},
+		{"30 8 * * mon-fri", "2024-05-10 09:00", "2024-05-13 08:30", "2024-05-10 08:30"},
+		{"0 0 1 * *", "2024-01-31 12:00", "2024-02-01 00:00", "2024-01-01 00:00"},
+		{"@yearly", "2024-06-01 00:00", "2025-01-01 00:00", "2024-01-01 00:00"},
+		{"0 0 * * 7", "2024-05-10 00:00", "2024-05-12 00:00", "2024-05-05 00:00"},
+		// Day of month or day of week when both are restricted
+		{"0 0 13 * fri", "2024-05-10 00:01", "2024-05-13 00:00", "2024-05-10 00:00"},
+		// The 29th of February is years apart
+		{"0 0 29 2 *", "2025-01-01 00:00", "2028-02-29 00:00", "2024-02-29 00:00"},
+		{"0 0 31 * *", "2024-04-01 00:00", "2024-05-31 00:00", "2024-03-31 00:00"},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		from := at(tt.from)
+		if got := s.Next(from); !got.Equal(at(tt.next)) {
+			t.Errorf("%q: Next(%s) = %s, want %s", tt.expr, tt.from, got, tt.next)
+		}
+		if got := s.Prev(from); !got.Equal(at(tt.prev)) {
+			t.Errorf("%q: Prev(%s) = %s, want %s", tt.expr, tt.from, got, tt.prev)
+		}
+	}
+}
+
+func TestNeverFires(t *testing.T) {
+	s, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if next := s.Next(now); !next.IsZero() {
+		t.Errorf("Next = %s, want the zero time", next)
+	}
+	if prev := s.Prev(now); !prev.IsZero() {
+		t.Errorf("Prev = %s, want the zero time", prev)
+	}
+}
+
+func TestDaylightSaving(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Clocks go from 02:00 to 03:00 on 2024-03-31 and from 03:00 back to 02:00 on 2024-10-27
+	spring := time.Date(2024, 3, 31, 0, 0, 0, 0, berlin)
+	autumn := time.Date(2024, 10, 27, 0, 0, 0, 0, berlin)
+	// The first 02:30 of the autumn day, time.Date picks the second one
+	firstHalfPastTwo := time.Date(2024, 10, 27, 0, 30, 0, 0, time.UTC).In(berlin)
+
+	tests := []struct {
+		name, expr string
+		from       time.Time
+		fires      []time.Time
+	}{
+		{
+			"skipped hour does not fire", "30 2 * * *", spring,
+			[]time.Time{time.Date(2024, 4, 1, 2, 30, 0, 0, berlin)},
+		},
+		{
+			"hour after the skipped one", "0 3 * * *", spring,
+			[]time.Time{time.Date(2024, 3, 31, 3, 0, 0, 0, berlin)},
+		},
+		{
+			"repeated hour fires once", "30 2 * * *", autumn,
+			[]time.Time{firstHalfPastTwo, time.Date(2024, 10, 28, 2, 30, 0, 0, berlin)},
+		},
+		{
+			"hourly fires in both", "30 * * * *", autumn.Add(2 * time.Hour),
+			[]time.Time{firstHalfPastTwo, firstHalfPastTwo.Add(time.Hour), firstHalfPastTwo.Add(2 * time.Hour)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			from := tt.from
+			for _, want := range tt.fires {
+				got := s.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("Next(%s) = %s, want %s", from, got, want)
+				}
+				from = got
+			}
+
+			// Prev walks back over the same firings
+			last := tt.fires[len(tt.fires)-1]
+			for i := len(tt.fires) - 2; i >= 0; i-- {
+				got := s.Prev(last.Add(-time.Minute))
+				if !got.Equal(tt.fires[i]) {
+					t.Fatalf("Prev(%s) = %s, want %s", last.Add(-time.Minute), got, tt.fires[i])
+				}
+				last = got
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package events
+
+import "sync"
+
+// Broker fans published values out to all current subscribers.
+// A subscriber that cannot keep up is dropped and its channel closed,
+// callers are expected to resume from the last value they received.
+type Broker[T any] struct {
+	mu     sync.Mutex
+	subs   map[chan T]struct{}
+	buffer int
+}
+
+func NewBroker[T any](buffer int) *Broker[T] {
+	return &Broker[T]{
+		subs:   map[chan T]struct{}{},
+		buffer: buffer,
+	}
+}
+
+// Subscribe returns a channel of published values and a function to stop the subscription
+func (b *Broker[T]) Subscribe() (<-chan T, func()) {
+	ch := make(chan T, b.buffer)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+
+	return ch, cancel
+}
+
+func (b *Broker[T]) Publish(v T) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- v:
+		default:
+			// Slow subscriber, drop it instead of blocking everyone else
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go-grpc/helpers"
+	"go-grpc/model"
+
+	"gorm.io/gorm"
+)
+
+// Circulation event types
+const (
+	BookBorrowed = "book_borrowed"
+	BookReturned = "book_returned"
+	BookRenewed  = "book_renewed"
+	BookOverdue  = "book_overdue"
+	StockChanged = "stock_changed"
+	HoldReady    = "hold_ready"
+)
+
+// Record stores a circulation event, call it with the transaction that makes the change
+func Record(tx *gorm.DB, event model.CirculationEvent) error {
+	event.CreatedAt = time.Now().Format(helpers.DateTimeLayout)
+	return tx.Create(&event).Error
+}
+
+// Ids are taken when an event is inserted, so a transaction that commits after
+// a later one fills in an id below the ones already published. Such gaps are
+// looked up again for gapTimeout, ids of rolled back transactions never show up.
+const (
+	gapTimeout = time.Minute
+	maxGap     = 1000 // missing ids remembered after a single event
+)
+
+// CirculationFeed publishes rows of circulation_events to subscribers as they
+// are committed. Writers call Notify after commit to skip the polling delay.
+// Events are published once each but not always in id order.
+type CirculationFeed struct {
+	DB     *gorm.DB
+	broker *Broker[model.CirculationEvent]
+	wake   chan struct{}
+	lastID int64
+	gaps   map[int64]time.Time // ids below lastID not seen yet, and when they were missed
+}
+
+func NewCirculationFeed(db *gorm.DB) *CirculationFeed {
+	return &CirculationFeed{
+		DB:     db,
+		broker: NewBroker[model.CirculationEvent](64),
+		wake:   make(chan struct{}, 1),
+		gaps:   map[int64]time.Time{},
+	}
+}
+
+// Notify wakes the feed up after new events have been committed
+func (f *CirculationFeed) Notify() {
+	if f == nil {
+		return
+	}
+
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (f *CirculationFeed) Subscribe() (<-chan model.CirculationEvent, func()) {
+	return f.broker.Subscribe()
+}
+
+// Since returns stored events after the given id, used to resume a stream
+func (f *CirculationFeed) Since(lastID int64, limit int) ([]model.CirculationEvent, error) {
+	var events []model.CirculationEvent
+	err := f.query().Where("id > ?", lastID).Order("id").Limit(limit).Scan(&events).Error
+	return events, err
+}
+
+func (f *CirculationFeed) query() *gorm.DB {
+	return f.DB.Table("circulation_events").
+		Select("id, type, COALESCE(book_id, 0) book_id, COALESCE(borrower_id, 0) borrower_id, COALESCE(transaction_id, 0) transaction_id, COALESCE(message, '') message, created_at")
+}
+
+// Run polls for committed events until ctx is cancelled
+func (f *CirculationFeed) Run(ctx context.Context, interval time.Duration) {
+	if err := f.DB.Table("circulation_events").Select("COALESCE(MAX(id), 0)").Row().Scan(&f.lastID); err != nil {
+		log.Printf("circulation feed: %v", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-f.wake:
+		}
+
+		if err := f.fillGaps(time.Now()); err != nil {
+			log.Printf("circulation feed: %v", err)
+		}
+
+		for {
+			events, err := f.Since(f.lastID, 500)
+			if err != nil {
+				log.Printf("circulation feed: %v", err)
+				break
+			}
+
+			for _, event := range events {
+				f.publishNext(event, time.Now())
+			}
+
+			if len(events) < 500 {
+				break
+			}
+		}
+	}
+}
+
+// publishNext publishes an event above lastID, the ids skipped on the way are remembered as gaps
+func (f *CirculationFeed) publishNext(event model.CirculationEvent, now time.Time) {
+	for id := max(f.lastID+1, event.ID-maxGap); id < event.ID; id++ {
+		f.gaps[id] = now
+	}
+	f.lastID = event.ID
+	f.broker.Publish(event)
+}
+
+// fillGaps publishes the events of gaps that were committed since, and gives
+// up on the gaps older than gapTimeout
+func (f *CirculationFeed) fillGaps(now time.Time) error {
+	if len(f.gaps) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(f.gaps))
+	for id := range f.gaps {
+		ids = append(ids, id)
+	}
+
+	var events []model.CirculationEvent
+	if err := f.query().Where("id IN ?", ids).Order("id").Scan(&events).Error; err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		delete(f.gaps, event.ID)
+		f.broker.Publish(event)
+	}
+
+	for id, missed := range f.gaps {
+		if now.Sub(missed) > gapTimeout {
+			delete(f.gaps, id)
+		}
+	}
+	return nil
+}
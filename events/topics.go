@@ -0,0 +1,69 @@
+package events
+
+import "sync"
+
+// Topics keeps a Broker per key, e.g. per borrower, so subscribers only
+// receive the values published for their key and a burst for many keys
+// does not fill the buffer of every subscriber
+type Topics[K comparable, T any] struct {
+	mu      sync.Mutex
+	brokers map[K]*Broker[T]
+	buffer  int
+}
+
+func NewTopics[K comparable, T any](buffer int) *Topics[K, T] {
+	return &Topics[K, T]{
+		brokers: map[K]*Broker[T]{},
+		buffer:  buffer,
+	}
+}
+
+// Subscribe returns a channel of the values published for the key and a function to stop the subscription
+func (t *Topics[K, T]) Subscribe(key K) (<-chan T, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	broker, ok := t.brokers[key]
+	if !ok {
+		broker = NewBroker[T](t.buffer)
+		t.brokers[key] = broker
+	}
+
+	ch, cancel := broker.Subscribe()
+
+	return ch, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		cancel()
+		t.prune(key, broker)
+	}
+}
+
+func (t *Topics[K, T]) Publish(key K, v T) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	broker, ok := t.brokers[key]
+	if !ok {
+		return
+	}
+
+	broker.Publish(v)
+	t.prune(key, broker)
+}
+
+// prune forgets the broker of a key once it has no subscribers left, call it with t.mu held
+func (t *Topics[K, T]) prune(key K, broker *Broker[T]) {
+	broker.mu.Lock()
+	empty := len(broker.subs) == 0
+	broker.mu.Unlock()
+
+	if empty && t.brokers[key] == broker {
+		delete(t.brokers, key)
+	}
+}
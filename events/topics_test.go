@@ -0,0 +1,74 @@
+package events
+
+import "testing"
+
+func TestTopicsOnlyDeliverOwnKey(t *testing.T) {
+	topics := NewTopics[int32, string](4)
+
+	mine, cancel := topics.Subscribe(1)
+	defer cancel()
+
+	// More values for other keys than the buffer holds, as in a broadcast to every borrower
+	for key := int32(2); key < 200; key++ {
+		topics.Publish(key, "announcement")
+	}
+	topics.Publish(1, "announcement")
+
+	select {
+	case v, ok := <-mine:
+		if !ok {
+			t.Fatal("subscription was closed by values published for other keys")
+		}
+		if v != "announcement" {
+			t.Fatalf("got %q", v)
+		}
+	default:
+		t.Fatal("value published for the key was not delivered")
+	}
+
+	select {
+	case v := <-mine:
+		t.Fatalf("unexpected second value %q", v)
+	default:
+	}
+}
+
+func TestTopicsPruneOnCancel(t *testing.T) {
+	topics := NewTopics[int32, string](1)
+
+	_, cancel1 := topics.Subscribe(7)
+	ch2, cancel2 := topics.Subscribe(7)
+
+	cancel1()
+	if len(topics.brokers) != 1 {
+		t.Fatal("broker removed while a subscriber is left")
+	}
+
+	topics.Publish(7, "x")
+	if v := <-ch2; v != "x" {
+		t.Fatalf("got %q", v)
+	}
+
+	cancel2()
+	if len(topics.brokers) != 0 {
+		t.Fatal("broker kept after the last subscriber left")
+	}
+}
+
+func TestTopicsDropSlowSubscriber(t *testing.T) {
+	topics := NewTopics[int32, int](1)
+
+	ch, cancel := topics.Subscribe(3)
+	defer cancel()
+
+	topics.Publish(3, 1)
+	topics.Publish(3, 2)
+
+	<-ch
+	if _, ok := <-ch; ok {
+		t.Fatal("slow subscriber was not dropped")
+	}
+	if len(topics.brokers) != 0 {
+		t.Fatal("broker of a dropped subscriber was kept")
+	}
+}
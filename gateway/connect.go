@@ -0,0 +1,236 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Connect envelope flag of the last message of a stream
+const connectEndStream = 0x02
+
+// Connect error codes, see https://connectrpc.com/docs/protocol#error-codes
+var connectCodes = map[codes.Code]string{
+	codes.Canceled:           "canceled",
+	codes.Unknown:            "unknown",
+	codes.InvalidArgument:    "invalid_argument",
+	codes.DeadlineExceeded:   "deadline_exceeded",
+	codes.NotFound:           "not_found",
+	codes.AlreadyExists:      "already_exists",
+	codes.PermissionDenied:   "permission_denied",
+	codes.ResourceExhausted:  "resource_exhausted",
+	codes.FailedPrecondition: "failed_precondition",
+	codes.Aborted:            "aborted",
+	codes.OutOfRange:         "out_of_range",
+	codes.Unimplemented:      "unimplemented",
+	codes.Internal:           "internal",
+	codes.Unavailable:        "unavailable",
+	codes.DataLoss:           "data_loss",
+	codes.Unauthenticated:    "unauthenticated",
+}
+
+// messageCodec encodes messages as binary protobuf or JSON
+type messageCodec struct {
+	json bool
+}
+
+func codecFor(json bool) messageCodec {
+	return messageCodec{json: json}
+}
+
+func (c messageCodec) marshal(msg proto.Message) ([]byte, error) {
+	if c.json {
+		return marshaler.Marshal(msg)
+	}
+	return proto.Marshal(msg)
+}
+
+func (c messageCodec) unmarshal(raw []byte, msg proto.Message) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if c.json {
+		return unmarshaler.Unmarshal(raw, msg)
+	}
+	return proto.Unmarshal(raw, msg)
+}
+
+// isConnect tells Connect requests apart by their content type
+func isConnect(contentType string) bool {
+	contentType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	switch contentType {
+	case "application/proto", "application/json", "application/connect+proto", "application/connect+json":
+		return true
+	}
+	return false
+}
+
+// connectCode names a gRPC code for Connect, including the HTTP codes JWTMiddleware returns
+func connectCode(code codes.Code) string {
+	if name, ok := connectCodes[code]; ok {
+		return name
+	}
+
+	switch HTTPStatus(code) {
+	case http.StatusUnauthorized:
+		return "unauthenticated"
+	case http.StatusForbidden:
+		return "permission_denied"
+	case http.StatusNotFound:
+		return "not_found"
+	}
+	return "unknown"
+}
+
+func connectError(err error) map[string]interface{} {
+	s := status.Convert(err)
+	return map[string]interface{}{"code": connectCode(s.Code()), "message": s.Message()}
+}
+
+// serveConnect answers a Connect unary or server streaming call to the RPC md
+func (g *Gateway) serveConnect(w http.ResponseWriter, r *http.Request, md protoreflect.MethodDescriptor) {
+	contentType := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0])
+	streaming := strings.HasPrefix(contentType, "application/connect+")
+	codec := codecFor(strings.HasSuffix(contentType, "json"))
+
+	if version := r.Header.Get("Connect-Protocol-Version"); version != "" && version != "1" {
+		writeConnectError(w, status.Errorf(codes.InvalidArgument, "unsupported connect protocol version %q", version))
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeConnectError(w, status.Error(codes.Unimplemented, "only POST is supported"))
+		return
+	}
+
+	encoding := r.Header.Get("Content-Encoding")
+	if streaming {
+		encoding = r.Header.Get("Connect-Content-Encoding")
+	}
+	if encoding != "" && encoding != "identity" {
+		writeConnectError(w, status.Errorf(codes.Unimplemented, "unsupported compression %q", encoding))
+		return
+	}
+
+	ctx, cancel := connectTimeout(outgoingContext(r), r.Header.Get("Connect-Timeout-Ms"))
+	defer cancel()
+
+	if md.IsStreamingClient() && md.IsStreamingServer() {
+		writeConnectError(w, status.Error(codes.Unimplemented, "bidirectional streaming is not supported"))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
+	if err != nil {
+		writeConnectError(w, status.Errorf(codes.InvalidArgument, "failed to read body: %v", err))
+		return
+	}
+
+	if streaming != (md.IsStreamingClient() || md.IsStreamingServer()) {
+		writeConnectError(w, status.Errorf(codes.InvalidArgument, "content type %v does not match the RPC", contentType))
+		return
+	}
+
+	if !streaming {
+		req := newMessage(md.Input())
+		if err := codec.unmarshal(body, req); err != nil {
+			writeConnectError(w, status.Errorf(codes.InvalidArgument, "invalid request message: %v", err))
+			return
+		}
+
+		resp, err := g.invoke(ctx, md, req)
+		if err != nil {
+			writeConnectError(w, err)
+			return
+		}
+
+		raw, err := codec.marshal(resp)
+		if err != nil {
+			writeConnectError(w, status.Error(codes.Internal, err.Error()))
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		w.Write(raw)
+		return
+	}
+
+	// Streams always answer 200, errors travel in the end-of-stream envelope
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+
+	send := func(msg proto.Message) error {
+		raw, err := codec.marshal(msg)
+		if err != nil {
+			return err
+		}
+		if err := writeFrame(w, frameData, raw); err != nil {
+			return err
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		return nil
+	}
+
+	err = func() error {
+		frames, err := readFrames(body)
+		if err != nil {
+			return err
+		}
+
+		reqs := make([]proto.Message, 0, len(frames))
+		for _, payload := range frames {
+			req := newMessage(md.Input())
+			if err := codec.unmarshal(payload, req); err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid request message: %v", err)
+			}
+			reqs = append(reqs, req)
+		}
+
+		// The whole request body is read first, client streams are sent in one go
+		if md.IsStreamingClient() {
+			resp, err := g.clientStream(ctx, md, reqs)
+			if err != nil {
+				return err
+			}
+			return send(resp)
+		}
+
+		if len(reqs) != 1 {
+			return status.Errorf(codes.InvalidArgument, "expected one request message, got %d", len(reqs))
+		}
+		return g.serverStream(ctx, md, reqs[0], send)
+	}()
+
+	end := map[string]interface{}{}
+	if err != nil {
+		end["error"] = connectError(err)
+	}
+	raw, _ := json.Marshal(end)
+	writeFrame(w, connectEndStream, raw)
+}
+
+func writeConnectError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(HTTPStatus(status.Code(err)))
+	json.NewEncoder(w).Encode(connectError(err))
+}
+
+func connectTimeout(ctx context.Context, value string) (context.Context, context.CancelFunc) {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(ms)*time.Millisecond)
+}
@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// HTTP statuses of the standard gRPC codes, following google.rpc.Code
+var httpStatuses = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// HTTPStatus maps a gRPC code to an HTTP status. JWTMiddleware reports
+// failures with HTTP codes (401) directly, those are passed through as is.
+func HTTPStatus(code codes.Code) int {
+	if s, ok := httpStatuses[code]; ok {
+		return s
+	}
+	if code >= 100 && code <= 599 {
+		return int(code)
+	}
+	return http.StatusInternalServerError
+}
+
+func errorBody(s *status.Status) map[string]interface{} {
+	name := s.Code().String()
+	if _, ok := httpStatuses[s.Code()]; !ok {
+		name = http.StatusText(HTTPStatus(s.Code()))
+	}
+
+	return map[string]interface{}{
+		"code":    HTTPStatus(s.Code()),
+		"status":  name,
+		"message": s.Message(),
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	s := status.Convert(err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(HTTPStatus(s.Code()))
+	json.NewEncoder(w).Encode(map[string]interface{}{"error": errorBody(s)})
+}
@@ -0,0 +1,127 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// setPathFields copies the path wildcards of the matched route into the request
+func setPathFields(msg proto.Message, r *http.Request, desc protoreflect.MessageDescriptor) error {
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		name := string(fields.Get(i).Name())
+		if value := r.PathValue(name); value != "" {
+			if err := setField(msg.ProtoReflect(), name, []string{value}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setQueryFields fills request fields from the query string, nested fields use dots (?book.id=1)
+func setQueryFields(msg proto.Message, r *http.Request) error {
+	for key, values := range r.URL.Query() {
+		if err := setField(msg.ProtoReflect(), key, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setField(m protoreflect.Message, path string, values []string) error {
+	parts := strings.Split(path, ".")
+
+	for i, part := range parts {
+		fd := findField(m.Descriptor(), part)
+		if fd == nil {
+			return status.Errorf(codes.InvalidArgument, "unknown field %q", path)
+		}
+
+		if i < len(parts)-1 {
+			if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+				return status.Errorf(codes.InvalidArgument, "field %q has no sub fields", part)
+			}
+			m = m.Mutable(fd).Message()
+			continue
+		}
+
+		if fd.IsMap() || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+			return status.Errorf(codes.InvalidArgument, "field %q cannot be set from a string", path)
+		}
+
+		if fd.IsList() {
+			list := m.Mutable(fd).List()
+			for _, value := range values {
+				v, err := parseScalar(fd, value)
+				if err != nil {
+					return status.Errorf(codes.InvalidArgument, "invalid value for %q: %v", path, err)
+				}
+				list.Append(v)
+			}
+			return nil
+		}
+
+		v, err := parseScalar(fd, values[len(values)-1])
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid value for %q: %v", path, err)
+		}
+		m.Set(fd, v)
+	}
+
+	return nil
+}
+
+// findField accepts both the proto name (book_id) and the JSON name (bookId)
+func findField(desc protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if fd := desc.Fields().ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return desc.Fields().ByJSONName(name)
+}
+
+func parseScalar(fd protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(value)
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(value)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		v, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	}
+
+	return protoreflect.Value{}, status.Errorf(codes.InvalidArgument, "unsupported field kind %v", fd.Kind())
+}
@@ -0,0 +1,381 @@
+// Package gateway serves the gRPC services of library.proto as REST/JSON.
+// Requests are translated in-process and forwarded over a gRPC client
+// connection, so they go through the same interceptors as native clients.
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	libraryPb "go-grpc/pb/library"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var marshaler = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+var unmarshaler = protojson.UnmarshalOptions{}
+
+type Gateway struct {
+	conn grpc.ClientConnInterface
+	mux  *http.ServeMux
+}
+
+// New builds the gateway for Routes plus the generic /rpc endpoint, calls are sent over conn.
+func New(conn grpc.ClientConnInterface) (*Gateway, error) {
+	g := &Gateway{conn: conn, mux: http.NewServeMux()}
+
+	for _, route := range Routes {
+		md, err := findMethod(route.Service, route.RPC)
+		if err != nil {
+			return nil, fmt.Errorf("gateway route %v %v: %w", route.Method, route.Path, err)
+		}
+		g.mux.HandleFunc(route.Method+" "+route.Path, g.restHandler(md))
+	}
+
+	g.mux.HandleFunc("POST /rpc/{service}/{method}", g.rpcHandler)
+	g.mux.HandleFunc("GET /openapi.json", serveOpenAPI)
+
+	return g, nil
+}
+
+// Handle mounts a plain HTTP endpoint, such as a harvesting protocol, next to the REST routes
+func (g *Gateway) Handle(pattern string, handler http.Handler) {
+	g.mux.Handle(pattern, handler)
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// findMethod looks up an RPC of library.proto by its short service name
+func findMethod(service, method string) (protoreflect.MethodDescriptor, error) {
+	service = strings.TrimPrefix(service, string(libraryPb.File_library_proto.Package())+".")
+
+	sd := libraryPb.File_library_proto.Services().ByName(protoreflect.Name(service))
+	if sd == nil {
+		return nil, fmt.Errorf("unknown service %q", service)
+	}
+
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("unknown method %q on %v", method, service)
+	}
+
+	return md, nil
+}
+
+func fullMethodName(md protoreflect.MethodDescriptor) string {
+	return "/" + string(md.Parent().FullName()) + "/" + string(md.Name())
+}
+
+func (g *Gateway) restHandler(md protoreflect.MethodDescriptor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if md.IsStreamingClient() {
+			g.callClientStream(w, r, md)
+			return
+		}
+
+		req := newMessage(md.Input())
+
+		if r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodPatch {
+			if err := decodeBody(r, req); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+
+		if err := setQueryFields(req, r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := setPathFields(req, r, md.Input()); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		g.call(w, r, md, req)
+	}
+}
+
+// POST /rpc/{service}/{method}
+func (g *Gateway) rpcHandler(w http.ResponseWriter, r *http.Request) {
+	md, err := findMethod(r.PathValue("service"), r.PathValue("method"))
+	if err != nil {
+		writeError(w, status.Error(codes.NotFound, err.Error()))
+		return
+	}
+
+	if md.IsStreamingClient() {
+		g.callClientStream(w, r, md)
+		return
+	}
+
+	req := newMessage(md.Input())
+	if err := decodeBody(r, req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	g.call(w, r, md, req)
+}
+
+func (g *Gateway) call(w http.ResponseWriter, r *http.Request, md protoreflect.MethodDescriptor, req proto.Message) {
+	ctx := outgoingContext(r)
+
+	if md.IsStreamingServer() {
+		g.stream(ctx, w, md, req)
+		return
+	}
+
+	resp, err := g.invoke(ctx, md, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeMessage(w, http.StatusOK, resp)
+}
+
+// callClientStream sends every message of the body, a JSON array or one JSON object per line
+func (g *Gateway) callClientStream(w http.ResponseWriter, r *http.Request, md protoreflect.MethodDescriptor) {
+	if md.IsStreamingServer() {
+		writeError(w, status.Error(codes.Unimplemented, "bidirectional streaming is not supported over REST"))
+		return
+	}
+
+	reqs, err := decodeMessages(r, md.Input())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resp, err := g.clientStream(outgoingContext(r), md, reqs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeMessage(w, http.StatusOK, resp)
+}
+
+// stream writes every message of a server stream as one JSON line
+func (g *Gateway) stream(ctx context.Context, w http.ResponseWriter, md protoreflect.MethodDescriptor, req proto.Message) {
+	flusher, _ := w.(http.Flusher)
+	started := false
+
+	err := g.serverStream(ctx, md, req, func(msg proto.Message) error {
+		raw, err := marshaler.Marshal(msg)
+		if err != nil {
+			return err
+		}
+
+		// Errors before the first message still get a proper HTTP status
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+
+		if _, err := fmt.Fprintf(w, "{\"result\":%s}\n", raw); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+
+	switch {
+	case !started && err != nil:
+		writeError(w, err)
+	case !started:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	case err != nil && ctx.Err() == nil:
+		body, _ := json.Marshal(map[string]interface{}{"error": errorBody(status.Convert(err))})
+		w.Write(append(body, '\n'))
+	}
+}
+
+// invoke runs a unary RPC over the client connection
+func (g *Gateway) invoke(ctx context.Context, md protoreflect.MethodDescriptor, req proto.Message) (proto.Message, error) {
+	resp := newMessage(md.Output())
+	if err := g.conn.Invoke(ctx, fullMethodName(md), req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// clientStream runs a client streaming RPC with all request messages at once
+func (g *Gateway) clientStream(ctx context.Context, md protoreflect.MethodDescriptor, reqs []proto.Message) (proto.Message, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	desc := &grpc.StreamDesc{StreamName: string(md.Name()), ClientStreams: true}
+
+	stream, err := g.conn.NewStream(ctx, desc, fullMethodName(md))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, req := range reqs {
+		// io.EOF means the server already ended the call, RecvMsg returns its status
+		if err := stream.SendMsg(req); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	resp := newMessage(md.Output())
+	if err := stream.RecvMsg(resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// serverStream runs a server streaming RPC and hands every response to send
+func (g *Gateway) serverStream(ctx context.Context, md protoreflect.MethodDescriptor, req proto.Message, send func(proto.Message) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	desc := &grpc.StreamDesc{StreamName: string(md.Name()), ServerStreams: true}
+
+	stream, err := g.conn.NewStream(ctx, desc, fullMethodName(md))
+	if err != nil {
+		return err
+	}
+	// io.EOF means the server already ended the call, RecvMsg returns its status
+	if err := stream.SendMsg(req); err != nil && err != io.EOF {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		msg := newMessage(md.Output())
+		if err := stream.RecvMsg(msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := send(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// outgoingContext forwards the Authorization header and the client address as gRPC metadata
+func outgoingContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		md.Set("authorization", auth)
+	}
+
+	forwarded := r.Header.Get("X-Forwarded-For")
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		if forwarded != "" {
+			forwarded += ", " + host
+		} else {
+			forwarded = host
+		}
+	}
+	if forwarded != "" {
+		md.Set("x-forwarded-for", forwarded)
+	}
+
+	return metadata.NewOutgoingContext(r.Context(), md)
+}
+
+// newMessage returns the generated Go type of a message, falling back to a dynamic one
+func newMessage(desc protoreflect.MessageDescriptor) proto.Message {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(desc.FullName())
+	if err != nil {
+		return dynamicpb.NewMessage(desc)
+	}
+	return mt.New().Interface()
+}
+
+func decodeBody(r *http.Request, msg proto.Message) error {
+	raw, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to read body: %v", err)
+	}
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		return nil
+	}
+
+	if err := unmarshaler.Unmarshal(raw, msg); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+	}
+
+	return nil
+}
+
+// decodeMessages reads the messages of a client stream from a JSON array or JSON lines body
+func decodeMessages(r *http.Request, desc protoreflect.MessageDescriptor) ([]proto.Message, error) {
+	raw, err := io.ReadAll(io.LimitReader(r.Body, 50<<20))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to read body: %v", err)
+	}
+
+	var items []json.RawMessage
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+		}
+	} else {
+		for _, line := range bytes.Split(raw, []byte("\n")) {
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				items = append(items, line)
+			}
+		}
+	}
+
+	msgs := make([]proto.Message, 0, len(items))
+	for i, item := range items {
+		msg := newMessage(desc)
+		if err := unmarshaler.Unmarshal(item, msg); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid message %d: %v", i+1, err)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, nil
+}
+
+func writeMessage(w http.ResponseWriter, code int, msg proto.Message) {
+	raw, err := marshaler.Marshal(msg)
+	if err != nil {
+		writeError(w, status.Error(codes.Internal, err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(raw)
+}
@@ -0,0 +1,205 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// gRPC-Web frame flags
+const (
+	frameData    = 0x00
+	frameTrailer = 0x80
+)
+
+func isGRPCWeb(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/grpc-web")
+}
+
+// serveGRPCWeb answers a gRPC-Web call (binary or text) to the RPC md
+func (g *Gateway) serveGRPCWeb(w http.ResponseWriter, r *http.Request, md protoreflect.MethodDescriptor) {
+	contentType := r.Header.Get("Content-Type")
+	text := strings.HasPrefix(contentType, "application/grpc-web-text")
+	codec := codecFor(strings.HasSuffix(contentType, "+json"))
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "gRPC-Web requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := grpcTimeout(outgoingContext(r), r.Header.Get("Grpc-Timeout"))
+	defer cancel()
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+
+	out := io.Writer(w)
+	if text {
+		encoder := base64.NewEncoder(base64.StdEncoding, w)
+		defer encoder.Close()
+		out = encoder
+	}
+
+	err := g.grpcWebCall(ctx, r, md, codec, text, func(msg proto.Message) error {
+		raw, err := codec.marshal(msg)
+		if err != nil {
+			return err
+		}
+		if err := writeFrame(out, frameData, raw); err != nil {
+			return err
+		}
+		if f, ok := w.(http.Flusher); ok && !text {
+			f.Flush()
+		}
+		return nil
+	})
+
+	s := status.Convert(err)
+	trailer := fmt.Sprintf("grpc-status: %d\r\ngrpc-message: %s\r\n", s.Code(), url.PathEscape(s.Message()))
+	writeFrame(out, frameTrailer, []byte(trailer))
+}
+
+func (g *Gateway) grpcWebCall(ctx context.Context, r *http.Request, md protoreflect.MethodDescriptor, codec messageCodec, text bool, send func(proto.Message) error) error {
+	if md.IsStreamingClient() && md.IsStreamingServer() {
+		return status.Error(codes.Unimplemented, "bidirectional streaming is not supported over gRPC-Web")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to read body: %v", err)
+	}
+	if text {
+		if body, err = decodeBase64Chunks(body); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid base64 body: %v", err)
+		}
+	}
+
+	frames, err := readFrames(body)
+	if err != nil {
+		return err
+	}
+
+	reqs := make([]proto.Message, 0, len(frames))
+	for _, payload := range frames {
+		req := newMessage(md.Input())
+		if err := codec.unmarshal(payload, req); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid request message: %v", err)
+		}
+		reqs = append(reqs, req)
+	}
+
+	// Browsers cannot stream request bodies, a client stream arrives as one body of frames
+	if md.IsStreamingClient() {
+		resp, err := g.clientStream(ctx, md, reqs)
+		if err != nil {
+			return err
+		}
+		return send(resp)
+	}
+
+	req := newMessage(md.Input())
+	if len(reqs) > 0 {
+		req = reqs[0]
+	}
+
+	if md.IsStreamingServer() {
+		return g.serverStream(ctx, md, req, send)
+	}
+
+	resp, err := g.invoke(ctx, md, req)
+	if err != nil {
+		return err
+	}
+	return send(resp)
+}
+
+// readFrames splits a body into the payloads of its length-prefixed frames
+func readFrames(body []byte) ([][]byte, error) {
+	var frames [][]byte
+	for len(body) > 0 {
+		if len(body) < 5 {
+			return nil, status.Error(codes.InvalidArgument, "truncated frame header")
+		}
+		if body[0]&0x01 != 0 {
+			return nil, status.Error(codes.Unimplemented, "compressed messages are not supported")
+		}
+
+		size := binary.BigEndian.Uint32(body[1:5])
+		if uint32(len(body)-5) < size {
+			return nil, status.Error(codes.InvalidArgument, "truncated frame")
+		}
+
+		frames = append(frames, body[5:5+size])
+		body = body[5+size:]
+	}
+	return frames, nil
+}
+
+func writeFrame(w io.Writer, flag byte, payload []byte) error {
+	header := make([]byte, 5)
+	header[0] = flag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// decodeBase64Chunks decodes grpc-web-text bodies, which may be several padded base64 strings in a row
+func decodeBase64Chunks(body []byte) ([]byte, error) {
+	body = bytes.Join(bytes.Fields(body), nil)
+
+	var out []byte
+	for len(body) > 0 {
+		n := len(body)
+		for i := 0; i+4 <= len(body); i += 4 {
+			if bytes.IndexByte(body[i:i+4], '=') >= 0 {
+				n = i + 4
+				break
+			}
+		}
+
+		chunk, err := base64.StdEncoding.DecodeString(string(body[:n]))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+		body = body[n:]
+	}
+
+	return out, nil
+}
+
+// grpcTimeout applies a grpc-timeout header such as "5S" or "250m"
+func grpcTimeout(ctx context.Context, value string) (context.Context, context.CancelFunc) {
+	if len(value) < 2 {
+		return context.WithCancel(ctx)
+	}
+
+	units := map[byte]time.Duration{
+		'H': time.Hour, 'M': time.Minute, 'S': time.Second,
+		'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond,
+	}
+
+	unit, ok := units[value[len(value)-1]]
+	var amount int64
+	if _, err := fmt.Sscan(value[:len(value)-1], &amount); err != nil || !ok {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, time.Duration(amount)*unit)
+}
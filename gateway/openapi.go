@@ -0,0 +1,249 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	libraryPb "go-grpc/pb/library"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var pathParam = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+var (
+	openAPIOnce sync.Once
+	openAPIJSON []byte
+)
+
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	openAPIOnce.Do(func() {
+		openAPIJSON, _ = json.MarshalIndent(OpenAPI(), "", "  ")
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIJSON)
+}
+
+// OpenAPI builds an OpenAPI 3.0 document for Routes and the /rpc endpoints from the proto descriptors
+func OpenAPI() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	paths := map[string]map[string]interface{}{}
+
+	addOperation := func(operationID, method, path string, md protoreflect.MethodDescriptor, params []string) {
+		op := map[string]interface{}{
+			"operationId": operationID,
+			"tags":        []string{string(md.Parent().Name())},
+			"responses":   operationResponses(md),
+		}
+
+		if md.Parent().Name() != "AuthService" {
+			op["security"] = []map[string][]string{{"bearerAuth": {}}}
+		}
+
+		var parameters []map[string]interface{}
+		inPath := map[string]bool{}
+		for _, name := range params {
+			inPath[name] = true
+			fd := md.Input().Fields().ByName(protoreflect.Name(name))
+			parameters = append(parameters, map[string]interface{}{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   fieldSchema(fd),
+			})
+		}
+
+		if method == "GET" || method == "DELETE" {
+			fields := md.Input().Fields()
+			for i := 0; i < fields.Len(); i++ {
+				fd := fields.Get(i)
+				if inPath[string(fd.Name())] || fd.Kind() == protoreflect.MessageKind {
+					continue
+				}
+				parameters = append(parameters, map[string]interface{}{
+					"name":   string(fd.Name()),
+					"in":     "query",
+					"schema": fieldSchema(fd),
+				})
+			}
+		} else {
+			content := map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schemaRef(md.Input())},
+			}
+			if md.IsStreamingClient() {
+				content = map[string]interface{}{
+					"application/json":     map[string]interface{}{"schema": map[string]interface{}{"type": "array", "items": schemaRef(md.Input())}},
+					"application/x-ndjson": map[string]interface{}{"schema": schemaRef(md.Input())},
+				}
+			}
+			op["requestBody"] = map[string]interface{}{"content": content}
+		}
+
+		if len(parameters) > 0 {
+			op["parameters"] = parameters
+		}
+
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(method)] = op
+
+		collectSchemas(md.Input(), schemas)
+		collectSchemas(md.Output(), schemas)
+	}
+
+	for _, route := range Routes {
+		md, err := findMethod(route.Service, route.RPC)
+		if err != nil {
+			continue
+		}
+
+		var params []string
+		for _, m := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+			params = append(params, m[1])
+		}
+
+		addOperation(route.Service+"_"+route.RPC, route.Method, route.Path, md, params)
+	}
+
+	services := libraryPb.File_library_proto.Services()
+	for i := 0; i < services.Len(); i++ {
+		methods := services.Get(i).Methods()
+		for j := 0; j < methods.Len(); j++ {
+			md := methods.Get(j)
+			service, method := string(md.Parent().Name()), string(md.Name())
+			addOperation("rpc_"+service+"_"+method, "POST", "/rpc/"+service+"/"+method, md, nil)
+		}
+	}
+
+	schemas["Error"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"error": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"code":    map[string]interface{}{"type": "integer"},
+					"status":  map[string]interface{}{"type": "string"},
+					"message": map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Library Management API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+}
+
+func operationResponses(md protoreflect.MethodDescriptor) map[string]interface{} {
+	content := map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": schemaRef(md.Output())},
+	}
+
+	if md.IsStreamingServer() {
+		content = map[string]interface{}{
+			"application/x-ndjson": map[string]interface{}{
+				"schema": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"result": schemaRef(md.Output())},
+				},
+			},
+		}
+	}
+
+	errorContent := map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"}},
+	}
+
+	return map[string]interface{}{
+		"200":     map[string]interface{}{"description": "OK", "content": content},
+		"default": map[string]interface{}{"description": "Error", "content": errorContent},
+	}
+}
+
+func schemaName(desc protoreflect.MessageDescriptor) string {
+	return strings.ReplaceAll(string(desc.FullName()), ".", "_")
+}
+
+func schemaRef(desc protoreflect.MessageDescriptor) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + schemaName(desc)}
+}
+
+// collectSchemas adds the schema of desc and every message it references
+func collectSchemas(desc protoreflect.MessageDescriptor, schemas map[string]interface{}) {
+	name := schemaName(desc)
+	if _, ok := schemas[name]; ok {
+		return
+	}
+
+	properties := map[string]interface{}{}
+	schemas[name] = map[string]interface{}{"type": "object", "properties": properties}
+
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		properties[string(fd.Name())] = fieldSchema(fd)
+
+		if fd.Kind() == protoreflect.MessageKind {
+			collectSchemas(fd.Message(), schemas)
+		}
+	}
+}
+
+func fieldSchema(fd protoreflect.FieldDescriptor) map[string]interface{} {
+	var schema map[string]interface{}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		schema = map[string]interface{}{"type": "string"}
+	case protoreflect.BoolKind:
+		schema = map[string]interface{}{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		schema = map[string]interface{}{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		schema = map[string]interface{}{"type": "integer", "format": "int64", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		// protojson writes 64 bit integers as strings, but reads both
+		schema = map[string]interface{}{"type": "string", "format": "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		schema = map[string]interface{}{"type": "string", "format": "uint64"}
+	case protoreflect.FloatKind:
+		schema = map[string]interface{}{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		schema = map[string]interface{}{"type": "number", "format": "double"}
+	case protoreflect.BytesKind:
+		schema = map[string]interface{}{"type": "string", "format": "byte"}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		enum := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			enum = append(enum, string(values.Get(i).Name()))
+		}
+		schema = map[string]interface{}{"type": "string", "enum": enum}
+	case protoreflect.MessageKind:
+		schema = schemaRef(fd.Message())
+	default:
+		schema = map[string]interface{}{}
+	}
+
+	if fd.IsList() {
+		return map[string]interface{}{"type": "array", "items": schema}
+	}
+
+	return schema
+}
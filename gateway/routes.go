@@ -0,0 +1,121 @@
+package gateway
+
+// Route maps an HTTP method and path onto an RPC. Path wildcards are named
+// after the request field they fill, e.g. {id} or {book_id}.
+type Route struct {
+	Method  string
+	Path    string
+	Service string // service name in library.proto, e.g. "BookService"
+	RPC     string
+}
+
+// Routes is the REST surface of the library API. Every RPC is also reachable
+// with POST /rpc/{service}/{method} and the request message as JSON body.
+var Routes = []Route{
+	{"POST", "/v1/auth/login", "AuthService", "Login"},
+	{"POST", "/v1/auth/borrowers", "AuthService", "RegisterBorrower"},
+	{"POST", "/v1/auth/admins", "AuthService", "RegisterAdmin"},
+
+	{"GET", "/v1/books", "BookService", "ListBooks"},
+	{"POST", "/v1/books", "BookService", "CreateBook"},
+	{"POST", "/v1/books/import", "BookService", "ImportBooks"},
+	{"POST", "/v1/books/import/marc", "BookService", "ImportMarc"},
+	{"GET", "/v1/books/export/marc", "BookService", "ExportMarc"},
+	{"GET", "/v1/books/export/citations", "BookService", "ExportCitations"},
+	{"GET", "/v1/books/{id}", "BookService", "GetBook"},
+	{"GET", "/v1/isbn/{isbn}", "BookService", "GetBookByIsbn"},
+	{"PUT", "/v1/books/{id}", "BookService", "UpdateBook"},
+	{"DELETE", "/v1/books/{id}", "BookService", "DeleteBook"},
+	{"POST", "/v1/books/{id}/restore", "BookService", "RestoreBook"},
+	{"DELETE", "/v1/books/{id}/purge", "BookService", "PurgeBook"},
+
+	{"GET", "/v1/authors", "AuthorService", "ListAuthors"},
+	{"POST", "/v1/authors", "AuthorService", "CreateAuthor"},
+	{"GET", "/v1/authors/{id}", "AuthorService", "GetAuthor"},
+	{"PUT", "/v1/authors/{id}", "AuthorService", "UpdateAuthor"},
+	{"DELETE", "/v1/authors/{id}", "AuthorService", "DeleteAuthor"},
+	{"POST", "/v1/authors/{id}/restore", "AuthorService", "RestoreAuthor"},
+	{"DELETE", "/v1/authors/{id}/purge", "AuthorService", "PurgeAuthor"},
+
+	{"GET", "/v1/categories", "CategoryService", "ListCategories"},
+	{"POST", "/v1/categories", "CategoryService", "CreateCategory"},
+	{"GET", "/v1/categories/{id}", "CategoryService", "GetCategory"},
+	{"PUT", "/v1/categories/{id}", "CategoryService", "UpdateCategory"},
+	{"DELETE", "/v1/categories/{id}", "CategoryService", "DeleteCategory"},
+	{"POST", "/v1/categories/{id}/restore", "CategoryService", "RestoreCategory"},
+	{"DELETE", "/v1/categories/{id}/purge", "CategoryService", "PurgeCategory"},
+
+	{"GET", "/v1/books/{id}/stock", "BookStockService", "GetBookStock"},
+	{"PUT", "/v1/books/{book_id}/stock", "BookStockService", "UpdateBookStock"},
+	{"POST", "/v1/books/{book_id}/stock/adjustments", "BookStockService", "AdjustStock"},
+	{"GET", "/v1/books/{book_id}/stock/movements", "BookStockService", "ListStockMovements"},
+
+	{"GET", "/v1/loans", "BorrowingService", "ListBorrowingTransactions"},
+	{"POST", "/v1/loans", "BorrowingService", "CreateBorrowingTransaction"},
+	{"GET", "/v1/loans/{id}", "BorrowingService", "GetBorrowingTransaction"},
+	{"PUT", "/v1/loans/{id}", "BorrowingService", "UpdateBorrowingTransaction"},
+	{"GET", "/v1/circulation/events", "BorrowingService", "WatchCirculation"},
+	{"POST", "/v1/loans/{transaction_id}/return", "ReturningService", "ReturnBook"},
+	{"POST", "/v1/loans/{transaction_id}/fine/pay", "ReturningService", "PayFine"},
+	{"POST", "/v1/loans/{transaction_id}/renew", "BorrowingService", "RenewBorrowingTransaction"},
+	{"GET", "/v1/me/reading-history", "BorrowingService", "ListMyReadingHistory"},
+	{"GET", "/v1/me/privacy-settings", "BorrowingService", "GetPrivacySettings"},
+	{"PUT", "/v1/me/privacy-settings", "BorrowingService", "UpdatePrivacySettings"},
+	{"GET", "/v1/holds", "BorrowingService", "ListHolds"},
+	{"POST", "/v1/holds", "BorrowingService", "PlaceHold"},
+	{"POST", "/v1/holds/{hold_id}/cancel", "BorrowingService", "CancelHold"},
+
+	{"GET", "/v1/webhooks", "WebhookService", "ListWebhooks"},
+	{"POST", "/v1/webhooks", "WebhookService", "RegisterWebhook"},
+	{"DELETE", "/v1/webhooks/{id}", "WebhookService", "DeleteWebhook"},
+	{"POST", "/v1/webhooks/{id}/test", "WebhookService", "TestWebhook"},
+	{"GET", "/v1/webhooks/{webhook_id}/deliveries", "WebhookService", "ListWebhookDeliveries"},
+	{"POST", "/v1/webhook-deliveries/{id}/retry", "WebhookService", "RetryWebhookDelivery"},
+
+	{"GET", "/v1/me/notification-preferences", "NotificationService", "GetNotificationPreferences"},
+	{"PUT", "/v1/me/notification-preferences", "NotificationService", "UpdateNotificationPreferences"},
+	{"GET", "/v1/me/notifications", "NotificationService", "ListMyNotifications"},
+	{"POST", "/v1/me/notifications/{id}/read", "NotificationService", "MarkRead"},
+	{"POST", "/v1/me/notifications/read", "NotificationService", "MarkAllRead"},
+	{"GET", "/v1/me/notifications/stream", "NotificationService", "SubscribeNotifications"},
+	{"POST", "/v1/announcements", "NotificationService", "BroadcastAnnouncement"},
+
+	{"GET", "/v1/reports", "ReportService", "ListReports"},
+	{"GET", "/v1/reports/{report}", "ReportService", "RunReport"},
+	{"GET", "/v1/reports/{report}/export", "ReportService", "ExportReport"},
+	{"GET", "/v1/report-schedules", "ReportService", "ListReportSchedules"},
+	{"POST", "/v1/report-schedules", "ReportService", "CreateReportSchedule"},
+	{"PUT", "/v1/report-schedules/{id}", "ReportService", "UpdateReportSchedule"},
+	{"DELETE", "/v1/report-schedules/{id}", "ReportService", "DeleteReportSchedule"},
+	{"POST", "/v1/report-schedules/{schedule_id}/run", "ReportService", "RunReportSchedule"},
+	{"GET", "/v1/report-schedules/{schedule_id}/runs", "ReportService", "ListReportRuns"},
+	{"GET", "/v1/report-runs", "ReportService", "ListReportRuns"},
+
+	{"GET", "/v1/me/data-export", "AccountService", "ExportMyData"},
+	{"GET", "/v1/borrowers/{borrower_id}/data-export", "AccountService", "ExportMyData"},
+	{"POST", "/v1/me/erase", "AccountService", "EraseAccount"},
+	{"POST", "/v1/borrowers/{borrower_id}/erase", "AccountService", "EraseAccount"},
+
+	{"GET", "/v1/books/{book_id}/reviews", "ReviewService", "ListBookReviews"},
+	{"POST", "/v1/books/{book_id}/reviews", "ReviewService", "PostReview"},
+	{"DELETE", "/v1/reviews/{id}", "ReviewService", "DeleteReview"},
+	{"GET", "/v1/reviews/queue", "ReviewService", "ListReviewQueue"},
+	{"POST", "/v1/reviews/{id}/moderate", "ReviewService", "ModerateReview"},
+
+	{"GET", "/v1/me/reading-lists", "ReadingListService", "ListReadingLists"},
+	{"POST", "/v1/me/reading-lists", "ReadingListService", "CreateReadingList"},
+	{"GET", "/v1/me/reading-lists/{id}", "ReadingListService", "GetReadingList"},
+	{"PUT", "/v1/me/reading-lists/{id}", "ReadingListService", "UpdateReadingList"},
+	{"DELETE", "/v1/me/reading-lists/{id}", "ReadingListService", "DeleteReadingList"},
+	{"POST", "/v1/me/reading-lists/{list_id}/items", "ReadingListService", "AddReadingListItem"},
+	{"DELETE", "/v1/me/reading-lists/{list_id}/items/{book_id}", "ReadingListService", "RemoveReadingListItem"},
+	{"PUT", "/v1/me/reading-lists/{list_id}/order", "ReadingListService", "ReorderReadingList"},
+	{"PUT", "/v1/me/reading-lists/{list_id}/share", "ReadingListService", "ShareReadingList"},
+	{"POST", "/v1/me/reading-lists/{list_id}/holds", "ReadingListService", "HoldUnavailable"},
+	{"GET", "/v1/shared-lists/{token}", "ReadingListService", "GetSharedReadingList"},
+
+	{"GET", "/v1/books/{book_id}/similar", "RecommendationService", "GetSimilarBooks"},
+	{"GET", "/v1/me/recommendations", "RecommendationService", "GetRecommendationsForMe"},
+
+	{"GET", "/v1/audit-events", "AuditService", "ListAuditEvents"},
+}
@@ -0,0 +1,113 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+)
+
+// CORS configures which browser origins may call the API
+type CORS struct {
+	AllowedOrigins   []string // "*" allows every origin, without credentials
+	AllowCredentials bool     // only for origins listed by name
+	MaxAge           time.Duration
+}
+
+var corsAllowedHeaders = []string{
+	"Authorization", "Content-Type", "Accept",
+	"X-Grpc-Web", "X-User-Agent", "Grpc-Timeout",
+	"Connect-Protocol-Version", "Connect-Timeout-Ms", "Connect-Content-Encoding", "Connect-Accept-Encoding",
+}
+
+var corsExposedHeaders = []string{
+	"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin",
+	"Content-Encoding", "Connect-Content-Encoding",
+}
+
+// Handler serves native gRPC, gRPC-Web, Connect and the REST routes on one
+// listener. HTTP/2 without TLS (h2c) and HTTP/1.1 are both accepted.
+func Handler(grpcServer *grpc.Server, g *Gateway, cors CORS) http.Handler {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType := r.Header.Get("Content-Type")
+
+		if r.ProtoMajor == 2 && strings.HasPrefix(contentType, "application/grpc") && !isGRPCWeb(contentType) {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+
+		// gRPC-Web and Connect use the gRPC path, /go_grpc.BookService/GetBook
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if len(parts) == 2 && strings.Contains(parts[0], ".") {
+			if md, err := findMethod(parts[0], parts[1]); err == nil {
+				switch {
+				case isGRPCWeb(contentType):
+					g.serveGRPCWeb(w, r, md)
+					return
+				case isConnect(contentType):
+					g.serveConnect(w, r, md)
+					return
+				}
+			}
+		}
+
+		g.ServeHTTP(w, r)
+	})
+
+	return h2c.NewHandler(cors.Handler(handler), &http2.Server{})
+}
+
+// Handler answers preflight requests and adds CORS headers for allowed origins
+func (c CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+
+		// Any site may call through the wildcard, but never with the user's credentials
+		switch {
+		case c.listed(origin):
+			h.Set("Access-Control-Allow-Origin", origin)
+			if c.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+		case c.listed("*"):
+			h.Set("Access-Control-Allow-Origin", "*")
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		h.Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", strings.Join(corsAllowedHeaders, ", "))
+			if c.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listed tells whether the origin is in AllowedOrigins by name, or "*" itself
+func (c CORS) listed(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
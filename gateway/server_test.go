@@ -0,0 +1,47 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSWildcardWithoutCredentials(t *testing.T) {
+	cors := CORS{AllowedOrigins: []string{"*", "https://app.library.test"}, AllowCredentials: true}
+	handler := cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		origin, allowOrigin, allowCredentials string
+	}{
+		{"https://app.library.test", "https://app.library.test", "true"},
+		{"https://evil.test", "*", ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/v1/books", nil)
+		req.Header.Set("Origin", tt.origin)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want %q", tt.origin, got, tt.allowOrigin)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tt.allowCredentials {
+			t.Errorf("%s: Access-Control-Allow-Credentials = %q, want %q", tt.origin, got, tt.allowCredentials)
+		}
+	}
+}
+
+func TestCORSUnlistedOrigin(t *testing.T) {
+	cors := CORS{AllowedOrigins: []string{"https://app.library.test"}, AllowCredentials: true}
+	handler := cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/books", nil)
+	req.Header.Set("Origin", "https://evil.test")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q for an unlisted origin", got)
+	}
+}
@@ -0,0 +1,89 @@
+package helpers
+
+import "strings"
+
+// NormalizeISBN strips hyphens and spaces from an ISBN and upper-cases the
+// ISBN-10 check digit X, e.g. "0-306-40615-x" becomes "030640615X".
+func NormalizeISBN(isbn string) string {
+	var b strings.Builder
+	for _, r := range isbn {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == 'x' || r == 'X':
+			b.WriteRune('X')
+		}
+	}
+	return b.String()
+}
+
+// ValidISBN checks the length and check digit of a normalized ISBN-10 or ISBN-13
+func ValidISBN(isbn string) bool {
+	switch len(isbn) {
+	case 10:
+		sum := 0
+		for i, r := range isbn {
+			d := int(r - '0')
+			if r == 'X' {
+				if i != 9 {
+					return false
+				}
+				d = 10
+			}
+			sum += d * (10 - i)
+		}
+		return sum%11 == 0
+	case 13:
+		sum := 0
+		for i, r := range isbn {
+			if r < '0' || r > '9' {
+				return false
+			}
+			if i%2 == 0 {
+				sum += int(r - '0')
+			} else {
+				sum += 3 * int(r-'0')
+			}
+		}
+		return sum%10 == 0
+	}
+	return false
+}
+
+// ISBN13 converts a normalized ISBN-10 to its ISBN-13 (978) form, other values are returned as is
+func ISBN13(isbn string) string {
+	if len(isbn) != 10 {
+		return isbn
+	}
+
+	isbn = "978" + isbn[:9]
+	sum := 0
+	for i, r := range isbn {
+		if i%2 == 0 {
+			sum += int(r - '0')
+		} else {
+			sum += 3 * int(r-'0')
+		}
+	}
+
+	return isbn + string(rune('0'+(10-sum%10)%10))
+}
+
+// ISBN10 converts a normalized 978 ISBN-13 to its ISBN-10 form, other values are returned as is
+func ISBN10(isbn string) string {
+	if len(isbn) != 13 || !strings.HasPrefix(isbn, "978") {
+		return isbn
+	}
+
+	isbn = isbn[3:12]
+	sum := 0
+	for i, r := range isbn {
+		sum += int(r-'0') * (10 - i)
+	}
+
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return isbn + "X"
+	}
+	return isbn + string(rune('0'+check))
+}
@@ -0,0 +1,9 @@
+package helpers
+
+import "strconv"
+
+// Money renders an amount of the decimal(10,2) money columns, e.g. "3000.00".
+// Amounts leave the API as strings so clients never round them through a float.
+func Money(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
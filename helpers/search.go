@@ -0,0 +1,10 @@
+package helpers
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Contains turns free text into a LIKE pattern matching it anywhere
+func Contains(search string) string {
+	return "%" + likeEscaper.Replace(strings.TrimSpace(search)) + "%"
+}
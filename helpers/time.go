@@ -0,0 +1,5 @@
+package helpers
+
+// DateTimeLayout is the format used for timestamps stored as strings and
+// returned in responses, e.g. "2024-08-21 06:05:59".
+const DateTimeLayout = "2006-01-02 15:04:05"
@@ -21,3 +21,10 @@ func GetData(ctx context.Context) (int, string, error) {
 	return userID, role, nil
 
 }
+
+// WithUser stores the caller like the JWT middleware does, for callers that
+// authenticate outside of gRPC such as the SIP2 server
+func WithUser(ctx context.Context, userID int, role string) context.Context {
+	ctx = context.WithValue(ctx, "userID", userID)
+	return context.WithValue(ctx, "role", role)
+}
@@ -0,0 +1,316 @@
+package importer
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-grpc/catalog"
+	"go-grpc/helpers"
+
+	"gorm.io/gorm"
+)
+
+// Row statuses in the report
+const (
+	StatusCreated = "created"
+	StatusSkipped = "skipped" // the ISBN is already in the catalog
+	StatusFailed  = "failed"
+)
+
+// Result is the outcome of one row
+type Result struct {
+	Line   int
+	ISBN   string
+	Title  string
+	Status string
+	BookID int32
+	Error  string
+}
+
+// AddCopies puts the copies of an imported book on the shelf, inside the batch transaction
+type AddCopies func(tx *gorm.DB, bookID, copies int32) error
+
+// errDryRun rolls back a batch that was only validated
+var errDryRun = errors.New("dry run")
+
+// Importer writes rows in batches, every batch is one transaction. A row
+// that fails is rolled back to its savepoint and reported, the rest of the
+// batch is kept. In dry run mode every batch is rolled back at the end.
+type Importer struct {
+	DB        *gorm.DB
+	BatchSize int
+	DryRun    bool
+	AddCopies AddCopies
+
+	batch   []Row
+	results []Result
+
+	// Lower-cased name to id, only holds rows of committed batches
+	authors    map[string]int32
+	categories map[string]int32
+
+	// ISBNs seen in this import, to report duplicates within the file
+	seen map[string]int
+}
+
+func New(db *gorm.DB, dryRun bool, addCopies AddCopies) *Importer {
+	return &Importer{
+		DB:         db,
+		BatchSize:  200,
+		DryRun:     dryRun,
+		AddCopies:  addCopies,
+		authors:    map[string]int32{},
+		categories: map[string]int32{},
+		seen:       map[string]int{},
+	}
+}
+
+// Add queues a row and writes the batch once it is full
+func (im *Importer) Add(row Row) error {
+	im.batch = append(im.batch, row)
+	if len(im.batch) >= im.BatchSize {
+		return im.Flush()
+	}
+	return nil
+}
+
+// Fail reports a row that could not be parsed
+func (im *Importer) Fail(line int, err error) {
+	im.results = append(im.results, Result{Line: line, Status: StatusFailed, Error: err.Error()})
+}
+
+// Flush writes the queued rows
+func (im *Importer) Flush() error {
+	if len(im.batch) == 0 {
+		return nil
+	}
+
+	batch := im.batch
+	im.batch = nil
+
+	var results []Result
+	authors := map[string]int32{}
+	categories := map[string]int32{}
+	seen := map[string]int{}
+
+	err := im.DB.Transaction(func(tx *gorm.DB) error {
+		results = make([]Result, 0, len(batch))
+		for i, row := range batch {
+			savepoint := fmt.Sprintf("import_row_%d", i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return err
+			}
+
+			result := im.importRow(tx, row, authors, categories, seen)
+			if result.Status == StatusFailed {
+				if err := tx.RollbackTo(savepoint).Error; err != nil {
+					return err
+				}
+			} else if result.ISBN != "" {
+				seen[result.ISBN] = result.Line
+			}
+			results = append(results, result)
+		}
+
+		if im.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errDryRun) {
+		// Nothing of the batch was written
+		for _, row := range batch {
+			im.results = append(im.results, Result{Line: row.Line, ISBN: row.ISBN, Title: row.Title, Status: StatusFailed, Error: err.Error()})
+		}
+		return err
+	}
+
+	// Ids created in a rolled back batch do not exist, only keep committed ones
+	if err == nil {
+		for name, id := range authors {
+			im.authors[name] = id
+		}
+		for name, id := range categories {
+			im.categories[name] = id
+		}
+	}
+
+	for isbn, line := range seen {
+		im.seen[isbn] = line
+	}
+
+	im.results = append(im.results, results...)
+	return nil
+}
+
+// Results returns the report of all rows flushed so far
+func (im *Importer) Results() []Result {
+	return im.results
+}
+
+func (im *Importer) importRow(tx *gorm.DB, row Row, authors, categories map[string]int32, seen map[string]int) Result {
+	result := Result{Line: row.Line, Title: row.Title}
+
+	fail := func(format string, args ...interface{}) Result {
+		result.Status = StatusFailed
+		result.Error = fmt.Sprintf(format, args...)
+		return result
+	}
+
+	row.Title = strings.TrimSpace(row.Title)
+	if row.Title == "" {
+		return fail("title is required")
+	}
+	if len(row.Authors) == 0 {
+		return fail("at least one author is required")
+	}
+	if row.Copies < 0 {
+		return fail("copies cannot be negative")
+	}
+
+	if row.ISBN != "" {
+		isbn := helpers.NormalizeISBN(row.ISBN)
+		if !helpers.ValidISBN(isbn) {
+			return fail("invalid isbn %q", row.ISBN)
+		}
+		result.ISBN = isbn
+
+		for _, lines := range []map[string]int{im.seen, seen} {
+			if line, ok := lines[isbn]; ok {
+				return fail("duplicate isbn, already imported from line %d", line)
+			}
+		}
+
+		var existing int32
+		err := tx.Table("books").Select("id").
+			Where("isbn IN ?", []string{helpers.ISBN13(isbn), helpers.ISBN10(isbn)}).
+			Limit(1).Scan(&existing).Error
+		if err != nil {
+			return fail("%v", err)
+		}
+		if existing != 0 {
+			result.Status = StatusSkipped
+			result.BookID = existing
+			return result
+		}
+	}
+
+	// Created names are only shared with later rows once this row succeeded,
+	// a failed row is rolled back together with its new authors and categories
+	newAuthors := map[string]int32{}
+	newCategories := map[string]int32{}
+
+	var contributors []catalog.Contributor
+	for _, names := range []struct {
+		role  string
+		names []string
+	}{
+		{catalog.RoleAuthor, row.Authors},
+		{catalog.RoleEditor, row.Editors},
+		{catalog.RoleTranslator, row.Translators},
+		{catalog.RoleIllustrator, row.Illustrators},
+	} {
+		for _, name := range names.names {
+			id, err := im.resolve(tx, "authors", name, newAuthors, im.authors, authors)
+			if err != nil {
+				return fail("%s %q: %v", names.role, name, err)
+			}
+			contributors = append(contributors, catalog.Contributor{AuthorID: id, Role: names.role})
+		}
+	}
+
+	contributors, err := catalog.CheckContributors(contributors)
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	var categoryID sql.NullInt32
+	if row.Category != "" {
+		id, err := im.resolve(tx, "categories", row.Category, newCategories, im.categories, categories)
+		if err != nil {
+			return fail("category %q: %v", row.Category, err)
+		}
+		categoryID = sql.NullInt32{Int32: id, Valid: true}
+	}
+
+	book := struct {
+		ID              int32 `gorm:"primaryKey"`
+		Title           string
+		Description     string
+		AuthorID        int32
+		CategoryID      sql.NullInt32
+		PublicationYear int32
+		ISBN            sql.NullString
+	}{
+		Title:           row.Title,
+		Description:     row.Description,
+		AuthorID:        catalog.FirstAuthor(contributors),
+		CategoryID:      categoryID,
+		PublicationYear: row.Year,
+		ISBN:            sql.NullString{String: result.ISBN, Valid: result.ISBN != ""},
+	}
+
+	if err := tx.Table("books").Create(&book).Error; err != nil {
+		return fail("%v", err)
+	}
+
+	if err := catalog.SetContributors(tx, book.ID, contributors); err != nil {
+		return fail("contributors: %v", err)
+	}
+
+	if row.Copies > 0 && im.AddCopies != nil {
+		if err := im.AddCopies(tx, book.ID, row.Copies); err != nil {
+			return fail("copies: %v", err)
+		}
+	}
+
+	for name, id := range newAuthors {
+		authors[name] = id
+	}
+	for name, id := range newCategories {
+		categories[name] = id
+	}
+
+	result.Status = StatusCreated
+	result.BookID = book.ID
+	return result
+}
+
+// resolve finds an author or category by name, case-insensitively, and creates it when missing.
+// Known ids are looked up in caches, newly resolved ones are added to created.
+func (im *Importer) resolve(tx *gorm.DB, table, name string, created map[string]int32, caches ...map[string]int32) (int32, error) {
+	name = strings.TrimSpace(name)
+	key := strings.ToLower(name)
+
+	for _, cache := range append(caches, created) {
+		if id, ok := cache[key]; ok {
+			return id, nil
+		}
+	}
+
+	var id int32
+	err := tx.Table(table).Select("id").
+		Where("LCASE(name) = ? AND deleted_at IS NULL", key).
+		Order("id").Limit(1).Scan(&id).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if id == 0 {
+		row := struct {
+			ID   int32 `gorm:"primaryKey"`
+			Name string
+		}{Name: name}
+
+		if err := tx.Table(table).Create(&row).Error; err != nil {
+			return 0, err
+		}
+		id = row.ID
+	}
+
+	created[key] = id
+	return id, nil
+}
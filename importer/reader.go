@@ -0,0 +1,263 @@
+// Package importer loads books into the catalog in bulk. It parses CSV and
+// JSON lines files into rows and writes rows in batched transactions,
+// resolving authors and categories by name and de-duplicating by ISBN.
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Row is one book to import
+type Row struct {
+	Line         int      `json:"-"` // position in the source, used in the report
+	Title        string   `json:"title"`
+	ISBN         string   `json:"isbn"`
+	Authors      []string `json:"authors"`
+	Editors      []string `json:"editors"`
+	Translators  []string `json:"translators"`
+	Illustrators []string `json:"illustrators"`
+	Category     string   `json:"category"`
+	Year         int32    `json:"year"`
+	Description  string   `json:"description"`
+	Copies       int32    `json:"copies"`
+}
+
+// Reader returns rows one at a time, io.EOF after the last one. A row that
+// cannot be parsed is returned together with a *RowError.
+type Reader interface {
+	Read() (Row, error)
+}
+
+// RowError reports a row of the source file that could not be parsed
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// NewReader returns a reader for format "csv" or "jsonl"
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch strings.ToLower(format) {
+	case "csv":
+		return NewCSVReader(r)
+	case "jsonl", "ndjson", "json":
+		return NewJSONLReader(r), nil
+	}
+	return nil, fmt.Errorf("unknown import format %q", format)
+}
+
+// CSV columns, matched case-insensitively against the header row
+var csvColumns = map[string]string{
+	"title":            "title",
+	"isbn":             "isbn",
+	"author":           "authors",
+	"authors":          "authors",
+	"editor":           "editors",
+	"editors":          "editors",
+	"translator":       "translators",
+	"translators":      "translators",
+	"illustrator":      "illustrators",
+	"illustrators":     "illustrators",
+	"category":         "category",
+	"year":             "year",
+	"publication_year": "year",
+	"description":      "description",
+	"copies":           "copies",
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns []string
+}
+
+// NewCSVReader reads CSV with a header row. Several authors, editors,
+// translators or illustrators in one cell are separated by semicolons,
+// e.g. "Kernighan, Brian; Ritchie, Dennis".
+func NewCSVReader(r io.Reader) (Reader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv file is empty")
+		}
+		return nil, err
+	}
+
+	columns := make([]string, len(header))
+	found := false
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[i] = csvColumns[name]
+		found = found || columns[i] == "title"
+	}
+	if !found {
+		return nil, errors.New("csv header has no title column")
+	}
+
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+func (c *csvReader) Read() (Row, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Row{Line: parseErr.Line}, &RowError{Line: parseErr.Line, Err: parseErr.Err}
+		}
+		return Row{}, err
+	}
+	line, _ := c.r.FieldPos(0)
+
+	row := Row{Line: line}
+	for i, value := range record {
+		if i >= len(c.columns) {
+			break
+		}
+		value = strings.TrimSpace(value)
+
+		switch c.columns[i] {
+		case "title":
+			row.Title = value
+		case "isbn":
+			row.ISBN = value
+		case "authors":
+			row.Authors = splitAuthors(value)
+		case "editors":
+			row.Editors = splitAuthors(value)
+		case "translators":
+			row.Translators = splitAuthors(value)
+		case "illustrators":
+			row.Illustrators = splitAuthors(value)
+		case "category":
+			row.Category = value
+		case "description":
+			row.Description = value
+		case "year", "copies":
+			if value == "" {
+				continue
+			}
+			n, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return row, &RowError{Line: line, Err: fmt.Errorf("%v is not a number: %q", c.columns[i], value)}
+			}
+			if c.columns[i] == "year" {
+				row.Year = int32(n)
+			} else {
+				row.Copies = int32(n)
+			}
+		}
+	}
+
+	return row, nil
+}
+
+func splitAuthors(value string) []string {
+	var authors []string
+	for _, name := range strings.Split(value, ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			authors = append(authors, name)
+		}
+	}
+	return authors
+}
+
+type jsonlReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewJSONLReader reads one JSON object per line, blank lines are skipped.
+// "authors", "editors", "translators" and "illustrators" may be a list or a
+// single string.
+func NewJSONLReader(r io.Reader) Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &jsonlReader{s: s}
+}
+
+func (j *jsonlReader) Read() (Row, error) {
+	for j.s.Scan() {
+		j.line++
+
+		raw := strings.TrimSpace(j.s.Text())
+		if raw == "" {
+			continue
+		}
+
+		var value struct {
+			Row
+			Authors         json.RawMessage `json:"authors"`
+			Author          string          `json:"author"`
+			Editors         json.RawMessage `json:"editors"`
+			Translators     json.RawMessage `json:"translators"`
+			Illustrators    json.RawMessage `json:"illustrators"`
+			PublicationYear int32           `json:"publication_year"`
+		}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return Row{Line: j.line}, &RowError{Line: j.line, Err: err}
+		}
+
+		row := value.Row
+		row.Line = j.line
+		if row.Year == 0 {
+			row.Year = value.PublicationYear
+		}
+
+		var err error
+		if len(value.Authors) == 0 {
+			row.Authors = splitAuthors(value.Author)
+		} else if row.Authors, err = names("authors", value.Authors); err != nil {
+			return row, &RowError{Line: j.line, Err: err}
+		}
+		if row.Editors, err = names("editors", value.Editors); err != nil {
+			return row, &RowError{Line: j.line, Err: err}
+		}
+		if row.Translators, err = names("translators", value.Translators); err != nil {
+			return row, &RowError{Line: j.line, Err: err}
+		}
+		if row.Illustrators, err = names("illustrators", value.Illustrators); err != nil {
+			return row, &RowError{Line: j.line, Err: err}
+		}
+
+		return row, nil
+	}
+
+	if err := j.s.Err(); err != nil {
+		return Row{}, err
+	}
+	return Row{}, io.EOF
+}
+
+// names reads a list of names or a single string with semicolons between names
+func names(field string, raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return list, nil
+	}
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return splitAuthors(single), nil
+	}
+	return nil, fmt.Errorf("%s must be a string or a list of strings", field)
+}
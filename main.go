@@ -1,20 +1,32 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
+	"net/http"
+	"time"
 
 	"go-grpc/cmd/config"
 	"go-grpc/cmd/service"
+	"go-grpc/cmd/worker"
+	"go-grpc/events"
+	"go-grpc/gateway"
 	"go-grpc/middleware"
+	"go-grpc/model"
+	"go-grpc/notification"
 	libraryPb "go-grpc/pb/library"
+	"go-grpc/recommend"
+	"go-grpc/webhook"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
 
 	port := ":50051"
+	gatewayPort := ":8080"
 	netListen, err := net.Listen("tcp", port)
 	if err != nil {
 		log.Fatalf("failed to listen %v", err.Error())
@@ -22,14 +34,63 @@ func main() {
 
 	db := config.ConnectDatabase()
 
-	// Create gRPC server with JWT middleware interceptor
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(middleware.JWTMiddleware(db)))
+	ctx := context.Background()
+
+	// Circulation events are polled from the database and pushed to WatchCirculation streams
+	circulationFeed := events.NewCirculationFeed(db)
+	go circulationFeed.Run(ctx, 2*time.Second)
+
+	overdueWorker := worker.OverdueWorker{DB: db, Events: circulationFeed}
+	go overdueWorker.Run(ctx, time.Minute)
+
+	// Returned loans of borrowers who opted out of a reading history lose their borrower after the retention window
+	retentionDays := config.HistoryRetentionDays()
+	historyAnonymizer := worker.HistoryAnonymizer{DB: db, Retention: time.Duration(retentionDays) * 24 * time.Hour, BatchSize: 500}
+	go historyAnonymizer.Run(ctx, time.Hour)
+
+	// Book similarities for recommendations are rebuilt from the borrowing history
+	recommendationBuilder := recommend.NewBuilder(db)
+	go recommendationBuilder.Run(ctx, 6*time.Hour)
+
+	// In-app notifications are pushed to the SubscribeNotifications streams of their borrower
+	notificationHub := events.NewTopics[int32, model.Notification](64)
+
+	// Due-date reminders and overdue notices, email and sms only when SMTP and an SMS gateway are configured
+	channels := []notification.Channel{&notification.InboxChannel{DB: db, Hub: notificationHub}}
+	if mailer := config.Mailer(); mailer != nil {
+		channels = append(channels, &notification.EmailChannel{Mailer: mailer})
+	}
+	if provider := config.SMSProvider(); provider != nil {
+		channels = append(channels, &notification.SMSChannel{Provider: provider})
+	}
+	notificationScheduler := notification.NewScheduler(db, channels...)
+	go notificationScheduler.Run(ctx, 15*time.Minute)
+
+	// Scheduled reports, emailed or written to REPORT_DIR
+	reportScheduler := config.ReportScheduler(db)
+	go reportScheduler.Run(ctx, time.Minute)
+
+	// Delivers outbox events to registered webhook endpoints
+	webhookDispatcher := webhook.NewDispatcher(db)
+	go webhookDispatcher.Run(ctx, 5*time.Second)
+
+	// Create gRPC server with JWT middleware interceptor, audit runs after JWT so the actor is known
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			middleware.JWTMiddleware(db),
+			middleware.AuditMiddleware(db),
+		),
+		grpc.ChainStreamInterceptor(
+			middleware.JWTStreamMiddleware(db),
+			middleware.AuditStreamMiddleware(db),
+		),
+	)
 
 	// Register services
 	authService := service.AuthService{DB: db}
 	libraryPb.RegisterAuthServiceServer(grpcServer, &authService)
 
-	bookService := service.BookService{DB: db}
+	bookService := service.BookService{DB: db, Events: circulationFeed}
 	libraryPb.RegisterBookServiceServer(grpcServer, &bookService)
 
 	categoryService := service.CategoryService{DB: db}
@@ -38,17 +99,79 @@ func main() {
 	authorService := service.AuthorService{DB: db}
 	libraryPb.RegisterAuthorServiceServer(grpcServer, &authorService)
 
-	stockService := service.BookStockService{DB: db}
+	stockService := service.BookStockService{DB: db, Events: circulationFeed}
 	libraryPb.RegisterBookStockServiceServer(grpcServer, &stockService)
 
-	borrowService := service.BorrowingServiceServer{DB: db}
+	borrowService := service.BorrowingServiceServer{DB: db, Events: circulationFeed, HistoryRetentionDays: retentionDays}
 	libraryPb.RegisterBorrowingServiceServer(grpcServer, &borrowService)
 
-	returnedService := service.ReturningServiceServer{DB: db}
+	returnedService := service.ReturningServiceServer{DB: db, Events: circulationFeed, FinePerDay: config.FinePerDay()}
 	libraryPb.RegisterReturningServiceServer(grpcServer, &returnedService)
 
+	// Self-checkout kiosks speak SIP2 on a port of their own, only when SIP2_ADDR is set
+	if sipServer, sipAddr := config.SIP2(db, &borrowService, &returnedService); sipServer != nil {
+		go func() {
+			log.Printf("SIP2 server start at %v", sipAddr)
+			if err := sipServer.ListenAndServe(ctx, sipAddr); err != nil {
+				log.Fatalf("failed to serve SIP2 %v", err.Error())
+			}
+		}()
+	}
+
+	webhookService := service.WebhookService{DB: db, Dispatcher: webhookDispatcher}
+	libraryPb.RegisterWebhookServiceServer(grpcServer, &webhookService)
+
+	notificationService := service.NotificationService{DB: db, Hub: notificationHub}
+	libraryPb.RegisterNotificationServiceServer(grpcServer, &notificationService)
+
+	reportService := service.ReportService{DB: db, Scheduler: reportScheduler}
+	libraryPb.RegisterReportServiceServer(grpcServer, &reportService)
+
+	accountService := service.AccountService{DB: db}
+	libraryPb.RegisterAccountServiceServer(grpcServer, &accountService)
+
+	reviewService := service.ReviewService{DB: db}
+	libraryPb.RegisterReviewServiceServer(grpcServer, &reviewService)
+
+	readingListService := service.ReadingListService{DB: db}
+	libraryPb.RegisterReadingListServiceServer(grpcServer, &readingListService)
+
+	recommendationService := service.RecommendationService{DB: db}
+	libraryPb.RegisterRecommendationServiceServer(grpcServer, &recommendationService)
+
+	auditService := service.AuditService{DB: db}
+	libraryPb.RegisterAuditServiceServer(grpcServer, &auditService)
+
+	// REST/JSON gateway, forwards to the gRPC server so calls pass the same interceptors
+	gatewayConn, err := grpc.NewClient("localhost"+port,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(middleware.GatewayDialer))
+	if err != nil {
+		log.Fatalf("failed to connect gateway %v", err.Error())
+	}
+	restGateway, err := gateway.New(gatewayConn)
+	if err != nil {
+		log.Fatalf("failed to build gateway %v", err.Error())
+	}
+
+	// OAI-PMH harvesting, the OPDS catalog and SRU search are public and served next to the REST routes
+	restGateway.Handle("/oai", config.OAI(db))
+	restGateway.Handle("/opds/", config.OPDS(db))
+	restGateway.Handle("/sru", config.SRU(db))
+
+	cors := config.CORS()
+	go func() {
+		log.Printf("HTTP gateway start at %v", gatewayPort)
+		if err := http.ListenAndServe(gatewayPort, cors.Handler(restGateway)); err != nil {
+			log.Fatalf("failed to serve gateway %v", err.Error())
+		}
+	}()
+
+	// Native gRPC, gRPC-Web, Connect and REST share the main port over HTTP/1.1 and h2c
+	httpServer := &http.Server{Handler: gateway.Handler(grpcServer, restGateway, cors)}
+
 	log.Printf("Server start at %v", netListen.Addr())
-	if err := grpcServer.Serve(netListen); err != nil {
+	if err := httpServer.Serve(netListen); err != nil {
 		log.Fatalf("failed to serve %v", err.Error())
 	}
 }
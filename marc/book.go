@@ -0,0 +1,209 @@
+package marc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-grpc/catalog"
+	"go-grpc/helpers"
+	"go-grpc/importer"
+	pb "go-grpc/pb/library"
+)
+
+var yearPattern = regexp.MustCompile(`\d{4}`)
+
+// ToRow maps a bibliographic record to an import row: 020 ISBN, 100/700
+// contributors, 245 title, 264/260 year, 520 summary and the first 650
+// subject as category
+func ToRow(r *Record) importer.Row {
+	row := importer.Row{
+		Title:       title(r),
+		ISBN:        isbn(r),
+		Year:        year(r),
+		Description: first(r, "520", 'a'),
+		Category:    trimPunctuation(first(r, "650", 'a')),
+	}
+
+	for _, tag := range []string{"100", "700"} {
+		for _, f := range r.Get(tag) {
+			name := personalName(f.Subfield('a'))
+			if name == "" {
+				continue
+			}
+			switch relator(f) {
+			case catalog.RoleEditor:
+				row.Editors = append(row.Editors, name)
+			case catalog.RoleTranslator:
+				row.Translators = append(row.Translators, name)
+			case catalog.RoleIllustrator:
+				row.Illustrators = append(row.Illustrators, name)
+			default:
+				row.Authors = append(row.Authors, name)
+			}
+		}
+	}
+
+	return row
+}
+
+// Relator codes of $4 for the contributor roles
+var relatorCodes = map[string]string{
+	"aut": catalog.RoleAuthor,
+	"edt": catalog.RoleEditor,
+	"trl": catalog.RoleTranslator,
+	"ill": catalog.RoleIllustrator,
+}
+
+// relator reads the role of a name from the relator term in $e or the code
+// in $4, names without a known role are authors
+func relator(f Field) string {
+	term := strings.ToLower(trimPunctuation(f.Subfield('e')))
+	for _, role := range catalog.Roles {
+		if term == role {
+			return role
+		}
+	}
+	if role, ok := relatorCodes[strings.ToLower(strings.TrimSpace(f.Subfield('4')))]; ok {
+		return role
+	}
+	return catalog.RoleAuthor
+}
+
+func first(r *Record, tag string, code byte) string {
+	for _, f := range r.Get(tag) {
+		if value := strings.TrimSpace(f.Subfield(code)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// title joins 245 $a and $b without the ISBD punctuation between them
+func title(r *Record) string {
+	fields := r.Get("245")
+	if len(fields) == 0 {
+		return ""
+	}
+
+	title := trimPunctuation(fields[0].Subfield('a'))
+	if subtitle := trimPunctuation(fields[0].Subfield('b')); subtitle != "" {
+		title += ": " + subtitle
+	}
+	return title
+}
+
+// isbn takes the first valid 020 $a, qualifiers like "(pbk.)" are dropped
+func isbn(r *Record) string {
+	for _, f := range r.Get("020") {
+		value := strings.Fields(f.Subfield('a'))
+		if len(value) == 0 {
+			continue
+		}
+		if isbn := helpers.NormalizeISBN(value[0]); helpers.ValidISBN(isbn) {
+			return isbn
+		}
+	}
+	return ""
+}
+
+// year prefers the publication statement in 264 over 260, then 008/07-10
+func year(r *Record) int32 {
+	var dates []string
+	for _, f := range r.Get("264") {
+		if f.Ind2 == '1' {
+			dates = append(dates, f.Subfield('c'))
+		}
+	}
+	for _, f := range r.Get("260") {
+		dates = append(dates, f.Subfield('c'))
+	}
+	if fixed := r.Control("008"); len(fixed) >= 11 {
+		dates = append(dates, fixed[7:11])
+	}
+
+	for _, date := range dates {
+		if match := yearPattern.FindString(date); match != "" {
+			year, _ := strconv.Atoi(match)
+			return int32(year)
+		}
+	}
+	return 0
+}
+
+func trimPunctuation(s string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(s), " /:;,=."))
+}
+
+// personalName drops the trailing comma or period of a heading, but keeps
+// the period of an initial as in "Kernighan, Brian W."
+func personalName(s string) string {
+	s = strings.TrimRight(strings.TrimSpace(s), ",")
+	words := strings.Fields(s)
+	if strings.HasSuffix(s, ".") && len(words) > 0 && len(words[len(words)-1]) > 2 {
+		s = strings.TrimSuffix(s, ".")
+	}
+	return strings.TrimSpace(s)
+}
+
+// FromBook builds the record of a catalog book, modified is the time
+// written to 005 and as date entered in 008
+func FromBook(book *pb.Book, modified time.Time) *Record {
+	r := &Record{Leader: DefaultLeader}
+
+	if book.DeletedAt != "" {
+		r.Leader = DefaultLeader[:5] + "d" + DefaultLeader[6:]
+	}
+
+	year := "    "
+	if book.PublicationYear > 0 {
+		year = fmt.Sprintf("%04d", book.PublicationYear)
+	}
+
+	r.AddControl("001", strconv.Itoa(int(book.Id)))
+	r.AddControl("005", modified.Format("20060102150405")+".0")
+	r.AddControl("008", fixedField(modified, year))
+
+	if book.Isbn != "" {
+		r.AddData("020", ' ', ' ', 'a', book.Isbn)
+	}
+
+	// Title added entry is indexed under the title when there is no author
+	ind1 := byte('0')
+	if book.Author != nil && book.Author.Name != "" {
+		ind1 = '1'
+		r.AddData("100", '1', ' ', 'a', book.Author.Name)
+	}
+
+	r.AddData("245", ind1, '0', 'a', book.Title)
+
+	if book.PublicationYear > 0 {
+		r.AddData("264", ' ', '1', 'c', year)
+	}
+
+	if book.Description != "" {
+		r.AddData("520", ' ', ' ', 'a', book.Description)
+	}
+
+	if book.Category != nil {
+		r.AddData("650", ' ', '4', 'a', book.Category.Name)
+	}
+
+	// The first author is the main entry, everybody else an added entry with their role
+	for _, c := range book.Contributors {
+		if c.Role == catalog.RoleAuthor && book.Author != nil && c.AuthorId == book.Author.Id {
+			continue
+		}
+		r.AddData("700", '1', ' ', 'a', c.Name, 'e', c.Role)
+	}
+
+	return r
+}
+
+// fixedField is 008 for books: date entered, single known date, unknown
+// place and language, cataloged by another agency
+func fixedField(entered time.Time, year string) string {
+	return entered.Format("060102") + "s" + year + "    " + "xx " + strings.Repeat(" ", 17) + "und" + " " + "d"
+}
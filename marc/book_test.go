@@ -0,0 +1,25 @@
+package marc
+
+import (
+	"testing"
+	"time"
+
+	pb "go-grpc/pb/library"
+)
+
+func TestFromBookSummary(t *testing.T) {
+	modified := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	r := FromBook(&pb.Book{Id: 1, Title: "The TeXbook"}, modified)
+	if fields := r.Get("520"); len(fields) != 0 {
+		t.Errorf("book without a description has 520 %+v", fields)
+	}
+
+	r = FromBook(&pb.Book{Id: 1, Title: "The TeXbook", Description: "A guide to TeX."}, modified)
+	if got := first(r, "520", 'a'); got != "A guide to TeX." {
+		t.Errorf("520 $a = %q, want the description", got)
+	}
+	if _, err := Encode(r); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,72 @@
+package marc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Supported formats
+const (
+	FormatMARC21  = "marc21"
+	FormatMARCXML = "marcxml"
+)
+
+// ParseFormat accepts the format names and common file extensions, marc21 by default
+func ParseFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "", "marc21", "marc", "mrc", "iso2709":
+		return FormatMARC21, nil
+	case "marcxml", "xml":
+		return FormatMARCXML, nil
+	default:
+		return "", fmt.Errorf("unsupported MARC format %q, use marc21 or marcxml", format)
+	}
+}
+
+// NewFormatReader reads records in one of the supported formats
+func NewFormatReader(r io.Reader, format string) (Reader, error) {
+	format, err := ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatMARCXML {
+		return NewXMLReader(r), nil
+	}
+	return NewReader(r), nil
+}
+
+// Writer writes records, Close ends the file
+type Writer interface {
+	Write(r *Record) error
+	Close() error
+}
+
+type binaryWriter struct {
+	w io.Writer
+}
+
+func (b binaryWriter) Write(r *Record) error {
+	raw, err := Encode(r)
+	if err != nil {
+		return err
+	}
+	_, err = b.w.Write(raw)
+	return err
+}
+
+func (b binaryWriter) Close() error {
+	return nil
+}
+
+// NewWriter writes records in one of the supported formats
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	format, err := ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatMARCXML {
+		return NewXMLWriter(w), nil
+	}
+	return binaryWriter{w: w}, nil
+}
@@ -0,0 +1,196 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ISO 2709 delimiters
+const (
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+)
+
+// Reader reads records one at a time, io.EOF after the last one
+type Reader interface {
+	Read() (*Record, error)
+}
+
+type binaryReader struct {
+	r *bufio.Reader
+}
+
+// NewReader reads MARC21 records in ISO 2709. Data is expected to be UTF-8
+// (leader/09 "a"), MARC-8 records are only read correctly for plain ASCII.
+func NewReader(r io.Reader) Reader {
+	return &binaryReader{r: bufio.NewReader(r)}
+}
+
+func (b *binaryReader) Read() (*Record, error) {
+	for {
+		raw, err := b.r.ReadBytes(recordTerminator)
+		if err != nil && !(errors.Is(err, io.EOF) && len(bytes.TrimSpace(raw)) > 0) {
+			return nil, err
+		}
+
+		// Files often end with a newline after the last record
+		raw = bytes.TrimLeft(raw, "\r\n ")
+		if len(raw) == 0 {
+			continue
+		}
+
+		record, err := decodeBinary(raw)
+		if err != nil {
+			return nil, &RecordError{Err: err}
+		}
+		return record, nil
+	}
+}
+
+// RecordError reports a record that could not be decoded, reading can go on
+// with the next record
+type RecordError struct {
+	Err error
+}
+
+func (e *RecordError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+func decodeBinary(raw []byte) (*Record, error) {
+	if len(raw) < 25 {
+		return nil, errors.New("marc: record is shorter than its leader")
+	}
+
+	leader := string(raw[:24])
+	base, ok := number(raw[12:17])
+	if !ok || base < 25 || base > len(raw) {
+		return nil, fmt.Errorf("marc: invalid base address %q", raw[12:17])
+	}
+
+	directory := raw[24 : base-1]
+	if len(directory)%12 != 0 {
+		return nil, fmt.Errorf("marc: directory length %d is not a multiple of 12", len(directory))
+	}
+
+	record := &Record{Leader: leader}
+	data := raw[base:]
+
+	for i := 0; i < len(directory); i += 12 {
+		entry := directory[i : i+12]
+		tag := string(entry[:3])
+		length, ok1 := number(entry[3:7])
+		start, ok2 := number(entry[7:12])
+		if !ok1 || !ok2 || start < 0 || length < 1 || start+length > len(data) {
+			return nil, fmt.Errorf("marc: invalid directory entry %q", entry)
+		}
+
+		// Drop the field terminator
+		value := data[start : start+length-1]
+		field := Field{Tag: tag}
+
+		if field.IsControl() {
+			field.Value = string(value)
+			record.Fields = append(record.Fields, field)
+			continue
+		}
+
+		if len(value) >= 2 {
+			field.Ind1, field.Ind2 = value[0], value[1]
+			value = value[2:]
+		}
+
+		for _, part := range bytes.Split(value, []byte{subfieldDelimiter}) {
+			if len(part) == 0 {
+				continue
+			}
+			field.Subfields = append(field.Subfields, Subfield{Code: part[0], Value: string(part[1:])})
+		}
+
+		record.Fields = append(record.Fields, field)
+	}
+
+	return record, nil
+}
+
+// number reads a fixed width number of the leader or directory, every byte
+// must be a digit so signs and spaces are rejected
+func number(b []byte) (int, bool) {
+	n := 0
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, len(b) > 0
+}
+
+// Encode renders a record in ISO 2709, record length, base address and
+// directory in the leader are computed
+func Encode(r *Record) ([]byte, error) {
+	var directory, data bytes.Buffer
+
+	for _, f := range r.Fields {
+		start := data.Len()
+
+		if f.IsControl() {
+			data.WriteString(f.Value)
+		} else {
+			data.WriteByte(indicator(f.Ind1))
+			data.WriteByte(indicator(f.Ind2))
+			for _, sf := range f.Subfields {
+				data.WriteByte(subfieldDelimiter)
+				data.WriteByte(sf.Code)
+				data.WriteString(sf.Value)
+			}
+		}
+		data.WriteByte(fieldTerminator)
+
+		length := data.Len() - start
+		if len(f.Tag) != 3 || length > 9999 || start > 99999 {
+			return nil, fmt.Errorf("marc: field %q does not fit ISO 2709", f.Tag)
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", f.Tag, length, start)
+	}
+	directory.WriteByte(fieldTerminator)
+
+	base := 24 + directory.Len()
+	total := base + data.Len() + 1
+	if total > 99999 {
+		return nil, errors.New("marc: record is longer than 99999 bytes")
+	}
+
+	leader := []byte(r.Leader)
+	if len(leader) != 24 {
+		leader = []byte(DefaultLeader)
+	}
+	copy(leader[0:5], fmt.Sprintf("%05d", total))
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+
+	out := make([]byte, 0, total)
+	out = append(out, leader...)
+	out = append(out, directory.Bytes()...)
+	out = append(out, data.Bytes()...)
+	out = append(out, recordTerminator)
+
+	return out, nil
+}
+
+func indicator(b byte) byte {
+	if b == 0 {
+		return ' '
+	}
+	return b
+}
+
+// DefaultLeader describes a new, UTF-8 encoded record of a printed monograph
+const DefaultLeader = "00000nam a2200000 i 4500"
@@ -0,0 +1,64 @@
+package marc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func sampleRecord() *Record {
+	r := &Record{Leader: DefaultLeader}
+	r.AddControl("001", "42")
+	r.AddData("100", '1', ' ', 'a', "Knuth, Donald E.")
+	r.AddData("245", '1', '0', 'a', "The TeXbook")
+	return r
+}
+
+func TestEncodeDecode(t *testing.T) {
+	raw, err := Encode(sampleRecord())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(raw)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Control("001"); got != "42" {
+		t.Errorf("001 = %q, want 42", got)
+	}
+	if got := first(r, "245", 'a'); got != "The TeXbook" {
+		t.Errorf("245 $a = %q, want The TeXbook", got)
+	}
+}
+
+func TestDecodeSignedDirectoryEntry(t *testing.T) {
+	raw, err := Encode(sampleRecord())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Starting position of the first directory entry, a sign used to slip through strconv.Atoi
+	bad := append([]byte{}, raw...)
+	copy(bad[24+7:24+12], "-0001")
+
+	if _, err := decodeBinary(bad); err == nil {
+		t.Fatal("decodeBinary accepted a negative starting position")
+	}
+
+	copy(bad[24+7:24+12], "+0000")
+	if _, err := decodeBinary(bad); err == nil {
+		t.Fatal("decodeBinary accepted a signed starting position")
+	}
+}
+
+func FuzzDecodeBinary(f *testing.F) {
+	raw, err := Encode(sampleRecord())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(raw)
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		decodeBinary(raw)
+	})
+}
@@ -0,0 +1,177 @@
+package marc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+)
+
+// Namespace of MARCXML documents
+const Namespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlRecord struct {
+	XMLName xml.Name       `xml:"record"`
+	Leader  string         `xml:"leader"`
+	Control []xmlControl   `xml:"controlfield"`
+	Data    []xmlDataField `xml:"datafield"`
+}
+
+type xmlControl struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlReader struct {
+	d *xml.Decoder
+}
+
+// NewXMLReader reads the record elements of a MARCXML collection, or a single record
+func NewXMLReader(r io.Reader) Reader {
+	return &xmlReader{d: xml.NewDecoder(r)}
+}
+
+func (x *xmlReader) Read() (*Record, error) {
+	for {
+		token, err := x.d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		var raw xmlRecord
+		if err := x.d.DecodeElement(&raw, &start); err != nil {
+			return nil, err
+		}
+
+		return raw.record(), nil
+	}
+}
+
+// record keeps control fields before data fields, which is the MARC order anyway
+func (raw *xmlRecord) record() *Record {
+	record := &Record{Leader: raw.Leader}
+
+	for _, c := range raw.Control {
+		record.AddControl(c.Tag, c.Value)
+	}
+
+	for _, d := range raw.Data {
+		field := Field{Tag: d.Tag, Ind1: firstByte(d.Ind1), Ind2: firstByte(d.Ind2)}
+		for _, sf := range d.Subfields {
+			field.Subfields = append(field.Subfields, Subfield{Code: firstByte(sf.Code), Value: sf.Value})
+		}
+		record.Fields = append(record.Fields, field)
+	}
+
+	return record
+}
+
+func firstByte(s string) byte {
+	if s == "" {
+		return ' '
+	}
+	return s[0]
+}
+
+// XMLWriter writes records into a MARCXML collection
+type XMLWriter struct {
+	w       io.Writer
+	e       *xml.Encoder
+	started bool
+}
+
+func NewXMLWriter(w io.Writer) *XMLWriter {
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	return &XMLWriter{w: w, e: e}
+}
+
+func (x *XMLWriter) start() error {
+	if x.started {
+		return nil
+	}
+	x.started = true
+
+	if _, err := io.WriteString(x.w, xml.Header); err != nil {
+		return err
+	}
+	return x.e.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "collection"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: Namespace}},
+	})
+}
+
+// Write adds a record to the collection
+func (x *XMLWriter) Write(r *Record) error {
+	if err := x.start(); err != nil {
+		return err
+	}
+
+	return x.e.Encode(toXML(r))
+}
+
+// MarshalRecord renders a single record element with the MARCXML
+// namespace, for embedding in other documents
+func MarshalRecord(r *Record, prefix, indent string) ([]byte, error) {
+	start := xml.StartElement{
+		Name: xml.Name{Local: "record"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: Namespace}},
+	}
+
+	var b bytes.Buffer
+	e := xml.NewEncoder(&b)
+	e.Indent(prefix, indent)
+	if err := e.EncodeElement(toXML(r), start); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func toXML(r *Record) xmlRecord {
+	raw := xmlRecord{Leader: r.Leader}
+	for _, f := range r.Fields {
+		if f.IsControl() {
+			raw.Control = append(raw.Control, xmlControl{Tag: f.Tag, Value: f.Value})
+			continue
+		}
+
+		d := xmlDataField{Tag: f.Tag, Ind1: string(indicator(f.Ind1)), Ind2: string(indicator(f.Ind2))}
+		for _, sf := range f.Subfields {
+			d.Subfields = append(d.Subfields, xmlSubfield{Code: string(sf.Code), Value: sf.Value})
+		}
+		raw.Data = append(raw.Data, d)
+	}
+
+	return raw
+}
+
+// Close ends the collection, an empty collection is still written
+func (x *XMLWriter) Close() error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	if err := x.e.EncodeToken(xml.EndElement{Name: xml.Name{Local: "collection"}}); err != nil {
+		return err
+	}
+	if err := x.e.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(x.w, "\n")
+	return err
+}
@@ -0,0 +1,83 @@
+// Package marc reads and writes bibliographic records in MARC21, both the
+// ISO 2709 exchange format and MARCXML, and maps them to catalog books.
+package marc
+
+import "strings"
+
+// Record is one MARC record. Control fields (001-009) only have a value,
+// data fields have indicators and subfields.
+type Record struct {
+	Leader string
+	Fields []Field
+}
+
+type Field struct {
+	Tag       string
+	Value     string // control fields only
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// IsControl tells control fields (00X) apart from data fields
+func (f Field) IsControl() bool {
+	return strings.HasPrefix(f.Tag, "00")
+}
+
+// Subfield returns the first subfield with the code, "" when missing
+func (f Field) Subfield(code byte) string {
+	for _, sf := range f.Subfields {
+		if sf.Code == code {
+			return sf.Value
+		}
+	}
+	return ""
+}
+
+// Get returns all fields with the tag, in record order
+func (r *Record) Get(tag string) []Field {
+	var fields []Field
+	for _, f := range r.Fields {
+		if f.Tag == tag {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Control returns the value of a control field, "" when missing
+func (r *Record) Control(tag string) string {
+	for _, f := range r.Fields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// AddControl appends a control field
+func (r *Record) AddControl(tag, value string) {
+	r.Fields = append(r.Fields, Field{Tag: tag, Value: value})
+}
+
+// AddData appends a data field, subfields are given as code and value pairs
+// and empty values are left out, e.g. AddData("245", '1', '0', 'a', title)
+func (r *Record) AddData(tag string, ind1, ind2 byte, subfields ...interface{}) {
+	field := Field{Tag: tag, Ind1: ind1, Ind2: ind2}
+	for i := 0; i+1 < len(subfields); i += 2 {
+		code, _ := subfields[i].(rune)
+		value, _ := subfields[i+1].(string)
+		if value == "" {
+			continue
+		}
+		field.Subfields = append(field.Subfields, Subfield{Code: byte(code), Value: value})
+	}
+	if len(field.Subfields) > 0 {
+		r.Fields = append(r.Fields, field)
+	}
+}
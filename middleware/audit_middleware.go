@@ -0,0 +1,494 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go-grpc/helpers"
+	"go-grpc/model"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gorm.io/gorm"
+)
+
+// auditEntity describes where the entity touched by a service lives, so the
+// row can be snapshotted before and after the call.
+type auditEntity struct {
+	Name    string
+	Table   string
+	Column  string // key column in Table, defaults to "id"
+	IDField string // request field holding the key, defaults to "id"
+}
+
+// Entities by service, "Service/Method" keys override the service for one method
+var auditEntities = map[string]auditEntity{
+	"AuthService":         {Name: "account"},
+	"BookService":         {Name: "book", Table: "books"},
+	"AuthorService":       {Name: "author", Table: "authors"},
+	"CategoryService":     {Name: "category", Table: "categories"},
+	"BookStockService":    {Name: "book_stock", Table: "book_stocks", Column: "book_id", IDField: "book_id"},
+	"BorrowingService":    {Name: "borrowing_transaction", Table: "borrowing_transactions"},
+	"ReturningService":    {Name: "borrowing_transaction", Table: "borrowing_transactions", IDField: "transaction_id"},
+	"WebhookService":      {Name: "webhook", Table: "webhook_endpoints"},
+	"NotificationService": {Name: "notification"},
+	"AccountService":      {Name: "borrower"},
+	"ReviewService":       {Name: "review", Table: "book_reviews"},
+	"ReadingListService":  {Name: "reading_list"},
+	"ReportService":       {Name: "report_schedule", Table: "report_schedules"},
+
+	"ReportService/RunReportSchedule":            {Name: "report_schedule", Table: "report_schedules", IDField: "schedule_id"},
+	"BorrowingService/PlaceHold":                 {Name: "hold", Table: "holds"},
+	"BorrowingService/CancelHold":                {Name: "hold", Table: "holds", IDField: "hold_id"},
+	"BorrowingService/RenewBorrowingTransaction": {Name: "borrowing_transaction", Table: "borrowing_transactions", IDField: "transaction_id"},
+	"ReturningService/PayFine":                   {Name: "returning_transaction", Table: "returning_transactions", Column: "borrowing_transaction_id", IDField: "transaction_id"},
+}
+
+// RPCs that change data and are written to the audit log, by "Service/Method".
+// Every RPC that is not a read belongs here, RunReport and ExportMarc only read.
+var auditedMethods = map[string]bool{
+	"AuthService/RegisterBorrower": true,
+	"AuthService/RegisterAdmin":    true,
+
+	"BookService/CreateBook":  true,
+	"BookService/ImportBooks": true,
+	"BookService/ImportMarc":  true,
+	"BookService/UpdateBook":  true,
+	"BookService/DeleteBook":  true,
+	"BookService/RestoreBook": true,
+	"BookService/PurgeBook":   true,
+
+	"AuthorService/CreateAuthor":  true,
+	"AuthorService/UpdateAuthor":  true,
+	"AuthorService/DeleteAuthor":  true,
+	"AuthorService/RestoreAuthor": true,
+	"AuthorService/PurgeAuthor":   true,
+
+	"CategoryService/CreateCategory":  true,
+	"CategoryService/UpdateCategory":  true,
+	"CategoryService/DeleteCategory":  true,
+	"CategoryService/RestoreCategory": true,
+	"CategoryService/PurgeCategory":   true,
+
+	"BookStockService/UpdateBookStock": true,
+	"BookStockService/AdjustStock":     true,
+
+	"BorrowingService/CreateBorrowingTransaction": true,
+	"BorrowingService/UpdateBorrowingTransaction": true,
+	"BorrowingService/RenewBorrowingTransaction":  true,
+	"BorrowingService/UpdatePrivacySettings":      true,
+	"BorrowingService/PlaceHold":                  true,
+	"BorrowingService/CancelHold":                 true,
+
+	"ReturningService/ReturnBook": true,
+	"ReturningService/PayFine":    true,
+
+	"WebhookService/RegisterWebhook":      true,
+	"WebhookService/DeleteWebhook":        true,
+	"WebhookService/TestWebhook":          true,
+	"WebhookService/RetryWebhookDelivery": true,
+
+	"NotificationService/UpdateNotificationPreferences": true,
+	"NotificationService/MarkRead":                      true,
+	"NotificationService/MarkAllRead":                   true,
+	"NotificationService/BroadcastAnnouncement":         true,
+
+	"ReportService/CreateReportSchedule": true,
+	"ReportService/UpdateReportSchedule": true,
+	"ReportService/DeleteReportSchedule": true,
+	"ReportService/RunReportSchedule":    true,
+
+	"AccountService/EraseAccount": true,
+
+	"ReviewService/PostReview":     true,
+	"ReviewService/DeleteReview":   true,
+	"ReviewService/ModerateReview": true,
+
+	"ReadingListService/CreateReadingList":     true,
+	"ReadingListService/UpdateReadingList":     true,
+	"ReadingListService/DeleteReadingList":     true,
+	"ReadingListService/AddReadingListItem":    true,
+	"ReadingListService/RemoveReadingListItem": true,
+	"ReadingListService/ReorderReadingList":    true,
+	"ReadingListService/ShareReadingList":      true,
+	"ReadingListService/HoldUnavailable":       true,
+}
+
+// Fields never written to the audit log
+var redactedFields = map[string]bool{"password": true, "token": true, "secret": true}
+
+// Columns that change on every write and only add noise to the diff
+var ignoredDiffColumns = map[string]bool{"updated_at": true}
+
+func AuditMiddleware(db *gorm.DB) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+
+		service, method := splitMethod(info.FullMethod)
+		if !auditedMethods[service+"/"+method] {
+			return handler(ctx, req)
+		}
+
+		entity := entityFor(service, method)
+
+		var entityID int64
+		var before map[string]interface{}
+		if entity.Table != "" {
+			entityID = messageID(req, entity.idField())
+			before = snapshot(db, entity, entityID)
+		}
+
+		resp, err := handler(ctx, req)
+
+		// Creates only know their id once the handler returned
+		if entityID == 0 {
+			entityID = responseID(resp)
+		}
+
+		var after map[string]interface{}
+		if entity.Table != "" {
+			after = snapshot(db, entity, entityID)
+		}
+
+		record(ctx, db, model.AuditEvent{
+			Method:      info.FullMethod,
+			Entity:      entity.Name,
+			EntityID:    entityID,
+			RequestData: jsonColumn(requestJSON(req)),
+			BeforeData:  jsonColumn(before),
+			AfterData:   jsonColumn(after),
+			Diff:        jsonColumn(diffSnapshots(before, after)),
+		}, err)
+
+		return resp, err
+	}
+}
+
+// AuditStreamMiddleware records client streaming calls that change data, such
+// as imports. The request data is the first message without its repeated and
+// bytes fields, and the number of messages received.
+func AuditStreamMiddleware(db *gorm.DB) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+
+		service, method := splitMethod(info.FullMethod)
+		if !auditedMethods[service+"/"+method] {
+			return handler(srv, ss)
+		}
+
+		stream := &auditedStream{ServerStream: ss}
+		err := handler(srv, stream)
+
+		request := requestJSON(stream.first)
+		if request == nil {
+			request = map[string]interface{}{}
+		}
+		request["messages"] = stream.received
+
+		record(ss.Context(), db, model.AuditEvent{
+			Method:      info.FullMethod,
+			Entity:      entityFor(service, method).Name,
+			RequestData: jsonColumn(request),
+		}, err)
+
+		return err
+	}
+}
+
+// auditedStream keeps a summary of the messages a client sent
+type auditedStream struct {
+	grpc.ServerStream
+	first    proto.Message
+	received int
+}
+
+func (s *auditedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	s.received++
+	if msg, ok := m.(proto.Message); ok && s.first == nil {
+		s.first = proto.Clone(msg)
+		r := s.first.ProtoReflect()
+		r.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			if fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.BytesKind {
+				r.Clear(fd)
+			}
+			return true
+		})
+	}
+	return nil
+}
+
+// record completes an audit event with the caller and outcome of the call and stores it
+func record(ctx context.Context, db *gorm.DB, event model.AuditEvent, err error) {
+	event.ClientIP = clientIP(ctx)
+	event.Status = status.Code(err).String()
+	event.CreatedAt = time.Now().Format(helpers.DateTimeLayout)
+
+	if userID, role, e := helpers.GetData(ctx); e == nil {
+		event.ActorID = int32(userID)
+		event.ActorRole = role
+	}
+
+	if err != nil {
+		event.Error = truncate(err.Error(), 1000)
+	}
+
+	// Auditing must never fail the call itself
+	if e := db.Create(&event).Error; e != nil {
+		log.Printf("audit: failed to record %v: %v", event.Method, e)
+	}
+}
+
+func entityFor(service, method string) auditEntity {
+	if entity, ok := auditEntities[service+"/"+method]; ok {
+		return entity
+	}
+	return auditEntities[service]
+}
+
+func (e auditEntity) idField() string {
+	if e.IDField == "" {
+		return "id"
+	}
+	return e.IDField
+}
+
+func (e auditEntity) column() string {
+	if e.Column == "" {
+		return "id"
+	}
+	return e.Column
+}
+
+// splitMethod turns "/go_grpc.BookService/UpdateBook" into ("BookService", "UpdateBook")
+func splitMethod(fullMethod string) (string, string) {
+	parts := strings.Split(strings.TrimPrefix(fullMethod, "/"), "/")
+	if len(parts) != 2 {
+		return "", fullMethod
+	}
+
+	service := parts[0]
+	if i := strings.LastIndex(service, "."); i >= 0 {
+		service = service[i+1:]
+	}
+
+	return service, parts[1]
+}
+
+// messageID reads an integer field by name from a request message
+func messageID(msg interface{}, field string) int64 {
+	m, ok := msg.(proto.Message)
+	if !ok || m == nil {
+		return 0
+	}
+
+	r := m.ProtoReflect()
+	if !r.IsValid() {
+		return 0
+	}
+
+	fd := r.Descriptor().Fields().ByName(protoreflect.Name(field))
+	if fd == nil {
+		return 0
+	}
+
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+		return r.Get(fd).Int()
+	case protoreflect.Uint32Kind, protoreflect.Uint64Kind:
+		return int64(r.Get(fd).Uint())
+	}
+
+	return 0
+}
+
+// responseID reads data.id from a response, used for created entities
+func responseID(resp interface{}) int64 {
+	m, ok := resp.(proto.Message)
+	if !ok || m == nil || !m.ProtoReflect().IsValid() {
+		return 0
+	}
+
+	r := m.ProtoReflect()
+	fd := r.Descriptor().Fields().ByName("data")
+	if fd == nil || fd.Kind() != protoreflect.MessageKind || fd.IsList() || !r.Has(fd) {
+		return 0
+	}
+
+	return messageID(r.Get(fd).Message().Interface(), "id")
+}
+
+func snapshot(db *gorm.DB, entity auditEntity, id int64) map[string]interface{} {
+	if id == 0 {
+		return nil
+	}
+
+	row := map[string]interface{}{}
+	if err := db.Table(entity.Table).Where(entity.column()+" = ?", id).Take(&row).Error; err != nil {
+		return nil
+	}
+
+	for k, v := range row {
+		if b, ok := v.([]byte); ok {
+			row[k] = string(b)
+		}
+		if redactedFields[k] {
+			row[k] = "[REDACTED]"
+		}
+	}
+
+	return row
+}
+
+func diffSnapshots(before, after map[string]interface{}) map[string]interface{} {
+	if before == nil && after == nil {
+		return nil
+	}
+
+	diff := map[string]interface{}{}
+	for k, v := range after {
+		if ignoredDiffColumns[k] {
+			continue
+		}
+		old, ok := before[k]
+		if !ok || fmt.Sprint(old) != fmt.Sprint(v) {
+			diff[k] = map[string]interface{}{"before": old, "after": v}
+		}
+	}
+	for k, v := range before {
+		if _, ok := after[k]; !ok && !ignoredDiffColumns[k] {
+			diff[k] = map[string]interface{}{"before": v, "after": nil}
+		}
+	}
+
+	return diff
+}
+
+func requestJSON(req interface{}) map[string]interface{} {
+	m, ok := req.(proto.Message)
+	if !ok || m == nil {
+		return nil
+	}
+
+	raw, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil
+	}
+
+	redact(data)
+	return data
+}
+
+func redact(data map[string]interface{}) {
+	for k, v := range data {
+		if redactedFields[k] {
+			data[k] = "[REDACTED]"
+			continue
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			redact(nested)
+		}
+	}
+}
+
+func jsonColumn(data map[string]interface{}) sql.NullString {
+	if data == nil {
+		return sql.NullString{}
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: string(raw), Valid: true}
+}
+
+// gatewayAddrs holds the local addresses of the connections dialed by the
+// in-process gateway, the only callers trusted to forward a client address
+var gatewayAddrs sync.Map
+
+// GatewayDialer dials the gRPC server for the in-process gateway, calls on
+// its connections are audited with the address the gateway forwards
+func GatewayDialer(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	local := conn.LocalAddr().String()
+	gatewayAddrs.Store(local, true)
+	return &gatewayConn{Conn: conn, local: local}, nil
+}
+
+type gatewayConn struct {
+	net.Conn
+	local string
+	once  sync.Once
+}
+
+func (c *gatewayConn) Close() error {
+	c.once.Do(func() { gatewayAddrs.Delete(c.local) })
+	return c.Conn.Close()
+}
+
+// clientIP is the peer address. For calls from the gateway it is the last
+// x-forwarded-for entry, the address the gateway itself saw, earlier entries
+// come from the HTTP client and are not trusted.
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	if _, trusted := gatewayAddrs.Load(p.Addr.String()); trusted {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if forwarded := md.Get("x-forwarded-for"); len(forwarded) > 0 {
+				hops := strings.Split(forwarded[len(forwarded)-1], ",")
+				return strings.TrimSpace(hops[len(hops)-1])
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
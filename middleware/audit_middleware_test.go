@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"go-grpc/gateway"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func callFrom(addr net.Addr, forwarded string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", forwarded))
+}
+
+func TestClientIPIgnoresForwardedFromClients(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 40000}
+
+	if got := clientIP(callFrom(addr, "10.0.0.1")); got != "203.0.113.7" {
+		t.Errorf("clientIP = %q, want the peer address", got)
+	}
+}
+
+func TestClientIPFromGateway(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	conn, err := GatewayDialer(context.Background(), listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	// The server sees the gateway's local address as the peer
+	ctx := callFrom(server.RemoteAddr(), "10.0.0.1, 198.51.100.4")
+	if got := clientIP(ctx); got != "198.51.100.4" {
+		t.Errorf("clientIP = %q, want the address the gateway saw", got)
+	}
+
+	conn.Close()
+	if got := clientIP(ctx); got != "127.0.0.1" {
+		t.Errorf("clientIP after the gateway connection closed = %q, want the peer address", got)
+	}
+}
+
+// readOnlyPosts are RPCs routed with POST that change nothing worth auditing
+var readOnlyPosts = map[string]bool{"AuthService/Login": true}
+
+func TestAuditedMethodsCoverRoutes(t *testing.T) {
+	routed := map[string]bool{}
+
+	for _, route := range gateway.Routes {
+		method := route.Service + "/" + route.RPC
+		routed[method] = true
+
+		mutating := route.Method != "GET" && !readOnlyPosts[method]
+		if got := auditedMethods[method]; got != mutating {
+			t.Errorf("%s %s: audited = %v, want %v", route.Method, method, got, mutating)
+		}
+	}
+
+	for method := range auditedMethods {
+		if !routed[method] {
+			t.Errorf("audited method %s is not an RPC of the gateway routes", method)
+		}
+	}
+}
+
+func TestEntityFor(t *testing.T) {
+	for method, want := range map[string]auditEntity{
+		"BookService/UpdateBook":                      {Name: "book", Table: "books"},
+		"BorrowingService/UpdateBorrowingTransaction": {Name: "borrowing_transaction", Table: "borrowing_transactions"},
+		"BorrowingService/PlaceHold":                  {Name: "hold", Table: "holds"},
+		"BorrowingService/CancelHold":                 {Name: "hold", Table: "holds", IDField: "hold_id"},
+		"BorrowingService/RenewBorrowingTransaction":  {Name: "borrowing_transaction", Table: "borrowing_transactions", IDField: "transaction_id"},
+		"ReturningService/PayFine":                    {Name: "returning_transaction", Table: "returning_transactions", Column: "borrowing_transaction_id", IDField: "transaction_id"},
+		"ReturningService/ReturnBook":                 {Name: "borrowing_transaction", Table: "borrowing_transactions", IDField: "transaction_id"},
+		"ReportService/RunReportSchedule":             {Name: "report_schedule", Table: "report_schedules", IDField: "schedule_id"},
+	} {
+		service, name, _ := strings.Cut(method, "/")
+		if got := entityFor(service, name); got != want {
+			t.Errorf("entityFor(%q) = %+v, want %+v", method, got, want)
+		}
+	}
+}
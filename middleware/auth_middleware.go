@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
@@ -15,6 +16,11 @@ import (
 
 var jwtSecretKey = []byte("your-secret-key")
 
+// Methods outside of AuthService that are served without a token
+var publicMethods = map[string]bool{
+	"/go_grpc.ReadingListService/GetSharedReadingList": true,
+}
+
 func JWTMiddleware(db *gorm.DB) grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context,
@@ -23,33 +29,77 @@ func JWTMiddleware(db *gorm.DB) grpc.UnaryServerInterceptor {
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
 
-		// Bypass JWT middleware for AuthService methods
-		if strings.Contains(info.FullMethod, "AuthService") {
-			return handler(ctx, req)
+		ctx, err := authenticate(ctx, db, info.FullMethod)
+		if err != nil {
+			return nil, err
 		}
 
-		// Implement JWT verification for other services
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Errorf(http.StatusUnauthorized, "missing metadata")
-		}
+		return handler(ctx, req)
+	}
+}
 
-		authHeader, exists := md["authorization"]
-		if !exists || len(authHeader) == 0 {
-			return nil, status.Errorf(http.StatusUnauthorized, "authorization token is not supplied")
-		}
+func JWTStreamMiddleware(db *gorm.DB) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
 
-		tokenStr := strings.TrimPrefix(authHeader[0], "Bearer ")
-		userId, role, err := helpers.ValidateToken(tokenStr)
+		ctx, err := authenticate(ss.Context(), db, info.FullMethod)
 		if err != nil {
-			return nil, status.Errorf(http.StatusUnauthorized, "invalid token")
+			return err
 		}
-		us := *userId
-		r := *role
-		// Set values into context
-		ctx = context.WithValue(ctx, "userID", us)
-		ctx = context.WithValue(ctx, "role", r)
 
-		return handler(ctx, req)
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
 	}
 }
+
+// authenticatedStream carries the context with the user data set by authenticate
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func authenticate(ctx context.Context, db *gorm.DB, fullMethod string) (context.Context, error) {
+
+	// Bypass JWT middleware for AuthService methods
+	if strings.Contains(fullMethod, "AuthService") || publicMethods[fullMethod] {
+		return ctx, nil
+	}
+
+	// Implement JWT verification for other services
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Errorf(http.StatusUnauthorized, "missing metadata")
+	}
+
+	authHeader, exists := md["authorization"]
+	if !exists || len(authHeader) == 0 {
+		return nil, status.Errorf(http.StatusUnauthorized, "authorization token is not supplied")
+	}
+
+	tokenStr := strings.TrimPrefix(authHeader[0], "Bearer ")
+	userId, role, err := helpers.ValidateToken(tokenStr)
+	if err != nil {
+		return nil, status.Errorf(http.StatusUnauthorized, "invalid token")
+	}
+
+	// A token outlives the erasure of its account, it must not act for the erased borrower
+	if *role == "borrower" {
+		var active int64
+		if err := db.WithContext(ctx).Table("borrowers").Where("id = ? AND erased_at IS NULL", *userId).Count(&active).Error; err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if active == 0 {
+			return nil, status.Errorf(http.StatusUnauthorized, "account no longer exists")
+		}
+	}
+
+	// Set values into context
+	return helpers.WithUser(ctx, *userId, *role), nil
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"go-grpc/helpers"
+
+	"google.golang.org/grpc/metadata"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func withToken(t *testing.T, userID int, role string) context.Context {
+	t.Helper()
+
+	token, err := helpers.GenerateToken(userID, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestAuthenticateRejectsErasedBorrower(t *testing.T) {
+	db := borrowers{1: false, 2: true}.db(t)
+	method := "/go_grpc.ReadingListService/CreateReadingList"
+
+	ctx, err := authenticate(withToken(t, 1, "borrower"), db, method)
+	if err != nil {
+		t.Fatalf("active borrower: %v", err)
+	}
+	if id, role, err := helpers.GetData(ctx); err != nil || id != 1 || role != "borrower" {
+		t.Errorf("GetData = %d, %q, %v", id, role, err)
+	}
+
+	if _, err := authenticate(withToken(t, 2, "borrower"), db, method); err == nil {
+		t.Error("erased borrower was authenticated with a token issued before the erasure")
+	}
+	if _, err := authenticate(withToken(t, 3, "borrower"), db, method); err == nil {
+		t.Error("unknown borrower was authenticated")
+	}
+
+	// Admins are not borrowers, their id is not looked up there
+	if _, err := authenticate(withToken(t, 2, "admin"), db, method); err != nil {
+		t.Errorf("admin: %v", err)
+	}
+}
+
+// borrowers answers whether a borrower exists and is not erased, by id
+type borrowers map[int64]bool
+
+func (b borrowers) db(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(b), SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// Connect and Driver make the borrowers a driver.Connector
+func (b borrowers) Connect(context.Context) (driver.Conn, error) { return testConn{borrowers: b}, nil }
+func (b borrowers) Driver() driver.Driver                        { return nil }
+
+type testConn struct{ borrowers borrowers }
+
+func (c testConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.Contains(query, "SELECT count(*) FROM `borrowers` WHERE id = ? AND erased_at IS NULL") {
+		return nil, fmt.Errorf("unexpected query %q", query)
+	}
+
+	var count int64
+	if erased, ok := c.borrowers[args[0].Value.(int64)]; ok && !erased {
+		count = 1
+	}
+	return &testRows{count: count}, nil
+}
+
+func (c testConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported: %q", query)
+}
+func (c testConn) Close() error { return nil }
+func (c testConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported")
+}
+
+type testRows struct {
+	count int64
+	done  bool
+}
+
+func (r *testRows) Columns() []string { return []string{"count(*)"} }
+func (r *testRows) Close() error      { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = r.count
+	r.done = true
+	return nil
+}
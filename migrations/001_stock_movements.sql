@@ -0,0 +1,22 @@
+--
+-- Inventory movement ledger for book_stocks.
+-- Every stock change (manual adjustment, borrow, return) is written here
+-- in the same transaction that updates book_stocks.
+--
+
+ALTER TABLE `book_stocks` ADD UNIQUE KEY `book_stocks_book_id_unique` (`book_id`);
+
+CREATE TABLE `stock_movements` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `book_id` int NOT NULL,
+  `delta` int NOT NULL,
+  `reason` varchar(20) NOT NULL,
+  `note` varchar(255) DEFAULT NULL,
+  `stock_after` int NOT NULL,
+  `actor_id` int DEFAULT NULL,
+  `actor_role` varchar(20) DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `book_id` (`book_id`),
+  CONSTRAINT `stock_movements_ibfk_1` FOREIGN KEY (`book_id`) REFERENCES `books` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
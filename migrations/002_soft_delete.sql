@@ -0,0 +1,12 @@
+--
+-- Soft delete for catalog entities.
+-- Rows are hidden by setting deleted_at; only the admin purge removes them,
+-- and loan history can no longer be wiped by deleting a book.
+--
+
+ALTER TABLE `books` ADD COLUMN `deleted_at` timestamp NULL DEFAULT NULL, ADD KEY `books_deleted_at` (`deleted_at`);
+ALTER TABLE `authors` ADD COLUMN `deleted_at` timestamp NULL DEFAULT NULL, ADD KEY `authors_deleted_at` (`deleted_at`);
+ALTER TABLE `categories` ADD COLUMN `deleted_at` timestamp NULL DEFAULT NULL, ADD KEY `categories_deleted_at` (`deleted_at`);
+
+ALTER TABLE `borrowing_transactions` DROP FOREIGN KEY `borrowing_transactions_ibfk_2`;
+ALTER TABLE `borrowing_transactions` ADD CONSTRAINT `borrowing_transactions_ibfk_2` FOREIGN KEY (`book_id`) REFERENCES `books` (`id`) ON DELETE RESTRICT;
@@ -0,0 +1,24 @@
+--
+-- Audit log of every mutating RPC, written by middleware.AuditMiddleware.
+--
+
+CREATE TABLE `audit_events` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `actor_id` int DEFAULT NULL,
+  `actor_role` varchar(20) DEFAULT NULL,
+  `method` varchar(255) NOT NULL,
+  `entity` varchar(50) DEFAULT NULL,
+  `entity_id` bigint DEFAULT NULL,
+  `request_data` json DEFAULT NULL,
+  `before_data` json DEFAULT NULL,
+  `after_data` json DEFAULT NULL,
+  `diff` json DEFAULT NULL,
+  `client_ip` varchar(64) DEFAULT NULL,
+  `status` varchar(50) NOT NULL,
+  `error` varchar(1000) DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `audit_events_actor` (`actor_role`, `actor_id`),
+  KEY `audit_events_entity` (`entity`, `entity_id`),
+  KEY `audit_events_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,18 @@
+--
+-- Circulation events pushed to BorrowingService.WatchCirculation subscribers.
+-- Rows are written in the same transaction as the change they describe, the
+-- id doubles as the resume position for clients.
+--
+
+CREATE TABLE `circulation_events` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `type` varchar(50) NOT NULL,
+  `book_id` int DEFAULT NULL,
+  `borrower_id` int DEFAULT NULL,
+  `transaction_id` int DEFAULT NULL,
+  `message` varchar(255) DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `circulation_events_book_id` (`book_id`),
+  KEY `circulation_events_borrower_id` (`borrower_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,47 @@
+--
+-- Transactional outbox and webhook delivery.
+-- outbox_events are written in the same transaction as loans and returns,
+-- webhook.Dispatcher fans them out to webhook_endpoints as webhook_deliveries.
+--
+
+ALTER TABLE `returning_transactions` ADD COLUMN `fine_amount` decimal(10,2) NOT NULL DEFAULT '0.00' AFTER `returned_at`;
+
+CREATE TABLE `outbox_events` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `event_type` varchar(50) NOT NULL,
+  `payload` json NOT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `dispatched_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `outbox_events_dispatched_at` (`dispatched_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE `webhook_endpoints` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `url` varchar(500) NOT NULL,
+  `secret` varchar(100) NOT NULL,
+  `event_types` varchar(500) NOT NULL DEFAULT '',
+  `description` varchar(255) DEFAULT NULL,
+  `active` tinyint(1) NOT NULL DEFAULT '1',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE `webhook_deliveries` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `outbox_event_id` bigint NOT NULL,
+  `endpoint_id` int NOT NULL,
+  `status` varchar(20) NOT NULL DEFAULT 'pending',
+  `attempts` int NOT NULL DEFAULT '0',
+  `next_attempt_at` timestamp NULL DEFAULT NULL,
+  `response_status` int DEFAULT NULL,
+  `last_error` varchar(1000) DEFAULT NULL,
+  `delivered_at` timestamp NULL DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `webhook_deliveries_event_endpoint` (`outbox_event_id`, `endpoint_id`),
+  KEY `webhook_deliveries_due` (`status`, `next_attempt_at`),
+  CONSTRAINT `webhook_deliveries_ibfk_1` FOREIGN KEY (`outbox_event_id`) REFERENCES `outbox_events` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `webhook_deliveries_ibfk_2` FOREIGN KEY (`endpoint_id`) REFERENCES `webhook_endpoints` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,44 @@
+--
+-- Due-date reminders and overdue notices.
+-- notification_logs has one row per (loan, notice, channel) so a notice is
+-- never sent twice; notifications is the in-app inbox.
+--
+
+CREATE TABLE `notification_preferences` (
+  `borrower_id` int NOT NULL,
+  `email_enabled` tinyint(1) NOT NULL DEFAULT '1',
+  `sms_enabled` tinyint(1) NOT NULL DEFAULT '0',
+  `inapp_enabled` tinyint(1) NOT NULL DEFAULT '1',
+  `phone` varchar(30) DEFAULT NULL,
+  `reminder_days_before` int NOT NULL DEFAULT '3',
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`borrower_id`),
+  CONSTRAINT `notification_preferences_ibfk_1` FOREIGN KEY (`borrower_id`) REFERENCES `borrowers` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE `notifications` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `borrower_id` int NOT NULL,
+  `kind` varchar(50) NOT NULL,
+  `title` varchar(255) NOT NULL,
+  `body` text,
+  `transaction_id` int DEFAULT NULL,
+  `read_at` timestamp NULL DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `notifications_borrower_read` (`borrower_id`, `read_at`),
+  CONSTRAINT `notifications_ibfk_1` FOREIGN KEY (`borrower_id`) REFERENCES `borrowers` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE `notification_logs` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `transaction_id` int NOT NULL,
+  `borrower_id` int NOT NULL,
+  `kind` varchar(50) NOT NULL,
+  `channel` varchar(20) NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `sent_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `notification_logs_once` (`transaction_id`, `kind`, `channel`),
+  CONSTRAINT `notification_logs_ibfk_1` FOREIGN KEY (`transaction_id`) REFERENCES `borrowing_transactions` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
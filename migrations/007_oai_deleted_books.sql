@@ -0,0 +1,15 @@
+--
+-- OAI-PMH harvesting.
+-- books.updated_at is the record datestamp; purged books leave a row in
+-- deleted_books so harvesters learn about the deletion (deletedRecord=persistent).
+--
+
+ALTER TABLE `books` ADD KEY `books_updated_at` (`updated_at`);
+
+CREATE TABLE `deleted_books` (
+  `book_id` int NOT NULL,
+  `category_id` int DEFAULT NULL,
+  `deleted_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`book_id`),
+  KEY `deleted_books_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,6 @@
+--
+-- Loan renewals.
+-- Borrowers may renew a loan a limited number of times, e.g. at a SIP2 kiosk.
+--
+
+ALTER TABLE `borrowing_transactions` ADD COLUMN `renewals` int NOT NULL DEFAULT '0';
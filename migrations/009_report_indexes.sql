@@ -0,0 +1,6 @@
+--
+-- Reports.
+-- Circulation reports select loans by the time they were borrowed.
+--
+
+ALTER TABLE `borrowing_transactions` ADD KEY `borrowing_transactions_borrowed_at` (`borrowed_at`);
@@ -0,0 +1,45 @@
+--
+-- Scheduled reports.
+-- report.Scheduler queues a report_run when a schedule's next_run_at has passed
+-- and delivers the file by email or into REPORT_DIR. Manual re-runs of a past
+-- period are queued the same way by RunReportSchedule.
+--
+
+CREATE TABLE `report_schedules` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) NOT NULL,
+  `report` varchar(50) NOT NULL,
+  `category_id` int NOT NULL DEFAULT '0',
+  `row_limit` int NOT NULL DEFAULT '0',
+  `format` varchar(10) NOT NULL DEFAULT 'csv',
+  `cron` varchar(100) NOT NULL,
+  `delivery` varchar(20) NOT NULL,
+  `email` varchar(255) DEFAULT NULL,
+  `alert_email` varchar(255) DEFAULT NULL,
+  `active` tinyint(1) NOT NULL DEFAULT '1',
+  `next_run_at` timestamp NULL DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `report_schedules_due` (`active`, `next_run_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE `report_runs` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `schedule_id` int NOT NULL,
+  `period_start` timestamp NOT NULL,
+  `period_end` timestamp NOT NULL,
+  `triggered_by` varchar(20) NOT NULL DEFAULT 'schedule',
+  `status` varchar(20) NOT NULL DEFAULT 'pending',
+  `row_count` int NOT NULL DEFAULT '0',
+  `filename` varchar(255) DEFAULT NULL,
+  `location` varchar(500) DEFAULT NULL,
+  `error` varchar(1000) DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `started_at` timestamp NULL DEFAULT NULL,
+  `finished_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `report_runs_schedule` (`schedule_id`, `id`),
+  KEY `report_runs_status` (`status`),
+  CONSTRAINT `report_runs_ibfk_1` FOREIGN KEY (`schedule_id`) REFERENCES `report_schedules` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
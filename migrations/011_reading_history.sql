@@ -0,0 +1,20 @@
+--
+-- Reading history and privacy.
+-- Borrowers who choose to anonymize their history have their returned loans
+-- detached by worker.HistoryAnonymizer once the retention window has passed:
+-- borrower_id becomes NULL while the loan, its return and fine stay, so loan
+-- counts per book, category and month are unchanged. circulation_events and
+-- outbox payloads of those loans lose the borrower too, reminders about them
+-- are deleted. The audit log is kept as it is.
+--
+
+ALTER TABLE `borrowing_transactions` MODIFY `borrower_id` int DEFAULT NULL;
+ALTER TABLE `borrowing_transactions` ADD KEY `borrowing_transactions_returned_at` (`returned_at`);
+
+CREATE TABLE `privacy_settings` (
+  `borrower_id` int NOT NULL,
+  `reading_history` varchar(10) NOT NULL DEFAULT 'retain',
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`borrower_id`),
+  CONSTRAINT `privacy_settings_ibfk_1` FOREIGN KEY (`borrower_id`) REFERENCES `borrowers` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,15 @@
+--
+-- Data subject requests.
+-- AccountService.EraseAccount keeps the borrowers row without personal data
+-- (erased_at is set) and detaches the loans like the history anonymizer, so
+-- circulation statistics survive. Deleting a borrower by hand no longer wipes
+-- their loans either, they lose the borrower instead.
+-- Fines count as open until ReturningService.PayFine sets fine_paid_at.
+--
+
+ALTER TABLE `borrowers` ADD COLUMN `erased_at` timestamp NULL DEFAULT NULL;
+
+ALTER TABLE `borrowing_transactions` DROP FOREIGN KEY `borrowing_transactions_ibfk_1`;
+ALTER TABLE `borrowing_transactions` ADD CONSTRAINT `borrowing_transactions_ibfk_1` FOREIGN KEY (`borrower_id`) REFERENCES `borrowers` (`id`) ON DELETE SET NULL;
+
+ALTER TABLE `returning_transactions` ADD COLUMN `fine_paid_at` timestamp NULL DEFAULT NULL AFTER `fine_amount`;
@@ -0,0 +1,30 @@
+--
+-- Book ratings and reviews.
+-- A borrower reviews a book once, and only after returning a loan of it.
+-- Reviews with text wait in the moderation queue as 'pending' until an admin
+-- approves or hides them; a bare rating is approved right away. Only approved
+-- reviews count towards the average rating of a book. Erasing an account
+-- deletes its reviews.
+--
+
+CREATE TABLE `book_reviews` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `book_id` int NOT NULL,
+  `borrower_id` int NOT NULL,
+  `rating` tinyint NOT NULL,
+  `body` text,
+  `status` varchar(10) NOT NULL DEFAULT 'pending',
+  `moderation_note` varchar(255) DEFAULT NULL,
+  `moderated_by` int DEFAULT NULL,
+  `moderated_at` timestamp NULL DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `book_reviews_book_borrower` (`book_id`, `borrower_id`),
+  KEY `book_reviews_book_status` (`book_id`, `status`),
+  KEY `book_reviews_status` (`status`, `id`),
+  KEY `borrower_id` (`borrower_id`),
+  CONSTRAINT `book_reviews_ibfk_1` FOREIGN KEY (`book_id`) REFERENCES `books` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `book_reviews_ibfk_2` FOREIGN KEY (`borrower_id`) REFERENCES `borrowers` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `book_reviews_rating` CHECK (`rating` BETWEEN 1 AND 5)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,52 @@
+--
+-- Reading lists and holds.
+-- Borrowers keep named lists of books; a list can be shared read-only with
+-- the link of its share_token. HoldUnavailable places a hold on every book of
+-- a list without a free copy.
+-- A hold waits until a copy comes back (a return or a stock adjustment), it is
+-- then 'ready' and the copy is set aside: other borrowers cannot check it out
+-- and loans of a book with waiting holds cannot be renewed. Checking the book
+-- out fulfills the hold.
+--
+
+CREATE TABLE `reading_lists` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `borrower_id` int NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `description` varchar(500) DEFAULT NULL,
+  `share_token` varchar(64) DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `reading_lists_borrower_name` (`borrower_id`, `name`),
+  UNIQUE KEY `reading_lists_share_token` (`share_token`),
+  CONSTRAINT `reading_lists_ibfk_1` FOREIGN KEY (`borrower_id`) REFERENCES `borrowers` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE `reading_list_items` (
+  `list_id` int NOT NULL,
+  `book_id` int NOT NULL,
+  `position` int NOT NULL,
+  `note` varchar(255) DEFAULT NULL,
+  `added_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`list_id`, `book_id`),
+  KEY `reading_list_items_position` (`list_id`, `position`),
+  KEY `book_id` (`book_id`),
+  CONSTRAINT `reading_list_items_ibfk_1` FOREIGN KEY (`list_id`) REFERENCES `reading_lists` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `reading_list_items_ibfk_2` FOREIGN KEY (`book_id`) REFERENCES `books` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE `holds` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `book_id` int NOT NULL,
+  `borrower_id` int NOT NULL,
+  `status` varchar(10) NOT NULL DEFAULT 'waiting',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `ready_at` timestamp NULL DEFAULT NULL,
+  `closed_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `holds_book_status` (`book_id`, `status`, `id`),
+  KEY `holds_borrower_status` (`borrower_id`, `status`),
+  CONSTRAINT `holds_ibfk_1` FOREIGN KEY (`book_id`) REFERENCES `books` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `holds_ibfk_2` FOREIGN KEY (`borrower_id`) REFERENCES `borrowers` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
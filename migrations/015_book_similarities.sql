@@ -0,0 +1,20 @@
+--
+-- Book similarities for recommendations.
+-- Rebuilt periodically from borrowing_transactions: two books are similar when
+-- the same borrowers borrowed both, scored by the cosine of their borrower sets.
+-- Every pair is stored in both directions and only the closest neighbours of a
+-- book are kept. Anonymized loans have no borrower and do not count.
+--
+
+CREATE TABLE `book_similarities` (
+  `book_id` int NOT NULL,
+  `similar_book_id` int NOT NULL,
+  `score` double NOT NULL,
+  `co_borrowers` int NOT NULL,
+  `computed_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`book_id`, `similar_book_id`),
+  KEY `book_similarities_score` (`book_id`, `score`),
+  KEY `similar_book_id` (`similar_book_id`),
+  CONSTRAINT `book_similarities_ibfk_1` FOREIGN KEY (`book_id`) REFERENCES `books` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `book_similarities_ibfk_2` FOREIGN KEY (`similar_book_id`) REFERENCES `books` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
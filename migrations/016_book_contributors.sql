@@ -0,0 +1,22 @@
+--
+-- Books with several contributors.
+-- A book credits authors, editors, translators and illustrators in order.
+-- books.author_id stays and is kept to the first author, so the Author of a
+-- book and older clients keep working. Existing books get their author as
+-- the only contributor.
+--
+
+CREATE TABLE `book_contributors` (
+  `book_id` int NOT NULL,
+  `author_id` int NOT NULL,
+  `role` varchar(20) NOT NULL DEFAULT 'author',
+  `position` int NOT NULL,
+  PRIMARY KEY (`book_id`, `author_id`, `role`),
+  KEY `book_contributors_position` (`book_id`, `position`),
+  KEY `book_contributors_author` (`author_id`, `role`),
+  CONSTRAINT `book_contributors_ibfk_1` FOREIGN KEY (`book_id`) REFERENCES `books` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `book_contributors_ibfk_2` FOREIGN KEY (`author_id`) REFERENCES `authors` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+INSERT INTO `book_contributors` (`book_id`, `author_id`, `role`, `position`)
+SELECT `id`, `author_id`, 'author', 1 FROM `books`;
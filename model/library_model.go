@@ -6,9 +6,10 @@ import (
 )
 
 type Borrower struct {
-	ID    int32  `gorm:"primaryKey"`
-	Name  string `gorm:"size:255"`
-	Email string `gorm:"size:255;unique"`
+	ID       int32  `gorm:"primaryKey"`
+	Name     string `gorm:"size:255"`
+	Email    string `gorm:"size:255;unique"`
+	ErasedAt sql.NullString
 }
 
 type BorrowingTransaction struct {
@@ -21,6 +22,7 @@ type BorrowingTransaction struct {
 	DueDate    string
 	ReturnedAt sql.NullString // Nullable, use pointer for nullable fields
 	Status     string         `gorm:"size:50"`
+	Renewals   int32
 }
 
 type Book struct {
@@ -33,18 +35,21 @@ type Book struct {
 	ISBN            string   `gorm:"size:13;unique;not null"`
 	PublicationYear int32    `gorm:"not null"`
 	Description     string   `gorm:"size:1000"`
+	DeletedAt       sql.NullString
 }
 
 type Category struct {
 	ID          int32  `gorm:"primaryKey"`
 	Name        string `gorm:"size:255;not null"`
 	Description string `gorm:"size:255"`
+	DeletedAt   sql.NullString
 }
 
 type Author struct {
-	ID   int32  `gorm:"primaryKey"`
-	Name string `gorm:"size:255;not null"`
-	Bio  string `gorm:"size:500"`
+	ID        int32  `gorm:"primaryKey"`
+	Name      string `gorm:"size:255;not null"`
+	Bio       string `gorm:"size:500"`
+	DeletedAt sql.NullString
 }
 
 type ReturningTransaction struct {
@@ -52,6 +57,8 @@ type ReturningTransaction struct {
 	BorrowingTransactionID int32                // Foreign key for BorrowingTransaction
 	BorrowingTransaction   BorrowingTransaction `gorm:"foreignKey:BorrowingTransactionID"` // Specifies the foreign key relationship
 	ReturnedAt             time.Time            `gorm:"not null"`
+	FineAmount             float64
+	FinePaidAt             sql.NullString
 }
 
 type BookStock struct {
@@ -59,3 +66,197 @@ type BookStock struct {
 	BookID     int `gorm:"index" json:"book_id"`
 	TotalStock int `gorm:"not null" json:"total_stock"`
 }
+
+type StockMovement struct {
+	ID         int32  `gorm:"primaryKey"`
+	BookID     int32  `gorm:"index"`
+	Delta      int32  `gorm:"not null"`
+	Reason     string `gorm:"size:20;not null"`
+	Note       string `gorm:"size:255"`
+	StockAfter int32  `gorm:"not null"`
+	ActorID    int32
+	ActorRole  string `gorm:"size:20"`
+	CreatedAt  string
+}
+
+type AuditEvent struct {
+	ID          int64 `gorm:"primaryKey"`
+	ActorID     int32
+	ActorRole   string `gorm:"size:20"`
+	Method      string `gorm:"size:255;not null"`
+	Entity      string `gorm:"size:50"`
+	EntityID    int64
+	RequestData sql.NullString
+	BeforeData  sql.NullString
+	AfterData   sql.NullString
+	Diff        sql.NullString
+	ClientIP    string `gorm:"column:client_ip;size:64"`
+	Status      string `gorm:"size:50;not null"`
+	Error       string `gorm:"size:1000"`
+	CreatedAt   string
+}
+
+type CirculationEvent struct {
+	ID            int64  `gorm:"primaryKey"`
+	Type          string `gorm:"size:50;not null"`
+	BookID        int32
+	BorrowerID    int32
+	TransactionID int32
+	Message       string `gorm:"size:255"`
+	CreatedAt     string
+}
+
+type OutboxEvent struct {
+	ID           int64  `gorm:"primaryKey"`
+	EventType    string `gorm:"size:50;not null"`
+	Payload      string `gorm:"type:json;not null"`
+	CreatedAt    string
+	DispatchedAt sql.NullString
+}
+
+type WebhookEndpoint struct {
+	ID          int32  `gorm:"primaryKey"`
+	URL         string `gorm:"column:url;size:500;not null"`
+	Secret      string `gorm:"size:100;not null"`
+	EventTypes  string `gorm:"size:500"` // comma separated, empty for all events
+	Description string `gorm:"size:255"`
+	Active      bool
+	CreatedAt   string
+}
+
+type WebhookDelivery struct {
+	ID             int64  `gorm:"primaryKey"`
+	OutboxEventID  int64  `gorm:"not null"`
+	EndpointID     int32  `gorm:"not null"`
+	Status         string `gorm:"size:20"` // 'pending', 'delivered', 'dead'
+	Attempts       int32
+	NextAttemptAt  sql.NullString
+	ResponseStatus sql.NullInt32
+	LastError      sql.NullString
+	DeliveredAt    sql.NullString
+	CreatedAt      string
+}
+
+type NotificationPreference struct {
+	BorrowerID         int32 `gorm:"primaryKey;autoIncrement:false"`
+	EmailEnabled       bool
+	SmsEnabled         bool
+	InappEnabled       bool
+	Phone              string `gorm:"size:30"`
+	ReminderDaysBefore int32
+}
+
+type Notification struct {
+	ID            int64  `gorm:"primaryKey"`
+	BorrowerID    int32  `gorm:"index"`
+	Kind          string `gorm:"size:50;not null"`
+	Title         string `gorm:"size:255;not null"`
+	Body          string
+	TransactionID sql.NullInt32
+	ReadAt        sql.NullString
+	CreatedAt     string
+}
+
+type NotificationLog struct {
+	ID            int64  `gorm:"primaryKey"`
+	TransactionID int32  `gorm:"not null"`
+	BorrowerID    int32  `gorm:"not null"`
+	Kind          string `gorm:"size:50;not null"`
+	Channel       string `gorm:"size:20;not null"`
+	Status        string `gorm:"size:20;not null"`
+	SentAt        string
+}
+
+type ReportSchedule struct {
+	ID         int32  `gorm:"primaryKey"`
+	Name       string `gorm:"size:100;not null"`
+	Report     string `gorm:"size:50;not null"`
+	CategoryID int32
+	RowLimit   int32
+	Format     string `gorm:"size:10;not null"`
+	Cron       string `gorm:"size:100;not null"`
+	Delivery   string `gorm:"size:20;not null"` // 'email' or 'directory'
+	Email      string `gorm:"size:255"`
+	AlertEmail string `gorm:"size:255"`
+	Active     bool
+	NextRunAt  sql.NullString
+	CreatedAt  string
+}
+
+type ReportRun struct {
+	ID          int64  `gorm:"primaryKey"`
+	ScheduleID  int32  `gorm:"not null"`
+	PeriodStart string `gorm:"not null"`
+	PeriodEnd   string `gorm:"not null"`
+	TriggeredBy string `gorm:"size:20;not null"` // 'schedule' or 'manual'
+	Status      string `gorm:"size:20;not null"` // 'pending', 'running', 'succeeded', 'failed'
+	RowCount    int32
+	Filename    string `gorm:"size:255"`
+	Location    string `gorm:"size:500"`
+	Error       sql.NullString
+	CreatedAt   string
+	StartedAt   sql.NullString
+	FinishedAt  sql.NullString
+}
+
+type PrivacySetting struct {
+	BorrowerID     int32  `gorm:"primaryKey;autoIncrement:false"`
+	ReadingHistory string `gorm:"size:10;not null"` // 'retain' or 'anonymize'
+}
+
+type BookReview struct {
+	ID             int32 `gorm:"primaryKey"`
+	BookID         int32 `gorm:"not null"`
+	BorrowerID     int32 `gorm:"not null"`
+	Rating         int32 `gorm:"not null"`
+	Body           string
+	Status         string `gorm:"size:10;not null"` // 'pending', 'approved', 'hidden'
+	ModerationNote string `gorm:"size:255"`
+	ModeratedBy    sql.NullInt32
+	ModeratedAt    sql.NullString
+	CreatedAt      string
+	UpdatedAt      string
+}
+
+type ReadingList struct {
+	ID          int32  `gorm:"primaryKey"`
+	BorrowerID  int32  `gorm:"not null"`
+	Name        string `gorm:"size:100;not null"`
+	Description string `gorm:"size:500"`
+	ShareToken  sql.NullString
+	CreatedAt   string
+	UpdatedAt   string
+}
+
+type ReadingListItem struct {
+	ListID   int32  `gorm:"primaryKey;autoIncrement:false"`
+	BookID   int32  `gorm:"primaryKey;autoIncrement:false"`
+	Position int32  `gorm:"not null"`
+	Note     string `gorm:"size:255"`
+	AddedAt  string
+}
+
+type Hold struct {
+	ID         int32  `gorm:"primaryKey"`
+	BookID     int32  `gorm:"not null"`
+	BorrowerID int32  `gorm:"not null"`
+	Status     string `gorm:"size:10;not null"` // 'waiting', 'ready', 'fulfilled', 'cancelled'
+	CreatedAt  string
+	ReadyAt    sql.NullString
+	ClosedAt   sql.NullString
+}
+
+type BookSimilarity struct {
+	BookID        int32   `gorm:"primaryKey;autoIncrement:false"`
+	SimilarBookID int32   `gorm:"primaryKey;autoIncrement:false"`
+	Score         float64 `gorm:"not null"`
+	CoBorrowers   int32   `gorm:"not null"`
+	ComputedAt    string
+}
+
+type BookContributor struct {
+	BookID   int32  `gorm:"primaryKey;autoIncrement:false"`
+	AuthorID int32  `gorm:"primaryKey;autoIncrement:false"`
+	Role     string `gorm:"primaryKey;size:20"` // 'author', 'editor', 'translator', 'illustrator'
+	Position int32  `gorm:"not null"`
+}
@@ -0,0 +1,118 @@
+package notification
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer sends a plain text email
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// SMTPMailer sends email through an SMTP server using PLAIN auth when a username is set
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + m.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	return smtp.SendMail(fmt.Sprintf("%s:%d", m.Host, m.Port), auth, m.From, []string{to}, []byte(msg))
+}
+
+// Attachment is a file sent along with an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// AttachmentMailer sends an email with a file attached, e.g. a scheduled report
+type AttachmentMailer interface {
+	Mailer
+	SendAttachment(to, subject, body string, attachment Attachment) error
+}
+
+// SendAttachment sends a multipart/mixed email with the body and a base64 encoded attachment
+func (m *SMTPMailer) SendAttachment(to, subject, body string, attachment Attachment) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	boundary := "library-" + hex.EncodeToString(random)
+
+	var b strings.Builder
+	b.WriteString(strings.Join([]string{
+		"From: " + m.From,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/mixed; boundary=" + boundary,
+		"",
+		"--" + boundary,
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+		"--" + boundary,
+		"Content-Type: " + attachment.ContentType,
+		"Content-Transfer-Encoding: base64",
+		fmt.Sprintf("Content-Disposition: attachment; filename=%q", attachment.Filename),
+		"",
+		"",
+	}, "\r\n"))
+
+	// Base64 lines may not be longer than 76 characters
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n--" + boundary + "--\r\n")
+
+	return smtp.SendMail(fmt.Sprintf("%s:%d", m.Host, m.Port), auth, m.From, []string{to}, []byte(b.String()))
+}
+
+type EmailChannel struct {
+	Mailer Mailer
+}
+
+func (c *EmailChannel) Name() string {
+	return "email"
+}
+
+func (c *EmailChannel) Send(ctx context.Context, msg Message) error {
+	if msg.Email == "" {
+		return fmt.Errorf("%w: no email address", ErrNoAddress)
+	}
+
+	return c.Mailer.Send(msg.Email, msg.Subject, msg.Body)
+}
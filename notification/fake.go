@@ -0,0 +1,42 @@
+package notification
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeChannel records messages instead of sending them, for tests
+type FakeChannel struct {
+	ChannelName string
+	Err         error
+
+	mu   sync.Mutex
+	sent []Message
+}
+
+func (c *FakeChannel) Name() string {
+	if c.ChannelName == "" {
+		return "fake"
+	}
+	return c.ChannelName
+}
+
+func (c *FakeChannel) Send(ctx context.Context, msg Message) error {
+	if c.Err != nil {
+		return c.Err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+// Sent returns a copy of the messages sent so far
+func (c *FakeChannel) Sent() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Message(nil), c.sent...)
+}
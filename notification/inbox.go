@@ -0,0 +1,45 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go-grpc/events"
+	"go-grpc/helpers"
+	"go-grpc/model"
+
+	"gorm.io/gorm"
+)
+
+// InboxChannel stores messages in the in-app notifications table and
+// publishes them to borrowers subscribed through SubscribeNotifications
+type InboxChannel struct {
+	DB  *gorm.DB
+	Hub *events.Topics[int32, model.Notification]
+}
+
+func (c *InboxChannel) Name() string {
+	return "inapp"
+}
+
+func (c *InboxChannel) Send(ctx context.Context, msg Message) error {
+	notification := model.Notification{
+		BorrowerID: msg.BorrowerID,
+		Kind:       msg.Kind,
+		Title:      msg.Subject,
+		Body:       msg.Body,
+		CreatedAt:  time.Now().Format(helpers.DateTimeLayout),
+	}
+
+	if msg.TransactionID > 0 {
+		notification.TransactionID = sql.NullInt32{Int32: msg.TransactionID, Valid: true}
+	}
+
+	if err := c.DB.WithContext(ctx).Create(&notification).Error; err != nil {
+		return err
+	}
+
+	c.Hub.Publish(notification.BorrowerID, notification)
+	return nil
+}
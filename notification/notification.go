@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"context"
+	"errors"
+)
+
+// Notice kinds, overdue notices escalate by days overdue
+const (
+	KindReminder     = "reminder"
+	KindDueToday     = "due_today"
+	KindOverdue      = "overdue"
+	KindAnnouncement = "announcement"
+)
+
+// ErrNoAddress is returned by a channel when the borrower has no address for
+// it, e.g. no phone number. Sending again cannot succeed, so the notice is
+// recorded as skipped instead of being retried.
+var ErrNoAddress = errors.New("borrower has no address for the channel")
+
+// Message is a notice addressed to a single borrower
+type Message struct {
+	BorrowerID    int32
+	Name          string
+	Email         string
+	Phone         string
+	Kind          string
+	Subject       string
+	Body          string
+	TransactionID int32
+}
+
+// Channel delivers messages to borrowers, e.g. email, SMS or the in-app inbox
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
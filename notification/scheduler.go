@@ -0,0 +1,240 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-grpc/helpers"
+	"go-grpc/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Scheduler sends due-date reminders and escalating overdue notices for open
+// loans through every channel the borrower has enabled. Each notice is claimed
+// in notification_logs before it is sent, so it goes out at most once per
+// loan and channel even with several schedulers running.
+type Scheduler struct {
+	DB       *gorm.DB
+	Channels []Channel
+
+	// Days before the due date to remind borrowers without preferences
+	DefaultReminderDays int32
+	// Days overdue at which a new, more urgent notice is sent
+	OverdueSteps []int
+	// A claim still sending after ClaimTimeout is taken for a crashed scheduler and released
+	ClaimTimeout time.Duration
+
+	// Now is replaceable in tests
+	Now func() time.Time
+}
+
+func NewScheduler(db *gorm.DB, channels ...Channel) *Scheduler {
+	return &Scheduler{
+		DB:                  db,
+		Channels:            channels,
+		DefaultReminderDays: 3,
+		OverdueSteps:        []int{1, 7, 14},
+		ClaimTimeout:        time.Hour,
+		Now:                 time.Now,
+	}
+}
+
+// openLoan is a loan that has not been returned, with the borrower's preferences
+type openLoan struct {
+	TransactionID      int32
+	BorrowerID         int32
+	DueDate            string
+	Title              string
+	Name               string
+	Email              string
+	EmailEnabled       bool
+	SmsEnabled         bool
+	InappEnabled       bool
+	Phone              string
+	ReminderDaysBefore int32
+}
+
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunOnce(ctx); err != nil {
+			log.Printf("notification scheduler: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	if err := s.releaseStale(ctx); err != nil {
+		return err
+	}
+
+	var loans []openLoan
+
+	err := s.DB.WithContext(ctx).Table("borrowing_transactions as bt").
+		Joins("JOIN borrowers br on br.id = bt.borrower_id").
+		Joins("JOIN books b on b.id = bt.book_id").
+		Joins("LEFT JOIN notification_preferences np on np.borrower_id = bt.borrower_id").
+		Select("bt.id transaction_id, bt.borrower_id, bt.due_date, b.title, br.name, COALESCE(br.email, '') email, "+
+			"COALESCE(np.email_enabled, 1) email_enabled, COALESCE(np.sms_enabled, 0) sms_enabled, COALESCE(np.inapp_enabled, 1) inapp_enabled, "+
+			"COALESCE(np.phone, '') phone, COALESCE(np.reminder_days_before, ?) reminder_days_before", s.DefaultReminderDays).
+		Where("bt.returned_at IS NULL AND bt.status IN ?", []string{"borrowed", "overdue"}).
+		Scan(&loans).Error
+	if err != nil {
+		return err
+	}
+
+	now := s.Now()
+
+	for _, loan := range loans {
+		kind, ok := s.noticeFor(loan, now)
+		if !ok {
+			continue
+		}
+
+		msg := compose(loan, kind)
+
+		for _, channel := range s.Channels {
+			if !loan.enabled(channel.Name()) {
+				continue
+			}
+
+			if err := s.send(ctx, channel, msg); err != nil {
+				log.Printf("notification scheduler: %v %v for transaction %d: %v", channel.Name(), kind, loan.TransactionID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// noticeFor returns the notice a loan is due for today, if any
+func (s *Scheduler) noticeFor(loan openLoan, now time.Time) (string, bool) {
+	due, err := time.ParseInLocation(helpers.DateTimeLayout, loan.DueDate, now.Location())
+	if err != nil {
+		return "", false
+	}
+
+	daysUntilDue := int(startOfDay(due).Sub(startOfDay(now)).Hours() / 24)
+
+	switch {
+	case daysUntilDue > 0 && daysUntilDue <= int(loan.ReminderDaysBefore):
+		return KindReminder, true
+	case daysUntilDue == 0:
+		return KindDueToday, true
+	case daysUntilDue < 0:
+		// Highest step reached, earlier steps that were missed are not sent anymore
+		step := 0
+		for _, s := range s.OverdueSteps {
+			if -daysUntilDue >= s {
+				step = s
+			}
+		}
+		if step > 0 {
+			return fmt.Sprintf("%s_%d", KindOverdue, step), true
+		}
+	}
+
+	return "", false
+}
+
+// releaseStale releases the claims of notices a scheduler stopped sending, so
+// they are sent on this run
+func (s *Scheduler) releaseStale(ctx context.Context) error {
+	cutoff := s.Now().Add(-s.ClaimTimeout).Format(helpers.DateTimeLayout)
+
+	return s.DB.WithContext(ctx).
+		Where("status = ? AND sent_at < ?", "sending", cutoff).
+		Delete(&model.NotificationLog{}).Error
+}
+
+// send claims the notice for the channel and sends it. A failed send releases
+// the claim so it is retried on the next run, a borrower without an address
+// for the channel has the notice recorded as skipped.
+func (s *Scheduler) send(ctx context.Context, channel Channel, msg Message) error {
+	claim := model.NotificationLog{
+		TransactionID: msg.TransactionID,
+		BorrowerID:    msg.BorrowerID,
+		Kind:          msg.Kind,
+		Channel:       channel.Name(),
+		Status:        "sending",
+		SentAt:        s.Now().Format(helpers.DateTimeLayout),
+	}
+
+	result := s.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&claim)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Already sent, or being sent by another scheduler
+		return nil
+	}
+
+	err := channel.Send(ctx, msg)
+	if errors.Is(err, ErrNoAddress) {
+		return s.DB.Model(&claim).Update("status", "skipped").Error
+	}
+	if err != nil {
+		// A claim that is not released blocks the notice for good, so it is worth a log line
+		if release := s.DB.Delete(&model.NotificationLog{}, claim.ID).Error; release != nil {
+			log.Printf("notification scheduler: releasing claim %d: %v", claim.ID, release)
+		}
+		return err
+	}
+
+	return s.DB.Model(&claim).Update("status", "sent").Error
+}
+
+func (l openLoan) enabled(channel string) bool {
+	switch channel {
+	case "email":
+		return l.EmailEnabled
+	case "sms":
+		return l.SmsEnabled
+	case "inapp":
+		return l.InappEnabled
+	}
+	return true
+}
+
+func compose(loan openLoan, kind string) Message {
+	msg := Message{
+		BorrowerID:    loan.BorrowerID,
+		Name:          loan.Name,
+		Email:         loan.Email,
+		Phone:         loan.Phone,
+		Kind:          kind,
+		TransactionID: loan.TransactionID,
+	}
+
+	switch kind {
+	case KindReminder:
+		msg.Subject = fmt.Sprintf("Reminder: %q is due on %s", loan.Title, loan.DueDate)
+		msg.Body = fmt.Sprintf("Hi %s,\n\nthe book %q you borrowed is due on %s. Please return or renew it before then.", loan.Name, loan.Title, loan.DueDate)
+	case KindDueToday:
+		msg.Subject = fmt.Sprintf("%q is due today", loan.Title)
+		msg.Body = fmt.Sprintf("Hi %s,\n\nthe book %q you borrowed is due today (%s). Please return it to avoid a fine.", loan.Name, loan.Title, loan.DueDate)
+	default:
+		msg.Subject = fmt.Sprintf("Overdue: %q", loan.Title)
+		msg.Body = fmt.Sprintf("Hi %s,\n\nthe book %q was due on %s and is now overdue. A fine is charged for every day it is late, please return it as soon as possible.", loan.Name, loan.Title, loan.DueDate)
+	}
+
+	return msg
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,371 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-grpc/helpers"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestNoticeFor(t *testing.T) {
+	s := NewScheduler(nil)
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	due := func(days int) string {
+		return now.AddDate(0, 0, days).Format(helpers.DateTimeLayout)
+	}
+
+	tests := []struct {
+		name    string
+		dueDate string
+		want    string
+	}{
+		{"beyond the reminder window", due(4), ""},
+		{"within the reminder window", due(3), KindReminder},
+		{"tomorrow", due(1), KindReminder},
+		{"earlier today", now.Add(-14 * time.Hour).Format(helpers.DateTimeLayout), KindDueToday},
+		{"later today", now.Add(8 * time.Hour).Format(helpers.DateTimeLayout), KindDueToday},
+		{"one day overdue", due(-1), KindOverdue + "_1"},
+		{"between steps", due(-6), KindOverdue + "_1"},
+		{"second step", due(-7), KindOverdue + "_7"},
+		{"past the last step", due(-40), KindOverdue + "_14"},
+		{"unparsable due date", "10/03/2024", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, ok := s.noticeFor(openLoan{DueDate: tt.dueDate, ReminderDaysBefore: 3}, now)
+			if ok != (tt.want != "") || kind != tt.want {
+				t.Errorf("noticeFor(%s) = %q, %v, want %q", tt.dueDate, kind, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoticeForNoReminder(t *testing.T) {
+	s := NewScheduler(nil)
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	loan := openLoan{DueDate: now.AddDate(0, 0, 1).Format(helpers.DateTimeLayout), ReminderDaysBefore: 0}
+	if kind, ok := s.noticeFor(loan, now); ok {
+		t.Errorf("borrower without reminders got %q", kind)
+	}
+}
+
+func TestRunOnceSendsNoticeOnce(t *testing.T) {
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	store := &store{loans: []testLoan{
+		{ID: 1, BorrowerID: 1, DueDate: now.Format(helpers.DateTimeLayout), Email: true, Inapp: true},
+		{ID: 2, BorrowerID: 2, DueDate: now.AddDate(0, 0, -7).Format(helpers.DateTimeLayout), Inapp: true},
+	}}
+
+	email := &FakeChannel{ChannelName: "email"}
+	inapp := &FakeChannel{ChannelName: "inapp"}
+	s := store.scheduler(t, now, email, inapp)
+
+	// A second run, or a second scheduler, finds the notices claimed
+	for i := 0; i < 2; i++ {
+		if err := s.RunOnce(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if sent := email.Sent(); len(sent) != 1 || sent[0].TransactionID != 1 || sent[0].Kind != KindDueToday {
+		t.Errorf("email sent %+v, want the due today notice of transaction 1", sent)
+	}
+	if sent := inapp.Sent(); len(sent) != 2 {
+		t.Errorf("inapp sent %d notices, want 2", len(sent))
+	}
+
+	// The next step of the overdue loan is a new notice
+	s.Now = func() time.Time { return now.AddDate(0, 0, 7) }
+	if err := s.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sent := inapp.Sent(); len(sent) != 4 || sent[3].Kind != KindOverdue+"_14" {
+		t.Errorf("inapp sent %+v, want the 14 days overdue notice last", sent)
+	}
+}
+
+func TestRunOnceRetriesFailedSend(t *testing.T) {
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	store := &store{loans: []testLoan{
+		{ID: 1, BorrowerID: 1, DueDate: now.Format(helpers.DateTimeLayout), Email: true},
+	}}
+
+	email := &FakeChannel{ChannelName: "email", Err: errors.New("smtp unavailable")}
+	s := store.scheduler(t, now, email)
+
+	if err := s.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.logs) != 0 {
+		t.Fatalf("failed send left its claim %+v", store.logs)
+	}
+
+	email.Err = nil
+	if err := s.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sent := email.Sent(); len(sent) != 1 {
+		t.Fatalf("email sent %d notices after the retry, want 1", len(sent))
+	}
+	if len(store.logs) != 1 || store.logs[0].Status != "sent" {
+		t.Errorf("notification_logs = %+v, want one sent notice", store.logs)
+	}
+}
+
+func TestRunOnceSkipsBorrowerWithoutPhone(t *testing.T) {
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	store := &store{loans: []testLoan{
+		{ID: 1, BorrowerID: 1, DueDate: now.Format(helpers.DateTimeLayout), SMS: true},
+		{ID: 2, BorrowerID: 2, DueDate: now.Format(helpers.DateTimeLayout), SMS: true, Phone: "+15550100"},
+	}}
+
+	gateway := &testSMSProvider{}
+	s := store.scheduler(t, now, &SMSChannel{Provider: gateway})
+
+	// The notice without a phone number is not tried again on the next run
+	for i := 0; i < 2; i++ {
+		if err := s.RunOnce(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(gateway.sent) != 1 || gateway.sent[0] != "+15550100" {
+		t.Errorf("sms sent to %v, want +15550100 only", gateway.sent)
+	}
+
+	statuses := map[int64]string{}
+	for _, l := range store.logs {
+		statuses[l.TransactionID] = l.Status
+	}
+	if statuses[1] != "skipped" || statuses[2] != "sent" {
+		t.Errorf("notification_logs statuses = %v, want transaction 1 skipped and 2 sent", statuses)
+	}
+}
+
+func TestRunOnceReleasesStaleClaims(t *testing.T) {
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	store := &store{
+		loans: []testLoan{
+			{ID: 1, BorrowerID: 1, DueDate: now.Format(helpers.DateTimeLayout), Email: true},
+			{ID: 2, BorrowerID: 2, DueDate: now.Format(helpers.DateTimeLayout), Email: true},
+		},
+		// Claimed by a scheduler that crashed two hours ago, and by one sending right now
+		logs: []testLog{
+			{ID: 1, TransactionID: 1, Kind: KindDueToday, Channel: "email", Status: "sending", SentAt: "2024-03-10 07:00:00"},
+			{ID: 2, TransactionID: 2, Kind: KindDueToday, Channel: "email", Status: "sending", SentAt: "2024-03-10 08:55:00"},
+		},
+		nextID: 2,
+	}
+
+	email := &FakeChannel{ChannelName: "email"}
+	s := store.scheduler(t, now, email)
+
+	if err := s.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent := email.Sent(); len(sent) != 1 || sent[0].TransactionID != 1 {
+		t.Errorf("email sent %+v, want the notice of the crashed scheduler only", sent)
+	}
+}
+
+type testSMSProvider struct {
+	sent []string
+}
+
+func (p *testSMSProvider) SendSMS(_ context.Context, to, _ string) error {
+	p.sent = append(p.sent, to)
+	return nil
+}
+
+// store holds the open loans and notification_logs the scheduler works on,
+// behind a database/sql connector
+type store struct {
+	mu sync.Mutex
+
+	loans  []testLoan
+	logs   []testLog
+	nextID int64
+}
+
+type testLoan struct {
+	ID, BorrowerID     int32
+	DueDate            string
+	Email, SMS, Inapp  bool
+	Phone              string
+	ReminderDaysBefore int32
+}
+
+type testLog struct {
+	ID                            int64
+	TransactionID                 int64
+	Kind, Channel, Status, SentAt string
+}
+
+func (s *store) scheduler(t *testing.T, now time.Time, channels ...Channel) *Scheduler {
+	t.Helper()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(s), SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheduler := NewScheduler(db, channels...)
+	scheduler.Now = func() time.Time { return now }
+	return scheduler
+}
+
+func (s *store) query(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.Contains(query, "FROM borrowing_transactions as bt") {
+		return nil, nil, fmt.Errorf("unexpected query %q", query)
+	}
+
+	var rows [][]driver.Value
+	for _, l := range s.loans {
+		reminder := l.ReminderDaysBefore
+		if reminder == 0 {
+			reminder = 3
+		}
+		rows = append(rows, []driver.Value{int64(l.ID), int64(l.BorrowerID), l.DueDate, "The TeXbook", "Ada", "ada@library.test",
+			l.Email, l.SMS, l.Inapp, l.Phone, int64(reminder)})
+	}
+	return []string{"transaction_id", "borrower_id", "due_date", "title", "name", "email",
+		"email_enabled", "sms_enabled", "inapp_enabled", "phone", "reminder_days_before"}, rows, nil
+}
+
+// exec claims, marks and releases notices, (transaction_id, kind, channel) is unique as in the schema
+func (s *store) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO `notification_logs`"):
+		columns := strings.Split(query[strings.Index(query, "(")+1:strings.Index(query, ")")], ",")
+		values := map[string]driver.Value{}
+		for i, column := range columns {
+			values[strings.Trim(column, "`")] = args[i].Value
+		}
+
+		entry := testLog{
+			TransactionID: values["transaction_id"].(int64),
+			Kind:          values["kind"].(string),
+			Channel:       values["channel"].(string),
+			Status:        values["status"].(string),
+			SentAt:        values["sent_at"].(string),
+		}
+		for _, l := range s.logs {
+			if l.TransactionID == entry.TransactionID && l.Kind == entry.Kind && l.Channel == entry.Channel {
+				return driver.RowsAffected(0), nil
+			}
+		}
+
+		s.nextID++
+		entry.ID = s.nextID
+		s.logs = append(s.logs, entry)
+		return testResult{id: entry.ID, rows: 1}, nil
+
+	case strings.HasPrefix(query, "UPDATE `notification_logs` SET `status`=?"):
+		for i := range s.logs {
+			if s.logs[i].ID == args[1].Value {
+				s.logs[i].Status = args[0].Value.(string)
+				return driver.RowsAffected(1), nil
+			}
+		}
+		return driver.RowsAffected(0), nil
+
+	case strings.HasPrefix(query, "DELETE FROM `notification_logs` WHERE status = ? AND sent_at < ?"):
+		kept := s.logs[:0]
+		for _, l := range s.logs {
+			if l.Status != args[0].Value || l.SentAt >= args[1].Value.(string) {
+				kept = append(kept, l)
+			}
+		}
+		released := len(s.logs) - len(kept)
+		s.logs = kept
+		return driver.RowsAffected(released), nil
+
+	case strings.HasPrefix(query, "DELETE FROM `notification_logs` WHERE `notification_logs`.`id` = ?"):
+		for i := range s.logs {
+			if s.logs[i].ID == args[0].Value {
+				s.logs = append(s.logs[:i], s.logs[i+1:]...)
+				return driver.RowsAffected(1), nil
+			}
+		}
+		return driver.RowsAffected(0), nil
+	}
+
+	return nil, fmt.Errorf("unexpected statement %q", query)
+}
+
+// Connect and Driver make the store a driver.Connector
+func (s *store) Connect(context.Context) (driver.Conn, error) { return &testConn{store: s}, nil }
+func (s *store) Driver() driver.Driver                        { return nil }
+
+type testConn struct{ store *store }
+
+func (c *testConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns, rows, err := c.store.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &testRows{columns: columns, rows: rows}, nil
+}
+
+func (c *testConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.store.exec(query, args)
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported: %q", query)
+}
+func (c *testConn) Close() error { return nil }
+
+// Begin hands out a transaction that applies every statement right away,
+// gorm wraps its writes in one
+func (c *testConn) Begin() (driver.Tx, error) { return testTx{}, nil }
+
+type testTx struct{}
+
+func (testTx) Commit() error   { return nil }
+func (testTx) Rollback() error { return nil }
+
+type testResult struct{ id, rows int64 }
+
+func (r testResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r testResult) RowsAffected() (int64, error) { return r.rows, nil }
+
+type testRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *testRows) Columns() []string { return r.columns }
+func (r *testRows) Close() error      { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SMSProvider is implemented by SMS gateways
+type SMSProvider interface {
+	SendSMS(ctx context.Context, to, body string) error
+}
+
+// HTTPSMSProvider posts {"to": ..., "body": ...} as JSON to an SMS gateway,
+// with the token as a bearer token when one is set
+type HTTPSMSProvider struct {
+	URL   string
+	Token string
+
+	Client *http.Client
+}
+
+func (p *HTTPSMSProvider) SendSMS(ctx context.Context, to, body string) error {
+	payload, err := json.Marshal(map[string]string{"to": to, "body": body})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway responded %s", resp.Status)
+	}
+	return nil
+}
+
+type SMSChannel struct {
+	Provider SMSProvider
+}
+
+func (c *SMSChannel) Name() string {
+	return "sms"
+}
+
+func (c *SMSChannel) Send(ctx context.Context, msg Message) error {
+	if msg.Phone == "" {
+		return fmt.Errorf("%w: no phone number", ErrNoAddress)
+	}
+
+	return c.Provider.SendSMS(ctx, msg.Phone, msg.Subject+": "+msg.Body)
+}
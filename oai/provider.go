@@ -0,0 +1,197 @@
+// Package oai is an OAI-PMH 2.0 data provider over the books table, so
+// discovery layers can harvest the catalog. Records are available as
+// oai_dc and marc21 (MARCXML), categories are the sets.
+package oai
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Datestamps are UTC with seconds granularity
+const (
+	granularity   = "YYYY-MM-DDThh:mm:ssZ"
+	datestampTime = "2006-01-02T15:04:05Z"
+	datestampDay  = "2006-01-02"
+)
+
+// Provider answers OAI-PMH requests, GET and POST are both accepted
+type Provider struct {
+	DB *gorm.DB
+
+	Name         string
+	AdminEmail   string
+	RepositoryID string // namespace of identifiers, oai:<RepositoryID>:<book id>
+	BaseURL      string // taken from the request when empty
+	PageSize     int
+}
+
+// verbs and the arguments they accept, exclusive arguments are handled by the verb
+var verbs = map[string]map[string]bool{
+	"Identify":            {},
+	"ListMetadataFormats": {"identifier": true},
+	"ListSets":            {"resumptionToken": true},
+	"GetRecord":           {"identifier": true, "metadataPrefix": true},
+	"ListIdentifiers":     {"metadataPrefix": true, "from": true, "until": true, "set": true, "resumptionToken": true},
+	"ListRecords":         {"metadataPrefix": true, "from": true, "until": true, "set": true, "resumptionToken": true},
+}
+
+// oaiError is one of the error conditions of the protocol
+type oaiError struct {
+	Code    string
+	Message string
+}
+
+func (e *oaiError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func errorf(code, format string, args ...interface{}) *oaiError {
+	return &oaiError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := &response{
+		baseURL: p.baseURL(r),
+		args:    map[string]string{},
+	}
+
+	verb, err := p.arguments(r, resp)
+	if err == nil {
+		err = p.handle(verb, resp)
+	}
+
+	switch e := err.(type) {
+	case nil:
+	case *oaiError:
+		resp.errors = append(resp.errors, e)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write(resp.render())
+}
+
+// arguments validates the request, on badVerb and badArgument the request
+// element of the response carries no attributes
+func (p *Provider) arguments(r *http.Request, resp *response) (string, error) {
+	verb := r.Form.Get("verb")
+	allowed, ok := verbs[verb]
+	if !ok || len(r.Form["verb"]) > 1 {
+		return "", errorf("badVerb", "illegal or missing verb %q", verb)
+	}
+
+	for name, values := range r.Form {
+		if name == "verb" {
+			continue
+		}
+		if !allowed[name] {
+			return "", errorf("badArgument", "%s is not an argument of %s", name, verb)
+		}
+		if len(values) > 1 {
+			return "", errorf("badArgument", "%s is repeated", name)
+		}
+	}
+
+	if r.Form.Has("resumptionToken") && len(r.Form) > 2 {
+		return "", errorf("badArgument", "resumptionToken is an exclusive argument")
+	}
+
+	resp.verb = verb
+	for name := range r.Form {
+		if name != "verb" {
+			resp.args[name] = r.Form.Get(name)
+		}
+	}
+
+	return verb, nil
+}
+
+func (p *Provider) handle(verb string, resp *response) error {
+	switch verb {
+	case "Identify":
+		return p.identify(resp)
+	case "ListMetadataFormats":
+		return p.listMetadataFormats(resp)
+	case "ListSets":
+		return p.listSets(resp)
+	case "GetRecord":
+		return p.getRecord(resp)
+	default:
+		return p.listRecords(resp, verb == "ListRecords")
+	}
+}
+
+func (p *Provider) baseURL(r *http.Request) string {
+	if p.BaseURL != "" {
+		return p.BaseURL
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+func (p *Provider) pageSize() int {
+	if p.PageSize > 0 {
+		return p.PageSize
+	}
+	return 100
+}
+
+func (p *Provider) identifier(bookID int32) string {
+	return fmt.Sprintf("oai:%s:%d", p.RepositoryID, bookID)
+}
+
+// bookID reads an identifier of this repository
+func (p *Provider) bookID(identifier string) (int32, bool) {
+	id, ok := strings.CutPrefix(identifier, "oai:"+p.RepositoryID+":")
+	if !ok {
+		return 0, false
+	}
+
+	var bookID int32
+	if _, err := fmt.Sscan(id, &bookID); err != nil || fmt.Sprint(bookID) != id || bookID <= 0 {
+		return 0, false
+	}
+	return bookID, true
+}
+
+// parseDatestamp reads from and until, a day as until covers the whole day
+func parseDatestamp(value string, until bool) (time.Time, string, error) {
+	if t, err := time.Parse(datestampTime, value); err == nil {
+		return t, datestampTime, nil
+	}
+
+	t, err := time.Parse(datestampDay, value)
+	if err != nil {
+		return time.Time{}, "", errorf("badArgument", "%q is not a datestamp of granularity %s", value, granularity)
+	}
+	if until {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t, datestampDay, nil
+}
+
+func datestamp(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format(datestampTime)
+}
@@ -0,0 +1,188 @@
+package oai
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-grpc/catalog"
+	"go-grpc/citation"
+	"go-grpc/marc"
+	pb "go-grpc/pb/library"
+
+	"gorm.io/gorm"
+)
+
+type metadataFormat struct {
+	Prefix    string
+	Schema    string
+	Namespace string
+}
+
+var metadataFormats = []metadataFormat{
+	{"oai_dc", "http://www.openarchives.org/OAI/2.0/oai_dc.xsd", citation.NamespaceOAIDC},
+	{"marc21", "http://www.loc.gov/standards/marcxml/schema/MARC21slim.xsd", marc.Namespace},
+}
+
+func findFormat(prefix string) (metadataFormat, error) {
+	for _, f := range metadataFormats {
+		if f.Prefix == prefix {
+			return f, nil
+		}
+	}
+	return metadataFormat{}, errorf("cannotDisseminateFormat", "metadataPrefix %q is not supported, see ListMetadataFormats", prefix)
+}
+
+// header is an item of the repository, a book or the tombstone of a purged one
+type header struct {
+	BookID     int32
+	Datestamp  int64
+	Deleted    bool
+	CategoryID sql.NullInt32
+}
+
+// items lists books and purged books with their datestamp as unix seconds,
+// UNIX_TIMESTAMP reads the column in the session time zone so the result is UTC
+func (p *Provider) items() *gorm.DB {
+	union := p.DB.Raw(`SELECT b.id book_id, COALESCE(UNIX_TIMESTAMP(COALESCE(b.updated_at, b.created_at)), 0) datestamp, b.deleted_at IS NOT NULL deleted, b.category_id
+		FROM books b
+		UNION ALL
+		SELECT d.book_id, COALESCE(UNIX_TIMESTAMP(d.deleted_at), 0), TRUE, d.category_id
+		FROM deleted_books d`)
+
+	return p.DB.Table("(?) as r", union)
+}
+
+func scanHeaders(sql *gorm.DB) ([]header, error) {
+	rows, err := sql.Select("r.book_id, r.datestamp, r.deleted, r.category_id").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var headers []header
+	for rows.Next() {
+		var h header
+		if err := rows.Scan(&h.BookID, &h.Datestamp, &h.Deleted, &h.CategoryID); err != nil {
+			return nil, err
+		}
+		headers = append(headers, h)
+	}
+	return headers, rows.Err()
+}
+
+func setSpec(categoryID int32) string {
+	return fmt.Sprintf("category-%d", categoryID)
+}
+
+func parseSetSpec(spec string) (int32, bool) {
+	var categoryID int32
+	id, ok := strings.CutPrefix(spec, "category-")
+	if _, err := fmt.Sscan(id, &categoryID); !ok || err != nil || setSpec(categoryID) != spec {
+		return 0, false
+	}
+	return categoryID, true
+}
+
+// token resumes a list after the last item sent, lists are ordered by
+// datestamp and book id so the position survives changes between requests
+type token struct {
+	Prefix    string `json:"p"`
+	From      string `json:"f,omitempty"`
+	Until     string `json:"u,omitempty"`
+	Set       string `json:"s,omitempty"`
+	Datestamp int64  `json:"d"`
+	BookID    int32  `json:"i"`
+	Cursor    int    `json:"c"`
+}
+
+func (t token) encode() string {
+	raw, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeToken(value string) (token, error) {
+	var t token
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(raw, &t)
+	}
+	if err != nil || t.Prefix == "" || t.Cursor <= 0 {
+		return token{}, errorf("badResumptionToken", "the resumptionToken is invalid")
+	}
+	return t, nil
+}
+
+// writeRecord renders the header and, unless only identifiers are listed
+// or the item is deleted, the metadata of the book
+func (p *Provider) writeRecord(resp *response, h header, book *pb.Book, format metadataFormat, withMetadata bool) error {
+	indent := "    "
+	if withMetadata {
+		resp.body.WriteString("    <record>\n")
+		indent = "      "
+	}
+
+	if h.Deleted {
+		fmt.Fprintf(&resp.body, "%s<header status=\"deleted\">\n", indent)
+	} else {
+		fmt.Fprintf(&resp.body, "%s<header>\n", indent)
+	}
+	resp.element(indent+"  ", "identifier", p.identifier(h.BookID))
+	resp.element(indent+"  ", "datestamp", datestamp(h.Datestamp))
+	if h.CategoryID.Valid {
+		resp.element(indent+"  ", "setSpec", setSpec(h.CategoryID.Int32))
+	}
+	fmt.Fprintf(&resp.body, "%s</header>\n", indent)
+
+	if !withMetadata {
+		return nil
+	}
+
+	if !h.Deleted && book != nil {
+		resp.body.WriteString("      <metadata>\n")
+
+		switch format.Prefix {
+		case "marc21":
+			raw, err := marc.MarshalRecord(marc.FromBook(book, time.Unix(h.Datestamp, 0)), "        ", "  ")
+			if err != nil {
+				return err
+			}
+			resp.body.Write(raw)
+			resp.body.WriteString("\n")
+		default:
+			resp.body.WriteString(citation.DublinCoreRecord(citation.FromBook(book), "        "))
+		}
+
+		resp.body.WriteString("      </metadata>\n")
+	}
+
+	resp.body.WriteString("    </record>\n")
+	return nil
+}
+
+// books loads the metadata of the items that are not deleted
+func (p *Provider) books(headers []header) (map[int32]*pb.Book, error) {
+	var ids []int32
+	for _, h := range headers {
+		if !h.Deleted {
+			ids = append(ids, h.BookID)
+		}
+	}
+
+	books := map[int32]*pb.Book{}
+	if len(ids) == 0 {
+		return books, nil
+	}
+
+	list, err := catalog.Books(p.DB, catalog.Filter{IDs: ids, IncludeDeleted: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, book := range list {
+		books[book.Id] = book
+	}
+	return books, nil
+}
@@ -0,0 +1,84 @@
+package oai
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	namespaceOAI = "http://www.openarchives.org/OAI/2.0/"
+	schemaOAI    = "http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd"
+)
+
+// response collects the content of the verb element, or the errors
+type response struct {
+	verb    string
+	args    map[string]string
+	baseURL string
+	errors  []*oaiError
+	body    strings.Builder
+}
+
+func (resp *response) render() []byte {
+	var b strings.Builder
+
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, "<OAI-PMH xmlns=%q xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\" xsi:schemaLocation=\"%s %s\">\n", namespaceOAI, namespaceOAI, schemaOAI)
+	fmt.Fprintf(&b, "  <responseDate>%s</responseDate>\n", time.Now().UTC().Format(datestampTime))
+
+	// Arguments are only echoed for a request that was understood
+	b.WriteString("  <request")
+	if !hasError(resp.errors, "badVerb", "badArgument") {
+		if resp.verb != "" {
+			fmt.Fprintf(&b, " verb=\"%s\"", escape(resp.verb))
+		}
+		names := make([]string, 0, len(resp.args))
+		for name := range resp.args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&b, " %s=\"%s\"", name, escape(resp.args[name]))
+		}
+	}
+	fmt.Fprintf(&b, ">%s</request>\n", escape(resp.baseURL))
+
+	if len(resp.errors) > 0 {
+		for _, e := range resp.errors {
+			fmt.Fprintf(&b, "  <error code=\"%s\">%s</error>\n", e.Code, escape(e.Message))
+		}
+	} else {
+		fmt.Fprintf(&b, "  <%s>\n", resp.verb)
+		b.WriteString(resp.body.String())
+		fmt.Fprintf(&b, "  </%s>\n", resp.verb)
+	}
+
+	b.WriteString("</OAI-PMH>\n")
+
+	return []byte(b.String())
+}
+
+// element writes <name>value</name> into the verb element
+func (resp *response) element(indent, name, value string) {
+	fmt.Fprintf(&resp.body, "%s<%s>%s</%s>\n", indent, name, escape(value), name)
+}
+
+func hasError(errors []*oaiError, codes ...string) bool {
+	for _, e := range errors {
+		for _, code := range codes {
+			if e.Code == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,256 @@
+package oai
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	pb "go-grpc/pb/library"
+
+	"gorm.io/gorm"
+)
+
+func (p *Provider) identify(resp *response) error {
+	var earliest sql.NullInt64
+	if err := p.items().Select("MIN(r.datestamp)").Row().Scan(&earliest); err != nil {
+		return err
+	}
+	if !earliest.Valid {
+		earliest.Int64 = time.Now().Unix()
+	}
+
+	resp.element("    ", "repositoryName", p.Name)
+	resp.element("    ", "baseURL", resp.baseURL)
+	resp.element("    ", "protocolVersion", "2.0")
+	resp.element("    ", "adminEmail", p.AdminEmail)
+	resp.element("    ", "earliestDatestamp", datestamp(earliest.Int64))
+	resp.element("    ", "deletedRecord", "persistent")
+	resp.element("    ", "granularity", granularity)
+
+	fmt.Fprintf(&resp.body, `    <description>
+      <oai-identifier xmlns="http://www.openarchives.org/OAI/2.0/oai-identifier" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.openarchives.org/OAI/2.0/oai-identifier http://www.openarchives.org/OAI/2.0/oai-identifier.xsd">
+        <scheme>oai</scheme>
+        <repositoryIdentifier>%s</repositoryIdentifier>
+        <delimiter>:</delimiter>
+        <sampleIdentifier>%s</sampleIdentifier>
+      </oai-identifier>
+    </description>
+`, escape(p.RepositoryID), escape(p.identifier(1)))
+
+	return nil
+}
+
+// findItem returns the header of a book or purged book by its identifier
+func (p *Provider) findItem(identifier string) (header, error) {
+	bookID, ok := p.bookID(identifier)
+	if !ok {
+		return header{}, errorf("idDoesNotExist", "%q is not an identifier of this repository", identifier)
+	}
+
+	headers, err := scanHeaders(p.items().Where("r.book_id = ?", bookID).Limit(1))
+	if err != nil {
+		return header{}, err
+	}
+	if len(headers) == 0 {
+		return header{}, errorf("idDoesNotExist", "%q does not exist", identifier)
+	}
+	return headers[0], nil
+}
+
+// listMetadataFormats lists the same formats for every item
+func (p *Provider) listMetadataFormats(resp *response) error {
+	if identifier, ok := resp.args["identifier"]; ok {
+		if _, err := p.findItem(identifier); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range metadataFormats {
+		resp.body.WriteString("    <metadataFormat>\n")
+		resp.element("      ", "metadataPrefix", f.Prefix)
+		resp.element("      ", "schema", f.Schema)
+		resp.element("      ", "metadataNamespace", f.Namespace)
+		resp.body.WriteString("    </metadataFormat>\n")
+	}
+	return nil
+}
+
+// listSets returns every category in one response
+func (p *Provider) listSets(resp *response) error {
+	if _, ok := resp.args["resumptionToken"]; ok {
+		return errorf("badResumptionToken", "ListSets is never split")
+	}
+
+	rows, err := p.DB.Table("categories").Select("id, name").Where("deleted_at IS NULL").Order("id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var id int32
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return err
+		}
+
+		resp.body.WriteString("    <set>\n")
+		resp.element("      ", "setSpec", setSpec(id))
+		resp.element("      ", "setName", name)
+		resp.body.WriteString("    </set>\n")
+		count++
+	}
+
+	if count == 0 {
+		return errorf("noSetHierarchy", "there are no categories")
+	}
+	return rows.Err()
+}
+
+func (p *Provider) getRecord(resp *response) error {
+	identifier, prefix := resp.args["identifier"], resp.args["metadataPrefix"]
+	if identifier == "" || prefix == "" {
+		return errorf("badArgument", "identifier and metadataPrefix are required")
+	}
+
+	format, err := findFormat(prefix)
+	if err != nil {
+		return err
+	}
+
+	h, err := p.findItem(identifier)
+	if err != nil {
+		return err
+	}
+
+	books, err := p.books([]header{h})
+	if err != nil {
+		return err
+	}
+
+	return p.writeRecord(resp, h, books[h.BookID], format, true)
+}
+
+// listRecords answers ListRecords and ListIdentifiers one page at a time
+func (p *Provider) listRecords(resp *response, withMetadata bool) error {
+	var t token
+	if value, ok := resp.args["resumptionToken"]; ok {
+		var err error
+		if t, err = decodeToken(value); err != nil {
+			return err
+		}
+	} else {
+		t = token{Prefix: resp.args["metadataPrefix"], From: resp.args["from"], Until: resp.args["until"], Set: resp.args["set"]}
+		if t.Prefix == "" {
+			return errorf("badArgument", "metadataPrefix is required")
+		}
+	}
+
+	format, err := findFormat(t.Prefix)
+	if err != nil {
+		return err
+	}
+
+	query, err := p.selection(t)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	if err := query().Count(&total).Error; err != nil {
+		return err
+	}
+
+	sql := query()
+	if t.Cursor > 0 {
+		sql = sql.Where("(r.datestamp > ? OR (r.datestamp = ? AND r.book_id > ?))", t.Datestamp, t.Datestamp, t.BookID)
+	}
+
+	size := p.pageSize()
+	headers, err := scanHeaders(sql.Order("r.datestamp, r.book_id").Limit(size + 1))
+	if err != nil {
+		return err
+	}
+
+	if len(headers) == 0 {
+		if t.Cursor > 0 {
+			return errorf("badResumptionToken", "the list this resumptionToken belongs to has changed")
+		}
+		return errorf("noRecordsMatch", "no records match the request")
+	}
+
+	more := len(headers) > size
+	if more {
+		headers = headers[:size]
+	}
+
+	var books map[int32]*pb.Book
+	if withMetadata {
+		if books, err = p.books(headers); err != nil {
+			return err
+		}
+	}
+
+	for _, h := range headers {
+		if err := p.writeRecord(resp, h, books[h.BookID], format, withMetadata); err != nil {
+			return err
+		}
+	}
+
+	// The first page only has a token when there is more, the last page of a resumed list an empty one
+	cursor := t.Cursor
+	switch {
+	case more:
+		last := headers[len(headers)-1]
+		next := t
+		next.Datestamp, next.BookID, next.Cursor = last.Datestamp, last.BookID, cursor+len(headers)
+		fmt.Fprintf(&resp.body, "    <resumptionToken completeListSize=\"%d\" cursor=\"%d\">%s</resumptionToken>\n", total, cursor, next.encode())
+	case t.Cursor > 0:
+		fmt.Fprintf(&resp.body, "    <resumptionToken completeListSize=\"%d\" cursor=\"%d\"/>\n", total, cursor)
+	}
+
+	return nil
+}
+
+// selection applies from, until and set, a new query is built for every use
+func (p *Provider) selection(t token) (func() *gorm.DB, error) {
+	var conditions []func(*gorm.DB) *gorm.DB
+	var fromLayout string
+
+	if t.From != "" {
+		from, layout, err := parseDatestamp(t.From, false)
+		if err != nil {
+			return nil, err
+		}
+		fromLayout = layout
+		conditions = append(conditions, func(sql *gorm.DB) *gorm.DB { return sql.Where("r.datestamp >= ?", from.Unix()) })
+	}
+
+	if t.Until != "" {
+		until, layout, err := parseDatestamp(t.Until, true)
+		if err != nil {
+			return nil, err
+		}
+		if fromLayout != "" && fromLayout != layout {
+			return nil, errorf("badArgument", "from and until must have the same granularity")
+		}
+		conditions = append(conditions, func(sql *gorm.DB) *gorm.DB { return sql.Where("r.datestamp <= ?", until.Unix()) })
+	}
+
+	if t.Set != "" {
+		categoryID, ok := parseSetSpec(t.Set)
+		if !ok {
+			return nil, errorf("noRecordsMatch", "set %q does not exist", t.Set)
+		}
+		conditions = append(conditions, func(sql *gorm.DB) *gorm.DB { return sql.Where("r.category_id = ?", categoryID) })
+	}
+
+	return func() *gorm.DB {
+		sql := p.items()
+		for _, condition := range conditions {
+			sql = condition(sql)
+		}
+		return sql
+	}, nil
+}
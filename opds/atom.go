@@ -0,0 +1,153 @@
+package opds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-grpc/catalog"
+)
+
+// Media types of OPDS 1.2
+const (
+	typeNavigation  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	typeAcquisition = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	typeOpenSearch  = "application/opensearchdescription+xml"
+	relBorrow       = "http://opds-spec.org/acquisition/borrow"
+)
+
+func writeAtom(w http.ResponseWriter, f *feed) {
+	kind := typeNavigation
+	if f.Acquisition {
+		kind = typeAcquisition
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<feed xmlns="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/terms/" xmlns:opds="http://opds-spec.org/2010/catalog" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">` + "\n")
+
+	element(&b, "  ", "id", f.ID)
+	element(&b, "  ", "title", f.Title)
+	element(&b, "  ", "updated", f.Updated.Format(time.RFC3339))
+
+	atomLink(&b, "  ", "self", f.Self, kind, "")
+	atomLink(&b, "  ", "start", f.Start, typeNavigation, "")
+	atomLink(&b, "  ", "up", f.Up, typeNavigation, "")
+	atomLink(&b, "  ", "search", f.SearchHref, typeOpenSearch, "")
+	atomLink(&b, "  ", "first", f.First, kind, "")
+	atomLink(&b, "  ", "previous", f.Previous, kind, "")
+	atomLink(&b, "  ", "next", f.Next, kind, "")
+	atomLink(&b, "  ", "last", f.Last, kind, "")
+
+	if f.PerPage > 0 {
+		element(&b, "  ", "opensearch:totalResults", fmt.Sprint(f.Total))
+		element(&b, "  ", "opensearch:itemsPerPage", fmt.Sprint(f.PerPage))
+		element(&b, "  ", "opensearch:startIndex", fmt.Sprint((f.Page-1)*f.PerPage+1))
+	}
+
+	for _, n := range f.Navigation {
+		linkType := typeNavigation
+		if n.Acquisition {
+			linkType = typeAcquisition
+		}
+
+		b.WriteString("  <entry>\n")
+		element(&b, "    ", "title", n.Title)
+		element(&b, "    ", "id", n.Href)
+		element(&b, "    ", "updated", f.Updated.Format(time.RFC3339))
+		fmt.Fprintf(&b, "    <content type=\"text\">%s</content>\n", escape(n.Content))
+		atomLink(&b, "    ", "subsection", n.Href, linkType, "")
+		b.WriteString("  </entry>\n")
+	}
+
+	for _, book := range f.Books {
+		b.WriteString("  <entry>\n")
+		element(&b, "    ", "title", book.Title)
+		element(&b, "    ", "id", bookURN(book))
+		element(&b, "    ", "updated", book.Updated.Format(time.RFC3339))
+
+		// Editors, translators and illustrators are Atom contributors
+		for _, c := range book.credits() {
+			tag := "contributor"
+			if c.Role == catalog.RoleAuthor {
+				tag = "author"
+			}
+			b.WriteString("    <" + tag + ">\n")
+			element(&b, "      ", "name", c.Name)
+			element(&b, "      ", "uri", c.Href)
+			b.WriteString("    </" + tag + ">\n")
+		}
+		if book.Isbn != "" {
+			element(&b, "    ", "dc:identifier", "urn:isbn:"+book.Isbn)
+		}
+		if book.PublicationYear > 0 {
+			element(&b, "    ", "dc:issued", fmt.Sprint(book.PublicationYear))
+		}
+		if book.Category != nil && book.Category.Name != "" {
+			fmt.Fprintf(&b, "    <category term=\"%s\" label=\"%s\"/>\n", escape(book.Category.Name), escape(book.Category.Name))
+		}
+		element(&b, "    ", "summary", book.Description)
+
+		atomLink(&b, "    ", relBorrow, book.Href, "application/json", "")
+		if book.AuthorHref != "" {
+			atomLink(&b, "    ", "related", book.AuthorHref, typeAcquisition, "More by "+book.Author.Name)
+		}
+		b.WriteString("  </entry>\n")
+	}
+
+	b.WriteString("</feed>\n")
+
+	w.Header().Set("Content-Type", kind+";charset=utf-8")
+	w.Write([]byte(b.String()))
+}
+
+// openSearch describes the search of the OPDS 1.2 catalog
+func (s *Server) openSearch(w http.ResponseWriter, r *http.Request) {
+	base := s.baseURL(r)
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<OpenSearchDescription xmlns="http://a9.com/-/spec/opensearch/1.1/">` + "\n")
+	element(&b, "  ", "ShortName", s.Title)
+	element(&b, "  ", "Description", "Search the catalog by title, author or ISBN")
+	element(&b, "  ", "InputEncoding", "UTF-8")
+	element(&b, "  ", "OutputEncoding", "UTF-8")
+	fmt.Fprintf(&b, "  <Url type=\"%s\" template=\"%s\"/>\n", escape(typeAcquisition), escape(base+"/opds/search?q={searchTerms}&page={startPage?}"))
+	b.WriteString("</OpenSearchDescription>\n")
+
+	w.Header().Set("Content-Type", typeOpenSearch+";charset=utf-8")
+	w.Write([]byte(b.String()))
+}
+
+// bookURN prefers the ISBN, which other catalogs know the book by
+func bookURN(b book) string {
+	if b.Isbn != "" {
+		return "urn:isbn:" + b.Isbn
+	}
+	return fmt.Sprintf("urn:library:book:%d", b.Id)
+}
+
+func element(b *strings.Builder, indent, name, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s<%s>%s</%s>\n", indent, name, escape(value), name)
+	}
+}
+
+func atomLink(b *strings.Builder, indent, rel, href, linkType, title string) {
+	if href == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s<link rel=\"%s\" href=\"%s\" type=\"%s\"", indent, escape(rel), escape(href), escape(linkType))
+	if title != "" {
+		fmt.Fprintf(b, " title=\"%s\"", escape(title))
+	}
+	b.WriteString("/>\n")
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
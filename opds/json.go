@@ -0,0 +1,131 @@
+package opds
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-grpc/catalog"
+)
+
+// Media type of OPDS 2.0
+const typeOPDS2 = "application/opds+json"
+
+type jsonFeed struct {
+	Metadata     jsonMetadata      `json:"metadata"`
+	Links        []jsonLink        `json:"links"`
+	Navigation   []jsonLink        `json:"navigation,omitempty"`
+	Publications []jsonPublication `json:"publications,omitempty"`
+}
+
+type jsonMetadata struct {
+	Title         string `json:"title"`
+	Modified      string `json:"modified,omitempty"`
+	NumberOfItems int    `json:"numberOfItems,omitempty"`
+	ItemsPerPage  int    `json:"itemsPerPage,omitempty"`
+	CurrentPage   int    `json:"currentPage,omitempty"`
+}
+
+type jsonLink struct {
+	Rel       string `json:"rel,omitempty"`
+	Href      string `json:"href"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+type jsonPublication struct {
+	Metadata jsonBookMetadata `json:"metadata"`
+	Links    []jsonLink       `json:"links"`
+}
+
+type jsonBookMetadata struct {
+	Type        string        `json:"@type"`
+	Identifier  string        `json:"identifier"`
+	Title       string        `json:"title"`
+	Author      []jsonContrib `json:"author,omitempty"`
+	Editor      []jsonContrib `json:"editor,omitempty"`
+	Translator  []jsonContrib `json:"translator,omitempty"`
+	Illustrator []jsonContrib `json:"illustrator,omitempty"`
+	Subject     []jsonContrib `json:"subject,omitempty"`
+	Published   string        `json:"published,omitempty"`
+	Modified    string        `json:"modified,omitempty"`
+	Description string        `json:"description,omitempty"`
+}
+
+type jsonContrib struct {
+	Name  string     `json:"name"`
+	Links []jsonLink `json:"links,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, f *feed) {
+	out := jsonFeed{
+		Metadata: jsonMetadata{Title: f.Title, Modified: f.Updated.Format(time.RFC3339)},
+	}
+	if f.PerPage > 0 {
+		out.Metadata.NumberOfItems, out.Metadata.ItemsPerPage, out.Metadata.CurrentPage = f.Total, f.PerPage, f.Page
+	}
+
+	for _, l := range []jsonLink{
+		{Rel: "self", Href: f.Self, Type: typeOPDS2},
+		{Rel: "start", Href: f.Start, Type: typeOPDS2},
+		{Rel: "up", Href: f.Up, Type: typeOPDS2},
+		{Rel: "search", Href: f.SearchHref, Type: typeOPDS2, Templated: true},
+		{Rel: "first", Href: f.First, Type: typeOPDS2},
+		{Rel: "previous", Href: f.Previous, Type: typeOPDS2},
+		{Rel: "next", Href: f.Next, Type: typeOPDS2},
+		{Rel: "last", Href: f.Last, Type: typeOPDS2},
+	} {
+		if l.Href != "" {
+			out.Links = append(out.Links, l)
+		}
+	}
+
+	for _, n := range f.Navigation {
+		out.Navigation = append(out.Navigation, jsonLink{Href: n.Href, Title: n.Title, Type: typeOPDS2, Rel: "subsection"})
+	}
+
+	for _, book := range f.Books {
+		metadata := jsonBookMetadata{
+			Type:        "http://schema.org/Book",
+			Identifier:  bookURN(book),
+			Title:       book.Title,
+			Modified:    book.Updated.Format(time.RFC3339),
+			Description: book.Description,
+		}
+		for _, c := range book.credits() {
+			contrib := jsonContrib{Name: c.Name}
+			if c.Href != "" {
+				contrib.Links = []jsonLink{{Href: c.Href, Type: typeOPDS2}}
+			}
+			switch c.Role {
+			case catalog.RoleEditor:
+				metadata.Editor = append(metadata.Editor, contrib)
+			case catalog.RoleTranslator:
+				metadata.Translator = append(metadata.Translator, contrib)
+			case catalog.RoleIllustrator:
+				metadata.Illustrator = append(metadata.Illustrator, contrib)
+			default:
+				metadata.Author = append(metadata.Author, contrib)
+			}
+		}
+		if book.Category != nil && book.Category.Name != "" {
+			metadata.Subject = []jsonContrib{{Name: book.Category.Name}}
+		}
+		if book.PublicationYear > 0 {
+			metadata.Published = fmt.Sprint(book.PublicationYear)
+		}
+
+		out.Publications = append(out.Publications, jsonPublication{
+			Metadata: metadata,
+			Links:    []jsonLink{{Rel: relBorrow, Href: book.Href, Type: "application/json"}},
+		})
+	}
+
+	w.Header().Set("Content-Type", typeOPDS2)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(out)
+}
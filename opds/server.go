@@ -0,0 +1,363 @@
+// Package opds serves the catalog to e-reader apps as OPDS 1.2 (Atom) under
+// /opds and OPDS 2.0 (JSON) under /opds/v2: navigation by category and
+// author, acquisition feeds of new arrivals and search results, and an
+// OpenSearch description.
+package opds
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-grpc/catalog"
+	pb "go-grpc/pb/library"
+
+	"gorm.io/gorm"
+)
+
+// Server answers the OPDS routes, it is mounted at /opds/
+type Server struct {
+	DB       *gorm.DB
+	Title    string
+	BaseURL  string // scheme and host of links, taken from the request when empty
+	PageSize int
+
+	mux *http.ServeMux
+}
+
+func New(db *gorm.DB, title, baseURL string) *Server {
+	s := &Server{DB: db, Title: title, BaseURL: baseURL, PageSize: 25, mux: http.NewServeMux()}
+
+	for _, version := range []string{"", "/v2"} {
+		prefix := "/opds" + version
+		s.mux.HandleFunc("GET "+prefix+"/{$}", s.handle(s.root))
+		s.mux.HandleFunc("GET "+prefix+"/new", s.handle(s.newArrivals))
+		s.mux.HandleFunc("GET "+prefix+"/categories", s.handle(s.categories))
+		s.mux.HandleFunc("GET "+prefix+"/categories/{id}", s.handle(s.category))
+		s.mux.HandleFunc("GET "+prefix+"/authors", s.handle(s.authors))
+		s.mux.HandleFunc("GET "+prefix+"/authors/{id}", s.handle(s.author))
+		s.mux.HandleFunc("GET "+prefix+"/search", s.handle(s.search))
+	}
+	s.mux.HandleFunc("GET /opds/opensearch.xml", s.openSearch)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// request is one feed request, paths are built for the version asked for
+type request struct {
+	*http.Request
+	base string // scheme and host
+	v2   bool
+	page int
+}
+
+// path returns an absolute link to a feed of the same version
+func (r *request) path(path string, query ...string) string {
+	prefix := "/opds"
+	if r.v2 {
+		prefix += "/v2"
+	}
+
+	link := r.base + prefix + path
+	if len(query) > 0 {
+		values := url.Values{}
+		for i := 0; i+1 < len(query); i += 2 {
+			if query[i+1] != "" {
+				values.Set(query[i], query[i+1])
+			}
+		}
+		if encoded := values.Encode(); encoded != "" {
+			link += "?" + encoded
+		}
+	}
+	return link
+}
+
+// errNotFound ends a feed request with 404
+var errNotFound = fmt.Errorf("not found")
+
+func (s *Server) handle(build func(*request) (*feed, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{Request: r, base: s.baseURL(r), v2: strings.HasPrefix(r.URL.Path, "/opds/v2/"), page: 1}
+		if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && page > 0 {
+			req.page = page
+		}
+
+		f, err := build(req)
+		if err == errNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		f.Self = req.path(f.path, f.query...)
+		f.Start = req.path("/")
+
+		if req.v2 {
+			f.SearchHref = req.base + "/opds/v2/search{?query}"
+			writeJSON(w, f)
+		} else {
+			f.SearchHref = req.base + "/opds/opensearch.xml"
+			writeAtom(w, f)
+		}
+	}
+}
+
+func (s *Server) baseURL(r *http.Request) string {
+	if s.BaseURL != "" {
+		return s.BaseURL
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func (s *Server) root(r *request) (*feed, error) {
+	return &feed{
+		ID:      "urn:library:opds",
+		Title:   s.Title,
+		path:    "/",
+		Updated: time.Now().UTC(),
+		Navigation: []navigation{
+			{Title: "New arrivals", Href: r.path("/new"), Content: "The latest additions to the catalog", Acquisition: true},
+			{Title: "Categories", Href: r.path("/categories"), Content: "Browse by category"},
+			{Title: "Authors", Href: r.path("/authors"), Content: "Browse by author"},
+		},
+	}, nil
+}
+
+func (s *Server) newArrivals(r *request) (*feed, error) {
+	return s.acquisition(r, &feed{ID: "urn:library:opds:new", Title: "New arrivals", path: "/new"}, catalog.Filter{Newest: true})
+}
+
+func (s *Server) search(r *request) (*feed, error) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		query = r.URL.Query().Get("query")
+	}
+
+	f := &feed{ID: "urn:library:opds:search:" + url.QueryEscape(query), Title: fmt.Sprintf("Search results for %q", query), path: "/search", query: []string{"q", query}}
+	if query == "" {
+		// Nothing is listed rather than the whole catalog
+		f.Updated = time.Now().UTC()
+		f.Books = []book{}
+		f.Acquisition = true
+		return f, nil
+	}
+
+	return s.acquisition(r, f, catalog.Filter{Search: query})
+}
+
+func (s *Server) category(r *request) (*feed, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return nil, errNotFound
+	}
+
+	var name string
+	if err := s.DB.Table("categories").Select("name").Where("id = ? AND deleted_at IS NULL", id).Row().Scan(&name); err != nil {
+		return nil, errNotFound
+	}
+
+	f := &feed{ID: fmt.Sprintf("urn:library:opds:category:%d", id), Title: name, path: fmt.Sprintf("/categories/%d", id), Up: r.path("/categories")}
+	return s.acquisition(r, f, catalog.Filter{CategoryID: int32(id)})
+}
+
+func (s *Server) author(r *request) (*feed, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return nil, errNotFound
+	}
+
+	var name string
+	if err := s.DB.Table("authors").Select("name").Where("id = ? AND deleted_at IS NULL", id).Row().Scan(&name); err != nil {
+		return nil, errNotFound
+	}
+
+	f := &feed{ID: fmt.Sprintf("urn:library:opds:author:%d", id), Title: name, path: fmt.Sprintf("/authors/%d", id), Up: r.path("/authors")}
+	return s.acquisition(r, f, catalog.Filter{AuthorID: int32(id)})
+}
+
+// acquisition fills a feed with a page of the books matching the filter
+func (s *Server) acquisition(r *request, f *feed, filter catalog.Filter) (*feed, error) {
+	total, err := catalog.Count(s.DB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Limit, filter.Offset = s.PageSize, (r.page-1)*s.PageSize
+	books, err := catalog.Books(s.DB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int32, len(books))
+	for i, b := range books {
+		ids[i] = b.Id
+	}
+	updated, err := catalog.Updated(s.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Acquisition = true
+	f.Books = make([]book, len(books))
+	for i, b := range books {
+		f.Books[i] = book{Book: b, Updated: updated[b.Id], Href: r.base + fmt.Sprintf("/v1/books/%d", b.Id), AuthorHrefs: map[int32]string{}}
+		if b.Author != nil && b.Author.Id > 0 {
+			f.Books[i].AuthorHref = r.path(fmt.Sprintf("/authors/%d", b.Author.Id))
+		}
+		for _, c := range b.Contributors {
+			f.Books[i].AuthorHrefs[c.AuthorId] = r.path(fmt.Sprintf("/authors/%d", c.AuthorId))
+		}
+		if f.Books[i].Updated.After(f.Updated) {
+			f.Updated = f.Books[i].Updated
+		}
+	}
+	if f.Updated.IsZero() {
+		f.Updated = time.Now().UTC()
+	}
+
+	s.paginate(r, f, total)
+	return f, nil
+}
+
+// categories is a navigation feed of the categories that have books
+func (s *Server) categories(r *request) (*feed, error) {
+	sql := s.DB.Table("categories as c").
+		Joins("JOIN books b on b.category_id = c.id AND b.deleted_at IS NULL").
+		Where("c.deleted_at IS NULL").
+		Group("c.id, c.name")
+
+	f := &feed{ID: "urn:library:opds:categories", Title: "Categories", path: "/categories", Up: r.path("/")}
+	return s.navigationFeed(r, f, sql, "c", "/categories/%d")
+}
+
+// authors is a navigation feed of the authors that contributed to books
+func (s *Server) authors(r *request) (*feed, error) {
+	sql := s.DB.Table("authors as au").
+		Joins("JOIN book_contributors bc on bc.author_id = au.id").
+		Joins("JOIN books b on b.id = bc.book_id AND b.deleted_at IS NULL").
+		Where("au.deleted_at IS NULL").
+		Group("au.id, au.name")
+
+	f := &feed{ID: "urn:library:opds:authors", Title: "Authors", path: "/authors", Up: r.path("/")}
+	return s.navigationFeed(r, f, sql, "au", "/authors/%d")
+}
+
+func (s *Server) navigationFeed(r *request, f *feed, sql *gorm.DB, alias, href string) (*feed, error) {
+	var total int64
+	if err := s.DB.Table("(?) as n", sql.Session(&gorm.Session{}).Select(alias+".id")).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	rows, err := sql.Select(alias + ".id, " + alias + ".name, COUNT(DISTINCT b.id)").
+		Order(alias + ".name").
+		Limit(s.PageSize).Offset((r.page - 1) * s.PageSize).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int32
+		var name string
+		var count int
+		if err := rows.Scan(&id, &name, &count); err != nil {
+			return nil, err
+		}
+
+		content := "1 book"
+		if count != 1 {
+			content = fmt.Sprintf("%d books", count)
+		}
+		f.Navigation = append(f.Navigation, navigation{Title: name, Href: r.path(fmt.Sprintf(href, id)), Content: content, Acquisition: true})
+	}
+
+	f.Updated = time.Now().UTC()
+	s.paginate(r, f, total)
+	return f, rows.Err()
+}
+
+func (s *Server) paginate(r *request, f *feed, total int64) {
+	f.Total, f.Page, f.PerPage = int(total), r.page, s.PageSize
+
+	last := (f.Total + s.PageSize - 1) / s.PageSize
+	page := func(n int) string {
+		return r.path(f.path, append(append([]string{}, f.query...), "page", strconv.Itoa(n))...)
+	}
+
+	if r.page > 1 {
+		f.First, f.Previous = page(1), page(r.page-1)
+	}
+	if r.page < last {
+		f.Next, f.Last = page(r.page+1), page(last)
+	}
+}
+
+// feed is rendered as Atom or OPDS 2.0 JSON
+type feed struct {
+	ID      string
+	Title   string
+	Updated time.Time
+
+	path  string
+	query []string
+
+	Self, Start, Up             string
+	First, Previous, Next, Last string
+	SearchHref                  string
+	Total, Page, PerPage        int
+	Acquisition                 bool
+	Navigation                  []navigation
+	Books                       []book
+}
+
+type navigation struct {
+	Title       string
+	Href        string
+	Content     string
+	Acquisition bool // the target is an acquisition feed
+}
+
+type book struct {
+	*pb.Book
+	Updated     time.Time
+	Href        string           // the book's REST resource, the borrow link
+	AuthorHref  string           // feed of the first author's books
+	AuthorHrefs map[int32]string // feed of each contributor's books, by author id
+}
+
+// credit is a contributor of a book with the feed of their books
+type credit struct {
+	Name string
+	Role string
+	Href string
+}
+
+// credits are the contributors of the book in order, the Author alone for
+// books loaded without contributors
+func (b book) credits() []credit {
+	var credits []credit
+	for _, c := range b.Contributors {
+		credits = append(credits, credit{Name: c.Name, Role: c.Role, Href: b.AuthorHrefs[c.AuthorId]})
+	}
+	if len(credits) == 0 && b.Author != nil && b.Author.Name != "" {
+		credits = append(credits, credit{Name: b.Author.Name, Role: catalog.RoleAuthor, Href: b.AuthorHref})
+	}
+	return credits
+}
@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"go-grpc/helpers"
+	"go-grpc/model"
+
+	"gorm.io/gorm"
+)
+
+// Event types published to other systems
+const (
+	LoanCreated  = "loan.created"
+	LoanReturned = "loan.returned"
+	LoanRenewed  = "loan.renewed"
+	FineAssessed = "fine.assessed"
+	FinePaid     = "fine.paid"
+)
+
+// Write stores an event in the outbox. It must be called with the transaction
+// that makes the change so the event is published if and only if it commits.
+func Write(tx *gorm.DB, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	event := model.OutboxEvent{
+		EventType: eventType,
+		Payload:   string(data),
+		CreatedAt: time.Now().Format(helpers.DateTimeLayout),
+	}
+
+	return tx.Create(&event).Error
+}
@@ -201,11 +201,18 @@ var AuthService_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	BookService_GetBook_FullMethodName    = "/go_grpc.BookService/GetBook"
-	BookService_ListBooks_FullMethodName  = "/go_grpc.BookService/ListBooks"
-	BookService_CreateBook_FullMethodName = "/go_grpc.BookService/CreateBook"
-	BookService_UpdateBook_FullMethodName = "/go_grpc.BookService/UpdateBook"
-	BookService_DeleteBook_FullMethodName = "/go_grpc.BookService/DeleteBook"
+	BookService_GetBook_FullMethodName         = "/go_grpc.BookService/GetBook"
+	BookService_GetBookByIsbn_FullMethodName   = "/go_grpc.BookService/GetBookByIsbn"
+	BookService_ListBooks_FullMethodName       = "/go_grpc.BookService/ListBooks"
+	BookService_CreateBook_FullMethodName      = "/go_grpc.BookService/CreateBook"
+	BookService_UpdateBook_FullMethodName      = "/go_grpc.BookService/UpdateBook"
+	BookService_DeleteBook_FullMethodName      = "/go_grpc.BookService/DeleteBook"
+	BookService_RestoreBook_FullMethodName     = "/go_grpc.BookService/RestoreBook"
+	BookService_PurgeBook_FullMethodName       = "/go_grpc.BookService/PurgeBook"
+	BookService_ImportBooks_FullMethodName     = "/go_grpc.BookService/ImportBooks"
+	BookService_ImportMarc_FullMethodName      = "/go_grpc.BookService/ImportMarc"
+	BookService_ExportMarc_FullMethodName      = "/go_grpc.BookService/ExportMarc"
+	BookService_ExportCitations_FullMethodName = "/go_grpc.BookService/ExportCitations"
 )
 
 // BookServiceClient is the client API for BookService service.
@@ -215,10 +222,17 @@ const (
 // Book Service
 type BookServiceClient interface {
 	GetBook(ctx context.Context, in *BookRequest, opts ...grpc.CallOption) (*BookResponse, error)
+	GetBookByIsbn(ctx context.Context, in *IsbnRequest, opts ...grpc.CallOption) (*BookResponse, error)
 	ListBooks(ctx context.Context, in *ParameterReq, opts ...grpc.CallOption) (*BooksResponse, error)
 	CreateBook(ctx context.Context, in *CreateBookRequest, opts ...grpc.CallOption) (*BookResponse, error)
 	UpdateBook(ctx context.Context, in *BookUpdateReq, opts ...grpc.CallOption) (*BookResponse, error)
 	DeleteBook(ctx context.Context, in *BookRequest, opts ...grpc.CallOption) (*Empty, error)
+	RestoreBook(ctx context.Context, in *BookRequest, opts ...grpc.CallOption) (*BookResponse, error)
+	PurgeBook(ctx context.Context, in *BookRequest, opts ...grpc.CallOption) (*Empty, error)
+	ImportBooks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportBooksRequest, ImportBooksResponse], error)
+	ImportMarc(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportMarcRequest, ImportBooksResponse], error)
+	ExportMarc(ctx context.Context, in *ExportMarcRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MarcChunk], error)
+	ExportCitations(ctx context.Context, in *ExportCitationsRequest, opts ...grpc.CallOption) (*ExportCitationsResponse, error)
 }
 
 type bookServiceClient struct {
@@ -239,6 +253,16 @@ func (c *bookServiceClient) GetBook(ctx context.Context, in *BookRequest, opts .
 	return out, nil
 }
 
+func (c *bookServiceClient) GetBookByIsbn(ctx context.Context, in *IsbnRequest, opts ...grpc.CallOption) (*BookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookResponse)
+	err := c.cc.Invoke(ctx, BookService_GetBookByIsbn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *bookServiceClient) ListBooks(ctx context.Context, in *ParameterReq, opts ...grpc.CallOption) (*BooksResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BooksResponse)
@@ -279,6 +303,81 @@ func (c *bookServiceClient) DeleteBook(ctx context.Context, in *BookRequest, opt
 	return out, nil
 }
 
+func (c *bookServiceClient) RestoreBook(ctx context.Context, in *BookRequest, opts ...grpc.CallOption) (*BookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookResponse)
+	err := c.cc.Invoke(ctx, BookService_RestoreBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookServiceClient) PurgeBook(ctx context.Context, in *BookRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, BookService_PurgeBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookServiceClient) ImportBooks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportBooksRequest, ImportBooksResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BookService_ServiceDesc.Streams[0], BookService_ImportBooks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportBooksRequest, ImportBooksResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BookService_ImportBooksClient = grpc.ClientStreamingClient[ImportBooksRequest, ImportBooksResponse]
+
+func (c *bookServiceClient) ImportMarc(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportMarcRequest, ImportBooksResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BookService_ServiceDesc.Streams[1], BookService_ImportMarc_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportMarcRequest, ImportBooksResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BookService_ImportMarcClient = grpc.ClientStreamingClient[ImportMarcRequest, ImportBooksResponse]
+
+func (c *bookServiceClient) ExportMarc(ctx context.Context, in *ExportMarcRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MarcChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BookService_ServiceDesc.Streams[2], BookService_ExportMarc_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportMarcRequest, MarcChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BookService_ExportMarcClient = grpc.ServerStreamingClient[MarcChunk]
+
+func (c *bookServiceClient) ExportCitations(ctx context.Context, in *ExportCitationsRequest, opts ...grpc.CallOption) (*ExportCitationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportCitationsResponse)
+	err := c.cc.Invoke(ctx, BookService_ExportCitations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BookServiceServer is the server API for BookService service.
 // All implementations must embed UnimplementedBookServiceServer
 // for forward compatibility.
@@ -286,10 +385,17 @@ func (c *bookServiceClient) DeleteBook(ctx context.Context, in *BookRequest, opt
 // Book Service
 type BookServiceServer interface {
 	GetBook(context.Context, *BookRequest) (*BookResponse, error)
+	GetBookByIsbn(context.Context, *IsbnRequest) (*BookResponse, error)
 	ListBooks(context.Context, *ParameterReq) (*BooksResponse, error)
 	CreateBook(context.Context, *CreateBookRequest) (*BookResponse, error)
 	UpdateBook(context.Context, *BookUpdateReq) (*BookResponse, error)
 	DeleteBook(context.Context, *BookRequest) (*Empty, error)
+	RestoreBook(context.Context, *BookRequest) (*BookResponse, error)
+	PurgeBook(context.Context, *BookRequest) (*Empty, error)
+	ImportBooks(grpc.ClientStreamingServer[ImportBooksRequest, ImportBooksResponse]) error
+	ImportMarc(grpc.ClientStreamingServer[ImportMarcRequest, ImportBooksResponse]) error
+	ExportMarc(*ExportMarcRequest, grpc.ServerStreamingServer[MarcChunk]) error
+	ExportCitations(context.Context, *ExportCitationsRequest) (*ExportCitationsResponse, error)
 	mustEmbedUnimplementedBookServiceServer()
 }
 
@@ -303,6 +409,9 @@ type UnimplementedBookServiceServer struct{}
 func (UnimplementedBookServiceServer) GetBook(context.Context, *BookRequest) (*BookResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBook not implemented")
 }
+func (UnimplementedBookServiceServer) GetBookByIsbn(context.Context, *IsbnRequest) (*BookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBookByIsbn not implemented")
+}
 func (UnimplementedBookServiceServer) ListBooks(context.Context, *ParameterReq) (*BooksResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListBooks not implemented")
 }
@@ -315,6 +424,24 @@ func (UnimplementedBookServiceServer) UpdateBook(context.Context, *BookUpdateReq
 func (UnimplementedBookServiceServer) DeleteBook(context.Context, *BookRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteBook not implemented")
 }
+func (UnimplementedBookServiceServer) RestoreBook(context.Context, *BookRequest) (*BookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreBook not implemented")
+}
+func (UnimplementedBookServiceServer) PurgeBook(context.Context, *BookRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeBook not implemented")
+}
+func (UnimplementedBookServiceServer) ImportBooks(grpc.ClientStreamingServer[ImportBooksRequest, ImportBooksResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportBooks not implemented")
+}
+func (UnimplementedBookServiceServer) ImportMarc(grpc.ClientStreamingServer[ImportMarcRequest, ImportBooksResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportMarc not implemented")
+}
+func (UnimplementedBookServiceServer) ExportMarc(*ExportMarcRequest, grpc.ServerStreamingServer[MarcChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportMarc not implemented")
+}
+func (UnimplementedBookServiceServer) ExportCitations(context.Context, *ExportCitationsRequest) (*ExportCitationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportCitations not implemented")
+}
 func (UnimplementedBookServiceServer) mustEmbedUnimplementedBookServiceServer() {}
 func (UnimplementedBookServiceServer) testEmbeddedByValue()                     {}
 
@@ -354,6 +481,24 @@ func _BookService_GetBook_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BookService_GetBookByIsbn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IsbnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).GetBookByIsbn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_GetBookByIsbn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).GetBookByIsbn(ctx, req.(*IsbnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _BookService_ListBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ParameterReq)
 	if err := dec(in); err != nil {
@@ -426,6 +571,85 @@ func _BookService_DeleteBook_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BookService_RestoreBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).RestoreBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_RestoreBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).RestoreBook(ctx, req.(*BookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookService_PurgeBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).PurgeBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_PurgeBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).PurgeBook(ctx, req.(*BookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookService_ImportBooks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BookServiceServer).ImportBooks(&grpc.GenericServerStream[ImportBooksRequest, ImportBooksResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BookService_ImportBooksServer = grpc.ClientStreamingServer[ImportBooksRequest, ImportBooksResponse]
+
+func _BookService_ImportMarc_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BookServiceServer).ImportMarc(&grpc.GenericServerStream[ImportMarcRequest, ImportBooksResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BookService_ImportMarcServer = grpc.ClientStreamingServer[ImportMarcRequest, ImportBooksResponse]
+
+func _BookService_ExportMarc_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportMarcRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BookServiceServer).ExportMarc(m, &grpc.GenericServerStream[ExportMarcRequest, MarcChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BookService_ExportMarcServer = grpc.ServerStreamingServer[MarcChunk]
+
+func _BookService_ExportCitations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportCitationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).ExportCitations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_ExportCitations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).ExportCitations(ctx, req.(*ExportCitationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BookService_ServiceDesc is the grpc.ServiceDesc for BookService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -437,6 +661,10 @@ var BookService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBook",
 			Handler:    _BookService_GetBook_Handler,
 		},
+		{
+			MethodName: "GetBookByIsbn",
+			Handler:    _BookService_GetBookByIsbn_Handler,
+		},
 		{
 			MethodName: "ListBooks",
 			Handler:    _BookService_ListBooks_Handler,
@@ -453,17 +681,47 @@ var BookService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteBook",
 			Handler:    _BookService_DeleteBook_Handler,
 		},
+		{
+			MethodName: "RestoreBook",
+			Handler:    _BookService_RestoreBook_Handler,
+		},
+		{
+			MethodName: "PurgeBook",
+			Handler:    _BookService_PurgeBook_Handler,
+		},
+		{
+			MethodName: "ExportCitations",
+			Handler:    _BookService_ExportCitations_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportBooks",
+			Handler:       _BookService_ImportBooks_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ImportMarc",
+			Handler:       _BookService_ImportMarc_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportMarc",
+			Handler:       _BookService_ExportMarc_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "library.proto",
 }
 
 const (
-	AuthorService_GetAuthor_FullMethodName    = "/go_grpc.AuthorService/GetAuthor"
-	AuthorService_ListAuthors_FullMethodName  = "/go_grpc.AuthorService/ListAuthors"
-	AuthorService_CreateAuthor_FullMethodName = "/go_grpc.AuthorService/CreateAuthor"
-	AuthorService_UpdateAuthor_FullMethodName = "/go_grpc.AuthorService/UpdateAuthor"
-	AuthorService_DeleteAuthor_FullMethodName = "/go_grpc.AuthorService/DeleteAuthor"
+	AuthorService_GetAuthor_FullMethodName     = "/go_grpc.AuthorService/GetAuthor"
+	AuthorService_ListAuthors_FullMethodName   = "/go_grpc.AuthorService/ListAuthors"
+	AuthorService_CreateAuthor_FullMethodName  = "/go_grpc.AuthorService/CreateAuthor"
+	AuthorService_UpdateAuthor_FullMethodName  = "/go_grpc.AuthorService/UpdateAuthor"
+	AuthorService_DeleteAuthor_FullMethodName  = "/go_grpc.AuthorService/DeleteAuthor"
+	AuthorService_RestoreAuthor_FullMethodName = "/go_grpc.AuthorService/RestoreAuthor"
+	AuthorService_PurgeAuthor_FullMethodName   = "/go_grpc.AuthorService/PurgeAuthor"
 )
 
 // AuthorServiceClient is the client API for AuthorService service.
@@ -477,6 +735,8 @@ type AuthorServiceClient interface {
 	CreateAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*AuthorResponse, error)
 	UpdateAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*AuthorResponse, error)
 	DeleteAuthor(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error)
+	RestoreAuthor(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*AuthorResponse, error)
+	PurgeAuthor(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type authorServiceClient struct {
@@ -537,6 +797,26 @@ func (c *authorServiceClient) DeleteAuthor(ctx context.Context, in *IdRequest, o
 	return out, nil
 }
 
+func (c *authorServiceClient) RestoreAuthor(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*AuthorResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthorResponse)
+	err := c.cc.Invoke(ctx, AuthorService_RestoreAuthor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authorServiceClient) PurgeAuthor(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, AuthorService_PurgeAuthor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AuthorServiceServer is the server API for AuthorService service.
 // All implementations must embed UnimplementedAuthorServiceServer
 // for forward compatibility.
@@ -548,6 +828,8 @@ type AuthorServiceServer interface {
 	CreateAuthor(context.Context, *Author) (*AuthorResponse, error)
 	UpdateAuthor(context.Context, *Author) (*AuthorResponse, error)
 	DeleteAuthor(context.Context, *IdRequest) (*Empty, error)
+	RestoreAuthor(context.Context, *IdRequest) (*AuthorResponse, error)
+	PurgeAuthor(context.Context, *IdRequest) (*Empty, error)
 	mustEmbedUnimplementedAuthorServiceServer()
 }
 
@@ -573,6 +855,12 @@ func (UnimplementedAuthorServiceServer) UpdateAuthor(context.Context, *Author) (
 func (UnimplementedAuthorServiceServer) DeleteAuthor(context.Context, *IdRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteAuthor not implemented")
 }
+func (UnimplementedAuthorServiceServer) RestoreAuthor(context.Context, *IdRequest) (*AuthorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreAuthor not implemented")
+}
+func (UnimplementedAuthorServiceServer) PurgeAuthor(context.Context, *IdRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeAuthor not implemented")
+}
 func (UnimplementedAuthorServiceServer) mustEmbedUnimplementedAuthorServiceServer() {}
 func (UnimplementedAuthorServiceServer) testEmbeddedByValue()                       {}
 
@@ -684,6 +972,42 @@ func _AuthorService_DeleteAuthor_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AuthorService_RestoreAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthorServiceServer).RestoreAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthorService_RestoreAuthor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthorServiceServer).RestoreAuthor(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthorService_PurgeAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthorServiceServer).PurgeAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthorService_PurgeAuthor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthorServiceServer).PurgeAuthor(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AuthorService_ServiceDesc is the grpc.ServiceDesc for AuthorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -711,17 +1035,27 @@ var AuthorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteAuthor",
 			Handler:    _AuthorService_DeleteAuthor_Handler,
 		},
+		{
+			MethodName: "RestoreAuthor",
+			Handler:    _AuthorService_RestoreAuthor_Handler,
+		},
+		{
+			MethodName: "PurgeAuthor",
+			Handler:    _AuthorService_PurgeAuthor_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "library.proto",
 }
 
 const (
-	CategoryService_GetCategory_FullMethodName    = "/go_grpc.CategoryService/GetCategory"
-	CategoryService_ListCategories_FullMethodName = "/go_grpc.CategoryService/ListCategories"
-	CategoryService_CreateCategory_FullMethodName = "/go_grpc.CategoryService/CreateCategory"
-	CategoryService_UpdateCategory_FullMethodName = "/go_grpc.CategoryService/UpdateCategory"
-	CategoryService_DeleteCategory_FullMethodName = "/go_grpc.CategoryService/DeleteCategory"
+	CategoryService_GetCategory_FullMethodName     = "/go_grpc.CategoryService/GetCategory"
+	CategoryService_ListCategories_FullMethodName  = "/go_grpc.CategoryService/ListCategories"
+	CategoryService_CreateCategory_FullMethodName  = "/go_grpc.CategoryService/CreateCategory"
+	CategoryService_UpdateCategory_FullMethodName  = "/go_grpc.CategoryService/UpdateCategory"
+	CategoryService_DeleteCategory_FullMethodName  = "/go_grpc.CategoryService/DeleteCategory"
+	CategoryService_RestoreCategory_FullMethodName = "/go_grpc.CategoryService/RestoreCategory"
+	CategoryService_PurgeCategory_FullMethodName   = "/go_grpc.CategoryService/PurgeCategory"
 )
 
 // CategoryServiceClient is the client API for CategoryService service.
@@ -735,6 +1069,8 @@ type CategoryServiceClient interface {
 	CreateCategory(ctx context.Context, in *CategoryRequest, opts ...grpc.CallOption) (*CategoryResponse, error)
 	UpdateCategory(ctx context.Context, in *CategoryRequest, opts ...grpc.CallOption) (*CategoryResponse, error)
 	DeleteCategory(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error)
+	RestoreCategory(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*CategoryResponse, error)
+	PurgeCategory(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type categoryServiceClient struct {
@@ -795,6 +1131,26 @@ func (c *categoryServiceClient) DeleteCategory(ctx context.Context, in *IdReques
 	return out, nil
 }
 
+func (c *categoryServiceClient) RestoreCategory(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*CategoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CategoryResponse)
+	err := c.cc.Invoke(ctx, CategoryService_RestoreCategory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *categoryServiceClient) PurgeCategory(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CategoryService_PurgeCategory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CategoryServiceServer is the server API for CategoryService service.
 // All implementations must embed UnimplementedCategoryServiceServer
 // for forward compatibility.
@@ -806,6 +1162,8 @@ type CategoryServiceServer interface {
 	CreateCategory(context.Context, *CategoryRequest) (*CategoryResponse, error)
 	UpdateCategory(context.Context, *CategoryRequest) (*CategoryResponse, error)
 	DeleteCategory(context.Context, *IdRequest) (*Empty, error)
+	RestoreCategory(context.Context, *IdRequest) (*CategoryResponse, error)
+	PurgeCategory(context.Context, *IdRequest) (*Empty, error)
 	mustEmbedUnimplementedCategoryServiceServer()
 }
 
@@ -831,6 +1189,12 @@ func (UnimplementedCategoryServiceServer) UpdateCategory(context.Context, *Categ
 func (UnimplementedCategoryServiceServer) DeleteCategory(context.Context, *IdRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteCategory not implemented")
 }
+func (UnimplementedCategoryServiceServer) RestoreCategory(context.Context, *IdRequest) (*CategoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreCategory not implemented")
+}
+func (UnimplementedCategoryServiceServer) PurgeCategory(context.Context, *IdRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeCategory not implemented")
+}
 func (UnimplementedCategoryServiceServer) mustEmbedUnimplementedCategoryServiceServer() {}
 func (UnimplementedCategoryServiceServer) testEmbeddedByValue()                         {}
 
@@ -942,6 +1306,42 @@ func _CategoryService_DeleteCategory_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CategoryService_RestoreCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).RestoreCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_RestoreCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).RestoreCategory(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CategoryService_PurgeCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).PurgeCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_PurgeCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).PurgeCategory(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CategoryService_ServiceDesc is the grpc.ServiceDesc for CategoryService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -969,14 +1369,24 @@ var CategoryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteCategory",
 			Handler:    _CategoryService_DeleteCategory_Handler,
 		},
+		{
+			MethodName: "RestoreCategory",
+			Handler:    _CategoryService_RestoreCategory_Handler,
+		},
+		{
+			MethodName: "PurgeCategory",
+			Handler:    _CategoryService_PurgeCategory_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "library.proto",
 }
 
 const (
-	BookStockService_GetBookStock_FullMethodName    = "/go_grpc.BookStockService/GetBookStock"
-	BookStockService_UpdateBookStock_FullMethodName = "/go_grpc.BookStockService/UpdateBookStock"
+	BookStockService_GetBookStock_FullMethodName       = "/go_grpc.BookStockService/GetBookStock"
+	BookStockService_UpdateBookStock_FullMethodName    = "/go_grpc.BookStockService/UpdateBookStock"
+	BookStockService_AdjustStock_FullMethodName        = "/go_grpc.BookStockService/AdjustStock"
+	BookStockService_ListStockMovements_FullMethodName = "/go_grpc.BookStockService/ListStockMovements"
 )
 
 // BookStockServiceClient is the client API for BookStockService service.
@@ -987,6 +1397,8 @@ const (
 type BookStockServiceClient interface {
 	GetBookStock(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*BookStockResponse, error)
 	UpdateBookStock(ctx context.Context, in *BookStockUpdate, opts ...grpc.CallOption) (*BookStockResponse, error)
+	AdjustStock(ctx context.Context, in *AdjustStockRequest, opts ...grpc.CallOption) (*BookStockResponse, error)
+	ListStockMovements(ctx context.Context, in *StockMovementsRequest, opts ...grpc.CallOption) (*StockMovementsResponse, error)
 }
 
 type bookStockServiceClient struct {
@@ -1017,19 +1429,41 @@ func (c *bookStockServiceClient) UpdateBookStock(ctx context.Context, in *BookSt
 	return out, nil
 }
 
-// BookStockServiceServer is the server API for BookStockService service.
-// All implementations must embed UnimplementedBookStockServiceServer
-// for forward compatibility.
-//
-// BookStock Service
-type BookStockServiceServer interface {
-	GetBookStock(context.Context, *IdRequest) (*BookStockResponse, error)
-	UpdateBookStock(context.Context, *BookStockUpdate) (*BookStockResponse, error)
-	mustEmbedUnimplementedBookStockServiceServer()
-}
-
-// UnimplementedBookStockServiceServer must be embedded to have
-// forward compatible implementations.
+func (c *bookStockServiceClient) AdjustStock(ctx context.Context, in *AdjustStockRequest, opts ...grpc.CallOption) (*BookStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookStockResponse)
+	err := c.cc.Invoke(ctx, BookStockService_AdjustStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookStockServiceClient) ListStockMovements(ctx context.Context, in *StockMovementsRequest, opts ...grpc.CallOption) (*StockMovementsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StockMovementsResponse)
+	err := c.cc.Invoke(ctx, BookStockService_ListStockMovements_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BookStockServiceServer is the server API for BookStockService service.
+// All implementations must embed UnimplementedBookStockServiceServer
+// for forward compatibility.
+//
+// BookStock Service
+type BookStockServiceServer interface {
+	GetBookStock(context.Context, *IdRequest) (*BookStockResponse, error)
+	UpdateBookStock(context.Context, *BookStockUpdate) (*BookStockResponse, error)
+	AdjustStock(context.Context, *AdjustStockRequest) (*BookStockResponse, error)
+	ListStockMovements(context.Context, *StockMovementsRequest) (*StockMovementsResponse, error)
+	mustEmbedUnimplementedBookStockServiceServer()
+}
+
+// UnimplementedBookStockServiceServer must be embedded to have
+// forward compatible implementations.
 //
 // NOTE: this should be embedded by value instead of pointer to avoid a nil
 // pointer dereference when methods are called.
@@ -1041,6 +1475,12 @@ func (UnimplementedBookStockServiceServer) GetBookStock(context.Context, *IdRequ
 func (UnimplementedBookStockServiceServer) UpdateBookStock(context.Context, *BookStockUpdate) (*BookStockResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateBookStock not implemented")
 }
+func (UnimplementedBookStockServiceServer) AdjustStock(context.Context, *AdjustStockRequest) (*BookStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdjustStock not implemented")
+}
+func (UnimplementedBookStockServiceServer) ListStockMovements(context.Context, *StockMovementsRequest) (*StockMovementsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStockMovements not implemented")
+}
 func (UnimplementedBookStockServiceServer) mustEmbedUnimplementedBookStockServiceServer() {}
 func (UnimplementedBookStockServiceServer) testEmbeddedByValue()                          {}
 
@@ -1098,6 +1538,42 @@ func _BookStockService_UpdateBookStock_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BookStockService_AdjustStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdjustStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookStockServiceServer).AdjustStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookStockService_AdjustStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookStockServiceServer).AdjustStock(ctx, req.(*AdjustStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookStockService_ListStockMovements_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StockMovementsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookStockServiceServer).ListStockMovements(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookStockService_ListStockMovements_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookStockServiceServer).ListStockMovements(ctx, req.(*StockMovementsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BookStockService_ServiceDesc is the grpc.ServiceDesc for BookStockService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1113,6 +1589,14 @@ var BookStockService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateBookStock",
 			Handler:    _BookStockService_UpdateBookStock_Handler,
 		},
+		{
+			MethodName: "AdjustStock",
+			Handler:    _BookStockService_AdjustStock_Handler,
+		},
+		{
+			MethodName: "ListStockMovements",
+			Handler:    _BookStockService_ListStockMovements_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "library.proto",
@@ -1123,6 +1607,14 @@ const (
 	BorrowingService_ListBorrowingTransactions_FullMethodName  = "/go_grpc.BorrowingService/ListBorrowingTransactions"
 	BorrowingService_CreateBorrowingTransaction_FullMethodName = "/go_grpc.BorrowingService/CreateBorrowingTransaction"
 	BorrowingService_UpdateBorrowingTransaction_FullMethodName = "/go_grpc.BorrowingService/UpdateBorrowingTransaction"
+	BorrowingService_RenewBorrowingTransaction_FullMethodName  = "/go_grpc.BorrowingService/RenewBorrowingTransaction"
+	BorrowingService_WatchCirculation_FullMethodName           = "/go_grpc.BorrowingService/WatchCirculation"
+	BorrowingService_ListMyReadingHistory_FullMethodName       = "/go_grpc.BorrowingService/ListMyReadingHistory"
+	BorrowingService_GetPrivacySettings_FullMethodName         = "/go_grpc.BorrowingService/GetPrivacySettings"
+	BorrowingService_UpdatePrivacySettings_FullMethodName      = "/go_grpc.BorrowingService/UpdatePrivacySettings"
+	BorrowingService_PlaceHold_FullMethodName                  = "/go_grpc.BorrowingService/PlaceHold"
+	BorrowingService_ListHolds_FullMethodName                  = "/go_grpc.BorrowingService/ListHolds"
+	BorrowingService_CancelHold_FullMethodName                 = "/go_grpc.BorrowingService/CancelHold"
 )
 
 // BorrowingServiceClient is the client API for BorrowingService service.
@@ -1135,6 +1627,14 @@ type BorrowingServiceClient interface {
 	ListBorrowingTransactions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BorrowingTransactionsResponse, error)
 	CreateBorrowingTransaction(ctx context.Context, in *CreateBorrowingTransactionRequest, opts ...grpc.CallOption) (*BorrowingTransactionResponse, error)
 	UpdateBorrowingTransaction(ctx context.Context, in *UpdateBorrowingTransactionRequest, opts ...grpc.CallOption) (*BorrowingTransactionResponse, error)
+	RenewBorrowingTransaction(ctx context.Context, in *RenewBorrowingTransactionRequest, opts ...grpc.CallOption) (*BorrowingTransactionResponse, error)
+	WatchCirculation(ctx context.Context, in *WatchCirculationRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CirculationEvent], error)
+	ListMyReadingHistory(ctx context.Context, in *ReadingHistoryRequest, opts ...grpc.CallOption) (*ReadingHistoryResponse, error)
+	GetPrivacySettings(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PrivacySettings, error)
+	UpdatePrivacySettings(ctx context.Context, in *PrivacySettings, opts ...grpc.CallOption) (*PrivacySettings, error)
+	PlaceHold(ctx context.Context, in *PlaceHoldRequest, opts ...grpc.CallOption) (*HoldResponse, error)
+	ListHolds(ctx context.Context, in *HoldsRequest, opts ...grpc.CallOption) (*HoldsResponse, error)
+	CancelHold(ctx context.Context, in *CancelHoldRequest, opts ...grpc.CallOption) (*ReturnSimpleResponse, error)
 }
 
 type borrowingServiceClient struct {
@@ -1185,6 +1685,95 @@ func (c *borrowingServiceClient) UpdateBorrowingTransaction(ctx context.Context,
 	return out, nil
 }
 
+func (c *borrowingServiceClient) RenewBorrowingTransaction(ctx context.Context, in *RenewBorrowingTransactionRequest, opts ...grpc.CallOption) (*BorrowingTransactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BorrowingTransactionResponse)
+	err := c.cc.Invoke(ctx, BorrowingService_RenewBorrowingTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowingServiceClient) WatchCirculation(ctx context.Context, in *WatchCirculationRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CirculationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BorrowingService_ServiceDesc.Streams[0], BorrowingService_WatchCirculation_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchCirculationRequest, CirculationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BorrowingService_WatchCirculationClient = grpc.ServerStreamingClient[CirculationEvent]
+
+func (c *borrowingServiceClient) ListMyReadingHistory(ctx context.Context, in *ReadingHistoryRequest, opts ...grpc.CallOption) (*ReadingHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadingHistoryResponse)
+	err := c.cc.Invoke(ctx, BorrowingService_ListMyReadingHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowingServiceClient) GetPrivacySettings(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PrivacySettings, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PrivacySettings)
+	err := c.cc.Invoke(ctx, BorrowingService_GetPrivacySettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowingServiceClient) UpdatePrivacySettings(ctx context.Context, in *PrivacySettings, opts ...grpc.CallOption) (*PrivacySettings, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PrivacySettings)
+	err := c.cc.Invoke(ctx, BorrowingService_UpdatePrivacySettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowingServiceClient) PlaceHold(ctx context.Context, in *PlaceHoldRequest, opts ...grpc.CallOption) (*HoldResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HoldResponse)
+	err := c.cc.Invoke(ctx, BorrowingService_PlaceHold_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowingServiceClient) ListHolds(ctx context.Context, in *HoldsRequest, opts ...grpc.CallOption) (*HoldsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HoldsResponse)
+	err := c.cc.Invoke(ctx, BorrowingService_ListHolds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowingServiceClient) CancelHold(ctx context.Context, in *CancelHoldRequest, opts ...grpc.CallOption) (*ReturnSimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReturnSimpleResponse)
+	err := c.cc.Invoke(ctx, BorrowingService_CancelHold_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BorrowingServiceServer is the server API for BorrowingService service.
 // All implementations must embed UnimplementedBorrowingServiceServer
 // for forward compatibility.
@@ -1195,6 +1784,14 @@ type BorrowingServiceServer interface {
 	ListBorrowingTransactions(context.Context, *Empty) (*BorrowingTransactionsResponse, error)
 	CreateBorrowingTransaction(context.Context, *CreateBorrowingTransactionRequest) (*BorrowingTransactionResponse, error)
 	UpdateBorrowingTransaction(context.Context, *UpdateBorrowingTransactionRequest) (*BorrowingTransactionResponse, error)
+	RenewBorrowingTransaction(context.Context, *RenewBorrowingTransactionRequest) (*BorrowingTransactionResponse, error)
+	WatchCirculation(*WatchCirculationRequest, grpc.ServerStreamingServer[CirculationEvent]) error
+	ListMyReadingHistory(context.Context, *ReadingHistoryRequest) (*ReadingHistoryResponse, error)
+	GetPrivacySettings(context.Context, *Empty) (*PrivacySettings, error)
+	UpdatePrivacySettings(context.Context, *PrivacySettings) (*PrivacySettings, error)
+	PlaceHold(context.Context, *PlaceHoldRequest) (*HoldResponse, error)
+	ListHolds(context.Context, *HoldsRequest) (*HoldsResponse, error)
+	CancelHold(context.Context, *CancelHoldRequest) (*ReturnSimpleResponse, error)
 	mustEmbedUnimplementedBorrowingServiceServer()
 }
 
@@ -1217,6 +1814,30 @@ func (UnimplementedBorrowingServiceServer) CreateBorrowingTransaction(context.Co
 func (UnimplementedBorrowingServiceServer) UpdateBorrowingTransaction(context.Context, *UpdateBorrowingTransactionRequest) (*BorrowingTransactionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateBorrowingTransaction not implemented")
 }
+func (UnimplementedBorrowingServiceServer) RenewBorrowingTransaction(context.Context, *RenewBorrowingTransactionRequest) (*BorrowingTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenewBorrowingTransaction not implemented")
+}
+func (UnimplementedBorrowingServiceServer) WatchCirculation(*WatchCirculationRequest, grpc.ServerStreamingServer[CirculationEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchCirculation not implemented")
+}
+func (UnimplementedBorrowingServiceServer) ListMyReadingHistory(context.Context, *ReadingHistoryRequest) (*ReadingHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMyReadingHistory not implemented")
+}
+func (UnimplementedBorrowingServiceServer) GetPrivacySettings(context.Context, *Empty) (*PrivacySettings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPrivacySettings not implemented")
+}
+func (UnimplementedBorrowingServiceServer) UpdatePrivacySettings(context.Context, *PrivacySettings) (*PrivacySettings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePrivacySettings not implemented")
+}
+func (UnimplementedBorrowingServiceServer) PlaceHold(context.Context, *PlaceHoldRequest) (*HoldResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlaceHold not implemented")
+}
+func (UnimplementedBorrowingServiceServer) ListHolds(context.Context, *HoldsRequest) (*HoldsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHolds not implemented")
+}
+func (UnimplementedBorrowingServiceServer) CancelHold(context.Context, *CancelHoldRequest) (*ReturnSimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelHold not implemented")
+}
 func (UnimplementedBorrowingServiceServer) mustEmbedUnimplementedBorrowingServiceServer() {}
 func (UnimplementedBorrowingServiceServer) testEmbeddedByValue()                          {}
 
@@ -1310,6 +1931,143 @@ func _BorrowingService_UpdateBorrowingTransaction_Handler(srv interface{}, ctx c
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BorrowingService_RenewBorrowingTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewBorrowingTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowingServiceServer).RenewBorrowingTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowingService_RenewBorrowingTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowingServiceServer).RenewBorrowingTransaction(ctx, req.(*RenewBorrowingTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowingService_WatchCirculation_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchCirculationRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BorrowingServiceServer).WatchCirculation(m, &grpc.GenericServerStream[WatchCirculationRequest, CirculationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BorrowingService_WatchCirculationServer = grpc.ServerStreamingServer[CirculationEvent]
+
+func _BorrowingService_ListMyReadingHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadingHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowingServiceServer).ListMyReadingHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowingService_ListMyReadingHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowingServiceServer).ListMyReadingHistory(ctx, req.(*ReadingHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowingService_GetPrivacySettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowingServiceServer).GetPrivacySettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowingService_GetPrivacySettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowingServiceServer).GetPrivacySettings(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowingService_UpdatePrivacySettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrivacySettings)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowingServiceServer).UpdatePrivacySettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowingService_UpdatePrivacySettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowingServiceServer).UpdatePrivacySettings(ctx, req.(*PrivacySettings))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowingService_PlaceHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaceHoldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowingServiceServer).PlaceHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowingService_PlaceHold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowingServiceServer).PlaceHold(ctx, req.(*PlaceHoldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowingService_ListHolds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HoldsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowingServiceServer).ListHolds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowingService_ListHolds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowingServiceServer).ListHolds(ctx, req.(*HoldsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowingService_CancelHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelHoldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowingServiceServer).CancelHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowingService_CancelHold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowingServiceServer).CancelHold(ctx, req.(*CancelHoldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BorrowingService_ServiceDesc is the grpc.ServiceDesc for BorrowingService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1333,13 +2091,48 @@ var BorrowingService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateBorrowingTransaction",
 			Handler:    _BorrowingService_UpdateBorrowingTransaction_Handler,
 		},
+		{
+			MethodName: "RenewBorrowingTransaction",
+			Handler:    _BorrowingService_RenewBorrowingTransaction_Handler,
+		},
+		{
+			MethodName: "ListMyReadingHistory",
+			Handler:    _BorrowingService_ListMyReadingHistory_Handler,
+		},
+		{
+			MethodName: "GetPrivacySettings",
+			Handler:    _BorrowingService_GetPrivacySettings_Handler,
+		},
+		{
+			MethodName: "UpdatePrivacySettings",
+			Handler:    _BorrowingService_UpdatePrivacySettings_Handler,
+		},
+		{
+			MethodName: "PlaceHold",
+			Handler:    _BorrowingService_PlaceHold_Handler,
+		},
+		{
+			MethodName: "ListHolds",
+			Handler:    _BorrowingService_ListHolds_Handler,
+		},
+		{
+			MethodName: "CancelHold",
+			Handler:    _BorrowingService_CancelHold_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchCirculation",
+			Handler:       _BorrowingService_WatchCirculation_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "library.proto",
 }
 
 const (
 	ReturningService_ReturnBook_FullMethodName = "/go_grpc.ReturningService/ReturnBook"
+	ReturningService_PayFine_FullMethodName    = "/go_grpc.ReturningService/PayFine"
 )
 
 // ReturningServiceClient is the client API for ReturningService service.
@@ -1349,6 +2142,7 @@ const (
 // Returning Service
 type ReturningServiceClient interface {
 	ReturnBook(ctx context.Context, in *ReturnBookRequest, opts ...grpc.CallOption) (*ReturnBookResponse, error)
+	PayFine(ctx context.Context, in *PayFineRequest, opts ...grpc.CallOption) (*ReturnSimpleResponse, error)
 }
 
 type returningServiceClient struct {
@@ -1369,6 +2163,16 @@ func (c *returningServiceClient) ReturnBook(ctx context.Context, in *ReturnBookR
 	return out, nil
 }
 
+func (c *returningServiceClient) PayFine(ctx context.Context, in *PayFineRequest, opts ...grpc.CallOption) (*ReturnSimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReturnSimpleResponse)
+	err := c.cc.Invoke(ctx, ReturningService_PayFine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ReturningServiceServer is the server API for ReturningService service.
 // All implementations must embed UnimplementedReturningServiceServer
 // for forward compatibility.
@@ -1376,6 +2180,7 @@ func (c *returningServiceClient) ReturnBook(ctx context.Context, in *ReturnBookR
 // Returning Service
 type ReturningServiceServer interface {
 	ReturnBook(context.Context, *ReturnBookRequest) (*ReturnBookResponse, error)
+	PayFine(context.Context, *PayFineRequest) (*ReturnSimpleResponse, error)
 	mustEmbedUnimplementedReturningServiceServer()
 }
 
@@ -1389,6 +2194,9 @@ type UnimplementedReturningServiceServer struct{}
 func (UnimplementedReturningServiceServer) ReturnBook(context.Context, *ReturnBookRequest) (*ReturnBookResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReturnBook not implemented")
 }
+func (UnimplementedReturningServiceServer) PayFine(context.Context, *PayFineRequest) (*ReturnSimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PayFine not implemented")
+}
 func (UnimplementedReturningServiceServer) mustEmbedUnimplementedReturningServiceServer() {}
 func (UnimplementedReturningServiceServer) testEmbeddedByValue()                          {}
 
@@ -1428,9 +2236,27 @@ func _ReturningService_ReturnBook_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
-// ReturningService_ServiceDesc is the grpc.ServiceDesc for ReturningService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
+func _ReturningService_PayFine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PayFineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReturningServiceServer).PayFine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReturningService_PayFine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReturningServiceServer).PayFine(ctx, req.(*PayFineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReturningService_ServiceDesc is the grpc.ServiceDesc for ReturningService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
 var ReturningService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "go_grpc.ReturningService",
 	HandlerType: (*ReturningServiceServer)(nil),
@@ -1439,6 +2265,2196 @@ var ReturningService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReturnBook",
 			Handler:    _ReturningService_ReturnBook_Handler,
 		},
+		{
+			MethodName: "PayFine",
+			Handler:    _ReturningService_PayFine_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "library.proto",
+}
+
+const (
+	WebhookService_RegisterWebhook_FullMethodName       = "/go_grpc.WebhookService/RegisterWebhook"
+	WebhookService_ListWebhooks_FullMethodName          = "/go_grpc.WebhookService/ListWebhooks"
+	WebhookService_TestWebhook_FullMethodName           = "/go_grpc.WebhookService/TestWebhook"
+	WebhookService_DeleteWebhook_FullMethodName         = "/go_grpc.WebhookService/DeleteWebhook"
+	WebhookService_ListWebhookDeliveries_FullMethodName = "/go_grpc.WebhookService/ListWebhookDeliveries"
+	WebhookService_RetryWebhookDelivery_FullMethodName  = "/go_grpc.WebhookService/RetryWebhookDelivery"
+)
+
+// WebhookServiceClient is the client API for WebhookService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Webhook Service
+type WebhookServiceClient interface {
+	RegisterWebhook(ctx context.Context, in *RegisterWebhookRequest, opts ...grpc.CallOption) (*WebhookResponse, error)
+	ListWebhooks(ctx context.Context, in *ParameterReq, opts ...grpc.CallOption) (*WebhooksResponse, error)
+	TestWebhook(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*TestWebhookResponse, error)
+	DeleteWebhook(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error)
+	ListWebhookDeliveries(ctx context.Context, in *WebhookDeliveriesRequest, opts ...grpc.CallOption) (*WebhookDeliveriesResponse, error)
+	RetryWebhookDelivery(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*ReturnSimpleResponse, error)
+}
+
+type webhookServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWebhookServiceClient(cc grpc.ClientConnInterface) WebhookServiceClient {
+	return &webhookServiceClient{cc}
+}
+
+func (c *webhookServiceClient) RegisterWebhook(ctx context.Context, in *RegisterWebhookRequest, opts ...grpc.CallOption) (*WebhookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebhookResponse)
+	err := c.cc.Invoke(ctx, WebhookService_RegisterWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) ListWebhooks(ctx context.Context, in *ParameterReq, opts ...grpc.CallOption) (*WebhooksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebhooksResponse)
+	err := c.cc.Invoke(ctx, WebhookService_ListWebhooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) TestWebhook(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*TestWebhookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TestWebhookResponse)
+	err := c.cc.Invoke(ctx, WebhookService_TestWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) DeleteWebhook(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, WebhookService_DeleteWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) ListWebhookDeliveries(ctx context.Context, in *WebhookDeliveriesRequest, opts ...grpc.CallOption) (*WebhookDeliveriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebhookDeliveriesResponse)
+	err := c.cc.Invoke(ctx, WebhookService_ListWebhookDeliveries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) RetryWebhookDelivery(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*ReturnSimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReturnSimpleResponse)
+	err := c.cc.Invoke(ctx, WebhookService_RetryWebhookDelivery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WebhookServiceServer is the server API for WebhookService service.
+// All implementations must embed UnimplementedWebhookServiceServer
+// for forward compatibility.
+//
+// Webhook Service
+type WebhookServiceServer interface {
+	RegisterWebhook(context.Context, *RegisterWebhookRequest) (*WebhookResponse, error)
+	ListWebhooks(context.Context, *ParameterReq) (*WebhooksResponse, error)
+	TestWebhook(context.Context, *IdRequest) (*TestWebhookResponse, error)
+	DeleteWebhook(context.Context, *IdRequest) (*Empty, error)
+	ListWebhookDeliveries(context.Context, *WebhookDeliveriesRequest) (*WebhookDeliveriesResponse, error)
+	RetryWebhookDelivery(context.Context, *IdRequest) (*ReturnSimpleResponse, error)
+	mustEmbedUnimplementedWebhookServiceServer()
+}
+
+// UnimplementedWebhookServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWebhookServiceServer struct{}
+
+func (UnimplementedWebhookServiceServer) RegisterWebhook(context.Context, *RegisterWebhookRequest) (*WebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterWebhook not implemented")
+}
+func (UnimplementedWebhookServiceServer) ListWebhooks(context.Context, *ParameterReq) (*WebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedWebhookServiceServer) TestWebhook(context.Context, *IdRequest) (*TestWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TestWebhook not implemented")
+}
+func (UnimplementedWebhookServiceServer) DeleteWebhook(context.Context, *IdRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (UnimplementedWebhookServiceServer) ListWebhookDeliveries(context.Context, *WebhookDeliveriesRequest) (*WebhookDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookDeliveries not implemented")
+}
+func (UnimplementedWebhookServiceServer) RetryWebhookDelivery(context.Context, *IdRequest) (*ReturnSimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetryWebhookDelivery not implemented")
+}
+func (UnimplementedWebhookServiceServer) mustEmbedUnimplementedWebhookServiceServer() {}
+func (UnimplementedWebhookServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeWebhookServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WebhookServiceServer will
+// result in compilation errors.
+type UnsafeWebhookServiceServer interface {
+	mustEmbedUnimplementedWebhookServiceServer()
+}
+
+func RegisterWebhookServiceServer(s grpc.ServiceRegistrar, srv WebhookServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWebhookServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WebhookService_ServiceDesc, srv)
+}
+
+func _WebhookService_RegisterWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).RegisterWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WebhookService_RegisterWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).RegisterWebhook(ctx, req.(*RegisterWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParameterReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WebhookService_ListWebhooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).ListWebhooks(ctx, req.(*ParameterReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_TestWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).TestWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WebhookService_TestWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).TestWebhook(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WebhookService_DeleteWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).DeleteWebhook(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_ListWebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).ListWebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WebhookService_ListWebhookDeliveries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).ListWebhookDeliveries(ctx, req.(*WebhookDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_RetryWebhookDelivery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).RetryWebhookDelivery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WebhookService_RetryWebhookDelivery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).RetryWebhookDelivery(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WebhookService_ServiceDesc is the grpc.ServiceDesc for WebhookService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WebhookService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "go_grpc.WebhookService",
+	HandlerType: (*WebhookServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterWebhook",
+			Handler:    _WebhookService_RegisterWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _WebhookService_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "TestWebhook",
+			Handler:    _WebhookService_TestWebhook_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _WebhookService_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhookDeliveries",
+			Handler:    _WebhookService_ListWebhookDeliveries_Handler,
+		},
+		{
+			MethodName: "RetryWebhookDelivery",
+			Handler:    _WebhookService_RetryWebhookDelivery_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "library.proto",
+}
+
+const (
+	NotificationService_GetNotificationPreferences_FullMethodName    = "/go_grpc.NotificationService/GetNotificationPreferences"
+	NotificationService_UpdateNotificationPreferences_FullMethodName = "/go_grpc.NotificationService/UpdateNotificationPreferences"
+	NotificationService_ListMyNotifications_FullMethodName           = "/go_grpc.NotificationService/ListMyNotifications"
+	NotificationService_MarkRead_FullMethodName                      = "/go_grpc.NotificationService/MarkRead"
+	NotificationService_MarkAllRead_FullMethodName                   = "/go_grpc.NotificationService/MarkAllRead"
+	NotificationService_SubscribeNotifications_FullMethodName        = "/go_grpc.NotificationService/SubscribeNotifications"
+	NotificationService_BroadcastAnnouncement_FullMethodName         = "/go_grpc.NotificationService/BroadcastAnnouncement"
+)
+
+// NotificationServiceClient is the client API for NotificationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Notification Service
+type NotificationServiceClient interface {
+	GetNotificationPreferences(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*NotificationPreferencesResponse, error)
+	UpdateNotificationPreferences(ctx context.Context, in *NotificationPreferences, opts ...grpc.CallOption) (*NotificationPreferencesResponse, error)
+	ListMyNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*NotificationsResponse, error)
+	MarkRead(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*ReturnSimpleResponse, error)
+	MarkAllRead(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReturnSimpleResponse, error)
+	SubscribeNotifications(ctx context.Context, in *Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Notification], error)
+	BroadcastAnnouncement(ctx context.Context, in *BroadcastRequest, opts ...grpc.CallOption) (*BroadcastResponse, error)
+}
+
+type notificationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNotificationServiceClient(cc grpc.ClientConnInterface) NotificationServiceClient {
+	return &notificationServiceClient{cc}
+}
+
+func (c *notificationServiceClient) GetNotificationPreferences(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*NotificationPreferencesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NotificationPreferencesResponse)
+	err := c.cc.Invoke(ctx, NotificationService_GetNotificationPreferences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) UpdateNotificationPreferences(ctx context.Context, in *NotificationPreferences, opts ...grpc.CallOption) (*NotificationPreferencesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NotificationPreferencesResponse)
+	err := c.cc.Invoke(ctx, NotificationService_UpdateNotificationPreferences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) ListMyNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*NotificationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NotificationsResponse)
+	err := c.cc.Invoke(ctx, NotificationService_ListMyNotifications_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) MarkRead(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*ReturnSimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReturnSimpleResponse)
+	err := c.cc.Invoke(ctx, NotificationService_MarkRead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) MarkAllRead(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReturnSimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReturnSimpleResponse)
+	err := c.cc.Invoke(ctx, NotificationService_MarkAllRead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SubscribeNotifications(ctx context.Context, in *Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Notification], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NotificationService_ServiceDesc.Streams[0], NotificationService_SubscribeNotifications_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Empty, Notification]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NotificationService_SubscribeNotificationsClient = grpc.ServerStreamingClient[Notification]
+
+func (c *notificationServiceClient) BroadcastAnnouncement(ctx context.Context, in *BroadcastRequest, opts ...grpc.CallOption) (*BroadcastResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BroadcastResponse)
+	err := c.cc.Invoke(ctx, NotificationService_BroadcastAnnouncement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NotificationServiceServer is the server API for NotificationService service.
+// All implementations must embed UnimplementedNotificationServiceServer
+// for forward compatibility.
+//
+// Notification Service
+type NotificationServiceServer interface {
+	GetNotificationPreferences(context.Context, *Empty) (*NotificationPreferencesResponse, error)
+	UpdateNotificationPreferences(context.Context, *NotificationPreferences) (*NotificationPreferencesResponse, error)
+	ListMyNotifications(context.Context, *ListNotificationsRequest) (*NotificationsResponse, error)
+	MarkRead(context.Context, *IdRequest) (*ReturnSimpleResponse, error)
+	MarkAllRead(context.Context, *Empty) (*ReturnSimpleResponse, error)
+	SubscribeNotifications(*Empty, grpc.ServerStreamingServer[Notification]) error
+	BroadcastAnnouncement(context.Context, *BroadcastRequest) (*BroadcastResponse, error)
+	mustEmbedUnimplementedNotificationServiceServer()
+}
+
+// UnimplementedNotificationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNotificationServiceServer struct{}
+
+func (UnimplementedNotificationServiceServer) GetNotificationPreferences(context.Context, *Empty) (*NotificationPreferencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNotificationPreferences not implemented")
+}
+func (UnimplementedNotificationServiceServer) UpdateNotificationPreferences(context.Context, *NotificationPreferences) (*NotificationPreferencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNotificationPreferences not implemented")
+}
+func (UnimplementedNotificationServiceServer) ListMyNotifications(context.Context, *ListNotificationsRequest) (*NotificationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMyNotifications not implemented")
+}
+func (UnimplementedNotificationServiceServer) MarkRead(context.Context, *IdRequest) (*ReturnSimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkRead not implemented")
+}
+func (UnimplementedNotificationServiceServer) MarkAllRead(context.Context, *Empty) (*ReturnSimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkAllRead not implemented")
+}
+func (UnimplementedNotificationServiceServer) SubscribeNotifications(*Empty, grpc.ServerStreamingServer[Notification]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeNotifications not implemented")
+}
+func (UnimplementedNotificationServiceServer) BroadcastAnnouncement(context.Context, *BroadcastRequest) (*BroadcastResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastAnnouncement not implemented")
+}
+func (UnimplementedNotificationServiceServer) mustEmbedUnimplementedNotificationServiceServer() {}
+func (UnimplementedNotificationServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeNotificationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NotificationServiceServer will
+// result in compilation errors.
+type UnsafeNotificationServiceServer interface {
+	mustEmbedUnimplementedNotificationServiceServer()
+}
+
+func RegisterNotificationServiceServer(s grpc.ServiceRegistrar, srv NotificationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedNotificationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NotificationService_ServiceDesc, srv)
+}
+
+func _NotificationService_GetNotificationPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).GetNotificationPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_GetNotificationPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).GetNotificationPreferences(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_UpdateNotificationPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NotificationPreferences)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).UpdateNotificationPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_UpdateNotificationPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).UpdateNotificationPreferences(ctx, req.(*NotificationPreferences))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_ListMyNotifications_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNotificationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ListMyNotifications(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_ListMyNotifications_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ListMyNotifications(ctx, req.(*ListNotificationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_MarkRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).MarkRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_MarkRead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).MarkRead(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_MarkAllRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).MarkAllRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_MarkAllRead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).MarkAllRead(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SubscribeNotifications_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NotificationServiceServer).SubscribeNotifications(m, &grpc.GenericServerStream[Empty, Notification]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NotificationService_SubscribeNotificationsServer = grpc.ServerStreamingServer[Notification]
+
+func _NotificationService_BroadcastAnnouncement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).BroadcastAnnouncement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_BroadcastAnnouncement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).BroadcastAnnouncement(ctx, req.(*BroadcastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NotificationService_ServiceDesc is the grpc.ServiceDesc for NotificationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NotificationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "go_grpc.NotificationService",
+	HandlerType: (*NotificationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetNotificationPreferences",
+			Handler:    _NotificationService_GetNotificationPreferences_Handler,
+		},
+		{
+			MethodName: "UpdateNotificationPreferences",
+			Handler:    _NotificationService_UpdateNotificationPreferences_Handler,
+		},
+		{
+			MethodName: "ListMyNotifications",
+			Handler:    _NotificationService_ListMyNotifications_Handler,
+		},
+		{
+			MethodName: "MarkRead",
+			Handler:    _NotificationService_MarkRead_Handler,
+		},
+		{
+			MethodName: "MarkAllRead",
+			Handler:    _NotificationService_MarkAllRead_Handler,
+		},
+		{
+			MethodName: "BroadcastAnnouncement",
+			Handler:    _NotificationService_BroadcastAnnouncement_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeNotifications",
+			Handler:       _NotificationService_SubscribeNotifications_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "library.proto",
+}
+
+const (
+	ReportService_ListReports_FullMethodName          = "/go_grpc.ReportService/ListReports"
+	ReportService_RunReport_FullMethodName            = "/go_grpc.ReportService/RunReport"
+	ReportService_ExportReport_FullMethodName         = "/go_grpc.ReportService/ExportReport"
+	ReportService_CreateReportSchedule_FullMethodName = "/go_grpc.ReportService/CreateReportSchedule"
+	ReportService_UpdateReportSchedule_FullMethodName = "/go_grpc.ReportService/UpdateReportSchedule"
+	ReportService_ListReportSchedules_FullMethodName  = "/go_grpc.ReportService/ListReportSchedules"
+	ReportService_DeleteReportSchedule_FullMethodName = "/go_grpc.ReportService/DeleteReportSchedule"
+	ReportService_RunReportSchedule_FullMethodName    = "/go_grpc.ReportService/RunReportSchedule"
+	ReportService_ListReportRuns_FullMethodName       = "/go_grpc.ReportService/ListReportRuns"
+)
+
+// ReportServiceClient is the client API for ReportService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Report Service
+type ReportServiceClient interface {
+	ListReports(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReportDefinitionsResponse, error)
+	RunReport(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (*ReportResponse, error)
+	ExportReport(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReportChunk], error)
+	CreateReportSchedule(ctx context.Context, in *ReportScheduleRequest, opts ...grpc.CallOption) (*ReportScheduleResponse, error)
+	UpdateReportSchedule(ctx context.Context, in *ReportScheduleRequest, opts ...grpc.CallOption) (*ReportScheduleResponse, error)
+	ListReportSchedules(ctx context.Context, in *ParameterReq, opts ...grpc.CallOption) (*ReportSchedulesResponse, error)
+	DeleteReportSchedule(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error)
+	RunReportSchedule(ctx context.Context, in *RunReportScheduleRequest, opts ...grpc.CallOption) (*ReportRunResponse, error)
+	ListReportRuns(ctx context.Context, in *ReportRunsRequest, opts ...grpc.CallOption) (*ReportRunsResponse, error)
+}
+
+type reportServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReportServiceClient(cc grpc.ClientConnInterface) ReportServiceClient {
+	return &reportServiceClient{cc}
+}
+
+func (c *reportServiceClient) ListReports(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReportDefinitionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportDefinitionsResponse)
+	err := c.cc.Invoke(ctx, ReportService_ListReports_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportServiceClient) RunReport(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (*ReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportResponse)
+	err := c.cc.Invoke(ctx, ReportService_RunReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportServiceClient) ExportReport(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReportChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ReportService_ServiceDesc.Streams[0], ReportService_ExportReport_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReportRequest, ReportChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ReportService_ExportReportClient = grpc.ServerStreamingClient[ReportChunk]
+
+func (c *reportServiceClient) CreateReportSchedule(ctx context.Context, in *ReportScheduleRequest, opts ...grpc.CallOption) (*ReportScheduleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportScheduleResponse)
+	err := c.cc.Invoke(ctx, ReportService_CreateReportSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportServiceClient) UpdateReportSchedule(ctx context.Context, in *ReportScheduleRequest, opts ...grpc.CallOption) (*ReportScheduleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportScheduleResponse)
+	err := c.cc.Invoke(ctx, ReportService_UpdateReportSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportServiceClient) ListReportSchedules(ctx context.Context, in *ParameterReq, opts ...grpc.CallOption) (*ReportSchedulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportSchedulesResponse)
+	err := c.cc.Invoke(ctx, ReportService_ListReportSchedules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportServiceClient) DeleteReportSchedule(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, ReportService_DeleteReportSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportServiceClient) RunReportSchedule(ctx context.Context, in *RunReportScheduleRequest, opts ...grpc.CallOption) (*ReportRunResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportRunResponse)
+	err := c.cc.Invoke(ctx, ReportService_RunReportSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportServiceClient) ListReportRuns(ctx context.Context, in *ReportRunsRequest, opts ...grpc.CallOption) (*ReportRunsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportRunsResponse)
+	err := c.cc.Invoke(ctx, ReportService_ListReportRuns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReportServiceServer is the server API for ReportService service.
+// All implementations must embed UnimplementedReportServiceServer
+// for forward compatibility.
+//
+// Report Service
+type ReportServiceServer interface {
+	ListReports(context.Context, *Empty) (*ReportDefinitionsResponse, error)
+	RunReport(context.Context, *ReportRequest) (*ReportResponse, error)
+	ExportReport(*ReportRequest, grpc.ServerStreamingServer[ReportChunk]) error
+	CreateReportSchedule(context.Context, *ReportScheduleRequest) (*ReportScheduleResponse, error)
+	UpdateReportSchedule(context.Context, *ReportScheduleRequest) (*ReportScheduleResponse, error)
+	ListReportSchedules(context.Context, *ParameterReq) (*ReportSchedulesResponse, error)
+	DeleteReportSchedule(context.Context, *IdRequest) (*Empty, error)
+	RunReportSchedule(context.Context, *RunReportScheduleRequest) (*ReportRunResponse, error)
+	ListReportRuns(context.Context, *ReportRunsRequest) (*ReportRunsResponse, error)
+	mustEmbedUnimplementedReportServiceServer()
+}
+
+// UnimplementedReportServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReportServiceServer struct{}
+
+func (UnimplementedReportServiceServer) ListReports(context.Context, *Empty) (*ReportDefinitionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReports not implemented")
+}
+func (UnimplementedReportServiceServer) RunReport(context.Context, *ReportRequest) (*ReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunReport not implemented")
+}
+func (UnimplementedReportServiceServer) ExportReport(*ReportRequest, grpc.ServerStreamingServer[ReportChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportReport not implemented")
+}
+func (UnimplementedReportServiceServer) CreateReportSchedule(context.Context, *ReportScheduleRequest) (*ReportScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReportSchedule not implemented")
+}
+func (UnimplementedReportServiceServer) UpdateReportSchedule(context.Context, *ReportScheduleRequest) (*ReportScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReportSchedule not implemented")
+}
+func (UnimplementedReportServiceServer) ListReportSchedules(context.Context, *ParameterReq) (*ReportSchedulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReportSchedules not implemented")
+}
+func (UnimplementedReportServiceServer) DeleteReportSchedule(context.Context, *IdRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteReportSchedule not implemented")
+}
+func (UnimplementedReportServiceServer) RunReportSchedule(context.Context, *RunReportScheduleRequest) (*ReportRunResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunReportSchedule not implemented")
+}
+func (UnimplementedReportServiceServer) ListReportRuns(context.Context, *ReportRunsRequest) (*ReportRunsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReportRuns not implemented")
+}
+func (UnimplementedReportServiceServer) mustEmbedUnimplementedReportServiceServer() {}
+func (UnimplementedReportServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeReportServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReportServiceServer will
+// result in compilation errors.
+type UnsafeReportServiceServer interface {
+	mustEmbedUnimplementedReportServiceServer()
+}
+
+func RegisterReportServiceServer(s grpc.ServiceRegistrar, srv ReportServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReportServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReportService_ServiceDesc, srv)
+}
+
+func _ReportService_ListReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).ListReports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReportService_ListReports_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).ListReports(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReportService_RunReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).RunReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReportService_RunReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).RunReport(ctx, req.(*ReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReportService_ExportReport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReportServiceServer).ExportReport(m, &grpc.GenericServerStream[ReportRequest, ReportChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ReportService_ExportReportServer = grpc.ServerStreamingServer[ReportChunk]
+
+func _ReportService_CreateReportSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).CreateReportSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReportService_CreateReportSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).CreateReportSchedule(ctx, req.(*ReportScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReportService_UpdateReportSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).UpdateReportSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReportService_UpdateReportSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).UpdateReportSchedule(ctx, req.(*ReportScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReportService_ListReportSchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParameterReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).ListReportSchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReportService_ListReportSchedules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).ListReportSchedules(ctx, req.(*ParameterReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReportService_DeleteReportSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).DeleteReportSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReportService_DeleteReportSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).DeleteReportSchedule(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReportService_RunReportSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunReportScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).RunReportSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReportService_RunReportSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).RunReportSchedule(ctx, req.(*RunReportScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReportService_ListReportRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).ListReportRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReportService_ListReportRuns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).ListReportRuns(ctx, req.(*ReportRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReportService_ServiceDesc is the grpc.ServiceDesc for ReportService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReportService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "go_grpc.ReportService",
+	HandlerType: (*ReportServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListReports",
+			Handler:    _ReportService_ListReports_Handler,
+		},
+		{
+			MethodName: "RunReport",
+			Handler:    _ReportService_RunReport_Handler,
+		},
+		{
+			MethodName: "CreateReportSchedule",
+			Handler:    _ReportService_CreateReportSchedule_Handler,
+		},
+		{
+			MethodName: "UpdateReportSchedule",
+			Handler:    _ReportService_UpdateReportSchedule_Handler,
+		},
+		{
+			MethodName: "ListReportSchedules",
+			Handler:    _ReportService_ListReportSchedules_Handler,
+		},
+		{
+			MethodName: "DeleteReportSchedule",
+			Handler:    _ReportService_DeleteReportSchedule_Handler,
+		},
+		{
+			MethodName: "RunReportSchedule",
+			Handler:    _ReportService_RunReportSchedule_Handler,
+		},
+		{
+			MethodName: "ListReportRuns",
+			Handler:    _ReportService_ListReportRuns_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportReport",
+			Handler:       _ReportService_ExportReport_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "library.proto",
+}
+
+const (
+	AccountService_ExportMyData_FullMethodName = "/go_grpc.AccountService/ExportMyData"
+	AccountService_EraseAccount_FullMethodName = "/go_grpc.AccountService/EraseAccount"
+)
+
+// AccountServiceClient is the client API for AccountService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Account Service, data subject requests
+type AccountServiceClient interface {
+	ExportMyData(ctx context.Context, in *ExportMyDataRequest, opts ...grpc.CallOption) (*ExportMyDataResponse, error)
+	EraseAccount(ctx context.Context, in *EraseAccountRequest, opts ...grpc.CallOption) (*ReturnSimpleResponse, error)
+}
+
+type accountServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAccountServiceClient(cc grpc.ClientConnInterface) AccountServiceClient {
+	return &accountServiceClient{cc}
+}
+
+func (c *accountServiceClient) ExportMyData(ctx context.Context, in *ExportMyDataRequest, opts ...grpc.CallOption) (*ExportMyDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportMyDataResponse)
+	err := c.cc.Invoke(ctx, AccountService_ExportMyData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) EraseAccount(ctx context.Context, in *EraseAccountRequest, opts ...grpc.CallOption) (*ReturnSimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReturnSimpleResponse)
+	err := c.cc.Invoke(ctx, AccountService_EraseAccount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AccountServiceServer is the server API for AccountService service.
+// All implementations must embed UnimplementedAccountServiceServer
+// for forward compatibility.
+//
+// Account Service, data subject requests
+type AccountServiceServer interface {
+	ExportMyData(context.Context, *ExportMyDataRequest) (*ExportMyDataResponse, error)
+	EraseAccount(context.Context, *EraseAccountRequest) (*ReturnSimpleResponse, error)
+	mustEmbedUnimplementedAccountServiceServer()
+}
+
+// UnimplementedAccountServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAccountServiceServer struct{}
+
+func (UnimplementedAccountServiceServer) ExportMyData(context.Context, *ExportMyDataRequest) (*ExportMyDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportMyData not implemented")
+}
+func (UnimplementedAccountServiceServer) EraseAccount(context.Context, *EraseAccountRequest) (*ReturnSimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EraseAccount not implemented")
+}
+func (UnimplementedAccountServiceServer) mustEmbedUnimplementedAccountServiceServer() {}
+func (UnimplementedAccountServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeAccountServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AccountServiceServer will
+// result in compilation errors.
+type UnsafeAccountServiceServer interface {
+	mustEmbedUnimplementedAccountServiceServer()
+}
+
+func RegisterAccountServiceServer(s grpc.ServiceRegistrar, srv AccountServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAccountServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AccountService_ServiceDesc, srv)
+}
+
+func _AccountService_ExportMyData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportMyDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).ExportMyData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_ExportMyData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).ExportMyData(ctx, req.(*ExportMyDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_EraseAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EraseAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).EraseAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_EraseAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).EraseAccount(ctx, req.(*EraseAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AccountService_ServiceDesc is the grpc.ServiceDesc for AccountService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AccountService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "go_grpc.AccountService",
+	HandlerType: (*AccountServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ExportMyData",
+			Handler:    _AccountService_ExportMyData_Handler,
+		},
+		{
+			MethodName: "EraseAccount",
+			Handler:    _AccountService_EraseAccount_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "library.proto",
+}
+
+const (
+	ReviewService_PostReview_FullMethodName      = "/go_grpc.ReviewService/PostReview"
+	ReviewService_ListBookReviews_FullMethodName = "/go_grpc.ReviewService/ListBookReviews"
+	ReviewService_DeleteReview_FullMethodName    = "/go_grpc.ReviewService/DeleteReview"
+	ReviewService_ListReviewQueue_FullMethodName = "/go_grpc.ReviewService/ListReviewQueue"
+	ReviewService_ModerateReview_FullMethodName  = "/go_grpc.ReviewService/ModerateReview"
+)
+
+// ReviewServiceClient is the client API for ReviewService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Review Service
+type ReviewServiceClient interface {
+	PostReview(ctx context.Context, in *PostReviewRequest, opts ...grpc.CallOption) (*ReviewResponse, error)
+	ListBookReviews(ctx context.Context, in *ListBookReviewsRequest, opts ...grpc.CallOption) (*ReviewsResponse, error)
+	DeleteReview(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error)
+	ListReviewQueue(ctx context.Context, in *ReviewQueueRequest, opts ...grpc.CallOption) (*ReviewsResponse, error)
+	ModerateReview(ctx context.Context, in *ModerateReviewRequest, opts ...grpc.CallOption) (*ReviewResponse, error)
+}
+
+type reviewServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReviewServiceClient(cc grpc.ClientConnInterface) ReviewServiceClient {
+	return &reviewServiceClient{cc}
+}
+
+func (c *reviewServiceClient) PostReview(ctx context.Context, in *PostReviewRequest, opts ...grpc.CallOption) (*ReviewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReviewResponse)
+	err := c.cc.Invoke(ctx, ReviewService_PostReview_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewServiceClient) ListBookReviews(ctx context.Context, in *ListBookReviewsRequest, opts ...grpc.CallOption) (*ReviewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReviewsResponse)
+	err := c.cc.Invoke(ctx, ReviewService_ListBookReviews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewServiceClient) DeleteReview(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, ReviewService_DeleteReview_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewServiceClient) ListReviewQueue(ctx context.Context, in *ReviewQueueRequest, opts ...grpc.CallOption) (*ReviewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReviewsResponse)
+	err := c.cc.Invoke(ctx, ReviewService_ListReviewQueue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewServiceClient) ModerateReview(ctx context.Context, in *ModerateReviewRequest, opts ...grpc.CallOption) (*ReviewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReviewResponse)
+	err := c.cc.Invoke(ctx, ReviewService_ModerateReview_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReviewServiceServer is the server API for ReviewService service.
+// All implementations must embed UnimplementedReviewServiceServer
+// for forward compatibility.
+//
+// Review Service
+type ReviewServiceServer interface {
+	PostReview(context.Context, *PostReviewRequest) (*ReviewResponse, error)
+	ListBookReviews(context.Context, *ListBookReviewsRequest) (*ReviewsResponse, error)
+	DeleteReview(context.Context, *IdRequest) (*Empty, error)
+	ListReviewQueue(context.Context, *ReviewQueueRequest) (*ReviewsResponse, error)
+	ModerateReview(context.Context, *ModerateReviewRequest) (*ReviewResponse, error)
+	mustEmbedUnimplementedReviewServiceServer()
+}
+
+// UnimplementedReviewServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReviewServiceServer struct{}
+
+func (UnimplementedReviewServiceServer) PostReview(context.Context, *PostReviewRequest) (*ReviewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostReview not implemented")
+}
+func (UnimplementedReviewServiceServer) ListBookReviews(context.Context, *ListBookReviewsRequest) (*ReviewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBookReviews not implemented")
+}
+func (UnimplementedReviewServiceServer) DeleteReview(context.Context, *IdRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteReview not implemented")
+}
+func (UnimplementedReviewServiceServer) ListReviewQueue(context.Context, *ReviewQueueRequest) (*ReviewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReviewQueue not implemented")
+}
+func (UnimplementedReviewServiceServer) ModerateReview(context.Context, *ModerateReviewRequest) (*ReviewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModerateReview not implemented")
+}
+func (UnimplementedReviewServiceServer) mustEmbedUnimplementedReviewServiceServer() {}
+func (UnimplementedReviewServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeReviewServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReviewServiceServer will
+// result in compilation errors.
+type UnsafeReviewServiceServer interface {
+	mustEmbedUnimplementedReviewServiceServer()
+}
+
+func RegisterReviewServiceServer(s grpc.ServiceRegistrar, srv ReviewServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReviewServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReviewService_ServiceDesc, srv)
+}
+
+func _ReviewService_PostReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewServiceServer).PostReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewService_PostReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewServiceServer).PostReview(ctx, req.(*PostReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewService_ListBookReviews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBookReviewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewServiceServer).ListBookReviews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewService_ListBookReviews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewServiceServer).ListBookReviews(ctx, req.(*ListBookReviewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewService_DeleteReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewServiceServer).DeleteReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewService_DeleteReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewServiceServer).DeleteReview(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewService_ListReviewQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewServiceServer).ListReviewQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewService_ListReviewQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewServiceServer).ListReviewQueue(ctx, req.(*ReviewQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewService_ModerateReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerateReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewServiceServer).ModerateReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewService_ModerateReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewServiceServer).ModerateReview(ctx, req.(*ModerateReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReviewService_ServiceDesc is the grpc.ServiceDesc for ReviewService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReviewService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "go_grpc.ReviewService",
+	HandlerType: (*ReviewServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PostReview",
+			Handler:    _ReviewService_PostReview_Handler,
+		},
+		{
+			MethodName: "ListBookReviews",
+			Handler:    _ReviewService_ListBookReviews_Handler,
+		},
+		{
+			MethodName: "DeleteReview",
+			Handler:    _ReviewService_DeleteReview_Handler,
+		},
+		{
+			MethodName: "ListReviewQueue",
+			Handler:    _ReviewService_ListReviewQueue_Handler,
+		},
+		{
+			MethodName: "ModerateReview",
+			Handler:    _ReviewService_ModerateReview_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "library.proto",
+}
+
+const (
+	ReadingListService_CreateReadingList_FullMethodName     = "/go_grpc.ReadingListService/CreateReadingList"
+	ReadingListService_UpdateReadingList_FullMethodName     = "/go_grpc.ReadingListService/UpdateReadingList"
+	ReadingListService_DeleteReadingList_FullMethodName     = "/go_grpc.ReadingListService/DeleteReadingList"
+	ReadingListService_ListReadingLists_FullMethodName      = "/go_grpc.ReadingListService/ListReadingLists"
+	ReadingListService_GetReadingList_FullMethodName        = "/go_grpc.ReadingListService/GetReadingList"
+	ReadingListService_AddReadingListItem_FullMethodName    = "/go_grpc.ReadingListService/AddReadingListItem"
+	ReadingListService_RemoveReadingListItem_FullMethodName = "/go_grpc.ReadingListService/RemoveReadingListItem"
+	ReadingListService_ReorderReadingList_FullMethodName    = "/go_grpc.ReadingListService/ReorderReadingList"
+	ReadingListService_ShareReadingList_FullMethodName      = "/go_grpc.ReadingListService/ShareReadingList"
+	ReadingListService_GetSharedReadingList_FullMethodName  = "/go_grpc.ReadingListService/GetSharedReadingList"
+	ReadingListService_HoldUnavailable_FullMethodName       = "/go_grpc.ReadingListService/HoldUnavailable"
+)
+
+// ReadingListServiceClient is the client API for ReadingListService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Reading List Service
+type ReadingListServiceClient interface {
+	CreateReadingList(ctx context.Context, in *ReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	UpdateReadingList(ctx context.Context, in *ReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	DeleteReadingList(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error)
+	ListReadingLists(ctx context.Context, in *ParameterReq, opts ...grpc.CallOption) (*ReadingListsResponse, error)
+	GetReadingList(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	AddReadingListItem(ctx context.Context, in *ReadingListItemRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	RemoveReadingListItem(ctx context.Context, in *ReadingListItemRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	ReorderReadingList(ctx context.Context, in *ReorderReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	ShareReadingList(ctx context.Context, in *ShareReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	GetSharedReadingList(ctx context.Context, in *SharedReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error)
+	HoldUnavailable(ctx context.Context, in *HoldUnavailableRequest, opts ...grpc.CallOption) (*HoldUnavailableResponse, error)
+}
+
+type readingListServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReadingListServiceClient(cc grpc.ClientConnInterface) ReadingListServiceClient {
+	return &readingListServiceClient{cc}
+}
+
+func (c *readingListServiceClient) CreateReadingList(ctx context.Context, in *ReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, ReadingListService_CreateReadingList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListServiceClient) UpdateReadingList(ctx context.Context, in *ReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, ReadingListService_UpdateReadingList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListServiceClient) DeleteReadingList(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, ReadingListService_DeleteReadingList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListServiceClient) ListReadingLists(ctx context.Context, in *ParameterReq, opts ...grpc.CallOption) (*ReadingListsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadingListsResponse)
+	err := c.cc.Invoke(ctx, ReadingListService_ListReadingLists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListServiceClient) GetReadingList(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, ReadingListService_GetReadingList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListServiceClient) AddReadingListItem(ctx context.Context, in *ReadingListItemRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, ReadingListService_AddReadingListItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListServiceClient) RemoveReadingListItem(ctx context.Context, in *ReadingListItemRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, ReadingListService_RemoveReadingListItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListServiceClient) ReorderReadingList(ctx context.Context, in *ReorderReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, ReadingListService_ReorderReadingList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListServiceClient) ShareReadingList(ctx context.Context, in *ShareReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, ReadingListService_ShareReadingList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListServiceClient) GetSharedReadingList(ctx context.Context, in *SharedReadingListRequest, opts ...grpc.CallOption) (*ReadingListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadingListResponse)
+	err := c.cc.Invoke(ctx, ReadingListService_GetSharedReadingList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *readingListServiceClient) HoldUnavailable(ctx context.Context, in *HoldUnavailableRequest, opts ...grpc.CallOption) (*HoldUnavailableResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HoldUnavailableResponse)
+	err := c.cc.Invoke(ctx, ReadingListService_HoldUnavailable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReadingListServiceServer is the server API for ReadingListService service.
+// All implementations must embed UnimplementedReadingListServiceServer
+// for forward compatibility.
+//
+// Reading List Service
+type ReadingListServiceServer interface {
+	CreateReadingList(context.Context, *ReadingListRequest) (*ReadingListResponse, error)
+	UpdateReadingList(context.Context, *ReadingListRequest) (*ReadingListResponse, error)
+	DeleteReadingList(context.Context, *IdRequest) (*Empty, error)
+	ListReadingLists(context.Context, *ParameterReq) (*ReadingListsResponse, error)
+	GetReadingList(context.Context, *IdRequest) (*ReadingListResponse, error)
+	AddReadingListItem(context.Context, *ReadingListItemRequest) (*ReadingListResponse, error)
+	RemoveReadingListItem(context.Context, *ReadingListItemRequest) (*ReadingListResponse, error)
+	ReorderReadingList(context.Context, *ReorderReadingListRequest) (*ReadingListResponse, error)
+	ShareReadingList(context.Context, *ShareReadingListRequest) (*ReadingListResponse, error)
+	GetSharedReadingList(context.Context, *SharedReadingListRequest) (*ReadingListResponse, error)
+	HoldUnavailable(context.Context, *HoldUnavailableRequest) (*HoldUnavailableResponse, error)
+	mustEmbedUnimplementedReadingListServiceServer()
+}
+
+// UnimplementedReadingListServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReadingListServiceServer struct{}
+
+func (UnimplementedReadingListServiceServer) CreateReadingList(context.Context, *ReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReadingList not implemented")
+}
+func (UnimplementedReadingListServiceServer) UpdateReadingList(context.Context, *ReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReadingList not implemented")
+}
+func (UnimplementedReadingListServiceServer) DeleteReadingList(context.Context, *IdRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteReadingList not implemented")
+}
+func (UnimplementedReadingListServiceServer) ListReadingLists(context.Context, *ParameterReq) (*ReadingListsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReadingLists not implemented")
+}
+func (UnimplementedReadingListServiceServer) GetReadingList(context.Context, *IdRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReadingList not implemented")
+}
+func (UnimplementedReadingListServiceServer) AddReadingListItem(context.Context, *ReadingListItemRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddReadingListItem not implemented")
+}
+func (UnimplementedReadingListServiceServer) RemoveReadingListItem(context.Context, *ReadingListItemRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveReadingListItem not implemented")
+}
+func (UnimplementedReadingListServiceServer) ReorderReadingList(context.Context, *ReorderReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReorderReadingList not implemented")
+}
+func (UnimplementedReadingListServiceServer) ShareReadingList(context.Context, *ShareReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareReadingList not implemented")
+}
+func (UnimplementedReadingListServiceServer) GetSharedReadingList(context.Context, *SharedReadingListRequest) (*ReadingListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSharedReadingList not implemented")
+}
+func (UnimplementedReadingListServiceServer) HoldUnavailable(context.Context, *HoldUnavailableRequest) (*HoldUnavailableResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HoldUnavailable not implemented")
+}
+func (UnimplementedReadingListServiceServer) mustEmbedUnimplementedReadingListServiceServer() {}
+func (UnimplementedReadingListServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeReadingListServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReadingListServiceServer will
+// result in compilation errors.
+type UnsafeReadingListServiceServer interface {
+	mustEmbedUnimplementedReadingListServiceServer()
+}
+
+func RegisterReadingListServiceServer(s grpc.ServiceRegistrar, srv ReadingListServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReadingListServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReadingListService_ServiceDesc, srv)
+}
+
+func _ReadingListService_CreateReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListServiceServer).CreateReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReadingListService_CreateReadingList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListServiceServer).CreateReadingList(ctx, req.(*ReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingListService_UpdateReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListServiceServer).UpdateReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReadingListService_UpdateReadingList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListServiceServer).UpdateReadingList(ctx, req.(*ReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingListService_DeleteReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListServiceServer).DeleteReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReadingListService_DeleteReadingList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListServiceServer).DeleteReadingList(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingListService_ListReadingLists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParameterReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListServiceServer).ListReadingLists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReadingListService_ListReadingLists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListServiceServer).ListReadingLists(ctx, req.(*ParameterReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingListService_GetReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListServiceServer).GetReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReadingListService_GetReadingList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListServiceServer).GetReadingList(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingListService_AddReadingListItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadingListItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListServiceServer).AddReadingListItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReadingListService_AddReadingListItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListServiceServer).AddReadingListItem(ctx, req.(*ReadingListItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingListService_RemoveReadingListItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadingListItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListServiceServer).RemoveReadingListItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReadingListService_RemoveReadingListItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListServiceServer).RemoveReadingListItem(ctx, req.(*ReadingListItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingListService_ReorderReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorderReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListServiceServer).ReorderReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReadingListService_ReorderReadingList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListServiceServer).ReorderReadingList(ctx, req.(*ReorderReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingListService_ShareReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListServiceServer).ShareReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReadingListService_ShareReadingList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListServiceServer).ShareReadingList(ctx, req.(*ShareReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingListService_GetSharedReadingList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SharedReadingListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListServiceServer).GetSharedReadingList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReadingListService_GetSharedReadingList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListServiceServer).GetSharedReadingList(ctx, req.(*SharedReadingListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReadingListService_HoldUnavailable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HoldUnavailableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReadingListServiceServer).HoldUnavailable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReadingListService_HoldUnavailable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReadingListServiceServer).HoldUnavailable(ctx, req.(*HoldUnavailableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReadingListService_ServiceDesc is the grpc.ServiceDesc for ReadingListService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReadingListService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "go_grpc.ReadingListService",
+	HandlerType: (*ReadingListServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateReadingList",
+			Handler:    _ReadingListService_CreateReadingList_Handler,
+		},
+		{
+			MethodName: "UpdateReadingList",
+			Handler:    _ReadingListService_UpdateReadingList_Handler,
+		},
+		{
+			MethodName: "DeleteReadingList",
+			Handler:    _ReadingListService_DeleteReadingList_Handler,
+		},
+		{
+			MethodName: "ListReadingLists",
+			Handler:    _ReadingListService_ListReadingLists_Handler,
+		},
+		{
+			MethodName: "GetReadingList",
+			Handler:    _ReadingListService_GetReadingList_Handler,
+		},
+		{
+			MethodName: "AddReadingListItem",
+			Handler:    _ReadingListService_AddReadingListItem_Handler,
+		},
+		{
+			MethodName: "RemoveReadingListItem",
+			Handler:    _ReadingListService_RemoveReadingListItem_Handler,
+		},
+		{
+			MethodName: "ReorderReadingList",
+			Handler:    _ReadingListService_ReorderReadingList_Handler,
+		},
+		{
+			MethodName: "ShareReadingList",
+			Handler:    _ReadingListService_ShareReadingList_Handler,
+		},
+		{
+			MethodName: "GetSharedReadingList",
+			Handler:    _ReadingListService_GetSharedReadingList_Handler,
+		},
+		{
+			MethodName: "HoldUnavailable",
+			Handler:    _ReadingListService_HoldUnavailable_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "library.proto",
+}
+
+const (
+	RecommendationService_GetSimilarBooks_FullMethodName         = "/go_grpc.RecommendationService/GetSimilarBooks"
+	RecommendationService_GetRecommendationsForMe_FullMethodName = "/go_grpc.RecommendationService/GetRecommendationsForMe"
+)
+
+// RecommendationServiceClient is the client API for RecommendationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Recommendation Service
+type RecommendationServiceClient interface {
+	GetSimilarBooks(ctx context.Context, in *SimilarBooksRequest, opts ...grpc.CallOption) (*RecommendationsResponse, error)
+	GetRecommendationsForMe(ctx context.Context, in *MyRecommendationsRequest, opts ...grpc.CallOption) (*RecommendationsResponse, error)
+}
+
+type recommendationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRecommendationServiceClient(cc grpc.ClientConnInterface) RecommendationServiceClient {
+	return &recommendationServiceClient{cc}
+}
+
+func (c *recommendationServiceClient) GetSimilarBooks(ctx context.Context, in *SimilarBooksRequest, opts ...grpc.CallOption) (*RecommendationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecommendationsResponse)
+	err := c.cc.Invoke(ctx, RecommendationService_GetSimilarBooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recommendationServiceClient) GetRecommendationsForMe(ctx context.Context, in *MyRecommendationsRequest, opts ...grpc.CallOption) (*RecommendationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecommendationsResponse)
+	err := c.cc.Invoke(ctx, RecommendationService_GetRecommendationsForMe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RecommendationServiceServer is the server API for RecommendationService service.
+// All implementations must embed UnimplementedRecommendationServiceServer
+// for forward compatibility.
+//
+// Recommendation Service
+type RecommendationServiceServer interface {
+	GetSimilarBooks(context.Context, *SimilarBooksRequest) (*RecommendationsResponse, error)
+	GetRecommendationsForMe(context.Context, *MyRecommendationsRequest) (*RecommendationsResponse, error)
+	mustEmbedUnimplementedRecommendationServiceServer()
+}
+
+// UnimplementedRecommendationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRecommendationServiceServer struct{}
+
+func (UnimplementedRecommendationServiceServer) GetSimilarBooks(context.Context, *SimilarBooksRequest) (*RecommendationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSimilarBooks not implemented")
+}
+func (UnimplementedRecommendationServiceServer) GetRecommendationsForMe(context.Context, *MyRecommendationsRequest) (*RecommendationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecommendationsForMe not implemented")
+}
+func (UnimplementedRecommendationServiceServer) mustEmbedUnimplementedRecommendationServiceServer() {}
+func (UnimplementedRecommendationServiceServer) testEmbeddedByValue()                               {}
+
+// UnsafeRecommendationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RecommendationServiceServer will
+// result in compilation errors.
+type UnsafeRecommendationServiceServer interface {
+	mustEmbedUnimplementedRecommendationServiceServer()
+}
+
+func RegisterRecommendationServiceServer(s grpc.ServiceRegistrar, srv RecommendationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRecommendationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RecommendationService_ServiceDesc, srv)
+}
+
+func _RecommendationService_GetSimilarBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimilarBooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommendationServiceServer).GetSimilarBooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecommendationService_GetSimilarBooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommendationServiceServer).GetSimilarBooks(ctx, req.(*SimilarBooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecommendationService_GetRecommendationsForMe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MyRecommendationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommendationServiceServer).GetRecommendationsForMe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecommendationService_GetRecommendationsForMe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommendationServiceServer).GetRecommendationsForMe(ctx, req.(*MyRecommendationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RecommendationService_ServiceDesc is the grpc.ServiceDesc for RecommendationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RecommendationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "go_grpc.RecommendationService",
+	HandlerType: (*RecommendationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSimilarBooks",
+			Handler:    _RecommendationService_GetSimilarBooks_Handler,
+		},
+		{
+			MethodName: "GetRecommendationsForMe",
+			Handler:    _RecommendationService_GetRecommendationsForMe_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "library.proto",
+}
+
+const (
+	AuditService_ListAuditEvents_FullMethodName = "/go_grpc.AuditService/ListAuditEvents"
+)
+
+// AuditServiceClient is the client API for AuditService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Audit Service
+type AuditServiceClient interface {
+	ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*AuditEventsResponse, error)
+}
+
+type auditServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAuditServiceClient(cc grpc.ClientConnInterface) AuditServiceClient {
+	return &auditServiceClient{cc}
+}
+
+func (c *auditServiceClient) ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*AuditEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuditEventsResponse)
+	err := c.cc.Invoke(ctx, AuditService_ListAuditEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuditServiceServer is the server API for AuditService service.
+// All implementations must embed UnimplementedAuditServiceServer
+// for forward compatibility.
+//
+// Audit Service
+type AuditServiceServer interface {
+	ListAuditEvents(context.Context, *ListAuditEventsRequest) (*AuditEventsResponse, error)
+	mustEmbedUnimplementedAuditServiceServer()
+}
+
+// UnimplementedAuditServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAuditServiceServer struct{}
+
+func (UnimplementedAuditServiceServer) ListAuditEvents(context.Context, *ListAuditEventsRequest) (*AuditEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEvents not implemented")
+}
+func (UnimplementedAuditServiceServer) mustEmbedUnimplementedAuditServiceServer() {}
+func (UnimplementedAuditServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeAuditServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AuditServiceServer will
+// result in compilation errors.
+type UnsafeAuditServiceServer interface {
+	mustEmbedUnimplementedAuditServiceServer()
+}
+
+func RegisterAuditServiceServer(s grpc.ServiceRegistrar, srv AuditServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAuditServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AuditService_ServiceDesc, srv)
+}
+
+func _AuditService_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuditServiceServer).ListAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuditService_ListAuditEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuditServiceServer).ListAuditEvents(ctx, req.(*ListAuditEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AuditService_ServiceDesc is the grpc.ServiceDesc for AuditService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AuditService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "go_grpc.AuditService",
+	HandlerType: (*AuditServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _AuditService_ListAuditEvents_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "library.proto",
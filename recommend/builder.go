@@ -0,0 +1,182 @@
+// Package recommend suggests books from the borrowing history: books borrowed
+// by the same borrowers are similar, and borrowers get the books most similar
+// to what they have read. Authors and categories a borrower reads fill in
+// when there is not enough history.
+package recommend
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"go-grpc/helpers"
+	"go-grpc/model"
+
+	"gorm.io/gorm"
+)
+
+// Builder periodically recomputes book_similarities from borrowing_transactions.
+// Two books are similar when the same borrowers borrowed both, scored by the
+// cosine of their borrower sets: co-borrowers / sqrt(borrowers of a * borrowers of b).
+// Anonymized loans have no borrower and do not count.
+type Builder struct {
+	DB *gorm.DB
+
+	// Pairs borrowed together by fewer borrowers are noise and left out
+	MinCoBorrowers int
+	// Neighbours kept per book
+	Neighbours int
+	// Borrowers with more books than MaxBasket, e.g. a class set account,
+	// would make everything similar to everything and are left out
+	MaxBasket int
+}
+
+func NewBuilder(db *gorm.DB) *Builder {
+	return &Builder{
+		DB:             db,
+		MinCoBorrowers: 2,
+		Neighbours:     50,
+		MaxBasket:      500,
+	}
+}
+
+func (b *Builder) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := b.Build(ctx); err != nil {
+			log.Printf("recommendations: %v", err)
+		} else {
+			log.Printf("recommendations: %d book similarities computed", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pair of book ids, the lower id first
+type pair struct {
+	a, b int32
+}
+
+type neighbour struct {
+	book        int32
+	score       float64
+	coBorrowers int
+}
+
+// Build replaces the similarities of all books and returns how many were stored
+func (b *Builder) Build(ctx context.Context) (int, error) {
+	rows, err := b.DB.WithContext(ctx).Table("borrowing_transactions bt").
+		Joins("JOIN books bk on bk.id = bt.book_id").
+		Select("DISTINCT bt.borrower_id, bt.book_id").
+		Where("bt.borrower_id IS NOT NULL AND bk.deleted_at IS NULL").
+		Order("bt.borrower_id").
+		Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	borrowers := map[int32]int{}
+	pairs := map[pair]int{}
+
+	var basket []int32
+	flush := func() {
+		if len(basket) > b.MaxBasket {
+			basket = basket[:0]
+			return
+		}
+		for i, x := range basket {
+			borrowers[x]++
+			for _, y := range basket[i+1:] {
+				if x < y {
+					pairs[pair{x, y}]++
+				} else {
+					pairs[pair{y, x}]++
+				}
+			}
+		}
+		basket = basket[:0]
+	}
+
+	current := int32(-1)
+	for rows.Next() {
+		var borrowerID, bookID int32
+		if err := rows.Scan(&borrowerID, &bookID); err != nil {
+			return 0, err
+		}
+		if borrowerID != current {
+			flush()
+			current = borrowerID
+		}
+		basket = append(basket, bookID)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	flush()
+
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	neighbours := map[int32][]neighbour{}
+	for p, co := range pairs {
+		if co < b.MinCoBorrowers {
+			continue
+		}
+		score := float64(co) / math.Sqrt(float64(borrowers[p.a])*float64(borrowers[p.b]))
+		neighbours[p.a] = append(neighbours[p.a], neighbour{p.b, score, co})
+		neighbours[p.b] = append(neighbours[p.b], neighbour{p.a, score, co})
+	}
+
+	now := time.Now().Format(helpers.DateTimeLayout)
+	var similarities []model.BookSimilarity
+	for book, list := range neighbours {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].score != list[j].score {
+				return list[i].score > list[j].score
+			}
+			if list[i].coBorrowers != list[j].coBorrowers {
+				return list[i].coBorrowers > list[j].coBorrowers
+			}
+			return list[i].book < list[j].book
+		})
+		if len(list) > b.Neighbours {
+			list = list[:b.Neighbours]
+		}
+		for _, n := range list {
+			similarities = append(similarities, model.BookSimilarity{
+				BookID:        book,
+				SimilarBookID: n.book,
+				Score:         n.score,
+				CoBorrowers:   int32(n.coBorrowers),
+				ComputedAt:    now,
+			})
+		}
+	}
+
+	// Readers see the old similarities until the new ones are committed
+	err = b.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM book_similarities").Error; err != nil {
+			return err
+		}
+		if len(similarities) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(similarities, 1000).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(similarities), nil
+}
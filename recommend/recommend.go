@@ -0,0 +1,203 @@
+package recommend
+
+import (
+	"gorm.io/gorm"
+)
+
+// Reasons a book is recommended
+const (
+	ReasonCoBorrowed = "co_borrowed"
+	ReasonAuthor     = "same_author"
+	ReasonCategory   = "same_category"
+	ReasonPopular    = "popular"
+)
+
+// Recent loans of a borrower that seed their recommendations
+const seedLoans = 50
+
+// Options narrow down the recommended books
+type Options struct {
+	Limit int
+	// Books never recommended, e.g. the book similar books are asked for
+	Exclude []int32
+	// Only books with a copy on the shelf that is not set aside for a hold
+	AvailableOnly bool
+	// Leave out the books this borrower has borrowed or has on hold
+	ExcludeBorrowedBy int32
+}
+
+// Recommendation is a recommended book, best first. Score is the summed
+// similarity for co-borrowed books and 0 for the fallbacks.
+type Recommendation struct {
+	BookID int32
+	Score  float64
+	Reason string
+}
+
+// Similar returns the books borrowed by the same borrowers as the book.
+// Books by the same author and then in the same category, most borrowed
+// first, fill up the list when the book has too little history.
+func Similar(db *gorm.DB, bookID int32, opts Options) ([]Recommendation, error) {
+	r := recommender{db: db, opts: opts, seen: map[int32]bool{bookID: true}}
+
+	r.similar(db.Table("book_similarities s").
+		Select("s.similar_book_id, s.score").
+		Where("s.book_id = ?", bookID).
+		Order("s.score DESC, s.similar_book_id"))
+
+	r.fallback(ReasonAuthor, sameAuthor("book_id = ?"), bookID)
+	r.fallback(ReasonCategory, "b.category_id = (SELECT category_id FROM books WHERE id = ?)", bookID)
+
+	return r.result, r.err
+}
+
+// ForBorrower returns the books most similar to what the borrower has
+// recently borrowed. Books by the authors and in the categories the borrower
+// reads, and then the most borrowed books, fill up the list for new borrowers.
+// Books the borrower has borrowed or has on hold are always left out.
+func ForBorrower(db *gorm.DB, borrowerID int32, opts Options) ([]Recommendation, error) {
+	opts.ExcludeBorrowedBy = borrowerID
+	r := recommender{db: db, opts: opts, seen: map[int32]bool{}}
+
+	var seeds []int32
+	if err := db.Table("borrowing_transactions").
+		Select("book_id").
+		Where("borrower_id = ?", borrowerID).
+		Group("book_id").
+		Order("MAX(borrowed_at) DESC").
+		Limit(seedLoans).
+		Pluck("book_id", &seeds).Error; err != nil {
+		return nil, err
+	}
+
+	if len(seeds) > 0 {
+		r.similar(db.Table("book_similarities s").
+			Select("s.similar_book_id, SUM(s.score)").
+			Where("s.book_id IN ?", seeds).
+			Group("s.similar_book_id").
+			Order("SUM(s.score) DESC, s.similar_book_id"))
+
+		r.fallback(ReasonAuthor, sameAuthor("book_id IN ?"), seeds)
+		r.fallback(ReasonCategory, "b.category_id IN (SELECT category_id FROM books WHERE id IN ?)", seeds)
+	}
+
+	r.fallback(ReasonPopular, "")
+
+	return r.result, r.err
+}
+
+// ComputedAt returns when the similarities were last rebuilt, empty before the first build
+func ComputedAt(db *gorm.DB) (string, error) {
+	var computedAt string
+	err := db.Table("book_similarities").Select("COALESCE(MAX(computed_at), '')").Row().Scan(&computedAt)
+	return computedAt, err
+}
+
+// recommender collects recommendations from the best source to the weakest
+// until the limit is reached, the first error stops it
+type recommender struct {
+	db     *gorm.DB
+	opts   Options
+	seen   map[int32]bool
+	result []Recommendation
+	err    error
+}
+
+func (r *recommender) remaining() int {
+	if r.err != nil {
+		return 0
+	}
+	return r.opts.Limit - len(r.result)
+}
+
+// filter leaves out deleted, excluded, already recommended and, as asked,
+// borrowed or unavailable books, b is the books table
+func (r *recommender) filter(sql *gorm.DB) *gorm.DB {
+	sql = sql.Where("b.deleted_at IS NULL")
+
+	exclude := append([]int32{}, r.opts.Exclude...)
+	for id := range r.seen {
+		exclude = append(exclude, id)
+	}
+	if len(exclude) > 0 {
+		sql = sql.Where("b.id NOT IN ?", exclude)
+	}
+
+	if r.opts.ExcludeBorrowedBy > 0 {
+		sql = sql.Where("b.id NOT IN (SELECT book_id FROM borrowing_transactions WHERE borrower_id = ?)", r.opts.ExcludeBorrowedBy).
+			Where("b.id NOT IN (SELECT book_id FROM holds WHERE borrower_id = ? AND status IN ('waiting', 'ready'))", r.opts.ExcludeBorrowedBy)
+	}
+
+	// Copies set aside for ready holds are not on the shelf for anybody else
+	if r.opts.AvailableOnly {
+		sql = sql.Where("(SELECT COALESCE(SUM(bs.total_stock), 0) FROM book_stocks bs WHERE bs.book_id = b.id) > " +
+			"(SELECT COUNT(*) FROM holds h WHERE h.book_id = b.id AND h.status = 'ready')")
+	}
+
+	return sql
+}
+
+// similar adds co-borrowed books, sql selects a book id and a score from book_similarities s
+func (r *recommender) similar(sql *gorm.DB) {
+	limit := r.remaining()
+	if limit <= 0 {
+		return
+	}
+
+	rows, err := r.filter(sql.Joins("JOIN books b on b.id = s.similar_book_id")).Limit(limit).Rows()
+	if err != nil {
+		r.err = err
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int32
+		var score float64
+		if err := rows.Scan(&id, &score); err != nil {
+			r.err = err
+			return
+		}
+		r.add(Recommendation{BookID: id, Score: score, Reason: ReasonCoBorrowed})
+	}
+	r.err = rows.Err()
+}
+
+// fallback adds the most borrowed books matching the condition over books b
+func (r *recommender) fallback(reason, where string, args ...interface{}) {
+	limit := r.remaining()
+	if limit <= 0 {
+		return
+	}
+
+	sql := r.filter(r.db.Table("books b").Select("b.id"))
+	if where != "" {
+		sql = sql.Where(where, args...)
+	}
+
+	var ids []int32
+	if err := sql.Order("(SELECT COUNT(*) FROM borrowing_transactions p WHERE p.book_id = b.id) DESC, b.id").
+		Limit(limit).
+		Pluck("b.id", &ids).Error; err != nil {
+		r.err = err
+		return
+	}
+
+	for _, id := range ids {
+		r.add(Recommendation{BookID: id, Reason: reason})
+	}
+}
+
+// sameAuthor matches books b by an author of the books the condition over book_id selects
+func sameAuthor(condition string) string {
+	return "b.id IN (SELECT book_id FROM book_contributors WHERE role = 'author' AND author_id IN " +
+		"(SELECT author_id FROM book_contributors WHERE role = 'author' AND " + condition + "))"
+}
+
+func (r *recommender) add(rec Recommendation) {
+	if r.seen[rec.BookID] {
+		return
+	}
+	r.seen[rec.BookID] = true
+	r.result = append(r.result, rec)
+}
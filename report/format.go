@@ -0,0 +1,191 @@
+package report
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Formats a table can be downloaded in
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ContentTypes = map[string]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ParseFormat accepts csv and xlsx, the default is csv
+func ParseFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	}
+	return "", fmt.Errorf("unknown format %q, use csv or xlsx", format)
+}
+
+// Write renders the table in the format
+func (t *Table) Write(w io.Writer, format string) error {
+	if format == FormatXLSX {
+		return t.WriteXLSX(w)
+	}
+	return t.WriteCSV(w)
+}
+
+// WriteCSV writes a header line and the rows
+func (t *Table) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+
+	header := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = c.Name
+	}
+	out.Write(header)
+
+	record := make([]string, 0, len(t.Columns))
+	for _, row := range t.Rows {
+		record = record[:0]
+		for i, value := range row {
+			if i >= len(t.Columns) || t.Columns[i].Type != Number || !isNumber(value) {
+				value = csvText(value)
+			}
+			record = append(record, value)
+		}
+		out.Write(record)
+	}
+	out.Flush()
+
+	return out.Error()
+}
+
+// csvText puts a ' in front of text a spreadsheet would read as a formula,
+// like a title starting with =HYPERLINK(. Numbers of number columns are
+// left alone so negative numbers stay numbers.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func isNumber(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+// WriteXLSX writes a workbook with a single sheet, the header row is bold
+// and number columns are numeric cells
+func (t *Table) WriteXLSX(w io.Writer) error {
+	z := zip.NewWriter(w)
+
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, escape(sheetName(t.Report)))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", t.sheet()},
+	}
+
+	for _, f := range files {
+		fw, err := z.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+
+	return z.Close()
+}
+
+func (t *Table) sheet() string {
+	var b strings.Builder
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	b.WriteString(`<row r="1">`)
+	for i, c := range t.Columns {
+		fmt.Fprintf(&b, `<c r="%s1" t="inlineStr" s="1"><is><t>%s</t></is></c>`, cellColumn(i), escape(c.Name))
+	}
+	b.WriteString(`</row>`)
+
+	for r, row := range t.Rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+2)
+		for i, value := range row {
+			ref := fmt.Sprintf("%s%d", cellColumn(i), r+2)
+			if i < len(t.Columns) && t.Columns[i].Type == Number && value != "" {
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, escape(value))
+			} else {
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(value))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// cellColumn is the letter reference of a zero based column, A to Z then AA
+func cellColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// sheetName keeps to the 31 characters a sheet name may have
+func sheetName(report string) string {
+	if len(report) > 31 {
+		return report[:31]
+	}
+	return report
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles has the default cell format and a bold one for the header
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
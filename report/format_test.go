@@ -0,0 +1,52 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+func TestWriteCSVEscapesFormulas(t *testing.T) {
+	table := &Table{
+		Report:  "popular_books",
+		Columns: []Column{{"book_id", Number}, {"title", String}, {"loans", Number}},
+		Rows: [][]string{
+			{"1", `=HYPERLINK("http://evil.test","click")`, "3"},
+			{"2", "+1 555 0100", "-2"},
+			{"3", "-cmd", "=1+1"},
+			{"4", "@SUM(A1:A2)", "1.5"},
+			{"5", "\tTabbed", ""},
+			{"6", "Plain title, with a comma", "0"},
+		},
+	}
+
+	var b bytes.Buffer
+	if err := table.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"book_id", "title", "loans"},
+		{"1", `'=HYPERLINK("http://evil.test","click")`, "3"},
+		{"2", "'+1 555 0100", "-2"},
+		{"3", "'-cmd", "'=1+1"},
+		{"4", "'@SUM(A1:A2)", "1.5"},
+		{"5", "'\tTabbed", ""},
+		{"6", "Plain title, with a comma", "0"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		for j := range want[i] {
+			if records[i][j] != want[i][j] {
+				t.Errorf("record %d field %d = %q, want %q", i, j, records[i][j], want[i][j])
+			}
+		}
+	}
+}
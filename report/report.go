@@ -0,0 +1,239 @@
+// Package report runs the circulation and collection reports management
+// asks for, as tables that can be returned over gRPC or written as CSV and
+// XLSX.
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go-grpc/helpers"
+
+	"gorm.io/gorm"
+)
+
+// Params narrow a report, the zero value covers everything
+type Params struct {
+	From       string // loans borrowed at or after, helpers.DateTimeLayout or a date
+	To         string // loans borrowed before
+	CategoryID int32
+	Limit      int // rows of ranked reports, 0 is the default of the report
+}
+
+// Column types, numbers are written as numeric cells in XLSX
+const (
+	String = "string"
+	Number = "number"
+)
+
+type Column struct {
+	Name string
+	Type string
+}
+
+// Table is the result of a report
+type Table struct {
+	Report      string
+	Columns     []Column
+	Rows        [][]string
+	GeneratedAt time.Time
+}
+
+// Definition describes a report for clients
+type Definition struct {
+	Name        string
+	Title       string
+	Description string
+
+	run func(db *gorm.DB, p Params) (*gorm.DB, []Column)
+}
+
+// Definitions lists the reports in the order they are offered
+var Definitions = []Definition{
+	{
+		Name:        "most_borrowed",
+		Title:       "Most borrowed books",
+		Description: "Books ranked by the number of loans in the period",
+		run:         mostBorrowed,
+	},
+	{
+		Name:        "loans_per_category",
+		Title:       "Loans per category",
+		Description: "Loans, distinct borrowers and overdue loans of each category in the period",
+		run:         loansPerCategory,
+	},
+	{
+		Name:        "overdue_rate",
+		Title:       "Overdue rate",
+		Description: "Share of the loans of each month that were returned late or are still overdue",
+		run:         overdueRate,
+	},
+	{
+		Name:        "active_borrowers",
+		Title:       "Active borrowers",
+		Description: "Borrowers with loans in the period, most active first",
+		run:         activeBorrowers,
+	},
+	{
+		Name:        "never_borrowed",
+		Title:       "Books never borrowed",
+		Description: "Books in the catalog without a loan in the period",
+		run:         neverBorrowed,
+	},
+}
+
+func Find(name string) (Definition, bool) {
+	for _, d := range Definitions {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// ParseTime reads a report bound, a date is its midnight
+func ParseTime(value string) (string, error) {
+	if _, err := time.Parse(helpers.DateTimeLayout, value); err == nil {
+		return value, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t.Format(helpers.DateTimeLayout), nil
+	}
+	return "", fmt.Errorf("%q must look like %q or a date", value, helpers.DateTimeLayout)
+}
+
+// Run runs a report
+func Run(db *gorm.DB, name string, p Params) (*Table, error) {
+	d, ok := Find(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown report %q", name)
+	}
+
+	var err error
+	if p.From != "" {
+		if p.From, err = ParseTime(p.From); err != nil {
+			return nil, fmt.Errorf("from: %v", err)
+		}
+	}
+	if p.To != "" {
+		if p.To, err = ParseTime(p.To); err != nil {
+			return nil, fmt.Errorf("to: %v", err)
+		}
+	}
+
+	table := &Table{Report: name, GeneratedAt: time.Now()}
+
+	sql, columns := d.run(db, p)
+	table.Columns = columns
+
+	rows, err := sql.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		values := make([]string, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		table.Rows = append(table.Rows, values)
+	}
+
+	return table, rows.Err()
+}
+
+// Filename is the download name of a table in the format
+func (t *Table) Filename(format string) string {
+	return fmt.Sprintf("%s-%s.%s", t.Report, t.GeneratedAt.Format("20060102-150405"), strings.ToLower(format))
+}
+
+// loans selects the loans of the period and category, joined with their book
+func loans(db *gorm.DB, p Params) *gorm.DB {
+	sql := db.Table("borrowing_transactions bt").Joins("JOIN books b ON b.id = bt.book_id")
+	if p.From != "" {
+		sql = sql.Where("bt.borrowed_at >= ?", p.From)
+	}
+	if p.To != "" {
+		sql = sql.Where("bt.borrowed_at < ?", p.To)
+	}
+	if p.CategoryID > 0 {
+		sql = sql.Where("b.category_id = ?", p.CategoryID)
+	}
+	return sql
+}
+
+func limit(p Params, fallback int) int {
+	if p.Limit > 0 {
+		return p.Limit
+	}
+	return fallback
+}
+
+// overdue is true for a loan returned after its due date or not returned and past due
+const overdue = "(bt.returned_at > bt.due_date OR (bt.returned_at IS NULL AND bt.due_date < NOW()))"
+
+func mostBorrowed(db *gorm.DB, p Params) (*gorm.DB, []Column) {
+	sql := loans(db, p).
+		Joins("LEFT JOIN authors au ON au.id = b.author_id").
+		Joins("LEFT JOIN categories c ON c.id = b.category_id").
+		Select("b.id, b.title, COALESCE(au.name, ''), COALESCE(c.name, ''), COUNT(*) loans, COUNT(DISTINCT bt.borrower_id)").
+		Group("b.id, b.title, au.name, c.name").
+		Order("loans DESC, b.id").
+		Limit(limit(p, 50))
+
+	return sql, []Column{{"book_id", Number}, {"title", String}, {"author", String}, {"category", String}, {"loans", Number}, {"borrowers", Number}}
+}
+
+func loansPerCategory(db *gorm.DB, p Params) (*gorm.DB, []Column) {
+	sql := loans(db, p).
+		Joins("LEFT JOIN categories c ON c.id = b.category_id").
+		Select("COALESCE(c.id, 0), COALESCE(c.name, 'Uncategorized'), COUNT(*) loans, COUNT(DISTINCT bt.borrower_id), " +
+			"SUM(bt.returned_at IS NOT NULL), SUM" + overdue).
+		Group("c.id, c.name").
+		Order("loans DESC, c.id")
+
+	return sql, []Column{{"category_id", Number}, {"category", String}, {"loans", Number}, {"borrowers", Number}, {"returned", Number}, {"overdue", Number}}
+}
+
+func overdueRate(db *gorm.DB, p Params) (*gorm.DB, []Column) {
+	sql := loans(db, p).
+		Select("DATE_FORMAT(bt.borrowed_at, '%Y-%m') month, COUNT(*), SUM" + overdue + ", " +
+			"ROUND(100 * SUM" + overdue + " / COUNT(*), 2)").
+		Group("month").
+		Order("month")
+
+	return sql, []Column{{"month", String}, {"loans", Number}, {"overdue", Number}, {"overdue_percent", Number}}
+}
+
+func activeBorrowers(db *gorm.DB, p Params) (*gorm.DB, []Column) {
+	sql := loans(db, p).
+		Joins("JOIN borrowers br ON br.id = bt.borrower_id").
+		Select("br.id, COALESCE(br.name, ''), COALESCE(br.email, ''), COUNT(*) loans, SUM(bt.returned_at IS NULL), MAX(bt.borrowed_at)").
+		Group("br.id, br.name, br.email").
+		Order("loans DESC, br.id").
+		Limit(limit(p, 100))
+
+	return sql, []Column{{"borrower_id", Number}, {"name", String}, {"email", String}, {"loans", Number}, {"on_loan", Number}, {"last_loan", String}}
+}
+
+func neverBorrowed(db *gorm.DB, p Params) (*gorm.DB, []Column) {
+	borrowed := loans(db, Params{From: p.From, To: p.To}).Select("DISTINCT bt.book_id")
+
+	sql := db.Table("books b").
+		Joins("LEFT JOIN authors au ON au.id = b.author_id").
+		Joins("LEFT JOIN categories c ON c.id = b.category_id").
+		Select("b.id, b.title, COALESCE(au.name, ''), COALESCE(c.name, ''), COALESCE(b.created_at, '')").
+		Where("b.deleted_at IS NULL AND b.id NOT IN (?)", borrowed).
+		Order("b.created_at, b.id")
+	if p.CategoryID > 0 {
+		sql = sql.Where("b.category_id = ?", p.CategoryID)
+	}
+
+	return sql, []Column{{"book_id", Number}, {"title", String}, {"author", String}, {"category", String}, {"added_at", String}}
+}
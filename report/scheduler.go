@@ -0,0 +1,374 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go-grpc/cron"
+	"go-grpc/helpers"
+	"go-grpc/model"
+	"go-grpc/notification"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Run statuses
+const (
+	RunPending   = "pending"
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+// What queued a run
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Ways a scheduled report is delivered
+const (
+	DeliveryEmail     = "email"
+	DeliveryDirectory = "directory"
+)
+
+// Scheduler queues a run for every schedule whose next_run_at has passed and
+// works through the queued runs, delivering each report by email or as a file
+// in Dir. A run covers the period between the last two firings of its
+// schedule; firings missed while no scheduler was running are skipped and can
+// be run again with RunReportSchedule. Failed runs are not retried, the
+// schedule's alert address is told instead.
+type Scheduler struct {
+	DB *gorm.DB
+
+	// Mailer sends email deliveries and failure alerts, nil when SMTP is not configured
+	Mailer notification.AttachmentMailer
+	// Dir receives directory deliveries, one sub directory per schedule
+	Dir string
+	// AlertEmail is told about failed runs of schedules without an alert address
+	AlertEmail string
+
+	// A run still running after RunTimeout is taken for a crashed scheduler and failed
+	RunTimeout time.Duration
+	BatchSize  int
+
+	// Now is replaceable in tests
+	Now func() time.Time
+}
+
+func NewScheduler(db *gorm.DB) *Scheduler {
+	return &Scheduler{
+		DB:         db,
+		RunTimeout: time.Hour,
+		BatchSize:  10,
+		Now:        time.Now,
+	}
+}
+
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunOnce(ctx); err != nil {
+			log.Printf("report scheduler: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	if err := s.queueDue(ctx); err != nil {
+		return err
+	}
+	if err := s.failStale(ctx); err != nil {
+		return err
+	}
+
+	var runs []model.ReportRun
+	if err := s.DB.WithContext(ctx).
+		Where("status = ?", RunPending).
+		Order("id").
+		Limit(s.BatchSize).
+		Find(&runs).Error; err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// Claimed by whoever moves it out of pending first
+		result := s.DB.WithContext(ctx).Model(&model.ReportRun{}).
+			Where("id = ? AND status = ?", run.ID, RunPending).
+			Updates(map[string]interface{}{"status": RunRunning, "started_at": s.Now().Format(helpers.DateTimeLayout)})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		s.execute(ctx, run)
+	}
+
+	return nil
+}
+
+// LastPeriod is the period between the last two times the schedule fired before now
+func LastPeriod(schedule *cron.Schedule, now time.Time) (time.Time, time.Time, error) {
+	end := schedule.Prev(now)
+	if end.IsZero() {
+		return time.Time{}, time.Time{}, errors.New("the schedule has not fired in the past five years")
+	}
+
+	start := schedule.Prev(end.Add(-time.Minute))
+	if start.IsZero() {
+		return time.Time{}, time.Time{}, errors.New("the schedule fires only once in five years")
+	}
+
+	return start, end, nil
+}
+
+// NextRunAt is when the schedule fires next, NULL if it never does
+func NextRunAt(schedule *cron.Schedule, now time.Time) sql.NullString {
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: next.Format(helpers.DateTimeLayout), Valid: true}
+}
+
+// queueDue queues a run of the last period of each due schedule and moves its next_run_at on
+func (s *Scheduler) queueDue(ctx context.Context) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := s.Now()
+
+		var schedules []model.ReportSchedule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("active = ? AND next_run_at <= ?", true, now.Format(helpers.DateTimeLayout)).
+			Find(&schedules).Error; err != nil {
+			return err
+		}
+
+		for _, schedule := range schedules {
+			expr, err := cron.Parse(schedule.Cron)
+			if err != nil {
+				// Validated when saved, only a hand edited row gets here
+				log.Printf("report scheduler: schedule %d: %v", schedule.ID, err)
+				if err := tx.Model(&schedule).Update("next_run_at", nil).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			start, end, err := LastPeriod(expr, now)
+			if err == nil {
+				run := model.ReportRun{
+					ScheduleID:  schedule.ID,
+					PeriodStart: start.Format(helpers.DateTimeLayout),
+					PeriodEnd:   end.Format(helpers.DateTimeLayout),
+					TriggeredBy: TriggerSchedule,
+					Status:      RunPending,
+					CreatedAt:   now.Format(helpers.DateTimeLayout),
+				}
+				if err := tx.Create(&run).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Model(&schedule).Update("next_run_at", NextRunAt(expr, now)).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// failStale fails runs left running by a scheduler that stopped
+func (s *Scheduler) failStale(ctx context.Context) error {
+	cutoff := s.Now().Add(-s.RunTimeout).Format(helpers.DateTimeLayout)
+
+	return s.DB.WithContext(ctx).Model(&model.ReportRun{}).
+		Where("status = ? AND started_at < ?", RunRunning, cutoff).
+		Updates(map[string]interface{}{
+			"status":      RunFailed,
+			"error":       "interrupted, the scheduler stopped while the report ran",
+			"finished_at": s.Now().Format(helpers.DateTimeLayout),
+		}).Error
+}
+
+// execute runs the report of a claimed run, delivers it and records the outcome
+func (s *Scheduler) execute(ctx context.Context, run model.ReportRun) {
+	var schedule model.ReportSchedule
+	if err := s.DB.WithContext(ctx).First(&schedule, run.ScheduleID).Error; err != nil {
+		// Deleting the schedule deletes its runs as well
+		log.Printf("report scheduler: run %d: %v", run.ID, err)
+		return
+	}
+
+	filename, location, rows, err := s.generate(schedule, run)
+
+	updates := map[string]interface{}{
+		"status":      RunSucceeded,
+		"row_count":   rows,
+		"filename":    filename,
+		"location":    location,
+		"finished_at": s.Now().Format(helpers.DateTimeLayout),
+	}
+	if err != nil {
+		updates["status"] = RunFailed
+		updates["error"] = truncate(err.Error(), 1000)
+	}
+
+	if err := s.DB.Model(&model.ReportRun{}).Where("id = ?", run.ID).Updates(updates).Error; err != nil {
+		log.Printf("report scheduler: run %d: %v", run.ID, err)
+	}
+
+	if err != nil {
+		log.Printf("report scheduler: schedule %d run %d failed: %v", schedule.ID, run.ID, err)
+		s.alert(schedule, run, err)
+	}
+}
+
+// generate runs the report for the period of the run and delivers the file
+func (s *Scheduler) generate(schedule model.ReportSchedule, run model.ReportRun) (string, string, int, error) {
+	format, err := ParseFormat(schedule.Format)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	table, err := Run(s.DB, schedule.Report, Params{
+		From:       run.PeriodStart,
+		To:         run.PeriodEnd,
+		CategoryID: schedule.CategoryID,
+		Limit:      int(schedule.RowLimit),
+	})
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	var file bytes.Buffer
+	if err := table.Write(&file, format); err != nil {
+		return "", "", 0, err
+	}
+
+	filename := periodFilename(schedule.Report, run, format)
+
+	switch schedule.Delivery {
+	case DeliveryEmail:
+		err = s.email(schedule, run, table, filename, format, file.Bytes())
+		return filename, schedule.Email, len(table.Rows), err
+	case DeliveryDirectory:
+		path, err := s.write(schedule, filename, file.Bytes())
+		return filename, path, len(table.Rows), err
+	}
+
+	return filename, "", len(table.Rows), fmt.Errorf("unknown delivery %q", schedule.Delivery)
+}
+
+func (s *Scheduler) email(schedule model.ReportSchedule, run model.ReportRun, table *Table, filename, format string, data []byte) error {
+	if s.Mailer == nil {
+		return errors.New("email delivery needs SMTP_HOST to be configured")
+	}
+	if schedule.Email == "" {
+		return errors.New("the schedule has no email address")
+	}
+
+	title := schedule.Report
+	if d, ok := Find(schedule.Report); ok {
+		title = d.Title
+	}
+
+	subject := fmt.Sprintf("%s: %s to %s", schedule.Name, run.PeriodStart, run.PeriodEnd)
+	body := fmt.Sprintf("The scheduled report %q (%s) for %s to %s is attached, it has %d rows.",
+		schedule.Name, title, run.PeriodStart, run.PeriodEnd, len(table.Rows))
+
+	return s.Mailer.SendAttachment(schedule.Email, subject, body, notification.Attachment{
+		Filename:    filename,
+		ContentType: ContentTypes[format],
+		Data:        data,
+	})
+}
+
+// write stores the file under Dir, a run of the same period again replaces it
+func (s *Scheduler) write(schedule model.ReportSchedule, filename string, data []byte) (string, error) {
+	if s.Dir == "" {
+		return "", errors.New("directory delivery needs REPORT_DIR to be configured")
+	}
+
+	dir := filepath.Join(s.Dir, fmt.Sprintf("schedule-%d", schedule.ID))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	// Written next to the target and renamed, readers never see half a file
+	tmp, err := os.CreateTemp(dir, "."+filename+"-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, filename)
+	return path, os.Rename(tmp.Name(), path)
+}
+
+// alert emails the failure of a run to the schedule's alert address
+func (s *Scheduler) alert(schedule model.ReportSchedule, run model.ReportRun, failure error) {
+	to := schedule.AlertEmail
+	if to == "" {
+		to = s.AlertEmail
+	}
+	if s.Mailer == nil || to == "" {
+		return
+	}
+
+	subject := fmt.Sprintf("Scheduled report %q failed", schedule.Name)
+	body := fmt.Sprintf("Run %d of the scheduled report %q for %s to %s failed:\n\n%v\n\nIt can be run again with RunReportSchedule once the problem is fixed.",
+		run.ID, schedule.Name, run.PeriodStart, run.PeriodEnd, failure)
+
+	if err := s.Mailer.Send(to, subject, body); err != nil {
+		log.Printf("report scheduler: alert for run %d: %v", run.ID, err)
+	}
+}
+
+// periodFilename names the file of a run after the report and its period
+func periodFilename(report string, run model.ReportRun, format string) string {
+	name := report
+	for _, bound := range []string{run.PeriodStart, run.PeriodEnd} {
+		if t, err := time.Parse(helpers.DateTimeLayout, bound); err == nil {
+			name += "-" + t.Format("20060102T1504")
+		}
+	}
+	return name + "." + format
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
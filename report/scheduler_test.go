@@ -0,0 +1,369 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-grpc/cron"
+	"go-grpc/helpers"
+	"go-grpc/model"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLastPeriod(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation(helpers.DateTimeLayout, s, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		expr, now, start, end string
+	}{
+		{"0 6 * * *", "2024-05-10 07:00:00", "2024-05-09 06:00:00", "2024-05-10 06:00:00"},
+		// Fired at the very minute of now
+		{"0 6 * * *", "2024-05-10 06:00:30", "2024-05-09 06:00:00", "2024-05-10 06:00:00"},
+		{"@monthly", "2024-03-15 12:00:00", "2024-02-01 00:00:00", "2024-03-01 00:00:00"},
+		{"0 0 29 2 *", "2024-03-01 00:00:00", "2020-02-29 00:00:00", "2024-02-29 00:00:00"},
+	}
+
+	for _, tt := range tests {
+		schedule, err := cron.Parse(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start, end, err := LastPeriod(schedule, at(tt.now))
+		if err != nil {
+			t.Errorf("%q at %s: %v", tt.expr, tt.now, err)
+			continue
+		}
+		if !start.Equal(at(tt.start)) || !end.Equal(at(tt.end)) {
+			t.Errorf("%q at %s = %s to %s, want %s to %s", tt.expr, tt.now, start, end, tt.start, tt.end)
+		}
+	}
+
+	never, err := cron.Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LastPeriod(never, at("2024-05-10 07:00:00")); err == nil {
+		t.Error("LastPeriod of a schedule that never fires succeeded")
+	}
+}
+
+func TestQueueDue(t *testing.T) {
+	now := time.Date(2024, 5, 10, 6, 0, 30, 0, time.UTC)
+	store := &reports{schedules: []model.ReportSchedule{
+		{ID: 1, Cron: "0 6 * * *", Active: true, NextRunAt: nullString("2024-05-10 06:00:00")},
+		{ID: 2, Cron: "0 6 * * *", Active: false, NextRunAt: nullString("2024-05-10 06:00:00")},
+		{ID: 3, Cron: "0 7 * * *", Active: true, NextRunAt: nullString("2024-05-10 07:00:00")},
+		{ID: 4, Cron: "not cron", Active: true, NextRunAt: nullString("2024-05-10 05:00:00")},
+	}}
+	s := store.scheduler(t, now)
+
+	// The second pass finds nothing due anymore
+	for i := 0; i < 2; i++ {
+		if err := s.queueDue(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(store.runs) != 1 {
+		t.Fatalf("queued %d runs, want 1: %+v", len(store.runs), store.runs)
+	}
+	run := store.runs[0]
+	if run.ScheduleID != 1 || run.Status != RunPending || run.TriggeredBy != TriggerSchedule ||
+		run.PeriodStart != "2024-05-09 06:00:00" || run.PeriodEnd != "2024-05-10 06:00:00" {
+		t.Errorf("queued %+v", run)
+	}
+
+	for id, want := range map[int]sql.NullString{
+		0: nullString("2024-05-11 06:00:00"),
+		1: nullString("2024-05-10 06:00:00"),
+		2: nullString("2024-05-10 07:00:00"),
+		3: {},
+	} {
+		if got := store.schedules[id].NextRunAt; got != want {
+			t.Errorf("schedule %d next_run_at = %+v, want %+v", store.schedules[id].ID, got, want)
+		}
+	}
+}
+
+func TestFailStale(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	store := &reports{runs: []model.ReportRun{
+		{ID: 1, Status: RunRunning, StartedAt: nullString("2024-05-10 10:30:00")},
+		{ID: 2, Status: RunRunning, StartedAt: nullString("2024-05-10 11:50:00")},
+		{ID: 3, Status: RunPending},
+	}}
+	s := store.scheduler(t, now)
+
+	if err := s.failStale(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if run := store.runs[0]; run.Status != RunFailed || !run.Error.Valid || run.FinishedAt.String != "2024-05-10 12:00:00" {
+		t.Errorf("stale run = %+v, want failed", run)
+	}
+	if store.runs[1].Status != RunRunning || store.runs[2].Status != RunPending {
+		t.Errorf("runs within the timeout changed: %+v", store.runs[1:])
+	}
+}
+
+func TestRunOnceClaimsPendingRuns(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	store := &reports{
+		schedules: []model.ReportSchedule{
+			{ID: 1, Cron: "0 6 * * *", Format: "pdf", Active: true},
+		},
+		runs: []model.ReportRun{
+			{ID: 1, ScheduleID: 1, Status: RunPending},
+			{ID: 2, ScheduleID: 1, Status: RunPending},
+		},
+		// Another scheduler claims run 2 between the query and the claim
+		claimedElsewhere: map[int64]bool{2: true},
+	}
+	s := store.scheduler(t, now)
+
+	if err := s.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run 1 is claimed and executed, the unknown format fails it
+	if run := store.runs[0]; run.Status != RunFailed || !strings.Contains(run.Error.String, "unknown format") ||
+		run.StartedAt.String != "2024-05-10 12:00:00" {
+		t.Errorf("run 1 = %+v, want failed by its format", run)
+	}
+	if run := store.runs[1]; run.Status != RunRunning || run.StartedAt.Valid {
+		t.Errorf("run 2 = %+v, want left to the scheduler that claimed it", run)
+	}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
+
+// reports holds the report_schedules and report_runs the scheduler works on,
+// behind a database/sql connector
+type reports struct {
+	mu sync.Mutex
+
+	schedules        []model.ReportSchedule
+	runs             []model.ReportRun
+	claimedElsewhere map[int64]bool
+}
+
+func (r *reports) scheduler(t *testing.T, now time.Time) *Scheduler {
+	t.Helper()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(r), SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewScheduler(db)
+	s.Now = func() time.Time { return now }
+	return s
+}
+
+var scheduleColumns = []string{"id", "name", "report", "format", "cron", "delivery", "active", "next_run_at"}
+
+func scheduleRow(s model.ReportSchedule) []driver.Value {
+	next, _ := s.NextRunAt.Value()
+	return []driver.Value{int64(s.ID), s.Name, s.Report, s.Format, s.Cron, s.Delivery, s.Active, next}
+}
+
+var runColumns = []string{"id", "schedule_id", "period_start", "period_end", "status", "started_at"}
+
+func runRow(run model.ReportRun) []driver.Value {
+	started, _ := run.StartedAt.Value()
+	return []driver.Value{run.ID, int64(run.ScheduleID), run.PeriodStart, run.PeriodEnd, run.Status, started}
+}
+
+func (r *reports) query(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var rows [][]driver.Value
+
+	switch {
+	case strings.Contains(query, "FROM `report_schedules` WHERE active = ? AND next_run_at <= ?"):
+		for _, s := range r.schedules {
+			if s.Active == args[0].Value && s.NextRunAt.Valid && s.NextRunAt.String <= args[1].Value.(string) {
+				rows = append(rows, scheduleRow(s))
+			}
+		}
+		return scheduleColumns, rows, nil
+
+	case strings.Contains(query, "FROM `report_schedules` WHERE `report_schedules`.`id` = ?"):
+		for _, s := range r.schedules {
+			if int64(s.ID) == args[0].Value {
+				rows = append(rows, scheduleRow(s))
+			}
+		}
+		return scheduleColumns, rows, nil
+
+	case strings.Contains(query, "FROM `report_runs` WHERE status = ?"):
+		for i, run := range r.runs {
+			if run.Status == args[0].Value {
+				rows = append(rows, runRow(run))
+				if r.claimedElsewhere[run.ID] {
+					r.runs[i].Status = RunRunning
+				}
+			}
+		}
+		return runColumns, rows, nil
+	}
+
+	return nil, nil, fmt.Errorf("unexpected query %q", query)
+}
+
+func (r *reports) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO `report_runs`"):
+		columns := strings.Split(query[strings.Index(query, "(")+1:strings.Index(query, ")")], ",")
+		run := model.ReportRun{ID: int64(len(r.runs) + 1)}
+		for i, column := range columns {
+			setRun(&run, strings.Trim(column, "`"), args[i].Value)
+		}
+		r.runs = append(r.runs, run)
+		return result{id: run.ID, rows: 1}, nil
+
+	case strings.HasPrefix(query, "UPDATE `report_schedules` SET `next_run_at`=? WHERE `id` = ?"):
+		for i := range r.schedules {
+			if int64(r.schedules[i].ID) == args[1].Value {
+				next, _ := args[0].Value.(string)
+				r.schedules[i].NextRunAt = sql.NullString{String: next, Valid: args[0].Value != nil}
+				return driver.RowsAffected(1), nil
+			}
+		}
+		return driver.RowsAffected(0), nil
+
+	case strings.HasPrefix(query, "UPDATE `report_runs` SET "):
+		set := strings.Split(query[len("UPDATE `report_runs` SET "):strings.Index(query, " WHERE ")], ",")
+		where := args[len(set):]
+
+		var match func(model.ReportRun) bool
+		switch condition := query[strings.Index(query, " WHERE ")+len(" WHERE "):]; condition {
+		case "status = ? AND started_at < ?":
+			match = func(run model.ReportRun) bool {
+				return run.Status == where[0].Value && run.StartedAt.Valid && run.StartedAt.String < where[1].Value.(string)
+			}
+		case "id = ? AND status = ?":
+			match = func(run model.ReportRun) bool { return run.ID == where[0].Value && run.Status == where[1].Value }
+		case "id = ?":
+			match = func(run model.ReportRun) bool { return run.ID == where[0].Value }
+		default:
+			return nil, fmt.Errorf("unexpected condition %q", condition)
+		}
+
+		var affected int64
+		for i := range r.runs {
+			if !match(r.runs[i]) {
+				continue
+			}
+			for j, column := range set {
+				setRun(&r.runs[i], strings.TrimSuffix(strings.Trim(column, "`"), "`=?"), args[j].Value)
+			}
+			affected++
+		}
+		return driver.RowsAffected(affected), nil
+	}
+
+	return nil, fmt.Errorf("unexpected statement %q", query)
+}
+
+func setRun(run *model.ReportRun, column string, value driver.Value) {
+	str, _ := value.(string)
+	null := sql.NullString{String: str, Valid: value != nil}
+
+	switch column {
+	case "schedule_id":
+		run.ScheduleID = int32(value.(int64))
+	case "period_start":
+		run.PeriodStart = str
+	case "period_end":
+		run.PeriodEnd = str
+	case "triggered_by":
+		run.TriggeredBy = str
+	case "status":
+		run.Status = str
+	case "error":
+		run.Error = null
+	case "started_at":
+		run.StartedAt = null
+	case "finished_at":
+		run.FinishedAt = null
+	case "created_at":
+		run.CreatedAt = str
+	}
+}
+
+// Connect and Driver make the reports a driver.Connector
+func (r *reports) Connect(context.Context) (driver.Conn, error) { return &testConn{reports: r}, nil }
+func (r *reports) Driver() driver.Driver                        { return nil }
+
+type testConn struct{ reports *reports }
+
+func (c *testConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns, rows, err := c.reports.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &testRows{columns: columns, rows: rows}, nil
+}
+
+func (c *testConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.reports.exec(query, args)
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported: %q", query)
+}
+func (c *testConn) Close() error { return nil }
+
+// Begin hands out a transaction that applies every statement right away
+func (c *testConn) Begin() (driver.Tx, error) { return testTx{}, nil }
+
+type testTx struct{}
+
+func (testTx) Commit() error   { return nil }
+func (testTx) Rollback() error { return nil }
+
+type result struct{ id, rows int64 }
+
+func (r result) LastInsertId() (int64, error) { return r.id, nil }
+func (r result) RowsAffected() (int64, error) { return r.rows, nil }
+
+type testRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *testRows) Columns() []string { return r.columns }
+func (r *testRows) Close() error      { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
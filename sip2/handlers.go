@@ -0,0 +1,538 @@
+package sip2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-grpc/helpers"
+	pb "go-grpc/pb/library"
+
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// 93 login, the kiosk authenticates with an admin account
+func (s *Server) login(ctx context.Context, sess *session, msg *Message) *Message {
+	var admin struct {
+		ID       int32
+		Password string
+	}
+
+	ok := false
+	err := s.DB.Table("admin").Select("id, password").Where("email = ?", msg.Get("CN")).First(&admin).Error
+	switch {
+	case err == nil:
+		ok = helpers.VerifyPassword(admin.Password, msg.Get("CO")) == nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		log.Printf("sip2: login: %v", err)
+	}
+
+	if ok {
+		sess.ctx = helpers.WithUser(ctx, int(admin.ID), "admin")
+	}
+	return NewMessage("94", bit(ok))
+}
+
+// 99 SC status, answered with the capabilities of the server
+func (s *Server) status() *Message {
+	fixed := "Y" + // on-line
+		"Y" + // checkin ok
+		"Y" + // checkout ok
+		"Y" + // renewal policy
+		"N" + // status update ok
+		"N" + // off-line ok
+		"030" + // timeout, tenths of seconds
+		"003" + // retries allowed
+		FormatDate(time.Now()) +
+		"2.00"
+
+	return NewMessage("98", fixed).
+		Add("AO", s.Institution).
+		Add("AM", s.Name).
+		Add("BX", supportedMessages)
+}
+
+// patron is a borrower identified by the kiosk
+type patron struct {
+	ID       int32
+	Name     string
+	Email    string
+	Password string
+
+	// PasswordOK is false when AD was sent and does not match
+	PasswordOK bool
+}
+
+// findPatron looks up AA, a borrower id or email, nil when there is no such borrower
+func (s *Server) findPatron(msg *Message) (*patron, error) {
+	identifier := strings.TrimSpace(msg.Get("AA"))
+	if identifier == "" {
+		return nil, nil
+	}
+
+	// Erased accounts are kept without personal data and are not patrons anymore
+	sql := s.DB.Table("borrowers").Select("id, name, email, password").Where("erased_at IS NULL")
+	if id, err := strconv.Atoi(identifier); err == nil {
+		sql = sql.Where("id = ?", id)
+	} else {
+		sql = sql.Where("email = ?", identifier)
+	}
+
+	var p patron
+	if err := sql.First(&p).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	p.PasswordOK = !msg.Has("AD") || helpers.VerifyPassword(p.Password, msg.Get("AD")) == nil
+	return &p, nil
+}
+
+// context runs a call as the patron, so borrowing rules apply as in the app
+func (p *patron) context(ctx context.Context) context.Context {
+	return helpers.WithUser(ctx, int(p.ID), "borrower")
+}
+
+// item is a book identified by its ISBN barcode
+type item struct {
+	ID    int32
+	Title string
+	ISBN  string
+}
+
+func (s *Server) findItem(barcode string) (*item, error) {
+	isbn := helpers.NormalizeISBN(barcode)
+	if len(isbn) != 10 && len(isbn) != 13 {
+		return nil, nil
+	}
+
+	var it item
+	err := s.DB.Table("books").Select("id, title, isbn").
+		Where("isbn IN ? AND deleted_at IS NULL", []string{helpers.ISBN13(isbn), helpers.ISBN10(isbn)}).
+		First(&it).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &it, err
+}
+
+// openLoan returns the id of the oldest loan of the book that is not returned, 0 when there is none
+func (s *Server) openLoan(bookID, borrowerID int32) (int32, error) {
+	sql := s.DB.Table("borrowing_transactions").Select("id").Where("book_id = ? AND returned_at IS NULL", bookID)
+	if borrowerID != 0 {
+		sql = sql.Where("borrower_id = ?", borrowerID)
+	}
+
+	var ids []int32
+	if err := sql.Order("borrowed_at, id").Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+// account sums up the loans and unpaid fines of a patron
+type account struct {
+	Charged     []string // ISBNs of the books on loan
+	Overdue     []string
+	Fined       []string
+	Fees        float64
+	Holds       []string // ISBNs of the books set aside for the patron
+	Unavailable []string // ISBNs of the books the patron is waiting for
+}
+
+func (s *Server) account(borrowerID int32) (*account, error) {
+	a := &account{}
+	now := time.Now().Format(helpers.DateTimeLayout)
+
+	rows, err := s.DB.Table("borrowing_transactions bt").
+		Joins("JOIN books b ON b.id = bt.book_id").
+		Select("COALESCE(b.isbn, ''), bt.due_date").
+		Where("bt.borrower_id = ? AND bt.returned_at IS NULL", borrowerID).
+		Order("bt.due_date").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var isbn, dueDate string
+		if err := rows.Scan(&isbn, &dueDate); err != nil {
+			return nil, err
+		}
+		a.Charged = append(a.Charged, isbn)
+		if dueDate < now {
+			a.Overdue = append(a.Overdue, isbn)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	fines, err := s.DB.Table("returning_transactions rt").
+		Joins("JOIN borrowing_transactions bt ON bt.id = rt.borrowing_transaction_id").
+		Joins("JOIN books b ON b.id = bt.book_id").
+		Select("COALESCE(b.isbn, ''), rt.fine_amount").
+		Where("bt.borrower_id = ? AND rt.fine_amount > 0 AND rt.fine_paid_at IS NULL", borrowerID).
+		Order("rt.returned_at").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer fines.Close()
+
+	for fines.Next() {
+		var isbn string
+		var amount float64
+		if err := fines.Scan(&isbn, &amount); err != nil {
+			return nil, err
+		}
+		a.Fined = append(a.Fined, isbn)
+		a.Fees += amount
+	}
+	if err := fines.Err(); err != nil {
+		return nil, err
+	}
+
+	holds, err := s.DB.Table("holds h").
+		Joins("JOIN books b ON b.id = h.book_id").
+		Select("COALESCE(b.isbn, ''), h.status").
+		Where("h.borrower_id = ? AND h.status IN ?", borrowerID, []string{"waiting", "ready"}).
+		Order("h.id").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer holds.Close()
+
+	for holds.Next() {
+		var isbn, status string
+		if err := holds.Scan(&isbn, &status); err != nil {
+			return nil, err
+		}
+		if status == "ready" {
+			a.Holds = append(a.Holds, isbn)
+		} else {
+			a.Unavailable = append(a.Unavailable, isbn)
+		}
+	}
+	return a, holds.Err()
+}
+
+// statusFlags is the 14 character patron status, every privilege is denied for an unknown patron
+func statusFlags(p *patron) string {
+	if p == nil || !p.PasswordOK {
+		return "YYYY          "
+	}
+	return strings.Repeat(" ", 14)
+}
+
+// addPatron adds the fields that describe the patron to a response
+func (s *Server) addPatron(resp *Message, msg *Message, p *patron) {
+	resp.Add("AO", s.Institution).Add("AA", msg.Get("AA"))
+	if p != nil {
+		resp.Add("AE", p.Name)
+	} else {
+		resp.Add("AE", "")
+	}
+	resp.Add("BL", flag(p != nil))
+	if msg.Has("AD") {
+		resp.Add("CQ", flag(p != nil && p.PasswordOK))
+	}
+}
+
+// 23 patron status
+func (s *Server) patronStatus(msg *Message) *Message {
+	language := msg.Fixed[:3]
+
+	p, err := s.findPatron(msg)
+	if err != nil {
+		log.Printf("sip2: patron status: %v", err)
+	}
+
+	resp := NewMessage("24", statusFlags(p)+language+FormatDate(time.Now()))
+	s.addPatron(resp, msg, p)
+
+	if p != nil && p.PasswordOK {
+		a, err := s.account(p.ID)
+		if err != nil {
+			log.Printf("sip2: patron status: %v", err)
+			return resp.Add("AF", "Account is not available")
+		}
+		if a.Fees > 0 {
+			resp.Add("BV", fee(a.Fees))
+		}
+	}
+	return resp
+}
+
+// 63 patron information, the summary field selects the list of items that is returned
+func (s *Server) patronInformation(msg *Message) *Message {
+	language, summary := msg.Fixed[:3], msg.Fixed[21:31]
+
+	p, err := s.findPatron(msg)
+	if err != nil {
+		log.Printf("sip2: patron information: %v", err)
+	}
+
+	a := &account{}
+	if p != nil && p.PasswordOK {
+		if a, err = s.account(p.ID); err != nil {
+			log.Printf("sip2: patron information: %v", err)
+			a = &account{}
+		}
+	}
+
+	// hold, overdue, charged, fine, recall and unavailable hold items
+	counts := fmt.Sprintf("%04d%04d%04d%04d%04d%04d", len(a.Holds), len(a.Overdue), len(a.Charged), len(a.Fined), 0, len(a.Unavailable))
+
+	resp := NewMessage("64", statusFlags(p)+language+FormatDate(time.Now())+counts)
+	s.addPatron(resp, msg, p)
+
+	if a.Fees > 0 {
+		resp.Add("BV", fee(a.Fees))
+	}
+	if p != nil && p.PasswordOK {
+		resp.Add("BE", p.Email)
+	}
+
+	var id string
+	var items []string
+	switch strings.IndexByte(summary, 'Y') {
+	case 0:
+		id, items = "AS", a.Holds
+	case 1:
+		id, items = "AT", a.Overdue
+	case 2:
+		id, items = "AU", a.Charged
+	case 3:
+		id, items = "AV", a.Fined
+	case 5:
+		id, items = "CD", a.Unavailable
+	}
+
+	// BP and BQ are the 1 based range of items the kiosk wants
+	start, end := 1, len(items)
+	if n, err := strconv.Atoi(msg.Get("BP")); err == nil && n > 0 {
+		start = n
+	}
+	if n, err := strconv.Atoi(msg.Get("BQ")); err == nil && n < end {
+		end = n
+	}
+	for i := start; i <= end; i++ {
+		resp.Add(id, items[i-1])
+	}
+
+	return resp
+}
+
+// 11 checkout, an item the patron already has is renewed when the kiosk allows it
+func (s *Server) checkout(ctx context.Context, msg *Message) *Message {
+	renewalPolicy := msg.Fixed[0] == 'Y'
+
+	reject := func(message string) *Message {
+		return NewMessage("12", "0NUN"+FormatDate(time.Now())).
+			Add("AO", s.Institution).Add("AA", msg.Get("AA")).Add("AB", msg.Get("AB")).Add("AJ", "").
+			Add("AH", "").Add("AF", message)
+	}
+
+	p, it, reason := s.lookup(msg)
+	if reason != "" {
+		return reject(reason)
+	}
+
+	loanID, err := s.openLoan(it.ID, p.ID)
+	if err != nil {
+		log.Printf("sip2: checkout: %v", err)
+		return reject("Checkout is not available")
+	}
+
+	var resp *pb.BorrowingTransactionResponse
+	renewal := loanID != 0
+	if renewal {
+		if !renewalPolicy {
+			return reject("Item is already checked out to you")
+		}
+		resp, err = s.Borrowing.RenewBorrowingTransaction(p.context(ctx), &pb.RenewBorrowingTransactionRequest{TransactionId: loanID})
+	} else {
+		resp, err = s.Borrowing.CreateBorrowingTransaction(p.context(ctx), &pb.CreateBorrowingTransactionRequest{
+			BookId:  it.ID,
+			DueDate: time.Now().Add(s.LoanPeriod).Format(helpers.DateTimeLayout),
+		})
+	}
+	if err != nil {
+		return reject(message(err))
+	}
+
+	return NewMessage("12", "1"+flag(renewal)+"UY"+FormatDate(time.Now())).
+		Add("AO", s.Institution).
+		Add("AA", msg.Get("AA")).
+		Add("AB", msg.Get("AB")).
+		Add("AJ", it.Title).
+		Add("AH", dueDate(resp.Data.DueDate))
+}
+
+// 09 checkin, the oldest loan of the item is returned as the admin of the session
+func (s *Server) checkin(ctx context.Context, msg *Message) *Message {
+	reply := func(ok bool, alert bool, title, borrower, message string) *Message {
+		resp := NewMessage("10", bit(ok)+flag(ok)+"U"+flag(alert)+FormatDate(time.Now())).
+			Add("AO", s.Institution).
+			Add("AB", msg.Get("AB")).
+			Add("AQ", s.Institution).
+			Add("AJ", title)
+		if borrower != "" {
+			resp.Add("AA", borrower)
+		}
+		if message != "" {
+			resp.Add("AF", message)
+		}
+		return resp
+	}
+
+	it, err := s.findItem(msg.Get("AB"))
+	if err != nil {
+		log.Printf("sip2: checkin: %v", err)
+		return reply(false, false, "", "", "Checkin is not available")
+	}
+	if it == nil {
+		return reply(false, false, "", "", "Item not found")
+	}
+
+	var loan struct {
+		ID         int32
+		BorrowerID int32
+	}
+	loanID, err := s.openLoan(it.ID, 0)
+	if err == nil && loanID != 0 {
+		err = s.DB.Table("borrowing_transactions").Select("id, borrower_id").Where("id = ?", loanID).Take(&loan).Error
+	}
+	if err != nil {
+		log.Printf("sip2: checkin: %v", err)
+		return reply(false, false, it.Title, "", "Checkin is not available")
+	}
+	if loanID == 0 {
+		return reply(false, false, it.Title, "", "Item is not checked out")
+	}
+
+	// The return date of an offline checkin is kept
+	returnedAt := time.Now()
+	if t, err := ParseDate(msg.Fixed[19:37]); err == nil {
+		returnedAt = t.Local()
+	}
+
+	resp, err := s.Returning.ReturnBook(ctx, &pb.ReturnBookRequest{
+		TransactionId: loanID,
+		ReturnedAt:    returnedAt.Format(helpers.DateTimeLayout),
+	})
+	if err != nil {
+		return reply(false, false, it.Title, "", message(err))
+	}
+	if !resp.Success {
+		return reply(false, false, it.Title, "", resp.Message)
+	}
+
+	// A fine is shown at the kiosk and raises the alert for staff
+	borrower := strconv.Itoa(int(loan.BorrowerID))
+	if fine, _ := strconv.ParseFloat(resp.FineAmount, 64); fine > 0 {
+		return reply(true, true, it.Title, borrower, "Returned late, fine "+fee(fine))
+	}
+	return reply(true, false, it.Title, borrower, "")
+}
+
+// 29 renew
+func (s *Server) renew(ctx context.Context, msg *Message) *Message {
+	reply := func(ok bool, title, due, message string) *Message {
+		resp := NewMessage("30", bit(ok)+flag(ok)+"UU"+FormatDate(time.Now())).
+			Add("AO", s.Institution).
+			Add("AA", msg.Get("AA")).
+			Add("AB", msg.Get("AB")).
+			Add("AJ", title).
+			Add("AH", due)
+		if message != "" {
+			resp.Add("AF", message)
+		}
+		return resp
+	}
+
+	p, it, reason := s.lookup(msg)
+	if reason != "" {
+		return reply(false, "", "", reason)
+	}
+
+	loanID, err := s.openLoan(it.ID, p.ID)
+	if err != nil {
+		log.Printf("sip2: renew: %v", err)
+		return reply(false, it.Title, "", "Renewal is not available")
+	}
+	if loanID == 0 {
+		return reply(false, it.Title, "", "Item is not checked out to you")
+	}
+
+	resp, err := s.Borrowing.RenewBorrowingTransaction(p.context(ctx), &pb.RenewBorrowingTransactionRequest{TransactionId: loanID})
+	if err != nil {
+		return reply(false, it.Title, "", message(err))
+	}
+	return reply(true, it.Title, dueDate(resp.Data.DueDate), "")
+}
+
+// 35 end patron session, nothing is kept per patron
+func (s *Server) endSession(msg *Message) *Message {
+	return NewMessage("36", "Y"+FormatDate(time.Now())).
+		Add("AO", s.Institution).
+		Add("AA", msg.Get("AA"))
+}
+
+// lookup finds the patron and item of a checkout or renew, reason is the
+// screen message when either is not valid
+func (s *Server) lookup(msg *Message) (*patron, *item, string) {
+	p, err := s.findPatron(msg)
+	if err != nil {
+		log.Printf("sip2: %v", err)
+		return nil, nil, "Patron is not available"
+	}
+	if p == nil || !p.PasswordOK {
+		return nil, nil, "Invalid patron"
+	}
+
+	it, err := s.findItem(msg.Get("AB"))
+	if err != nil {
+		log.Printf("sip2: %v", err)
+		return nil, nil, "Item is not available"
+	}
+	if it == nil {
+		return nil, nil, "Item not found"
+	}
+	return p, it, ""
+}
+
+// message is the screen message of a failed call
+func message(err error) string {
+	if st, ok := status.FromError(err); ok {
+		return st.Message()
+	}
+	log.Printf("sip2: %v", err)
+	return "Request failed"
+}
+
+// dueDate converts a stored due date to a SIP2 date
+func dueDate(value string) string {
+	t, err := time.ParseInLocation(helpers.DateTimeLayout, value, time.Local)
+	if err != nil {
+		return value
+	}
+	return FormatDate(t)
+}
+
+func fee(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,286 @@
+package sip2
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-grpc/helpers"
+	pb "go-grpc/pb/library"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// library is the seeded data the tests run against. It answers the queries
+// of the handlers through a database/sql connector and stands in for the
+// borrowing and returning services, so a session can be replayed without
+// a MySQL server.
+type library struct {
+	mu sync.Mutex
+
+	adminEmail, adminPassword string
+	borrowers                 []testBorrower
+	books                     []testBook
+	loans                     []testLoan
+}
+
+type testBorrower struct {
+	ID                    int32
+	Name, Email, Password string
+}
+
+type testBook struct {
+	ID          int32
+	Title, ISBN string
+}
+
+type testLoan struct {
+	ID, BookID, BorrowerID int32
+	BorrowedAt, DueDate    string
+	Returned               bool
+}
+
+// seededLibrary has the kiosk account admin@library.test / secret, borrower
+// 1 and the book with ISBN 9780306406157, the data of sessions/*.sip
+func seededLibrary(t *testing.T) *library {
+	t.Helper()
+
+	admin, err := helpers.HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	patron, err := helpers.HashPassword("1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &library{
+		adminEmail:    "admin@library.test",
+		adminPassword: admin,
+		borrowers: []testBorrower{
+			{ID: 1, Name: "Ada Lovelace", Email: "ada@library.test", Password: patron},
+		},
+		books: []testBook{
+			{ID: 1, Title: "The Art of Computer Programming", ISBN: "9780306406157"},
+		},
+	}
+}
+
+// db opens gorm on the seeded data
+func (l *library) db(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(l), SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// server is a SIP2 server on the seeded data
+func (l *library) server(t *testing.T) *Server {
+	return NewServer(l.db(t), &testBorrowing{lib: l}, &testReturning{lib: l})
+}
+
+// query answers a query of the handlers, the table and conditions tell them apart
+func (l *library) query(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	arg := func(i int) driver.Value { return args[i].Value }
+	var rows [][]driver.Value
+
+	switch {
+	case strings.Contains(query, "FROM `admin`"):
+		if arg(0) == l.adminEmail {
+			rows = append(rows, []driver.Value{int64(1), l.adminPassword})
+		}
+		return []string{"id", "password"}, rows, nil
+
+	case strings.Contains(query, "FROM `borrowers`"):
+		for _, b := range l.borrowers {
+			if arg(0) == int64(b.ID) || arg(0) == b.Email {
+				rows = append(rows, []driver.Value{int64(b.ID), b.Name, b.Email, b.Password})
+			}
+		}
+		return []string{"id", "name", "email", "password"}, rows, nil
+
+	case strings.Contains(query, "FROM `books`"):
+		for _, b := range l.books {
+			if arg(0) == b.ISBN || arg(1) == b.ISBN {
+				rows = append(rows, []driver.Value{int64(b.ID), b.Title, b.ISBN})
+			}
+		}
+		return []string{"id", "title", "isbn"}, rows, nil
+
+	case strings.Contains(query, "FROM borrowing_transactions bt"):
+		for _, loan := range l.loans {
+			if !loan.Returned && arg(0) == int64(loan.BorrowerID) {
+				rows = append(rows, []driver.Value{l.isbn(loan.BookID), loan.DueDate})
+			}
+		}
+		return []string{"isbn", "due_date"}, rows, nil
+
+	case strings.Contains(query, "FROM returning_transactions rt"):
+		return []string{"isbn", "fine_amount"}, nil, nil
+
+	case strings.Contains(query, "FROM holds h"):
+		return []string{"isbn", "status"}, nil, nil
+
+	case strings.Contains(query, "FROM `borrowing_transactions` WHERE id = ?"):
+		for _, loan := range l.loans {
+			if arg(0) == int64(loan.ID) {
+				rows = append(rows, []driver.Value{int64(loan.ID), int64(loan.BorrowerID)})
+			}
+		}
+		return []string{"id", "borrower_id"}, rows, nil
+
+	case strings.Contains(query, "FROM `borrowing_transactions`") && strings.Contains(query, "book_id = ?"):
+		// The oldest open loan of the book, of the borrower when one is given
+		for _, loan := range l.loans {
+			if !loan.Returned && arg(0) == int64(loan.BookID) && (!strings.Contains(query, "borrower_id = ?") || arg(1) == int64(loan.BorrowerID)) {
+				rows = append(rows, []driver.Value{int64(loan.ID)})
+				break
+			}
+		}
+		return []string{"id"}, rows, nil
+	}
+
+	return nil, nil, fmt.Errorf("unexpected query %q", query)
+}
+
+func (l *library) isbn(bookID int32) string {
+	for _, b := range l.books {
+		if b.ID == bookID {
+			return b.ISBN
+		}
+	}
+	return ""
+}
+
+// Connect and Driver make the library a driver.Connector
+func (l *library) Connect(context.Context) (driver.Conn, error) { return &testConn{lib: l}, nil }
+func (l *library) Driver() driver.Driver                        { return nil }
+
+type testConn struct{ lib *library }
+
+func (c *testConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns, rows, err := c.lib.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &testRows{columns: columns, rows: rows}, nil
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported: %q", query)
+}
+func (c *testConn) Close() error { return nil }
+func (c *testConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported")
+}
+
+type testRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *testRows) Columns() []string { return r.columns }
+func (r *testRows) Close() error      { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// testBorrowing lends and renews as the borrower in the context, like the borrowing service
+type testBorrowing struct {
+	pb.UnimplementedBorrowingServiceServer
+	lib *library
+}
+
+func (s *testBorrowing) CreateBorrowingTransaction(ctx context.Context, req *pb.CreateBorrowingTransactionRequest) (*pb.BorrowingTransactionResponse, error) {
+	borrowerID, role, err := helpers.GetData(ctx)
+	if err != nil || role != "borrower" {
+		return nil, status.Errorf(codes.PermissionDenied, "checkout must run as the borrower")
+	}
+
+	s.lib.mu.Lock()
+	defer s.lib.mu.Unlock()
+
+	for _, loan := range s.lib.loans {
+		if loan.BookID == req.BookId && !loan.Returned {
+			return nil, status.Errorf(codes.FailedPrecondition, "Book is not available")
+		}
+	}
+
+	loan := testLoan{
+		ID:         int32(len(s.lib.loans) + 1),
+		BookID:     req.BookId,
+		BorrowerID: int32(borrowerID),
+		BorrowedAt: time.Now().Format(helpers.DateTimeLayout),
+		DueDate:    req.DueDate,
+	}
+	s.lib.loans = append(s.lib.loans, loan)
+
+	return &pb.BorrowingTransactionResponse{Data: &pb.BorrowingTransaction{Id: loan.ID, DueDate: loan.DueDate}}, nil
+}
+
+func (s *testBorrowing) RenewBorrowingTransaction(ctx context.Context, req *pb.RenewBorrowingTransactionRequest) (*pb.BorrowingTransactionResponse, error) {
+	borrowerID, _, err := helpers.GetData(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "no borrower")
+	}
+
+	s.lib.mu.Lock()
+	defer s.lib.mu.Unlock()
+
+	for i := range s.lib.loans {
+		loan := &s.lib.loans[i]
+		if loan.ID != req.TransactionId || loan.Returned || loan.BorrowerID != int32(borrowerID) {
+			continue
+		}
+
+		due, err := time.ParseInLocation(helpers.DateTimeLayout, loan.DueDate, time.Local)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+		loan.DueDate = due.AddDate(0, 0, 14).Format(helpers.DateTimeLayout)
+		return &pb.BorrowingTransactionResponse{Data: &pb.BorrowingTransaction{Id: loan.ID, DueDate: loan.DueDate}}, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "Borrowing transaction not found")
+}
+
+// testReturning returns a loan without a fine
+type testReturning struct {
+	pb.UnimplementedReturningServiceServer
+	lib *library
+}
+
+func (s *testReturning) ReturnBook(_ context.Context, req *pb.ReturnBookRequest) (*pb.ReturnBookResponse, error) {
+	s.lib.mu.Lock()
+	defer s.lib.mu.Unlock()
+
+	for i := range s.lib.loans {
+		if s.lib.loans[i].ID == req.TransactionId && !s.lib.loans[i].Returned {
+			s.lib.loans[i].Returned = true
+			return &pb.ReturnBookResponse{Success: true, Message: "Book returned successfully"}, nil
+		}
+	}
+	return &pb.ReturnBookResponse{Success: false, Message: "Book is already returned"}, nil
+}
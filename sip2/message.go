@@ -0,0 +1,202 @@
+// Package sip2 is a SIP2 (Standard Interchange Protocol 2.00) server for
+// self-checkout kiosks. Checkout, checkin and renew go through the same
+// borrowing and returning services as the gRPC API.
+package sip2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fixedLength is the length of the fixed fields that follow the command
+// identifier of the requests the server understands
+var fixedLength = map[string]int{
+	"93": 2,
+	"99": 8,
+	"23": 21,
+	"63": 31,
+	"11": 38,
+	"09": 37,
+	"29": 38,
+	"35": 18,
+	"97": 0,
+}
+
+// dateLayout is the 18 character SIP2 date, the four spaces are the local time zone
+const dateLayout = "20060102    150405"
+
+// Message is a request or response, fields keep the order they were added in
+type Message struct {
+	Code   string
+	Fixed  string
+	Fields []Field
+
+	// Sequence is the AY sequence number, -1 when error detection is not used
+	Sequence int
+}
+
+type Field struct {
+	ID    string
+	Value string
+}
+
+func NewMessage(code, fixed string) *Message {
+	return &Message{Code: code, Fixed: fixed, Sequence: -1}
+}
+
+// Add appends a variable field, it returns the message for chaining
+func (m *Message) Add(id, value string) *Message {
+	m.Fields = append(m.Fields, Field{ID: id, Value: value})
+	return m
+}
+
+// Get returns the first field with the identifier
+func (m *Message) Get(id string) string {
+	for _, f := range m.Fields {
+		if f.ID == id {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Has tells whether the field was sent, even when it is empty
+func (m *Message) Has(id string) bool {
+	for _, f := range m.Fields {
+		if f.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Encode renders the message without the terminating carriage return, the
+// sequence number and checksum are appended when Sequence is set
+func (m *Message) Encode() string {
+	var b strings.Builder
+	b.WriteString(m.Code)
+	b.WriteString(m.Fixed)
+	for _, f := range m.Fields {
+		b.WriteString(f.ID)
+		b.WriteString(sanitize(f.Value))
+		b.WriteByte('|')
+	}
+
+	if m.Sequence >= 0 {
+		fmt.Fprintf(&b, "AY%dAZ", m.Sequence%10)
+		b.WriteString(Checksum(b.String()))
+	}
+	return b.String()
+}
+
+// Parse reads a message without its terminator. A message that carries a
+// checksum is verified, ErrChecksum is returned when it does not match.
+func Parse(line string) (*Message, error) {
+	if len(line) < 2 {
+		return nil, fmt.Errorf("message %q is too short", line)
+	}
+
+	m := NewMessage(line[:2], "")
+	body := line[2:]
+
+	// AY and AZ close the message when error detection is on
+	if i := strings.LastIndex(body, "AY"); i >= 0 && len(body)-i == 9 && body[i+3:i+5] == "AZ" {
+		if Checksum(line[:2+i+5]) != strings.ToUpper(body[i+5:]) {
+			return nil, ErrChecksum
+		}
+		seq, err := strconv.Atoi(body[i+2 : i+3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid sequence number %q", body[i+2:i+3])
+		}
+		m.Sequence = seq
+		body = body[:i]
+	}
+
+	length, ok := fixedLength[m.Code]
+	if !ok {
+		return nil, fmt.Errorf("unsupported message %q", m.Code)
+	}
+	if len(body) < length {
+		return nil, fmt.Errorf("message %s needs %d characters of fixed fields", m.Code, length)
+	}
+	m.Fixed, body = body[:length], body[length:]
+
+	for body != "" {
+		if len(body) < 2 {
+			return nil, fmt.Errorf("truncated field %q", body)
+		}
+		end := strings.IndexByte(body, '|')
+		if end < 0 {
+			// The last delimiter is optional for many kiosks
+			end = len(body)
+		}
+		if end < 2 {
+			return nil, fmt.Errorf("truncated field %q", body[:end])
+		}
+		m.Fields = append(m.Fields, Field{ID: body[:2], Value: body[2:end]})
+		body = body[min(end+1, len(body)):]
+	}
+
+	return m, nil
+}
+
+// ErrChecksum is returned by Parse for a corrupted message, it is answered
+// with a request to resend
+var ErrChecksum = fmt.Errorf("checksum mismatch")
+
+// Checksum is the two's complement of the byte sum of everything up to and
+// including AZ, as four hex digits
+func Checksum(s string) string {
+	var sum uint16
+	for i := 0; i < len(s); i++ {
+		sum += uint16(s[i])
+	}
+	return fmt.Sprintf("%04X", -sum)
+}
+
+// FormatDate renders a time as a SIP2 date in local time
+func FormatDate(t time.Time) string {
+	return t.Local().Format(dateLayout)
+}
+
+// ParseDate reads a SIP2 date, the time zone is either blank for local
+// time or Z for UTC
+func ParseDate(s string) (time.Time, error) {
+	if len(s) != 18 {
+		return time.Time{}, fmt.Errorf("date %q must have 18 characters", s)
+	}
+
+	location := time.Local
+	if strings.TrimSpace(s[8:12]) == "Z" {
+		location = time.UTC
+	}
+	return time.ParseInLocation("20060102150405", s[:8]+s[12:], location)
+}
+
+// sanitize keeps values from breaking the framing
+func sanitize(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '|' || r == '\r' || r == '\n' {
+			return ' '
+		}
+		return r
+	}, value)
+}
+
+// flag renders a boolean as Y or N
+func flag(b bool) string {
+	if b {
+		return "Y"
+	}
+	return "N"
+}
+
+// bit renders a boolean as 1 or 0
+func bit(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
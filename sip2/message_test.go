@@ -0,0 +1,56 @@
+package sip2
+
+import (
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	m, err := Parse("9300CNkiosk|COsecret|CPbranch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Code != "93" || m.Fixed != "00" {
+		t.Fatalf("got code %q fixed %q", m.Code, m.Fixed)
+	}
+	if got := m.Get("CN"); got != "kiosk" {
+		t.Errorf("CN = %q, want kiosk", got)
+	}
+	if got := m.Get("CP"); got != "branch" {
+		t.Errorf("CP = %q, want branch", got)
+	}
+}
+
+func TestParseTruncatedField(t *testing.T) {
+	for _, line := range []string{"9300|0", "9300CNkiosk||", "9300C|", "9300C"} {
+		if _, err := Parse(line); err == nil {
+			t.Errorf("Parse(%q) accepted a truncated field", line)
+		}
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	m := NewMessage("99", "0 602.00")
+	m.Sequence = 3
+	line := m.Encode()
+
+	if _, err := Parse(line); err != nil {
+		t.Fatalf("Parse(%q): %v", line, err)
+	}
+
+	corrupted := line[:len(line)-1] + "0"
+	if corrupted == line {
+		corrupted = line[:len(line)-1] + "1"
+	}
+	if _, err := Parse(corrupted); err != ErrChecksum {
+		t.Errorf("Parse(%q) = %v, want ErrChecksum", corrupted, err)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{"9300CNkiosk|COsecret|", "9300|0", "990 602.00AY1AZF000", "2300120240101    120000AOlib|AA1|AC|AD|"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		Parse(line)
+	})
+}
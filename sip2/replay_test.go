@@ -0,0 +1,151 @@
+package sip2
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestReplaySessions replays every recorded kiosk session against a server
+// on the seeded library, over a loopback connection
+func TestReplaySessions(t *testing.T) {
+	names, err := filepath.Glob("sessions/*.sip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		t.Fatal("no sessions to replay")
+	}
+
+	for _, name := range names {
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			lib := seededLibrary(t)
+			conn := serve(t, lib.server(t))
+
+			session, err := os.Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer session.Close()
+
+			if err := replay(conn, session); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, loan := range lib.loans {
+				if !loan.Returned {
+					t.Errorf("loan %d of book %d is still open after the session", loan.ID, loan.BookID)
+				}
+			}
+		})
+	}
+}
+
+// serve runs the server on a loopback listener until the test ends and connects to it
+func serve(t *testing.T, s *Server) net.Conn {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, listener) }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return conn
+}
+
+// replay sends a recorded kiosk session and compares the answers. The
+// session has one message per line: "> " lines are sent, "< " lines are
+// the expected response to the message before. In expected lines * matches
+// any run of characters up to the next field delimiter, for dates and
+// checksums. In sent lines {now} is replaced by the current date, and a
+// message ending in AZ gets its checksum filled in.
+// Empty lines and lines starting with # are skipped.
+func replay(conn io.ReadWriter, session io.Reader) error {
+	responses := bufio.NewReader(conn)
+	lines := bufio.NewScanner(session)
+
+	number := 0
+	for lines.Scan() {
+		number++
+		line := strings.TrimRight(lines.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "> "):
+			request := strings.ReplaceAll(line[2:], "{now}", FormatDate(time.Now()))
+			if strings.HasSuffix(request, "AZ") {
+				request += Checksum(request)
+			}
+			if _, err := io.WriteString(conn, request+"\r"); err != nil {
+				return fmt.Errorf("line %d: %v", number, err)
+			}
+
+		case strings.HasPrefix(line, "< "):
+			got, err := responses.ReadString('\r')
+			if err != nil {
+				return fmt.Errorf("line %d: no response: %v", number, err)
+			}
+			got = strings.Trim(got, "\r\n")
+
+			if err := verifyChecksum(got); err != nil {
+				return fmt.Errorf("line %d: %v in %q", number, err, got)
+			}
+			if !pattern(line[2:]).MatchString(got) {
+				return fmt.Errorf("line %d: got %q, want %q", number, got, line[2:])
+			}
+
+		default:
+			return fmt.Errorf("line %d: must start with > or <", number)
+		}
+	}
+
+	return lines.Err()
+}
+
+// pattern turns an expected line into a regular expression
+func pattern(expected string) *regexp.Regexp {
+	parts := strings.Split(expected, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, "[^|]*") + "$")
+}
+
+// verifyChecksum checks the trailing AY and AZ of a response when they are there
+func verifyChecksum(response string) error {
+	i := strings.LastIndex(response, "AZ")
+	if i < 0 || i != len(response)-6 || i < 3 || response[i-3:i-1] != "AY" {
+		return nil
+	}
+	if Checksum(response[:i+2]) != response[i+2:] {
+		return ErrChecksum
+	}
+	return nil
+}
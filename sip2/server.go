@@ -0,0 +1,173 @@
+package sip2
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	pb "go-grpc/pb/library"
+
+	"gorm.io/gorm"
+)
+
+// Server answers SIP2 over TCP, one session per connection. A kiosk logs in
+// with the email and password of an admin account, checkout and renew then
+// run as the patron and checkin as the admin.
+type Server struct {
+	DB        *gorm.DB
+	Borrowing pb.BorrowingServiceServer
+	Returning pb.ReturningServiceServer
+
+	Institution string // AO, the institution id kiosks send
+	Name        string // AM, the library name
+	LoanPeriod  time.Duration
+	Timeout     time.Duration // idle time before a session is dropped
+}
+
+func NewServer(db *gorm.DB, borrowing pb.BorrowingServiceServer, returning pb.ReturningServiceServer) *Server {
+	return &Server{
+		DB:          db,
+		Borrowing:   borrowing,
+		Returning:   returning,
+		Institution: "library",
+		Name:        "Library",
+		LoanPeriod:  14 * 24 * time.Hour,
+		Timeout:     10 * time.Minute,
+	}
+}
+
+// supportedMessages is BX of the status response: patron status, checkout,
+// checkin, SC/ACS status, resend, login, patron information, end session and renew
+const supportedMessages = "YYYNYYYYYNNNNNYN"
+
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve accepts kiosks until the context is done
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(ctx, conn)
+	}
+}
+
+// session is the state of one kiosk connection
+type session struct {
+	ctx  context.Context // carries the logged in admin
+	last string          // the last response, sent again on 97
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	// A bug triggered by one kiosk ends its session, not the server
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("sip2: %v: closing session after panic: %v\n%s", conn.RemoteAddr(), r, debug.Stack())
+		}
+	}()
+
+	sess := &session{}
+	reader := bufio.NewReader(conn)
+
+	for {
+		if s.Timeout > 0 {
+			conn.SetDeadline(time.Now().Add(s.Timeout))
+		}
+
+		line, err := reader.ReadString('\r')
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("sip2: %v: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		// Some kiosks end messages with CR LF
+		line = strings.Trim(line, "\r\n")
+		if line == "" {
+			continue
+		}
+
+		reply, ok := s.reply(ctx, sess, line)
+		if !ok {
+			log.Printf("sip2: %v: closing session, message %.2q sent before login", conn.RemoteAddr(), line)
+			return
+		}
+
+		if _, err := io.WriteString(conn, reply+"\r"); err != nil {
+			log.Printf("sip2: %v: %v", conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
+
+// reply answers one message, it returns false when the session must end
+func (s *Server) reply(ctx context.Context, sess *session, line string) (string, bool) {
+	msg, err := Parse(line)
+	if err != nil {
+		// A corrupted or unknown message is answered with a request to resend
+		if !errors.Is(err, ErrChecksum) {
+			log.Printf("sip2: %v", err)
+		}
+		return "96", true
+	}
+
+	if msg.Code == "97" {
+		return sess.last, true
+	}
+
+	// Only login and status are answered before the kiosk has logged in
+	if sess.ctx == nil && msg.Code != "93" && msg.Code != "99" {
+		return "", false
+	}
+
+	resp := s.handle(ctx, sess, msg)
+	resp.Sequence = msg.Sequence
+
+	sess.last = resp.Encode()
+	return sess.last, true
+}
+
+func (s *Server) handle(ctx context.Context, sess *session, msg *Message) *Message {
+	switch msg.Code {
+	case "93":
+		return s.login(ctx, sess, msg)
+	case "99":
+		return s.status()
+	case "23":
+		return s.patronStatus(msg)
+	case "63":
+		return s.patronInformation(msg)
+	case "11":
+		return s.checkout(sess.ctx, msg)
+	case "09":
+		return s.checkin(sess.ctx, msg)
+	case "29":
+		return s.renew(sess.ctx, msg)
+	default:
+		return s.endSession(msg)
+	}
+}
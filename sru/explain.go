@@ -0,0 +1,70 @@
+package sru
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const namespaceExplain = "http://explain.z3950.org/dtd/2.0/"
+
+// explain describes the server, the searchable indexes and the record schemas
+func (s *Server) explain(req *request) []byte {
+	resp := newResponse(req, "explainResponse")
+	resp.record(namespaceExplain, s.explainRecord(req), 0)
+
+	if req.diagnostic != nil {
+		return resp.diagnostics(req.diagnostic)
+	}
+	return resp.finish()
+}
+
+func (s *Server) explainRecord(req *request) string {
+	var b strings.Builder
+	host, port, path := database(req.baseURL)
+
+	fmt.Fprintf(&b, "<zr:explain xmlns:zr=%q>\n", namespaceExplain)
+	fmt.Fprintf(&b, "  <zr:serverInfo protocol=\"SRU\" version=%q>\n", req.version)
+	fmt.Fprintf(&b, "    <zr:host>%s</zr:host>\n", escape(host))
+	fmt.Fprintf(&b, "    <zr:port>%s</zr:port>\n", escape(port))
+	fmt.Fprintf(&b, "    <zr:database>%s</zr:database>\n", escape(path))
+	b.WriteString("  </zr:serverInfo>\n")
+
+	b.WriteString("  <zr:databaseInfo>\n")
+	fmt.Fprintf(&b, "    <zr:title lang=\"en\" primary=\"true\">%s</zr:title>\n", escape(s.Title))
+	b.WriteString("  </zr:databaseInfo>\n")
+
+	b.WriteString("  <zr:indexInfo>\n")
+	sets := make([]string, 0, len(contextSets))
+	for name := range contextSets {
+		sets = append(sets, name)
+	}
+	sort.Strings(sets)
+	for _, name := range sets {
+		fmt.Fprintf(&b, "    <zr:set name=%q identifier=%q/>\n", name, contextSets[name])
+	}
+	for _, ix := range indexes {
+		b.WriteString("    <zr:index>\n")
+		fmt.Fprintf(&b, "      <zr:title>%s</zr:title>\n", escape(ix.Title))
+		fmt.Fprintf(&b, "      <zr:map><zr:name set=%q>%s</zr:name></zr:map>\n", ix.Set, ix.Name)
+		b.WriteString("    </zr:index>\n")
+	}
+	b.WriteString("  </zr:indexInfo>\n")
+
+	b.WriteString("  <zr:schemaInfo>\n")
+	for _, schema := range recordSchemas {
+		fmt.Fprintf(&b, "    <zr:schema name=%q identifier=%q sort=\"false\" retrieve=\"true\">\n", schema.Name, schema.Identifier)
+		fmt.Fprintf(&b, "      <zr:title>%s</zr:title>\n", schema.Title)
+		b.WriteString("    </zr:schema>\n")
+	}
+	b.WriteString("  </zr:schemaInfo>\n")
+
+	b.WriteString("  <zr:configInfo>\n")
+	fmt.Fprintf(&b, "    <zr:default type=\"numberOfRecords\">%d</zr:default>\n", s.defaultRecords())
+	fmt.Fprintf(&b, "    <zr:setting type=\"maximumRecords\">%d</zr:setting>\n", s.maxRecords())
+	fmt.Fprintf(&b, "    <zr:default type=\"retrieveSchema\">%s</zr:default>\n", recordSchemas[0].Name)
+	b.WriteString("  </zr:configInfo>\n")
+
+	b.WriteString("</zr:explain>\n")
+	return b.String()
+}
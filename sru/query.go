@@ -0,0 +1,286 @@
+package sru
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"go-grpc/catalog"
+	"go-grpc/cql"
+	"go-grpc/helpers"
+)
+
+type indexKind int
+
+const (
+	kindAny indexKind = iota // title, author, subject and ISBN
+	kindText
+	kindISBN
+	kindYear
+	kindAll // cql.allRecords
+)
+
+// index is a CQL index that can be searched, it is listed by explain
+type index struct {
+	Set     string
+	Name    string
+	Title   string
+	Aliases []string // names accepted without a context set
+
+	kind   indexKind
+	column string
+}
+
+// contributorName is the name of any contributor of a book, see catalog.ByContributor
+const contributorName = "ca.name"
+
+var indexes = []index{
+	{Set: "cql", Name: "serverChoice", Title: "Title, author, subject or ISBN", Aliases: []string{"serverchoice", "anywhere", "keyword"}, kind: kindAny},
+	{Set: "cql", Name: "allRecords", Title: "All records", kind: kindAll},
+	{Set: "dc", Name: "title", Title: "Title", Aliases: []string{"title"}, kind: kindText, column: "b.title"},
+	{Set: "dc", Name: "creator", Title: "Author, editor, translator or illustrator", Aliases: []string{"creator", "author", "contributor"}, kind: kindText, column: contributorName},
+	{Set: "dc", Name: "subject", Title: "Subject", Aliases: []string{"subject"}, kind: kindText, column: "c.name"},
+	{Set: "dc", Name: "date", Title: "Publication year", Aliases: []string{"date", "year"}, kind: kindYear, column: "b.publication_year"},
+	{Set: "bath", Name: "isbn", Title: "ISBN", Aliases: []string{"isbn"}, kind: kindISBN},
+}
+
+// contextSets are the sets of the indexes, by short name
+var contextSets = map[string]string{
+	"cql":  "info:srw/cql-context-set/1/cql-v1.2",
+	"dc":   "info:srw/cql-context-set/1/dc-v1.1",
+	"bath": "http://zing.z3950.org/cql/bath/2.0/",
+}
+
+func findIndex(name string) (index, bool) {
+	set, short, qualified := strings.Cut(strings.ToLower(name), ".")
+	for _, ix := range indexes {
+		if qualified {
+			if set == ix.Set && short == strings.ToLower(ix.Name) {
+				return ix, true
+			}
+			continue
+		}
+		for _, alias := range ix.Aliases {
+			if set == alias {
+				return ix, true
+			}
+		}
+	}
+	return index{}, false
+}
+
+// translate turns a parsed query into a condition over the columns of catalog.Query
+func translate(node cql.Node) (string, []interface{}, error) {
+	switch n := node.(type) {
+	case *cql.Boolean:
+		if len(n.Modifiers) > 0 {
+			return "", nil, diagnosticf(46, n.Modifiers[0].Name, "boolean modifiers are not supported")
+		}
+
+		left, leftArgs, err := translate(n.Left)
+		if err != nil {
+			return "", nil, err
+		}
+		right, rightArgs, err := translate(n.Right)
+		if err != nil {
+			return "", nil, err
+		}
+
+		args := append(leftArgs, rightArgs...)
+		switch n.Operator {
+		case "and":
+			return "(" + left + " AND " + right + ")", args, nil
+		case "or":
+			return "(" + left + " OR " + right + ")", args, nil
+		case "not":
+			return "(" + left + " AND NOT " + right + ")", args, nil
+		default:
+			return "", nil, diagnosticf(37, n.Operator, "%s is not supported", n.Operator)
+		}
+
+	case *cql.Clause:
+		return translateClause(n)
+	}
+
+	return "", nil, diagnosticf(1, "", "unexpected query node")
+}
+
+func translateClause(c *cql.Clause) (string, []interface{}, error) {
+	ix, ok := findIndex(c.Index)
+	if !ok {
+		return "", nil, diagnosticf(16, c.Index, "index %s is not supported, see explain", c.Index)
+	}
+	if len(c.Modifiers) > 0 {
+		return "", nil, diagnosticf(20, c.Modifiers[0].Name, "relation modifiers are not supported")
+	}
+
+	switch ix.kind {
+	case kindAll:
+		return "TRUE", nil, nil
+
+	case kindYear:
+		return yearCondition(ix.column, c)
+
+	case kindISBN:
+		return isbnCondition(c)
+
+	case kindText:
+		return textCondition([]string{ix.column}, c)
+
+	default:
+		sql, args, err := textCondition([]string{"b.title", contributorName, "c.name"}, c)
+		if err != nil || c.Relation == "<>" {
+			return sql, args, err
+		}
+		// A term that looks like an ISBN also matches it
+		if isbn := helpers.NormalizeISBN(c.Term); len(isbn) == 10 || len(isbn) == 13 {
+			isbnSQL, isbnArgs, _ := isbnCondition(&cql.Clause{Relation: "=", Term: c.Term})
+			return "(" + sql + " OR " + isbnSQL + ")", append(args, isbnArgs...), nil
+		}
+		return sql, args, nil
+	}
+}
+
+// textCondition matches words of the term in any of the columns:
+// = and all need every word, any one of them, adj the phrase, == the whole
+// value and <> excludes the phrase
+func textCondition(columns []string, c *cql.Clause) (string, []interface{}, error) {
+	var words []string
+	switch c.Relation {
+	case "=", "all", "any":
+		words = strings.Fields(c.Term)
+	case "adj", "==", "exact", "<>":
+		words = []string{c.Term}
+	default:
+		return "", nil, diagnosticf(19, c.Relation, "relation %s is not supported for text", c.Relation)
+	}
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	join := " AND "
+	if c.Relation == "any" {
+		join = " OR "
+	}
+
+	var parts []string
+	var args []interface{}
+	for _, word := range words {
+		pattern, masked := likePattern(word)
+		if c.Relation != "==" && c.Relation != "exact" {
+			pattern = "%" + pattern + "%"
+		}
+
+		var alternatives []string
+		for _, column := range columns {
+			alternatives = append(alternatives, compare(column, c.Relation, masked || pattern != word))
+			args = append(args, pattern)
+		}
+
+		inner := " OR "
+		if c.Relation == "<>" {
+			inner = " AND "
+		}
+		parts = append(parts, "("+strings.Join(alternatives, inner)+")")
+	}
+
+	return "(" + strings.Join(parts, join) + ")", args, nil
+}
+
+// compare is the condition of one column for a word, a book matches a
+// contributor name when any of its contributors does and <> when none does
+func compare(column, relation string, like bool) string {
+	op := " = ?"
+	if like {
+		op = " LIKE ?"
+	}
+
+	if column == contributorName {
+		if relation == "<>" {
+			return "NOT " + catalog.ByContributor(column+" LIKE ?")
+		}
+		return catalog.ByContributor(column + op)
+	}
+
+	if relation == "<>" {
+		return "COALESCE(" + column + ", '') NOT LIKE ?"
+	}
+	return column + op
+}
+
+// likePattern escapes a term for LIKE, the CQL masks * and ? become % and _,
+// masked is true when the term has one
+func likePattern(term string) (string, bool) {
+	var b strings.Builder
+	masked := false
+	for i := 0; i < len(term); i++ {
+		switch c := term[i]; c {
+		case '\\':
+			if i+1 < len(term) {
+				i++
+				writeLiteral(&b, term[i])
+			}
+		case '*':
+			b.WriteByte('%')
+			masked = true
+		case '?':
+			b.WriteByte('_')
+			masked = true
+		case '^':
+			// Anchoring is not supported, the mask is dropped
+		default:
+			writeLiteral(&b, c)
+		}
+	}
+	return b.String(), masked
+}
+
+func writeLiteral(b *strings.Builder, c byte) {
+	if c == '%' || c == '_' || c == '\\' {
+		b.WriteByte('\\')
+	}
+	b.WriteByte(c)
+}
+
+func isbnCondition(c *cql.Clause) (string, []interface{}, error) {
+	switch c.Relation {
+	case "=", "==", "exact", "adj", "all", "any":
+	default:
+		return "", nil, diagnosticf(19, c.Relation, "relation %s is not supported for isbn", c.Relation)
+	}
+
+	isbn := helpers.NormalizeISBN(c.Term)
+	if len(isbn) != 10 && len(isbn) != 13 {
+		return "", nil, diagnosticf(36, c.Term, "%q is not an ISBN", c.Term)
+	}
+	return "(b.isbn IN ?)", []interface{}{[]string{helpers.ISBN13(isbn), helpers.ISBN10(isbn)}}, nil
+}
+
+func yearCondition(column string, c *cql.Clause) (string, []interface{}, error) {
+	year, err := strconv.Atoi(strings.TrimSpace(c.Term))
+	if err != nil {
+		return "", nil, diagnosticf(36, c.Term, "%q is not a year", c.Term)
+	}
+
+	operator := c.Relation
+	switch c.Relation {
+	case "=", "==", "exact":
+		operator = "="
+	case "<", ">", "<=", ">=", "<>":
+	default:
+		return "", nil, diagnosticf(19, c.Relation, "relation %s is not supported for dates", c.Relation)
+	}
+	return "(" + column + " " + operator + " ?)", []interface{}{year}, nil
+}
+
+// parseQuery parses and translates the query parameter
+func parseQuery(query string) (string, []interface{}, error) {
+	node, err := cql.Parse(query)
+	if errors.Is(err, cql.ErrSortBy) {
+		return "", nil, diagnosticf(80, "", "sortBy is not supported")
+	}
+	if err != nil {
+		return "", nil, diagnosticf(10, query, "%v", err)
+	}
+	return translate(node)
+}
@@ -0,0 +1,116 @@
+package sru
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Namespaces of the responses and diagnostics of each version
+var namespaces = map[string]struct{ Prefix, Response, Diagnostic string }{
+	"1.2": {"srw", "http://www.loc.gov/zing/srw/", "http://www.loc.gov/zing/srw/diagnostic/"},
+	"2.0": {"sruResponse", "http://docs.oasis-open.org/ns/search-ws/sruResponse", "http://docs.oasis-open.org/ns/search-ws/diagnostic"},
+}
+
+// response writes the elements of an explainResponse or searchRetrieveResponse in order
+type response struct {
+	req    *request
+	prefix string
+	root   string
+	depth  int
+	b      strings.Builder
+}
+
+func newResponse(req *request, root string) *response {
+	ns := namespaces[req.version]
+	resp := &response{req: req, prefix: ns.Prefix, root: root, depth: 1}
+
+	resp.b.WriteString(xml.Header)
+	fmt.Fprintf(&resp.b, "<%s:%s xmlns:%s=%q>\n", ns.Prefix, root, ns.Prefix, ns.Response)
+	resp.element("version", req.version)
+	return resp
+}
+
+func (resp *response) indent() string {
+	return strings.Repeat("  ", resp.depth)
+}
+
+func (resp *response) element(name, value string) {
+	fmt.Fprintf(&resp.b, "%s<%s:%s>%s</%s:%s>\n", resp.indent(), resp.prefix, name, escape(value), resp.prefix, name)
+}
+
+func (resp *response) open(name string) {
+	fmt.Fprintf(&resp.b, "%s<%s:%s>\n", resp.indent(), resp.prefix, name)
+	resp.depth++
+}
+
+func (resp *response) close(name string) {
+	resp.depth--
+	fmt.Fprintf(&resp.b, "%s</%s:%s>\n", resp.indent(), resp.prefix, name)
+}
+
+// record writes a record, position is 0 for the explain record
+func (resp *response) record(schema, data string, position int) {
+	resp.open("record")
+	resp.element("recordSchema", schema)
+
+	packing := "xml"
+	if resp.req.escaped {
+		packing = "string"
+	}
+	if resp.req.version == "2.0" {
+		resp.element("recordXMLEscaping", packing)
+	} else {
+		resp.element("recordPacking", packing)
+	}
+
+	if resp.req.escaped {
+		resp.element("recordData", data)
+	} else {
+		fmt.Fprintf(&resp.b, "%s<%s:recordData>\n", resp.indent(), resp.prefix)
+		for _, line := range strings.Split(strings.TrimRight(data, "\n"), "\n") {
+			fmt.Fprintf(&resp.b, "%s  %s\n", resp.indent(), line)
+		}
+		fmt.Fprintf(&resp.b, "%s</%s:recordData>\n", resp.indent(), resp.prefix)
+	}
+
+	if position > 0 {
+		resp.element("recordPosition", fmt.Sprint(position))
+	}
+	resp.close("record")
+}
+
+// fail ends a response with a fatal diagnostic, a search reports no records
+func (resp *response) fail(d *diagnostic) []byte {
+	if resp.root == "searchRetrieveResponse" {
+		resp.element("numberOfRecords", "0")
+	}
+	return resp.diagnostics(d)
+}
+
+func (resp *response) diagnostics(d *diagnostic) []byte {
+	ns := namespaces[resp.req.version].Diagnostic
+
+	resp.open("diagnostics")
+	fmt.Fprintf(&resp.b, "%s<diag:diagnostic xmlns:diag=%q>\n", resp.indent(), ns)
+	fmt.Fprintf(&resp.b, "%s  <diag:uri>info:srw/diagnostic/1/%d</diag:uri>\n", resp.indent(), d.Code)
+	if d.Details != "" {
+		fmt.Fprintf(&resp.b, "%s  <diag:details>%s</diag:details>\n", resp.indent(), escape(d.Details))
+	}
+	fmt.Fprintf(&resp.b, "%s  <diag:message>%s</diag:message>\n", resp.indent(), escape(d.Message))
+	fmt.Fprintf(&resp.b, "%s</diag:diagnostic>\n", resp.indent())
+	resp.close("diagnostics")
+
+	return resp.finish()
+}
+
+func (resp *response) finish() []byte {
+	fmt.Fprintf(&resp.b, "</%s:%s>\n", resp.prefix, resp.root)
+	return []byte(resp.b.String())
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,315 @@
+// Package sru is an SRU 1.2 and 2.0 endpoint over the catalog, so partner
+// libraries can run federated searches. It implements explain and
+// searchRetrieve, queries are CQL and records are Dublin Core or MARCXML.
+package sru
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-grpc/catalog"
+	"go-grpc/citation"
+	"go-grpc/marc"
+	pb "go-grpc/pb/library"
+
+	"gorm.io/gorm"
+)
+
+// Server answers SRU requests over GET and POST
+type Server struct {
+	DB *gorm.DB
+
+	Title          string // database title shown by explain
+	BaseURL        string // taken from the request when empty
+	DefaultRecords int
+	MaxRecords     int
+}
+
+// recordSchema is a format records can be retrieved in
+type recordSchema struct {
+	Name       string
+	Identifier string
+	Title      string
+}
+
+var recordSchemas = []recordSchema{
+	{"dc", "info:srw/schema/1/dc-v1.1", "Dublin Core"},
+	{"marcxml", "info:srw/schema/1/marcxml-v1.1", "MARCXML"},
+}
+
+func findSchema(name string) (recordSchema, bool) {
+	if name == "" {
+		return recordSchemas[0], true
+	}
+	for _, s := range recordSchemas {
+		if name == s.Name || name == s.Identifier {
+			return s, true
+		}
+	}
+	return recordSchema{}, false
+}
+
+// diagnostic is an SRU diagnostic, info:srw/diagnostic/1/<Code>
+type diagnostic struct {
+	Code    int
+	Details string
+	Message string
+}
+
+func (d *diagnostic) Error() string {
+	return fmt.Sprintf("diagnostic %d: %s", d.Code, d.Message)
+}
+
+func diagnosticf(code int, details, format string, args ...interface{}) *diagnostic {
+	return &diagnostic{Code: code, Details: details, Message: fmt.Sprintf(format, args...)}
+}
+
+// request is a parsed SRU request
+type request struct {
+	version    string // 1.2 or 2.0, responses use the namespaces of the version
+	operation  string
+	query      string
+	start      int
+	maximum    int
+	schema     recordSchema
+	escaped    bool // records are sent as escaped strings instead of XML
+	baseURL    string
+	diagnostic *diagnostic
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := s.parse(r.Form)
+	req.baseURL = s.baseURL(r)
+
+	var body []byte
+	var err error
+	if req.operation == "explain" {
+		body = s.explain(req)
+	} else {
+		body, err = s.searchRetrieve(req)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write(body)
+}
+
+// parse reads the parameters, a problem is kept as the diagnostic of the response
+func (s *Server) parse(form url.Values) *request {
+	req := &request{start: 1, maximum: s.defaultRecords(), schema: recordSchemas[0]}
+
+	// 2.0 has no version parameter, a request without version and operation is 2.0
+	switch version := form.Get("version"); version {
+	case "1.1", "1.2":
+		req.version = "1.2"
+	case "2.0":
+		req.version = "2.0"
+	case "":
+		req.version = "1.2"
+		if !form.Has("operation") {
+			req.version = "2.0"
+		}
+	default:
+		req.version = "1.2"
+		req.operation = "explain"
+		req.diagnostic = diagnosticf(5, "2.0", "version %s is not supported", version)
+		return req
+	}
+
+	fail := func(d *diagnostic) *request {
+		req.diagnostic = d
+		return req
+	}
+
+	req.operation = form.Get("operation")
+	req.query = form.Get("query")
+	if req.operation == "" {
+		req.operation = "explain"
+		if req.query != "" {
+			req.operation = "searchRetrieve"
+		}
+	}
+	switch req.operation {
+	case "explain":
+		return req
+	case "searchRetrieve":
+	default:
+		return fail(diagnosticf(4, req.operation, "operation %s is not supported", req.operation))
+	}
+
+	if req.query == "" {
+		return fail(diagnosticf(7, "query", "query is required"))
+	}
+	if queryType := form.Get("queryType"); queryType != "" && queryType != "cql" {
+		return fail(diagnosticf(6, "queryType", "only cql queries are supported"))
+	}
+
+	if value := form.Get("startRecord"); value != "" {
+		start, err := strconv.Atoi(value)
+		if err != nil || start < 1 {
+			return fail(diagnosticf(6, "startRecord", "startRecord must be a positive number"))
+		}
+		req.start = start
+	}
+	if value := form.Get("maximumRecords"); value != "" {
+		maximum, err := strconv.Atoi(value)
+		if err != nil || maximum < 0 {
+			return fail(diagnosticf(6, "maximumRecords", "maximumRecords must be a number"))
+		}
+		req.maximum = min(maximum, s.maxRecords())
+	}
+
+	schema, ok := findSchema(form.Get("recordSchema"))
+	if !ok {
+		return fail(diagnosticf(66, form.Get("recordSchema"), "record schema %s is not supported, see explain", form.Get("recordSchema")))
+	}
+	req.schema = schema
+
+	// Escaping is recordPacking in 1.2 and recordXMLEscaping in 2.0, where recordPacking means packed or unpacked
+	escaping := form.Get("recordPacking")
+	if req.version == "2.0" {
+		if packing := form.Get("recordPacking"); packing != "" && packing != "packed" {
+			return fail(diagnosticf(6, "recordPacking", "only packed records are supported"))
+		}
+		escaping = form.Get("recordXMLEscaping")
+	}
+	switch escaping {
+	case "", "xml":
+	case "string":
+		req.escaped = true
+	default:
+		return fail(diagnosticf(71, escaping, "record packing %s is not supported", escaping))
+	}
+
+	return req
+}
+
+func (s *Server) searchRetrieve(req *request) ([]byte, error) {
+	resp := newResponse(req, "searchRetrieveResponse")
+	if req.diagnostic != nil {
+		return resp.fail(req.diagnostic), nil
+	}
+
+	where, args, err := parseQuery(req.query)
+	if d, ok := err.(*diagnostic); ok {
+		return resp.fail(d), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	filter := catalog.Filter{Where: where, Args: args}
+	total, err := catalog.Count(s.DB, filter)
+	if err != nil {
+		return nil, err
+	}
+	resp.element("numberOfRecords", strconv.FormatInt(total, 10))
+
+	if total > 0 && int64(req.start) > total {
+		return resp.diagnostics(diagnosticf(61, strconv.Itoa(req.start), "there are only %d records", total)), nil
+	}
+	if req.maximum == 0 || total == 0 {
+		return resp.finish(), nil
+	}
+
+	filter.Limit, filter.Offset = req.maximum, req.start-1
+	books, err := catalog.Books(s.DB, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int32, len(books))
+	for i, b := range books {
+		ids[i] = b.Id
+	}
+	updated, err := catalog.Updated(s.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.open("records")
+	for i, book := range books {
+		data, err := recordData(req.schema, book, updated[book.Id])
+		if err != nil {
+			return nil, err
+		}
+		resp.record(req.schema.Identifier, data, req.start+i)
+	}
+	resp.close("records")
+
+	if next := req.start + len(books); int64(next) <= total {
+		resp.element("nextRecordPosition", strconv.Itoa(next))
+	}
+
+	return resp.finish(), nil
+}
+
+// recordData renders a book in the schema, the record declares its own namespaces
+func recordData(schema recordSchema, book *pb.Book, updated time.Time) (string, error) {
+	if schema.Name == "marcxml" {
+		raw, err := marc.MarshalRecord(marc.FromBook(book, updated), "", "  ")
+		return string(raw), err
+	}
+	return citation.SRWDublinCoreRecord(citation.FromBook(book), ""), nil
+}
+
+func (s *Server) baseURL(r *http.Request) string {
+	if s.BaseURL != "" {
+		return s.BaseURL
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+func (s *Server) defaultRecords() int {
+	if s.DefaultRecords > 0 {
+		return s.DefaultRecords
+	}
+	return 10
+}
+
+func (s *Server) maxRecords() int {
+	if s.MaxRecords > 0 {
+		return s.MaxRecords
+	}
+	return 100
+}
+
+// database is the path of the base URL, the database name explain reports
+func database(baseURL string) (host, port, path string) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return baseURL, "80", ""
+	}
+
+	port = u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return u.Hostname(), port, strings.TrimPrefix(u.Path, "/")
+}
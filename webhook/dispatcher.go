@@ -0,0 +1,308 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-grpc/helpers"
+	"go-grpc/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+// Payload is the JSON body posted to endpoints
+type Payload struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt string          `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Dispatcher moves outbox events to webhook endpoints. Failed deliveries are
+// retried with exponential backoff and dead-lettered after MaxAttempts.
+type Dispatcher struct {
+	DB          *gorm.DB
+	Client      *http.Client
+	MaxAttempts int32
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	BatchSize   int
+}
+
+func NewDispatcher(db *gorm.DB) *Dispatcher {
+	return &Dispatcher{
+		DB:          db,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts: 8,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  6 * time.Hour,
+		BatchSize:   50,
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.FanOut(); err != nil {
+			log.Printf("webhook dispatcher: %v", err)
+		}
+		if err := d.DeliverDue(ctx); err != nil {
+			log.Printf("webhook dispatcher: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// FanOut creates one delivery per subscribed endpoint for undispatched outbox events
+func (d *Dispatcher) FanOut() error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		var events []model.OutboxEvent
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL").
+			Order("id").
+			Limit(d.BatchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		var endpoints []model.WebhookEndpoint
+		if err := tx.Where("active = ?", true).Find(&endpoints).Error; err != nil {
+			return err
+		}
+
+		now := time.Now().Format(helpers.DateTimeLayout)
+
+		for _, delivery := range deliveries(events, endpoints, now) {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&delivery).Error; err != nil {
+				return err
+			}
+		}
+
+		ids := make([]int64, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+		return tx.Model(&model.OutboxEvent{}).Where("id IN ?", ids).Update("dispatched_at", now).Error
+	})
+}
+
+// deliveries pairs every event with the endpoints subscribed to its type, due right away
+func deliveries(events []model.OutboxEvent, endpoints []model.WebhookEndpoint, now string) []model.WebhookDelivery {
+	var result []model.WebhookDelivery
+	for _, event := range events {
+		for _, endpoint := range endpoints {
+			if !Subscribed(endpoint, event.EventType) {
+				continue
+			}
+
+			result = append(result, model.WebhookDelivery{
+				OutboxEventID: event.ID,
+				EndpointID:    endpoint.ID,
+				Status:        StatusPending,
+				NextAttemptAt: sql.NullString{String: now, Valid: true},
+				CreatedAt:     now,
+			})
+		}
+	}
+	return result
+}
+
+// DeliverDue posts pending deliveries whose next attempt is due
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	deliveries, err := d.claim()
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return nil
+		}
+		d.deliver(ctx, delivery)
+	}
+
+	return nil
+}
+
+// claim leases due deliveries by pushing their next attempt forward, so other
+// dispatcher instances skip them while the HTTP calls run outside the transaction
+func (d *Dispatcher) claim() ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", StatusPending, now.Format(helpers.DateTimeLayout)).
+			Order("next_attempt_at").
+			Limit(d.BatchSize).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+
+		lease := now.Add(2 * d.Client.Timeout).Format(helpers.DateTimeLayout)
+		return tx.Model(&model.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", lease).Error
+	})
+
+	return deliveries, err
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery model.WebhookDelivery) {
+	var endpoint model.WebhookEndpoint
+	var event model.OutboxEvent
+
+	if err := d.DB.First(&endpoint, delivery.EndpointID).Error; err != nil {
+		log.Printf("webhook dispatcher: delivery %d: %v", delivery.ID, err)
+		return
+	}
+
+	if err := d.DB.First(&event, delivery.OutboxEventID).Error; err != nil {
+		log.Printf("webhook dispatcher: delivery %d: %v", delivery.ID, err)
+		return
+	}
+
+	statusCode, err := d.Post(ctx, endpoint, event, delivery.ID)
+	updates := d.outcome(delivery.Attempts+1, statusCode, err, time.Now())
+
+	if err := d.DB.Model(&model.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+		log.Printf("webhook dispatcher: delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// outcome is the update of a delivery after an attempt: delivered, retried
+// after the backoff, or dead-lettered once MaxAttempts are used up
+func (d *Dispatcher) outcome(attempts int32, statusCode int, err error, now time.Time) map[string]interface{} {
+	updates := map[string]interface{}{
+		"attempts": attempts,
+	}
+
+	if statusCode > 0 {
+		updates["response_status"] = statusCode
+	}
+
+	switch {
+	case err == nil:
+		updates["status"] = StatusDelivered
+		updates["delivered_at"] = now.Format(helpers.DateTimeLayout)
+		updates["last_error"] = nil
+	case attempts >= d.MaxAttempts:
+		updates["status"] = StatusDead
+		updates["last_error"] = truncate(err.Error(), 1000)
+	default:
+		updates["last_error"] = truncate(err.Error(), 1000)
+		updates["next_attempt_at"] = now.Add(d.Backoff(attempts)).Format(helpers.DateTimeLayout)
+	}
+
+	return updates
+}
+
+// Backoff returns the wait before the next attempt: BaseBackoff * 2^(attempts-1), capped at MaxBackoff
+func (d *Dispatcher) Backoff(attempts int32) time.Duration {
+	wait := d.BaseBackoff
+	for i := int32(1); i < attempts; i++ {
+		wait *= 2
+		if wait >= d.MaxBackoff {
+			return d.MaxBackoff
+		}
+	}
+	return wait
+}
+
+// Post sends a single signed event to an endpoint. Any non-2xx response is an error.
+func (d *Dispatcher) Post(ctx context.Context, endpoint model.WebhookEndpoint, event model.OutboxEvent, deliveryID int64) (int, error) {
+	body, err := json.Marshal(Payload{
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt,
+		Data:      json.RawMessage(event.Payload),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "library-management-webhook/1.0")
+	req.Header.Set("X-Library-Event", event.EventType)
+	req.Header.Set("X-Library-Delivery", strconv.FormatInt(deliveryID, 10))
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, time.Now().Unix(), body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %v", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Subscribed reports whether an endpoint wants events of the given type
+func Subscribed(endpoint model.WebhookEndpoint, eventType string) bool {
+	if strings.TrimSpace(endpoint.EventTypes) == "" {
+		return true
+	}
+
+	for _, t := range strings.Split(endpoint.EventTypes, ",") {
+		if t = strings.TrimSpace(t); t == eventType || t == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
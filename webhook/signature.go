@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries "t=<unix timestamp>,v1=<hex hmac-sha256>". The MAC is
+// computed over "<timestamp>.<body>" with the endpoint secret, so receivers can
+// reject both tampered and replayed requests.
+const SignatureHeader = "X-Library-Signature"
+
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Verify checks a signature header, tolerance bounds the age of the timestamp
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var timestamp int64
+	var signature string
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid signature timestamp")
+			}
+			timestamp = t
+		case "v1":
+			signature = value
+		}
+	}
+
+	if timestamp == 0 || signature == "" {
+		return fmt.Errorf("malformed signature header")
+	}
+
+	if age := time.Since(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("signature timestamp outside tolerance")
+	}
+
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(fmt.Sprintf("t=%d,v1=%s", timestamp, signature))) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	return nil
+}
+
+// NewSecret generates the signing secret for a new endpoint
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
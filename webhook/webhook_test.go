@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-grpc/model"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	now := time.Now().Unix()
+	header := Sign("whsec_test", now, body)
+
+	if err := Verify("whsec_test", header, body, 5*time.Minute); err != nil {
+		t.Fatalf("Verify of a fresh signature: %v", err)
+	}
+
+	for name, check := range map[string]func() error{
+		"tampered body": func() error { return Verify("whsec_test", header, []byte(`{"id":2}`), 5*time.Minute) },
+		"other secret":  func() error { return Verify("whsec_other", header, body, 5*time.Minute) },
+		"replayed": func() error {
+			old := now - int64(10*time.Minute/time.Second)
+			return Verify("whsec_test", Sign("whsec_test", old, body), body, 5*time.Minute)
+		},
+		"malformed": func() error { return Verify("whsec_test", "v1=abc", body, 5*time.Minute) },
+	} {
+		if check() == nil {
+			t.Errorf("%s: Verify accepted the signature", name)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{BaseBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+
+	for attempts, want := range map[int32]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		4:  4 * time.Minute,
+		5:  5 * time.Minute,
+		40: 5 * time.Minute,
+	} {
+		if got := d.Backoff(attempts); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestPostSignsEvent(t *testing.T) {
+	const secret = "whsec_test"
+	endpoint := model.WebhookEndpoint{ID: 3, Secret: secret}
+	event := model.OutboxEvent{ID: 42, EventType: "loan.returned", CreatedAt: "2024-05-01 10:00:00", Payload: `{"fine_amount":"3000.00"}`}
+
+	var got Payload
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify(secret, r.Header.Get(SignatureHeader), body, time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Library-Event") != event.EventType || r.Header.Get("X-Library-Delivery") != "7" {
+			http.Error(w, "missing headers", http.StatusBadRequest)
+			return
+		}
+		json.Unmarshal(body, &got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	endpoint.URL = receiver.URL
+
+	d := NewDispatcher(nil)
+	code, err := d.Post(context.Background(), endpoint, event, 7)
+	if err != nil {
+		t.Fatalf("Post = %d, %v", code, err)
+	}
+	if got.ID != 42 || got.Type != event.EventType || string(got.Data) != event.Payload {
+		t.Errorf("receiver got %+v", got)
+	}
+
+	// A receiver with another secret rejects the delivery, which is an error
+	endpoint.Secret = "whsec_other"
+	if code, err := d.Post(context.Background(), endpoint, event, 7); err == nil || code != http.StatusUnauthorized {
+		t.Errorf("Post with a wrong secret = %d, %v", code, err)
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	d := &Dispatcher{MaxAttempts: 3, BaseBackoff: time.Minute, MaxBackoff: time.Hour}
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+	failure := errors.New("endpoint responded with 500 Internal Server Error")
+
+	delivered := d.outcome(1, 200, nil, now)
+	if delivered["status"] != StatusDelivered || delivered["delivered_at"] != "2024-05-01 10:00:00" {
+		t.Errorf("successful attempt: %v", delivered)
+	}
+
+	retried := d.outcome(2, 500, failure, now)
+	if _, ok := retried["status"]; ok || retried["next_attempt_at"] != "2024-05-01 10:02:00" {
+		t.Errorf("second failed attempt: %v", retried)
+	}
+
+	dead := d.outcome(3, 500, failure, now)
+	if dead["status"] != StatusDead || dead["last_error"] != failure.Error() {
+		t.Errorf("last failed attempt: %v", dead)
+	}
+	if _, ok := dead["next_attempt_at"]; ok {
+		t.Errorf("dead delivery is scheduled again: %v", dead)
+	}
+}
+
+func TestDeliveries(t *testing.T) {
+	events := []model.OutboxEvent{{ID: 1, EventType: "loan.returned"}, {ID: 2, EventType: "fine.assessed"}}
+	endpoints := []model.WebhookEndpoint{
+		{ID: 10},
+		{ID: 11, EventTypes: "fine.assessed, fine.paid"},
+		{ID: 12, EventTypes: "book.created"},
+	}
+
+	got := deliveries(events, endpoints, "2024-05-01 10:00:00")
+
+	want := [][2]int64{{1, 10}, {2, 10}, {2, 11}}
+	if len(got) != len(want) {
+		t.Fatalf("%d deliveries, want %d: %+v", len(got), len(want), got)
+	}
+	for i, delivery := range got {
+		if delivery.OutboxEventID != want[i][0] || int64(delivery.EndpointID) != want[i][1] {
+			t.Errorf("delivery %d is event %d to endpoint %d, want %v", i, delivery.OutboxEventID, delivery.EndpointID, want[i])
+		}
+		if delivery.Status != StatusPending || delivery.NextAttemptAt.String != "2024-05-01 10:00:00" {
+			t.Errorf("delivery %d is not due right away: %+v", i, delivery)
+		}
+	}
+}